@@ -0,0 +1,36 @@
+// Package db provides database maintenance commands
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates the db command and its subcommands
+func Command(settings *conf.Settings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance commands",
+	}
+
+	cmd.AddCommand(migrateCommand(settings))
+	cmd.AddCommand(schemaCommand(settings))
+
+	return cmd
+}
+
+// confirm prompts the user for a yes/no answer
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
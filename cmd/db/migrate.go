@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// migrateOptions holds the flags of the migrate command
+type migrateOptions struct {
+	from       string
+	to         string
+	batchSize  int
+	skipVerify bool
+	keepState  bool
+	assumeYes  bool
+}
+
+// migrateCommand creates the db migrate command
+func migrateCommand(settings *conf.Settings) *cobra.Command {
+	var opts migrateOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate --from <engine> --to <engine>",
+		Short: "Copy all data from one database engine to another",
+		Long: `Copy detections, results, reviews, comments, locks, daily events, hourly weather,
+image cache and sound levels from one database engine to another. Engines are sqlite,
+mysql and postgresql, both are configured in the output section of the configuration
+file whether enabled or not.
+
+Rows are copied in batches and get new IDs in the target database. The target must be
+empty, an interrupted migration continues where it stopped when the same command is
+run again. Row counts and checksums of every table are compared at the end.
+Stop any running BirdNET-Go instance before migrating.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runMigrate(ctx, settings, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Source database engine: sqlite, mysql or postgresql")
+	cmd.Flags().StringVar(&opts.to, "to", "", "Target database engine: sqlite, mysql or postgresql")
+	cmd.Flags().IntVar(&opts.batchSize, "batch-size", datastore.DefaultTransferBatchSize, "Rows copied per transaction")
+	cmd.Flags().BoolVar(&opts.skipVerify, "skip-verify", false, "Skip the row count and checksum verification")
+	cmd.Flags().BoolVar(&opts.keepState, "keep-state", false, "Keep the migration state tables in the target database")
+	cmd.Flags().BoolVarP(&opts.assumeYes, "yes", "y", false, "Do not ask for confirmation")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// runMigrate opens both databases, copies the data and verifies the result
+func runMigrate(ctx context.Context, settings *conf.Settings, opts *migrateOptions) error {
+	if strings.EqualFold(opts.from, opts.to) {
+		return fmt.Errorf("source and target engine must differ")
+	}
+
+	src, err := datastore.NewForEngine(settings, opts.from)
+	if err != nil {
+		return err
+	}
+	dst, err := datastore.NewForEngine(settings, opts.to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrating data from %s to %s\n", describeEngine(settings, opts.from), describeEngine(settings, opts.to))
+	fmt.Println("   Make sure BirdNET-Go is not running before continuing.")
+	if !opts.assumeYes && !confirm("Continue?") {
+		fmt.Println("Migration canceled")
+		return nil
+	}
+
+	if err := src.Open(); err != nil {
+		return fmt.Errorf("failed to open source database: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	// Opening the target creates the schema
+	if err := dst.Open(); err != nil {
+		return fmt.Errorf("failed to open target database: %w", err)
+	}
+	defer func() { _ = dst.Close() }()
+
+	transfer, err := datastore.NewDatabaseTransfer(src, dst, datastore.TransferOptions{
+		BatchSize: opts.batchSize,
+		Progress:  printProgress,
+	})
+	if err != nil {
+		return err
+	}
+	if transfer.Resumable() {
+		fmt.Println("Resuming interrupted migration")
+	}
+
+	start := time.Now()
+	results, err := transfer.Run(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Println("\n⚠️ Migration interrupted, run the same command again to resume")
+		}
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	for i := range results {
+		if results[i].Skipped > 0 {
+			fmt.Printf("⚠️ Skipped %d orphaned rows in %s\n", results[i].Skipped, results[i].Table)
+		}
+	}
+	fmt.Printf("✅ Copied all tables in %s\n", time.Since(start).Round(time.Second))
+
+	if opts.skipVerify {
+		fmt.Println("Verification skipped, the migration state is kept in the target database")
+		return nil
+	}
+
+	fmt.Println("Verifying row counts and checksums")
+	verified, err := transfer.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	failed := 0
+	fmt.Printf("%-16s  %10s  %10s  %s\n", "Table", "Source", "Target", "Result")
+	for i := range verified {
+		r := &verified[i]
+		status := "ok"
+		if !r.Verified() {
+			status = "MISMATCH"
+			failed++
+		}
+		fmt.Printf("%-16s  %10d  %10d  %s\n", r.Table, r.SourceRows, r.TargetRows, status)
+	}
+	if failed > 0 {
+		return fmt.Errorf("verification failed for %d tables, the migration state is kept in the target database", failed)
+	}
+
+	if !opts.keepState {
+		if err := transfer.Cleanup(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Migration verified. Enable output.%s and disable output.%s in the configuration to switch databases.\n",
+		strings.ToLower(opts.to), strings.ToLower(opts.from))
+	return nil
+}
+
+// printProgress prints the progress of the table being copied on a single line
+func printProgress(table string, copied, skipped, total int64) {
+	done := copied + skipped
+	if done >= total {
+		fmt.Printf("\r%-16s %d/%d rows\n", table, done, total)
+		return
+	}
+	fmt.Printf("\r%-16s %d/%d rows", table, done, total)
+}
+
+// describeEngine returns a short description of the database of an engine
+func describeEngine(settings *conf.Settings, engine string) string {
+	switch strings.ToLower(engine) {
+	case datastore.EngineSQLite:
+		return fmt.Sprintf("SQLite (%s)", settings.Output.SQLite.Path)
+	case datastore.EngineMySQL:
+		return fmt.Sprintf("MySQL (%s@%s/%s)", settings.Output.MySQL.Username, settings.Output.MySQL.Host, settings.Output.MySQL.Database)
+	case datastore.EnginePostgreSQL:
+		return fmt.Sprintf("PostgreSQL (%s@%s/%s)", settings.Output.PostgreSQL.Username, settings.Output.PostgreSQL.Host, settings.Output.PostgreSQL.Database)
+	default:
+		return engine
+	}
+}
@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// schemaCommand creates the db schema command and its subcommands
+func schemaCommand(settings *conf.Settings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Show and change the database schema version",
+		Long: `Versioned schema migrations are applied automatically when BirdNET-Go opens the
+database. SQLite databases are backed up next to the database file before a migration
+changes them. These commands show the applied migrations, preview pending ones and
+revert migrations when downgrading.`,
+	}
+
+	cmd.AddCommand(schemaStatusCommand(settings))
+	cmd.AddCommand(schemaUpCommand(settings))
+	cmd.AddCommand(schemaDownCommand(settings))
+
+	return cmd
+}
+
+// schemaStatusCommand creates the db schema status command
+func schemaStatusCommand(settings *conf.Settings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List schema migrations and whether they are applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSchemaMigrator(settings, func(m *datastore.SchemaMigrator) error {
+				return printSchemaStatus(cmd.Context(), m)
+			})
+		},
+	}
+}
+
+// schemaUpCommand creates the db schema up command
+func schemaUpCommand(settings *conf.Settings) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending schema migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRun {
+				return withSchemaMigrator(settings, func(m *datastore.SchemaMigrator) error {
+					pending, err := m.Up(cmd.Context(), datastore.SchemaMigrationOptions{DryRun: true})
+					if err != nil {
+						return err
+					}
+					printMigrationPlan("apply", pending)
+					return nil
+				})
+			}
+
+			// Opening the datastore creates the tables and applies pending migrations
+			store := datastore.New(settings)
+			if store == nil {
+				return fmt.Errorf("no database is enabled in the output settings")
+			}
+			if err := store.Open(); err != nil {
+				return fmt.Errorf("failed to migrate database: %w", err)
+			}
+			if err := store.Close(); err != nil {
+				return err
+			}
+
+			return withSchemaMigrator(settings, func(m *datastore.SchemaMigrator) error {
+				version, err := m.CurrentVersion(cmd.Context())
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ Database schema is at version %d\n", version)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List pending migrations without applying them")
+
+	return cmd
+}
+
+// schemaDownCommand creates the db schema down command
+func schemaDownCommand(settings *conf.Settings) *cobra.Command {
+	var (
+		target     int
+		dryRun     bool
+		skipBackup bool
+		assumeYes  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "down --to <version>",
+		Short: "Revert schema migrations above a version",
+		Long: `Revert the applied schema migrations above the given version, newest first, before
+downgrading BirdNET-Go. Nothing is reverted when one of the migrations cannot be reverted.
+Stop any running BirdNET-Go instance first, it applies pending migrations on startup.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSchemaMigrator(settings, func(m *datastore.SchemaMigrator) error {
+				ctx := cmd.Context()
+				plan, err := m.Down(ctx, target, datastore.SchemaMigrationOptions{DryRun: true})
+				if err != nil {
+					return err
+				}
+				printMigrationPlan("revert", plan)
+				if dryRun || len(plan) == 0 {
+					return nil
+				}
+				if !assumeYes && !confirm("Continue?") {
+					fmt.Println("Revert canceled")
+					return nil
+				}
+
+				reverted, err := m.Down(ctx, target, datastore.SchemaMigrationOptions{SkipBackup: skipBackup})
+				if err != nil {
+					return fmt.Errorf("reverted %d migrations before failing: %w", len(reverted), err)
+				}
+				fmt.Printf("✅ Reverted %d migrations, database schema is at version %d\n", len(reverted), target)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&target, "to", 0, "Schema version to revert to")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the migrations that would be reverted")
+	cmd.Flags().BoolVar(&skipBackup, "skip-backup", false, "Do not back up the database before reverting")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// withSchemaMigrator runs fn with a migrator for the configured database
+func withSchemaMigrator(settings *conf.Settings, fn func(m *datastore.SchemaMigrator) error) error {
+	m, err := datastore.OpenSchemaMigrator(settings)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = m.Close() }()
+	return fn(m)
+}
+
+// printSchemaStatus prints every known migration and the current schema version
+func printSchemaStatus(ctx context.Context, m *datastore.SchemaMigrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Schema version %d, latest %d\n", version, m.LatestVersion())
+	fmt.Printf("%-8s  %-40s  %-20s  %s\n", "Version", "Name", "Applied", "Reversible")
+	for i := range status {
+		s := &status[i]
+		applied := "pending"
+		if s.Applied {
+			applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-8d  %-40s  %-20s  %v\n", s.Version, s.Name, applied, s.Reversible)
+	}
+	return nil
+}
+
+// printMigrationPlan lists the migrations an operation would run
+func printMigrationPlan(action string, migrations []datastore.SchemaMigration) {
+	if len(migrations) == 0 {
+		fmt.Printf("No migrations to %s\n", action)
+		return
+	}
+	fmt.Printf("Migrations to %s:\n", action)
+	for _, m := range migrations {
+		fmt.Printf("  %d  %s\n", m.Version, m.Name)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,8 +19,8 @@ import (
 func Command(settings *conf.Settings) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "directory [path]",
-		Short: "Analyze all *.wav files in a directory",
-		Long:  "Provide a directory path to analyze all *.wav files within it.",
+		Short: "Analyze all audio files in a directory",
+		Long:  "Provide a directory path to analyze all WAV, FLAC, MP3, OGG, Opus and M4A files within it.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Create a context that can be cancelled
@@ -75,6 +76,11 @@ func setupFlags(cmd *cobra.Command, settings *conf.Settings) error {
 	cmd.Flags().BoolVarP(&settings.Input.Watch, "watch", "w", false, "Watch directory for new files")
 	cmd.Flags().StringVarP(&settings.Output.File.Path, "output", "o", viper.GetString("output.file.path"), "Path to output directory")
 	cmd.Flags().StringVar(&settings.Output.File.Type, "type", viper.GetString("output.file.type"), "Output type: table, csv")
+	cmd.Flags().BoolVar(&settings.Input.Database, "database", false, "Save detections to the configured database, timed from the recording metadata or file name")
+	cmd.Flags().StringVar(&settings.Input.Timezone, "timezone", "", "Time zone of timestamps in file names, such as Local or Europe/Helsinki (default UTC for AudioMoth names, local time otherwise)")
+	cmd.Flags().BoolVar(&settings.Input.Clips, "clips", false, "Export an audio clip of each detection to the clip export path in the export format")
+	cmd.Flags().DurationVar(&settings.Input.ClipPrePadding, "clip-pre", 3*time.Second, "Audio included in clips before a detection")
+	cmd.Flags().DurationVar(&settings.Input.ClipPostPadding, "clip-post", 3*time.Second, "Audio included in clips after a detection")
 
 	if err := viper.BindPFlags(cmd.Flags()); err != nil {
 		return fmt.Errorf("error binding flags: %w", err)
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -67,6 +68,11 @@ func setupFlags(cmd *cobra.Command, settings *conf.Settings) error {
 
 	cmd.Flags().StringVarP(&settings.Output.File.Path, "output", "o", viper.GetString("output.file.path"), "Path to output directory")
 	cmd.Flags().StringVar(&settings.Output.File.Type, "type", viper.GetString("output.file.type"), "Output type: table, csv")
+	cmd.Flags().BoolVar(&settings.Input.Database, "database", false, "Save detections to the configured database, timed from the recording metadata or file name")
+	cmd.Flags().StringVar(&settings.Input.Timezone, "timezone", "", "Time zone of timestamps in file names, such as Local or Europe/Helsinki (default UTC for AudioMoth names, local time otherwise)")
+	cmd.Flags().BoolVar(&settings.Input.Clips, "clips", false, "Export an audio clip of each detection to the clip export path in the export format")
+	cmd.Flags().DurationVar(&settings.Input.ClipPrePadding, "clip-pre", 3*time.Second, "Audio included in clips before a detection")
+	cmd.Flags().DurationVar(&settings.Input.ClipPostPadding, "clip-post", 3*time.Second, "Audio included in clips after a detection")
 
 	if err := viper.BindPFlags(cmd.Flags()); err != nil {
 		return fmt.Errorf("error binding flags: %w", err)
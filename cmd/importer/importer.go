@@ -0,0 +1,193 @@
+// Package importer provides the import command for detections from other BirdNET applications
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/importer"
+)
+
+// importOptions holds the flags shared by the import subcommands
+type importOptions struct {
+	dryRun     bool
+	noClipCopy bool
+	batchSize  int
+	reportPath string
+}
+
+// Command creates the import command and its subcommands
+func Command(settings *conf.Settings) *cobra.Command {
+	var opts importOptions
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import detections from BirdNET-Pi or BirdNET-Analyzer",
+		Long: `Import detections recorded by BirdNET-Pi or analyzed with BirdNET-Analyzer into the
+configured database. Species codes are added from the eBird taxonomy and detections
+already in the database, matched by date, time and scientific name, are skipped so an
+import can be run again. Existing clips are copied into the clip export directory.`,
+	}
+
+	cmd.PersistentFlags().BoolVar(&opts.dryRun, "dry-run", false, "Read and check the detections without importing them")
+	cmd.PersistentFlags().BoolVar(&opts.noClipCopy, "no-clip-copy", false, "Do not copy clips, only attach clips already inside the clip export directory")
+	cmd.PersistentFlags().IntVar(&opts.batchSize, "batch-size", importer.DefaultBatchSize, "Detections written per transaction")
+	cmd.PersistentFlags().StringVar(&opts.reportPath, "report", "", "Write the skipped detections and the reasons to a CSV file")
+
+	cmd.AddCommand(birdnetPiCommand(settings, &opts))
+	cmd.AddCommand(analyzerCommand(settings, &opts))
+
+	return cmd
+}
+
+// birdnetPiCommand creates the import birdnet-pi command
+func birdnetPiCommand(settings *conf.Settings, opts *importOptions) *cobra.Command {
+	var clipsDir string
+
+	cmd := &cobra.Command{
+		Use:   "birdnet-pi <birds.db>",
+		Short: "Import the detections of a BirdNET-Pi birds.db database",
+		Long: `Import the detections table of a BirdNET-Pi birds.db database. With --clips-dir pointing
+at the BirdNET-Pi extracted clips directory, usually ~/BirdSongs/Extracted, the clip of
+each detection is attached.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImport(cmd.Context(), settings, opts, &importer.BirdNETPiSource{
+				DBPath:   args[0],
+				ClipsDir: clipsDir,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&clipsDir, "clips-dir", "", "BirdNET-Pi extracted clips directory")
+
+	return cmd
+}
+
+// analyzerCommand creates the import analyzer command
+func analyzerCommand(settings *conf.Settings, opts *importOptions) *cobra.Command {
+	var recordingStart string
+
+	cmd := &cobra.Command{
+		Use:   "analyzer <file or directory>...",
+		Short: "Import BirdNET-Analyzer CSV results and Raven selection tables",
+		Long: `Import BirdNET-Analyzer results in the CSV or Raven selection table format. Directories
+are searched recursively. Detection times are offsets into the analyzed recording, the
+recording start is read from file names containing YYYYMMDD_HHMMSS as written by
+AudioMoth and Song Meter recorders. Use --recording-start for recordings named otherwise.
+Location, threshold and sensitivity are taken from the configuration.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src := &importer.AnalyzerSource{Paths: args}
+			if recordingStart != "" {
+				start, err := time.ParseInLocation(time.DateTime, recordingStart, time.Local)
+				if err != nil {
+					return fmt.Errorf("invalid --recording-start, use \"YYYY-MM-DD HH:MM:SS\": %w", err)
+				}
+				src.RecordingStart = start
+			}
+			return runImport(cmd.Context(), settings, opts, src)
+		},
+	}
+
+	cmd.Flags().StringVar(&recordingStart, "recording-start", "", "Start time of recordings without a timestamp in the file name, \"YYYY-MM-DD HH:MM:SS\"")
+
+	return cmd
+}
+
+// runImport opens the datastore, imports the source and prints the report
+func runImport(ctx context.Context, settings *conf.Settings, opts *importOptions, src importer.Source) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store := datastore.New(settings)
+	if store == nil {
+		return fmt.Errorf("no database is enabled in the output settings")
+	}
+	if err := store.Open(); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	imp, err := importer.New(settings, store, importer.Options{
+		DryRun:    opts.dryRun,
+		CopyClips: !opts.noClipCopy,
+		BatchSize: opts.batchSize,
+		Progress: func(r *importer.Report) {
+			fmt.Printf("\rRead %d, imported %d, duplicates %d", r.Read, r.Imported, r.Duplicates)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		fmt.Printf("Checking %s, nothing is imported\n", src.Name())
+	} else {
+		fmt.Printf("Importing %s\n", src.Name())
+	}
+
+	start := time.Now()
+	report, err := imp.Import(ctx, src)
+	fmt.Println()
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Println("⚠️ Import interrupted, run the same command again to continue, imported detections are skipped")
+		}
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	printReport(report, opts.dryRun, time.Since(start))
+	if opts.reportPath != "" && len(report.Skipped) > 0 {
+		if err := writeReport(opts.reportPath, report); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("Skipped detections written to %s\n", opts.reportPath)
+	}
+	return nil
+}
+
+// printReport prints the import summary and the first skipped detections
+func printReport(report *importer.Report, dryRun bool, elapsed time.Duration) {
+	const maxListed = 10
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("✅ %s %d of %d detections in %s\n", verb, report.Imported, report.Read, elapsed.Round(time.Second))
+	fmt.Printf("   %d already in the database, %d clips attached, %d clips not found\n",
+		report.Duplicates, report.ClipsAttached, report.ClipsMissing)
+
+	if len(report.Skipped) == 0 {
+		return
+	}
+	fmt.Printf("⚠️ Skipped %d detections\n", len(report.Skipped))
+	for i, s := range report.Skipped {
+		if i == maxListed {
+			fmt.Printf("   ... and %d more, use --report to list all\n", len(report.Skipped)-maxListed)
+			break
+		}
+		fmt.Printf("   %s: %s\n", s.Origin, s.Reason)
+	}
+}
+
+// writeReport writes the skipped detections to a CSV file
+func writeReport(path string, report *importer.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteSkipped(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
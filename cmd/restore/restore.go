@@ -0,0 +1,185 @@
+// Package restore provides the command for restoring the database from a backup
+package restore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates the restore command
+func Command(settings *conf.Settings) *cobra.Command {
+	var targetName string
+	var list bool
+	var assumeYes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <backup-id>",
+		Short: "Restore the database from a backup",
+		Long: `Restore the database from a backup stored on one of the configured backup targets.
+
+The archive is decrypted if needed and verified before the database is replaced.
+The current database is kept next to the restored one with a .pre-restore suffix.
+Stop any running BirdNET-Go instance before restoring.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, defaultTarget, err := newRestoreManager(settings)
+			if err != nil {
+				return err
+			}
+
+			if list {
+				return listBackups(cmd.Context(), manager)
+			}
+
+			if targetName == "" {
+				targetName = defaultTarget
+			}
+
+			return runRestore(cmd.Context(), manager, args[0], targetName, assumeYes)
+		},
+	}
+
+	cmd.Flags().StringVarP(&targetName, "target", "t", "", "Backup target to restore from (defaults to the first enabled target)")
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "List available backups instead of restoring")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// newRestoreManager creates a backup manager with the configured targets and
+// the database restorer registered. It returns the name of the first target.
+func newRestoreManager(settings *conf.Settings) (manager *backup.Manager, defaultTarget string, err error) {
+	level := slog.LevelWarn
+	if settings.Debug || settings.Backup.Debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	stateManager, err := backup.NewStateManager(logger)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize backup state: %w", err)
+	}
+
+	manager, err = backup.NewManager(settings, logger, stateManager, settings.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize backup manager: %w", err)
+	}
+
+	for i := range settings.Backup.Targets {
+		targetConfig := settings.Backup.Targets[i]
+		if !targetConfig.Enabled {
+			continue
+		}
+		target, err := targets.NewFromConfig(targetConfig, logger)
+		if err != nil {
+			fmt.Printf("⚠️ Skipping %s backup target: %v\n", targetConfig.Type, err)
+			continue
+		}
+		if err := manager.RegisterTarget(target); err != nil {
+			fmt.Printf("⚠️ Skipping %s backup target: %v\n", targetConfig.Type, err)
+			continue
+		}
+		if defaultTarget == "" {
+			defaultTarget = target.Name()
+		}
+	}
+
+	if defaultTarget == "" {
+		return nil, "", fmt.Errorf("no usable backup targets configured")
+	}
+
+	if settings.Output.SQLite.Enabled {
+		manager.RegisterRestorer(sources.NewSQLiteSource(settings, logger))
+	}
+
+	return manager, defaultTarget, nil
+}
+
+// listBackups prints the backups available on all registered targets
+func listBackups(ctx context.Context, manager *backup.Manager) error {
+	backups, err := manager.ListBackups(ctx)
+	if err != nil {
+		fmt.Printf("⚠️ Some backup targets could not be listed: %v\n", err)
+	}
+
+	if len(backups) == 0 {
+		fmt.Println("No backups found")
+		return nil
+	}
+
+	fmt.Printf("%-36s  %-8s  %-19s  %10s\n", "ID", "Target", "Created", "Size")
+	for i := range backups {
+		b := &backups[i]
+		fmt.Printf("%-36s  %-8s  %-19s  %10s\n",
+			b.ID, b.Target, b.Timestamp.Local().Format("2006-01-02 15:04:05"), formatSize(b.Size))
+	}
+
+	return nil
+}
+
+// runRestore asks for confirmation and restores the given backup
+func runRestore(ctx context.Context, manager *backup.Manager, id, targetName string, assumeYes bool) error {
+	fmt.Printf("⚠️ Restoring backup %s from %s will replace the current database.\n", id, targetName)
+	fmt.Println("   Make sure BirdNET-Go is not running before continuing.")
+
+	if !assumeYes && !confirm("Continue?") {
+		fmt.Println("Restore canceled")
+		return nil
+	}
+
+	result, err := manager.Restore(ctx, id, targetName)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	fmt.Printf("✅ Restored backup %s (%s) in %s\n", result.BackupID, result.Source, result.Duration.Round(time.Millisecond))
+	if result.PreviousDataPath != "" {
+		fmt.Printf("   Previous database kept at %s\n", result.PreviousDataPath)
+	}
+	if result.ConfigChanged {
+		fmt.Println("   Note: the current configuration differs from the one stored in the backup")
+	}
+
+	return nil
+}
+
+// confirm prompts the user for a yes/no answer
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// formatSize returns a human readable byte size
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
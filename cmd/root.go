@@ -9,12 +9,16 @@ import (
 	"github.com/spf13/viper"
 	"github.com/tphakala/birdnet-go/cmd/authors"
 	"github.com/tphakala/birdnet-go/cmd/benchmark"
+	"github.com/tphakala/birdnet-go/cmd/db"
 	"github.com/tphakala/birdnet-go/cmd/directory"
 	"github.com/tphakala/birdnet-go/cmd/file"
+	"github.com/tphakala/birdnet-go/cmd/importer"
 	"github.com/tphakala/birdnet-go/cmd/license"
 	"github.com/tphakala/birdnet-go/cmd/rangefilter"
 	"github.com/tphakala/birdnet-go/cmd/realtime"
+	"github.com/tphakala/birdnet-go/cmd/restore"
 	"github.com/tphakala/birdnet-go/cmd/support"
+	"github.com/tphakala/birdnet-go/cmd/train"
 	"github.com/tphakala/birdnet-go/internal/conf"
 )
 
@@ -41,6 +45,10 @@ func RootCommand(settings *conf.Settings) *cobra.Command {
 	rangeCmd := rangefilter.Command(settings)
 	supportCmd := support.Command(settings)
 	benchmarkCmd := benchmark.Command(settings)
+	restoreCmd := restore.Command(settings)
+	dbCmd := db.Command(settings)
+	importCmd := importer.Command(settings)
+	trainCmd := train.Command(settings)
 
 	subcommands := []*cobra.Command{
 		fileCmd,
@@ -51,6 +59,10 @@ func RootCommand(settings *conf.Settings) *cobra.Command {
 		rangeCmd,
 		supportCmd,
 		benchmarkCmd,
+		restoreCmd,
+		dbCmd,
+		importCmd,
+		trainCmd,
 	}
 
 	rootCmd.AddCommand(subcommands...)
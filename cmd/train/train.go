@@ -0,0 +1,147 @@
+// Package train provides the command that trains the classifier from reviewed detections
+package train
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/classifier"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// trainOptions holds the flags of the train command
+type trainOptions struct {
+	output string
+	model  string
+	train  classifier.TrainOptions
+}
+
+// Command creates the train command
+func Command(settings *conf.Settings) *cobra.Command {
+	opts := trainOptions{train: classifier.DefaultTrainOptions()}
+
+	cmd := &cobra.Command{
+		Use:   "train",
+		Short: "Train a classifier from reviewed detections",
+		Long: `Train a species verification classifier on the embeddings of detections reviewed as
+correct or false positive. Species with enough reviews and at least one false positive
+get a logistic regression head estimating how likely their detections are correct at
+this station. Embeddings are saved with detections when birdnet.embeddings is enabled.
+
+Each training writes a new classifier version to the output directory. Enable
+realtime.classifier to suppress or re-score detections with the latest version.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTrain(settings, &opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", settings.Realtime.Classifier.Path, "Directory of the classifier versions")
+	cmd.Flags().StringVar(&opts.model, "model", "", "Train on embeddings of this model ID (default the model with the most reviewed embeddings)")
+	cmd.Flags().IntVar(&opts.train.MinReviews, "min-reviews", classifier.DefaultMinReviews, "Reviewed detections a species needs to be trained")
+	cmd.Flags().IntVar(&opts.train.Epochs, "epochs", classifier.DefaultEpochs, "Training passes over the reviewed detections of a species")
+	cmd.Flags().Float64Var(&opts.train.LearningRate, "learning-rate", classifier.DefaultLearningRate, "Gradient descent step size")
+	cmd.Flags().Float64Var(&opts.train.L2, "l2", classifier.DefaultL2, "L2 regularization of the weights")
+
+	return cmd
+}
+
+// runTrain reads the reviewed embeddings, trains the classifier and saves it as a new version
+func runTrain(settings *conf.Settings, opts *trainOptions) error {
+	if opts.output == "" {
+		return fmt.Errorf("output directory is required")
+	}
+
+	store := datastore.New(settings)
+	if store == nil {
+		return fmt.Errorf("no database is enabled in the output settings")
+	}
+	if err := store.Open(); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	reviewed, err := store.GetReviewedEmbeddings()
+	if err != nil {
+		return fmt.Errorf("failed to read reviewed detections: %w", err)
+	}
+	model := opts.model
+	if model == "" {
+		model = mostReviewedModel(reviewed)
+	}
+	samples, err := trainingSamples(reviewed, model)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no reviewed detections with embeddings, enable birdnet.embeddings and review detections first")
+	}
+	fmt.Printf("Training on %d reviewed detections with embeddings of %s\n", len(samples), model)
+
+	start := time.Now()
+	c, skipped, err := classifier.Train(model, samples, opts.train)
+	if err != nil {
+		return err
+	}
+
+	species := make([]string, 0, len(c.Species))
+	for name := range c.Species {
+		species = append(species, name)
+	}
+	sort.Strings(species)
+	fmt.Printf("%-32s  %8s  %8s  %17s\n", "Species", "Correct", "False", "Training accuracy")
+	for _, name := range species {
+		head := c.Species[name]
+		fmt.Printf("%-32s  %8d  %8d  %16.1f%%\n", name, head.Correct, head.FalsePositives, head.Accuracy*100)
+	}
+	fmt.Println("Training accuracy is measured on the reviewed detections the classifier was trained on, expect lower accuracy on new detections")
+	for _, s := range skipped {
+		fmt.Printf("Skipped %s with %d reviews: %s\n", s.Species, s.Reviews, s.Reason)
+	}
+
+	path, err := classifier.Save(opts.output, c)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Trained classifier version %d for %d species in %s, saved to %s\n",
+		c.Version, len(c.Species), time.Since(start).Round(time.Millisecond), path)
+	return nil
+}
+
+// mostReviewedModel returns the model with the most reviewed embeddings
+func mostReviewedModel(reviewed []datastore.ReviewedEmbedding) string {
+	counts := make(map[string]int)
+	best := ""
+	for i := range reviewed {
+		model := reviewed[i].Model
+		counts[model]++
+		if counts[model] > counts[best] || (counts[model] == counts[best] && model < best) {
+			best = model
+		}
+	}
+	return best
+}
+
+// trainingSamples converts the reviewed embeddings of the model to training samples
+func trainingSamples(reviewed []datastore.ReviewedEmbedding, model string) ([]classifier.Sample, error) {
+	samples := make([]classifier.Sample, 0, len(reviewed))
+	for i := range reviewed {
+		r := &reviewed[i]
+		if r.Model != model {
+			continue
+		}
+		values, err := (&datastore.Embedding{Vector: r.Vector}).Values()
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding of detection %d: %w", r.NoteID, err)
+		}
+		samples = append(samples, classifier.Sample{
+			Species:   r.ScientificName,
+			Embedding: values,
+			Correct:   r.Verified == "correct",
+		})
+	}
+	return samples, nil
+}
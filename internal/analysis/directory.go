@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
 )
 
 // cleanupProcessingFiles removes all .processing files from the output directory
@@ -175,7 +177,7 @@ func isFileReadyForProcessing(path string, ctx context.Context) (bool, error) {
 }
 
 // processFile handles the analysis of a single audio file
-func processFile(path string, settings *conf.Settings, processedFiles map[string]bool, ctx context.Context) (bool, error) {
+func processFile(path string, settings *conf.Settings, processedFiles map[string]bool, store datastore.Interface, ctx context.Context) (bool, error) {
 	if isProcessed(path, settings.Output.File.Path, processedFiles) {
 		return false, nil // File was already processed
 	}
@@ -198,12 +200,8 @@ func processFile(path string, settings *conf.Settings, processedFiles map[string
 
 	// Create processing lock file
 	outputPath := filepath.Join(settings.Output.File.Path, filepath.Base(path))
-	ext := strings.ToLower(filepath.Ext(outputPath))
-	switch ext {
-	case ".wav":
-		outputPath = outputPath[:len(outputPath)-4]
-	case ".flac":
-		outputPath = outputPath[:len(outputPath)-5]
+	if myaudio.IsSupportedAudioFile(outputPath) {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
 	}
 	lockFile := outputPath + ".processing"
 
@@ -228,7 +226,7 @@ func processFile(path string, settings *conf.Settings, processedFiles map[string
 	// Run FileAnalysis in a goroutine so we can handle interruption
 	analysisDone := make(chan error)
 	go func() {
-		analysisDone <- FileAnalysis(settings, analysisCtx)
+		analysisDone <- analyzeFile(settings, analysisCtx, store)
 	}()
 
 	// Wait for either completion or interruption
@@ -262,7 +260,7 @@ func processFile(path string, settings *conf.Settings, processedFiles map[string
 }
 
 // scanDirectory scans a directory for audio files and processes them
-func scanDirectory(watchDir string, settings *conf.Settings, processedFiles map[string]bool, ctx context.Context) error {
+func scanDirectory(watchDir string, settings *conf.Settings, processedFiles map[string]bool, store datastore.Interface, ctx context.Context) error {
 	log.Printf("Scanning directory: %s", watchDir)
 	startTime := time.Now()
 	filesAnalyzed := 0
@@ -287,10 +285,9 @@ func scanDirectory(watchDir string, settings *conf.Settings, processedFiles map[
 			return nil
 		}
 
-		// Check for both .wav and .flac files (case-insensitive)
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		if ext == ".wav" || ext == ".flac" {
-			wasProcessed, err := processFile(path, settings, processedFiles, ctx)
+		// Check for supported audio files (case-insensitive)
+		if myaudio.IsSupportedAudioFile(d.Name()) {
+			wasProcessed, err := processFile(path, settings, processedFiles, store, ctx)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return context.Canceled
@@ -324,7 +321,7 @@ func scanDirectory(watchDir string, settings *conf.Settings, processedFiles map[
 }
 
 // watchDirectory continuously monitors a directory for new files
-func watchDirectory(watchDir string, settings *conf.Settings, processedFiles map[string]bool, ctx context.Context) error {
+func watchDirectory(watchDir string, settings *conf.Settings, processedFiles map[string]bool, store datastore.Interface, ctx context.Context) error {
 	log.Printf("Starting directory watch on %s (Press Ctrl+C to stop)", watchDir)
 	watchStartTime := time.Now()
 
@@ -340,7 +337,7 @@ func watchDirectory(watchDir string, settings *conf.Settings, processedFiles map
 
 		case <-timer.C:
 			// Do the scan
-			if err := scanDirectory(watchDir, settings, processedFiles, ctx); err != nil {
+			if err := scanDirectory(watchDir, settings, processedFiles, store, ctx); err != nil {
 				if errors.Is(err, context.Canceled) {
 					cleanupProcessingFiles(settings.Output.File.Path)
 					return context.Canceled
@@ -372,12 +369,22 @@ func DirectoryAnalysis(settings *conf.Settings, ctx context.Context) error {
 		return err
 	}
 
+	// Open the database once for all files when detections are saved
+	store, err := openFileDatastore(settings)
+	if err != nil {
+		log.Printf("Failed to open database: %v", err)
+		return err
+	}
+	if store != nil {
+		defer closeFileDatastore(store)
+	}
+
 	// Create a map to track processed files
 	processedFiles := make(map[string]bool)
 
 	// Do initial scan
 	log.Printf("Performing initial directory scan...")
-	if err := scanDirectory(settings.Input.Path, settings, processedFiles, ctx); err != nil {
+	if err := scanDirectory(settings.Input.Path, settings, processedFiles, store, ctx); err != nil {
 		if errors.Is(err, context.Canceled) {
 			return context.Canceled
 		}
@@ -391,5 +398,5 @@ func DirectoryAnalysis(settings *conf.Settings, ctx context.Context) error {
 	}
 
 	// Start watching directory
-	return watchDirectory(settings.Input.Path, settings, processedFiles, ctx)
+	return watchDirectory(settings.Input.Path, settings, processedFiles, store, ctx)
 }
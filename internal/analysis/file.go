@@ -7,7 +7,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -58,7 +57,22 @@ func (h *errorHolder) getError() error {
 
 // FileAnalysis conducts an analysis of an audio file and outputs the results.
 // It reads an audio file, analyzes it for bird sounds, and prints the results based on the provided configuration.
+// With settings.Input.Database the detections are also saved to the configured database.
 func FileAnalysis(settings *conf.Settings, ctx context.Context) error {
+	store, err := openFileDatastore(settings)
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer closeFileDatastore(store)
+	}
+
+	return analyzeFile(settings, ctx, store)
+}
+
+// analyzeFile analyzes the audio file in settings.Input.Path, writes the results, saves
+// the detections when store is not nil and exports their clips when enabled
+func analyzeFile(settings *conf.Settings, ctx context.Context, store datastore.Interface) error {
 	// Initialize BirdNET interpreter
 	if err := initializeBirdNET(settings); err != nil {
 		return err
@@ -97,7 +111,25 @@ func FileAnalysis(settings *conf.Settings, ctx context.Context) error {
 		return err
 	}
 
-	return writeResults(settings, notes)
+	if err := writeResults(settings, notes); err != nil {
+		return err
+	}
+
+	// Partial results are not saved, the recording would count as saved when analyzed again
+	if store != nil || settings.Input.Clips {
+		duration := time.Duration(float64(audioInfo.TotalSamples) / float64(audioInfo.SampleRate) * float64(time.Second))
+		saved, clips, err := saveFileDetections(ctx, settings, store, settings.Input.Path, duration, notes)
+		if clips > 0 {
+			fmt.Printf("🎵 Exported %d clips to %s\n", clips, settings.Realtime.Audio.Export.Path)
+		}
+		if err != nil {
+			return err
+		}
+		if saved > 0 {
+			fmt.Printf("💾 Saved %d detections to the database\n", saved)
+		}
+	}
+	return nil
 }
 
 // validateAudioFile checks if the provided file path is a valid audio file.
@@ -118,8 +150,7 @@ func validateAudioFile(filePath string) error {
 	}
 
 	// Check file extension (case-insensitive)
-	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext != ".wav" && ext != ".flac" {
+	if !myaudio.IsSupportedAudioFile(filePath) {
 		return fmt.Errorf("\033[31m❌ Invalid audio file %s: unsupported audio format: %s\033[0m", filepath.Base(filePath), filepath.Ext(filePath))
 	}
 
@@ -521,12 +552,14 @@ func processAudioData(
 	channels processingChannels,
 	errHolder *errorHolder,
 ) error {
-	// Initialize filePosition before the loop
+	// Chunk positions are offsets into the file from the zero time, consecutive chunks
+	// start (3 - overlap) seconds apart
 	filePosition := time.Time{}
+	step := time.Duration((3 - settings.BirdNET.Overlap) * float64(time.Second))
 
 	// Read and send audio chunks with timing information
 	return myaudio.ReadAudioFileBuffered(settings, func(chunkData []float32, isEOF bool) error {
-		return handleAudioChunk(
+		err := handleAudioChunk(
 			ctx,
 			chunkData,
 			isEOF,
@@ -534,6 +567,10 @@ func processAudioData(
 			channels,
 			errHolder,
 		)
+		if len(chunkData) > 0 {
+			filePosition = filePosition.Add(step)
+		}
+		return err
 	})
 }
 
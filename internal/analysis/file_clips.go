@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// fileClip is the sample range of a detection clip in an analyzed recording
+type fileClip struct {
+	detection int // Index of the detection the clip belongs to
+	from, to  int // Sample range at conf.SampleRate
+	samples   []float32
+}
+
+// exportFileClips cuts a clip for each detection of an analyzed recording, from the configured
+// padding before the detection to the padding after it, and saves it in the clip export
+// directory like realtime clips. The clip names are set on the detections.
+// The recording is read once, clips of overlapping detections share the same samples.
+func exportFileClips(ctx context.Context, settings *conf.Settings, path string, start time.Time, duration time.Duration, detections []datastore.Note) (int, error) {
+	if settings.Realtime.Audio.Export.Path == "" {
+		return 0, errors.Newf("clip export path is not configured").
+			Component("analysis.file").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "export_file_clips").
+			Build()
+	}
+
+	clips := make([]*fileClip, 0, len(detections))
+	for i := range detections {
+		from := max(detections[i].BeginTime.Sub(start)-settings.Input.ClipPrePadding, 0)
+		to := min(detections[i].EndTime.Sub(start)+settings.Input.ClipPostPadding, duration)
+		if to <= from {
+			continue
+		}
+		clips = append(clips, &fileClip{
+			detection: i,
+			from:      int(from.Seconds() * conf.SampleRate),
+			to:        int(to.Seconds() * conf.SampleRate),
+		})
+	}
+
+	// Read without overlap so that every sample is seen once, resampled to the clip sample rate
+	readSettings := &conf.Settings{Debug: settings.Debug}
+	readSettings.Input.Path = path
+	readSettings.Realtime.Audio.FfmpegPath = settings.Realtime.Audio.FfmpegPath
+
+	exported := 0
+	next, position := 0, 0
+	var active []*fileClip
+	err := myaudio.ReadAudioFileBuffered(readSettings, func(chunk []float32, isEOF bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		chunkEnd := position + len(chunk)
+		for next < len(clips) && clips[next].from < chunkEnd {
+			active = append(active, clips[next])
+			next++
+		}
+
+		remaining := active[:0]
+		for _, clip := range active {
+			lo, hi := max(clip.from, position), min(clip.to, chunkEnd)
+			if hi > lo {
+				clip.samples = append(clip.samples, chunk[lo-position:hi-position]...)
+			}
+			if clip.to > chunkEnd && !isEOF {
+				remaining = append(remaining, clip)
+				continue
+			}
+			if err := saveFileClip(settings, clip, &detections[clip.detection]); err != nil {
+				return err
+			}
+			exported++
+		}
+		active = remaining
+		position = chunkEnd
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	// Clips ending after the last chunk was read
+	for _, clip := range active {
+		if err := saveFileClip(settings, clip, &detections[clip.detection]); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+	return exported, nil
+}
+
+// saveFileClip encodes a clip in the export format and sets its name on the detection
+func saveFileClip(settings *conf.Settings, clip *fileClip, detection *datastore.Note) error {
+	if len(clip.samples) == 0 {
+		return nil
+	}
+	exportType := settings.Realtime.Audio.Export.Type
+	if exportType == "" {
+		exportType = "wav"
+	}
+
+	clipName := processor.GenerateClipName(exportType, detection.ScientificName, float32(detection.Confidence), detection.BeginTime)
+	outputPath := filepath.Join(settings.Realtime.Audio.Export.Path, clipName)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create clip directory: %w", err)
+	}
+
+	pcmData := float32ToPCM16(clip.samples)
+	clip.samples = nil
+	if exportType == "wav" {
+		if err := myaudio.SavePCMDataToWAV(outputPath, pcmData); err != nil {
+			return err
+		}
+	} else if err := myaudio.ExportAudioWithFFmpeg(pcmData, outputPath, &settings.Realtime.Audio); err != nil {
+		return err
+	}
+
+	detection.ClipName = clipName
+	return nil
+}
+
+// float32ToPCM16 converts samples in the -1 to 1 range to 16-bit little endian PCM
+func float32ToPCM16(samples []float32) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		value := math.Round(float64(max(-1, min(1, sample))) * math.MaxInt16)
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(value)))
+	}
+	return pcm
+}
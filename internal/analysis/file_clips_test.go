@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/recording"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// TestExportFileClips tests that clips cover the padded detections within the recording
+func TestExportFileClips(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "20240501_063000.wav")
+	require.NoError(t, myaudio.SavePCMDataToWAV(path, make([]byte, 10*conf.SampleRate*2)))
+
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.7
+	settings.Input.Clips = true
+	settings.Input.ClipPrePadding = time.Second
+	settings.Input.ClipPostPadding = time.Second
+	settings.Realtime.Audio.Export.Path = filepath.Join(t.TempDir(), "clips")
+	settings.Realtime.Audio.Export.Type = "wav"
+
+	start := time.Date(2024, 5, 1, 6, 30, 0, 0, time.Local)
+	sourceID, err := recording.SourceID(path)
+	require.NoError(t, err)
+	detections := recording.Detections(settings.BirdNET.Threshold, path, sourceID, start, []datastore.Note{
+		chunkNote(0, "Turdus merula", "Eurasian Blackbird", 0.9),
+		chunkNote(1, "Erithacus rubecula", "European Robin", 0.8), // overlaps the first clip
+		chunkNote(4, "Parus major", "Great Tit", 0.75),
+		chunkNote(9, "Cuculus canorus", "Common Cuckoo", 0.85), // ends after the recording
+	})
+	require.Len(t, detections, 4)
+	const wavHeaderSize = 44
+
+	exported, err := exportFileClips(context.Background(), settings, path, start, 10*time.Second, detections)
+	require.NoError(t, err)
+	assert.Equal(t, 4, exported)
+
+	want := []time.Duration{
+		2500 * time.Millisecond, // clamped to the recording start
+		3500 * time.Millisecond,
+		3500 * time.Millisecond,
+		2 * time.Second, // clamped to the recording end
+	}
+	for i := range detections {
+		require.NotEmpty(t, detections[i].ClipName, detections[i].ScientificName)
+		assert.Contains(t, detections[i].ClipName, "2024/05/")
+		assert.Contains(t, detections[i].ClipName, "T0630")
+
+		info, err := os.Stat(filepath.Join(settings.Realtime.Audio.Export.Path, detections[i].ClipName))
+		require.NoError(t, err)
+		assert.Equal(t, wavHeaderSize+int64(want[i].Seconds()*conf.SampleRate)*2, info.Size(), detections[i].ScientificName)
+	}
+}
+
+// TestExportFileClipsWithoutPath tests that clips are not exported without an export path
+func TestExportFileClipsWithoutPath(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.Input.Clips = true
+	_, err := exportFileClips(context.Background(), settings, "recording.wav", time.Now(), time.Minute, nil)
+	assert.Error(t, err)
+}
+
+func TestFloat32ToPCM16(t *testing.T) {
+	t.Parallel()
+	pcm := float32ToPCM16([]float32{0, 1, -1, 2, 0.5})
+	assert.Equal(t, []byte{0x00, 0x00, 0xff, 0x7f, 0x01, 0x80, 0xff, 0x7f, 0x00, 0x40}, pcm)
+}
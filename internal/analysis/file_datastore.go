@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/recording"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// openFileDatastore opens the configured database when detections of analyzed files are saved,
+// it returns nil when saving is not enabled
+func openFileDatastore(settings *conf.Settings) (datastore.Interface, error) {
+	if !settings.Input.Database {
+		return nil, nil
+	}
+
+	store := datastore.New(settings)
+	if store == nil {
+		return nil, errors.Newf("no database is enabled in the output settings").
+			Component("analysis.file").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "open_file_datastore").
+			Build()
+	}
+	if err := store.Open(); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return store, nil
+}
+
+// closeFileDatastore closes the database opened for file analysis
+func closeFileDatastore(store datastore.Interface) {
+	if err := store.Close(); err != nil {
+		GetLogger().Warn("Failed to close database",
+			"component", "analysis.file",
+			"error", err,
+			"operation", "close_file_datastore")
+	}
+}
+
+// saveFileDetections stores the detections of an analyzed recording like realtime detections
+// and exports their clips when enabled, store is nil when only clips are exported.
+// Detection times are offsets into the recording and are placed at the recording start read
+// from the file metadata or name. Detections of a recording already in the database are not
+// saved again. Returns the number of saved detections and exported clips.
+func saveFileDetections(ctx context.Context, settings *conf.Settings, store datastore.Interface, path string, duration time.Duration, notes []datastore.Note) (saved, clips int, err error) {
+	loc, err := myaudio.ParseRecordingTimezone(settings.Input.Timezone)
+	if err != nil {
+		return 0, 0, err
+	}
+	start, timeSource, err := myaudio.RecordingStartTime(path, duration, loc)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to determine recording start time: %w", err)
+	}
+	if timeSource == myaudio.RecordingTimeModified {
+		fmt.Printf("\033[33m⚠️  No recording time in the metadata or name of %s, using the file modification time\033[0m\n", filepath.Base(path))
+	}
+
+	sourceID, err := recording.SourceID(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	detections := recording.Detections(settings.BirdNET.Threshold, path, sourceID, start, notes)
+	if len(detections) == 0 {
+		return 0, 0, nil
+	}
+
+	if store != nil {
+		exists, err := recording.Saved(store, sourceID, detections)
+		if err != nil {
+			return 0, 0, err
+		}
+		if exists {
+			fmt.Printf("Detections of %s are already in the database, skipping\n", filepath.Base(path))
+			return 0, 0, nil
+		}
+	}
+
+	if settings.Input.Clips {
+		clips, err = exportFileClips(ctx, settings, path, start, duration, detections)
+		if err != nil {
+			return 0, clips, fmt.Errorf("failed to export clips: %w", err)
+		}
+	}
+
+	if store == nil {
+		return 0, clips, nil
+	}
+	if saved, err = recording.Save(store, detections); err != nil {
+		return saved, clips, err
+	}
+
+	GetLogger().Info("Saved file detections to database",
+		"component", "analysis.file",
+		"file", filepath.Base(path),
+		"source_id", sourceID,
+		"detections", saved,
+		"clips", clips,
+		"recording_start", start.Format(time.RFC3339),
+		"recording_time_source", timeSource,
+		"operation", "save_file_detections")
+	return saved, clips, nil
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/recording"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// chunkNote returns an analysis result of the chunk starting at offset seconds into the file
+func chunkNote(offset float64, scientificName, commonName string, confidence float64) datastore.Note {
+	begin := time.Time{}.Add(time.Duration(offset * float64(time.Second)))
+	return datastore.Note{
+		Date:           "2026-01-01", // File analysis results carry the analysis date
+		Time:           "12:00:00",
+		BeginTime:      begin,
+		EndTime:        begin.Add(1500 * time.Millisecond),
+		ScientificName: scientificName,
+		CommonName:     commonName,
+		SpeciesCode:    "code",
+		Confidence:     confidence,
+	}
+}
+
+// TestSaveFileDetections tests that detections of a recording are saved once
+func TestSaveFileDetections(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.7
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	settings.Input.Database = true
+
+	store, err := openFileDatastore(settings)
+	require.NoError(t, err)
+	require.NotNil(t, store)
+	t.Cleanup(func() { closeFileDatastore(store) })
+
+	path := filepath.Join(t.TempDir(), "AM01_20240501_063000.flac")
+	require.NoError(t, os.WriteFile(path, []byte("fLaC"), 0o600))
+	notes := []datastore.Note{
+		chunkNote(0, "Turdus merula", "Eurasian Blackbird", 0.9),
+		chunkNote(30, "Erithacus rubecula", "European Robin", 0.8),
+	}
+
+	saved, clips, err := saveFileDetections(context.Background(), settings, store, path, time.Minute, notes)
+	require.NoError(t, err)
+	assert.Zero(t, clips, "clips are exported only when enabled")
+	assert.Equal(t, 2, saved)
+
+	var stored []datastore.Note
+	require.NoError(t, store.Transaction(func(tx *gorm.DB) error {
+		return tx.Preload("Results").Order("time").Find(&stored).Error
+	}))
+	require.Len(t, stored, 2)
+	assert.Equal(t, "2024-05-01", stored[0].Date)
+	assert.Equal(t, "06:30:00", stored[0].Time)
+	assert.Equal(t, "06:30:30", stored[1].Time)
+	sourceID, err := recording.SourceID(path)
+	require.NoError(t, err)
+	assert.Equal(t, sourceID, stored[1].SourceID)
+	require.Len(t, stored[0].Results, 1)
+	assert.Equal(t, "Turdus merula_Eurasian Blackbird", stored[0].Results[0].Species)
+
+	// Analyzing the recording again does not duplicate its detections
+	saved, _, err = saveFileDetections(context.Background(), settings, store, path, time.Minute, notes)
+	require.NoError(t, err)
+	assert.Zero(t, saved)
+}
+
+// TestOpenFileDatastoreDisabled tests that no database is opened unless saving is enabled
+func TestOpenFileDatastoreDisabled(t *testing.T) {
+	t.Parallel()
+	store, err := openFileDatastore(&conf.Settings{})
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	settings := &conf.Settings{}
+	settings.Input.Database = true
+	_, err = openFileDatastore(settings)
+	assert.Error(t, err, "saving requires a configured database")
+}
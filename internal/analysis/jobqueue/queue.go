@@ -649,15 +649,16 @@ func (q *JobQueue) executeJob(ctx context.Context, job *Job) {
 		stats.LastErrorMessage = sanitizedErr
 		stats.LastFailedTime = executionEndTime
 
-		if job.Attempts >= job.MaxAttempts {
-			// No more retries
+		if job.Attempts >= job.MaxAttempts || !isRetryable(err) {
+			// No more retries, attempts are used up or the action reported a permanent failure
 			job.Status = JobStatusFailed
 
 			q.stats.FailedJobs++
 			stats.Failed++
 			q.stats.ActionStats[actionKey] = stats
 
-			LogJobFailed(ctx, job.ID, actionDesc, job.Attempts, job.MaxAttempts, err)
+			// Attempts left after a permanent failure are not used, log the failure as final
+			LogJobFailed(ctx, job.ID, actionDesc, job.Attempts, min(job.Attempts, job.MaxAttempts), err)
 		} else {
 			// Schedule for retry
 			job.Status = JobStatusRetrying
@@ -687,6 +688,17 @@ func (q *JobQueue) executeJob(ctx context.Context, job *Job) {
 	}
 }
 
+// isRetryable reports whether a failed job may be retried. Actions mark permanent failures,
+// such as configuration errors, with the "retryable" context of an enhanced error set to false.
+func isRetryable(err error) bool {
+	var enhanced *errors.EnhancedError
+	if !errors.As(err, &enhanced) {
+		return true
+	}
+	retryable, ok := enhanced.GetContext()["retryable"].(bool)
+	return !ok || retryable
+}
+
 // cleanupOldActionStats removes the oldest action stats entries to prevent unbounded memory growth
 // This method must be called with q.mu locked
 func (q *JobQueue) cleanupOldActionStats() {
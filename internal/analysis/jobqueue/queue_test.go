@@ -15,6 +15,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	birdneterrors "github.com/tphakala/birdnet-go/internal/errors"
 )
 
 // MockClock is a mock implementation of the Clock interface for testing
@@ -516,6 +518,47 @@ func TestRetryExhaustion(t *testing.T) {
 	assert.True(t, jobFailed, "Job should have failed permanently after exhausting retries")
 }
 
+// TestPermanentFailureNotRetried tests that a failure marked not retryable fails the job
+// without using the retries left
+func TestPermanentFailureNotRetried(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := setupTestQueue(t, 100, 10, false)
+	defer teardownTestQueue(t, queue)
+
+	action := &MockAction{
+		ExecuteFunc: func(data interface{}) error {
+			return birdneterrors.Newf("endpoint rejected the request").
+				Component("analysis.jobqueue").
+				Category(birdneterrors.CategoryIntegration).
+				Context("retryable", false).
+				Build()
+		},
+	}
+	config := RetryConfig{
+		Enabled:      true,
+		MaxRetries:   3,
+		InitialDelay: 1 * time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		Multiplier:   1.2,
+	}
+
+	_, err := queue.Enqueue(context.Background(), action, &TestData{ID: "permanent-test"}, config)
+	require.NoError(t, err, "Failed to enqueue job")
+
+	for i := 0; i <= config.MaxRetries; i++ {
+		queue.ProcessImmediately(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, 1, action.GetExecuteCount(), "A permanent failure should not be retried")
+	stats := queue.GetStats()
+	assert.Equal(t, 1, stats.FailedJobs, "Failed jobs should be 1")
+	assert.Equal(t, 0, stats.RetryAttempts, "No retry attempts should be made")
+}
+
 // TestRetryBackoff tests that the retry backoff mechanism works correctly
 func TestRetryBackoff(t *testing.T) {
 	// TODO: This test could be improved by using a mock clock implementation
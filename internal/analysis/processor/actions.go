@@ -89,6 +89,8 @@ type DatabaseAction struct {
 	Ds                datastore.Interface
 	Note              datastore.Note
 	Results           []datastore.Results
+	Embedding         []float32 // Embedding of the detection audio, saved for similarity search when set
+	EmbeddingModel    string    // ID of the model that produced the embedding
 	EventTracker      *EventTracker
 	NewSpeciesTracker *NewSpeciesTracker // Add reference to new species tracker
 	processor         *Processor         // Add reference to processor for source name resolution
@@ -123,6 +125,7 @@ type MqttAction struct {
 	MqttClient     mqtt.Client
 	EventTracker   *EventTracker
 	RetryConfig    jobqueue.RetryConfig // Configuration for retry behavior
+	HomeAssistant  *HomeAssistantPublisher // Publishes species and source sub-topics, may be nil
 	Description    string
 	mu             sync.Mutex // Protect concurrent access to Note
 }
@@ -540,6 +543,9 @@ func (a *DatabaseAction) Execute(data interface{}) error {
 		return err
 	}
 
+	// Save the embedding of the detection for similarity search, a failure does not affect the detection
+	a.saveEmbedding()
+
 	// After successful save, publish detection event for new species
 	a.publishNewSpeciesDetectionEvent(isNewSpecies, daysSinceFirstSeen)
 
@@ -611,6 +617,22 @@ func isEOFError(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "eof")
 }
 
+// saveEmbedding saves the embedding of the saved note for similarity search. Failures are
+// logged only, the detection itself is already saved.
+func (a *DatabaseAction) saveEmbedding() {
+	if len(a.Embedding) == 0 || a.Note.ID == 0 {
+		return
+	}
+	if err := a.Ds.SaveEmbedding(datastore.NewEmbedding(a.Note.ID, a.EmbeddingModel, a.Embedding)); err != nil {
+		GetLogger().Warn("Failed to save detection embedding",
+			"component", "analysis.processor.actions",
+			"error", err,
+			"note_id", a.Note.ID,
+			"species", a.Note.CommonName,
+			"operation", "save_embedding")
+	}
+}
+
 // publishNewSpeciesDetectionEvent publishes a detection event for new species
 // This helper method handles event bus retrieval, event creation, publishing, and debug logging
 func (a *DatabaseAction) publishNewSpeciesDetectionEvent(isNewSpecies bool, daysSinceFirstSeen int) {
@@ -1011,6 +1033,16 @@ func (a *MqttAction) Execute(data interface{}) error {
 		return enhancedErr
 	}
 
+	// Sub-topic failures are only logged, retrying would publish the detection again
+	if err := a.HomeAssistant.PublishDetection(ctx, a.MqttClient, &noteCopy, string(noteJson)); err != nil {
+		GetLogger().Warn("Failed to publish detection to Home Assistant sub-topics",
+			"component", "analysis.processor.actions",
+			"error", sanitizeError(err),
+			"species", a.Note.CommonName,
+			"scientific_name", a.Note.ScientificName,
+			"operation", "homeassistant_publish_detection")
+	}
+
 	if a.Settings.Debug {
 		// Add structured logging
 		GetLogger().Debug("Successfully published to MQTT",
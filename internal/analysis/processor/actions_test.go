@@ -31,6 +31,16 @@ func (m *MockMqttClient) Publish(ctx context.Context, topic, payload string) err
 	return args.Error(0)
 }
 
+func (m *MockMqttClient) PublishRetained(ctx context.Context, topic, payload string) error {
+	args := m.Called(ctx, topic, payload)
+	return args.Error(0)
+}
+
+func (m *MockMqttClient) Subscribe(ctx context.Context, topic string, handler mqtt.MessageHandler) error {
+	args := m.Called(ctx, topic, handler)
+	return args.Error(0)
+}
+
 func (m *MockMqttClient) IsConnected() bool {
 	args := m.Called()
 	return args.Bool(0)
@@ -0,0 +1,369 @@
+// adaptive_rules.go: false positive rules learned from reviewed detections
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// AnyHour is the hour of adaptive rules that apply at all hours of the day
+const AnyHour = -1
+
+// AdaptiveRulesDatastore is the datastore the adaptive rules are learned from
+type AdaptiveRulesDatastore interface {
+	GetReviewOutcomes(startDate string) ([]datastore.ReviewOutcome, error)
+}
+
+// AdaptiveRuleKey is the context of an adaptive rule: a species, optionally limited to an
+// audio source and an hour of day
+type AdaptiveRuleKey struct {
+	ScientificName string
+	Source         string // audio source ID, empty for all sources
+	Hour           int    // hour of day, AnyHour for all hours
+}
+
+// AdaptiveRule raises the threshold of a species or suppresses it in a context where
+// reviewers marked many of its detections as false positives
+type AdaptiveRule struct {
+	AdaptiveRuleKey
+	CommonName        string
+	Action            string  // conf.AdaptiveRuleRaiseThreshold, conf.AdaptiveRuleSuppress or conf.AdaptiveRuleAllow
+	MinConfidence     float64 // detections at or below this are suppressed by a raised threshold
+	Reviews           int     // detections reviewed as correct or false positive, 0 for overrides
+	FalsePositives    int     // detections reviewed as false positive
+	FalsePositiveRate float64 // false positives of the reviewed detections, 0 to 1
+	Override          bool    // true when set by hand rather than learned
+	Suppressed        int64   // detections suppressed by the rule since the processor started
+}
+
+// suppressionKey identifies the rule counted in suppressions, an override and a learned
+// rule may share a context
+type suppressionKey struct {
+	AdaptiveRuleKey
+	override bool
+}
+
+// AdaptiveRules learns false positive rules from reviewed detections and matches detections
+// against them. Overrides set by hand take precedence over learned rules.
+type AdaptiveRules struct {
+	settings *conf.AdaptiveRulesSettings
+	ds       AdaptiveRulesDatastore
+
+	mu         sync.RWMutex
+	learned    map[AdaptiveRuleKey]*AdaptiveRule
+	overrides  map[AdaptiveRuleKey]*AdaptiveRule
+	suppressed map[suppressionKey]int64 // kept when the rules are learned again
+	learnedAt  time.Time
+}
+
+// NewAdaptiveRules creates the adaptive rules with the overrides of the settings. Rules
+// are learned by Refresh.
+func NewAdaptiveRules(settings *conf.AdaptiveRulesSettings, ds AdaptiveRulesDatastore) *AdaptiveRules {
+	r := &AdaptiveRules{
+		settings:   settings,
+		ds:         ds,
+		learned:    make(map[AdaptiveRuleKey]*AdaptiveRule),
+		suppressed: make(map[suppressionKey]int64),
+	}
+	r.SetOverrides(settings.Overrides)
+	return r
+}
+
+// OverrideKey returns the context of an override
+func OverrideKey(override *conf.AdaptiveRuleOverride) AdaptiveRuleKey {
+	key := AdaptiveRuleKey{ScientificName: override.Species, Source: override.Source, Hour: AnyHour}
+	if override.Hour != nil {
+		key.Hour = *override.Hour
+	}
+	return key
+}
+
+// SetOverrides replaces the rules set by hand
+func (r *AdaptiveRules) SetOverrides(overrides []conf.AdaptiveRuleOverride) {
+	rules := make(map[AdaptiveRuleKey]*AdaptiveRule, len(overrides))
+	for i := range overrides {
+		key := OverrideKey(&overrides[i])
+		rules[key] = &AdaptiveRule{
+			AdaptiveRuleKey: key,
+			Action:          overrides[i].Action,
+			MinConfidence:   overrides[i].MinConfidence,
+			Override:        true,
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = rules
+}
+
+// Refresh learns the rules again from the detections reviewed within the window
+func (r *AdaptiveRules) Refresh() error {
+	if r.ds == nil {
+		return fmt.Errorf("datastore is not available")
+	}
+	startDate := time.Now().AddDate(0, 0, -r.settings.WindowDays).Format("2006-01-02")
+	outcomes, err := r.ds.GetReviewOutcomes(startDate)
+	if err != nil {
+		return err
+	}
+	learned := learnAdaptiveRules(outcomes, r.settings)
+
+	r.mu.Lock()
+	r.learned = learned
+	r.learnedAt = time.Now()
+	r.mu.Unlock()
+
+	GetLogger().Info("Adaptive rules learned from reviewed detections",
+		"reviews", len(outcomes),
+		"rules", len(learned),
+		"start_date", startDate,
+		"operation", "adaptive_rules_refresh")
+	return nil
+}
+
+// run learns the rules at start and then at the refresh interval until the context is done
+func (r *AdaptiveRules) run(ctx context.Context) {
+	interval := time.Duration(r.settings.RefreshInterval) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(); err != nil {
+			GetLogger().Warn("Failed to learn adaptive rules",
+				"error", err,
+				"operation", "adaptive_rules_refresh")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rules returns the overrides and learned rules ordered by species, source and hour, and
+// the time the rules were last learned
+func (r *AdaptiveRules) Rules() (rules []AdaptiveRule, learnedAt time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules = make([]AdaptiveRule, 0, len(r.overrides)+len(r.learned))
+	for _, ruleSet := range []map[AdaptiveRuleKey]*AdaptiveRule{r.overrides, r.learned} {
+		for _, rule := range ruleSet {
+			copied := *rule
+			copied.Suppressed = r.suppressed[suppressionKey{rule.AdaptiveRuleKey, rule.Override}]
+			rules = append(rules, copied)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.ScientificName != b.ScientificName {
+			return a.ScientificName < b.ScientificName
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Hour != b.Hour {
+			return a.Hour < b.Hour
+		}
+		return a.Override && !b.Override
+	})
+	return rules, r.learnedAt
+}
+
+// Match returns the rule applied to a detection of the species from the source at the hour
+// of day. Overrides are matched before learned rules, and more specific contexts before
+// general ones: source and hour, source, hour, then the species alone.
+func (r *AdaptiveRules) Match(scientificName, source string, hour int) (AdaptiveRule, bool) {
+	keys := make([]AdaptiveRuleKey, 0, 4)
+	if source != "" {
+		keys = append(keys,
+			AdaptiveRuleKey{ScientificName: scientificName, Source: source, Hour: hour},
+			AdaptiveRuleKey{ScientificName: scientificName, Source: source, Hour: AnyHour})
+	}
+	keys = append(keys,
+		AdaptiveRuleKey{ScientificName: scientificName, Hour: hour},
+		AdaptiveRuleKey{ScientificName: scientificName, Hour: AnyHour})
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ruleSet := range []map[AdaptiveRuleKey]*AdaptiveRule{r.overrides, r.learned} {
+		for _, key := range keys {
+			if rule, ok := ruleSet[key]; ok {
+				return *rule, true
+			}
+		}
+	}
+	return AdaptiveRule{}, false
+}
+
+// recordSuppression counts a detection suppressed by the rule
+func (r *AdaptiveRules) recordSuppression(rule *AdaptiveRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.suppressed[suppressionKey{rule.AdaptiveRuleKey, rule.Override}]++
+}
+
+// Suppresses reports whether the rule suppresses a detection with the confidence
+func (rule *AdaptiveRule) Suppresses(confidence float32) bool {
+	switch rule.Action {
+	case conf.AdaptiveRuleSuppress:
+		return true
+	case conf.AdaptiveRuleRaiseThreshold:
+		return float64(confidence) <= rule.MinConfidence
+	default:
+		return false
+	}
+}
+
+// reviewStats are the review outcomes of a context
+type reviewStats struct {
+	commonName       string
+	reviews          int
+	falsePositives   int
+	maxFalsePositive float64 // highest confidence of a false positive
+}
+
+// learnAdaptiveRules learns a rule for each context with enough reviewed detections and a
+// false positive rate of at least the raise rate. Contexts at or above the suppress rate
+// suppress all detections, others raise the threshold to the highest confidence of their
+// false positives.
+func learnAdaptiveRules(outcomes []datastore.ReviewOutcome, settings *conf.AdaptiveRulesSettings) map[AdaptiveRuleKey]*AdaptiveRule {
+	stats := make(map[AdaptiveRuleKey]*reviewStats)
+	for i := range outcomes {
+		outcome := &outcomes[i]
+		hour := AnyHour
+		if len(outcome.Time) >= 2 {
+			if h, err := strconv.Atoi(outcome.Time[:2]); err == nil && h >= 0 && h <= 23 {
+				hour = h
+			}
+		}
+
+		keys := []AdaptiveRuleKey{{ScientificName: outcome.ScientificName, Hour: AnyHour}}
+		if hour != AnyHour {
+			keys = append(keys, AdaptiveRuleKey{ScientificName: outcome.ScientificName, Hour: hour})
+		}
+		if outcome.SourceID != "" {
+			keys = append(keys, AdaptiveRuleKey{ScientificName: outcome.ScientificName, Source: outcome.SourceID, Hour: AnyHour})
+			if hour != AnyHour {
+				keys = append(keys, AdaptiveRuleKey{ScientificName: outcome.ScientificName, Source: outcome.SourceID, Hour: hour})
+			}
+		}
+
+		for _, key := range keys {
+			s, ok := stats[key]
+			if !ok {
+				s = &reviewStats{commonName: outcome.CommonName}
+				stats[key] = s
+			}
+			s.reviews++
+			if outcome.Verified == "false_positive" {
+				s.falsePositives++
+				s.maxFalsePositive = max(s.maxFalsePositive, outcome.Confidence)
+			}
+		}
+	}
+
+	rules := make(map[AdaptiveRuleKey]*AdaptiveRule)
+	for key, s := range stats {
+		if s.reviews < settings.MinReviews {
+			continue
+		}
+		rate := float64(s.falsePositives) / float64(s.reviews)
+		if rate < settings.RaiseRate {
+			continue
+		}
+		rule := &AdaptiveRule{
+			AdaptiveRuleKey:   key,
+			CommonName:        s.commonName,
+			Action:            conf.AdaptiveRuleRaiseThreshold,
+			MinConfidence:     s.maxFalsePositive,
+			Reviews:           s.reviews,
+			FalsePositives:    s.falsePositives,
+			FalsePositiveRate: rate,
+		}
+		if rate >= settings.SuppressRate {
+			rule.Action = conf.AdaptiveRuleSuppress
+			rule.MinConfidence = 0
+		}
+		rules[key] = rule
+	}
+	return rules
+}
+
+// isSuppressedByAdaptiveRule reports whether a detection that passed the other filters is
+// suppressed by an adaptive rule, logging every suppression
+func (p *Processor) isSuppressedByAdaptiveRule(result *datastore.Results, scientificName, source string, hour int) bool {
+	rules := p.GetAdaptiveRules()
+	if rules == nil {
+		return false
+	}
+	// Rules are learned from the sanitized source IDs stored with detections
+	rule, ok := rules.Match(scientificName, privacy.SanitizeRTSPUrl(source), hour)
+	if !ok || !rule.Suppresses(result.Confidence) {
+		return false
+	}
+
+	rules.recordSuppression(&rule)
+	GetLogger().Info("Detection suppressed by adaptive rule",
+		"species", result.Species,
+		"confidence", result.Confidence,
+		"source", p.getDisplayNameForSource(source),
+		"hour", hour,
+		"action", rule.Action,
+		"min_confidence", rule.MinConfidence,
+		"rule_source", rule.Source,
+		"rule_hour", rule.Hour,
+		"false_positive_rate", rule.FalsePositiveRate,
+		"reviews", rule.Reviews,
+		"override", rule.Override,
+		"operation", "adaptive_rule_filter")
+	return true
+}
+
+// startAdaptiveRules starts learning false positive rules from reviewed detections if enabled
+func (p *Processor) startAdaptiveRules() {
+	settings := &p.Settings.Realtime.AdaptiveRules
+	if !settings.Enabled {
+		return
+	}
+
+	rules := NewAdaptiveRules(settings, p.Ds)
+	p.SetAdaptiveRules(rules)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.adaptiveRulesCancel = cancel
+	go rules.run(ctx)
+
+	GetLogger().Info("Adaptive rules enabled",
+		"window_days", settings.WindowDays,
+		"min_reviews", settings.MinReviews,
+		"raise_rate", settings.RaiseRate,
+		"suppress_rate", settings.SuppressRate,
+		"overrides", len(settings.Overrides),
+		"operation", "adaptive_rules_config")
+}
+
+// SetAdaptiveRules safely sets the false positive rules learned from reviews
+func (p *Processor) SetAdaptiveRules(rules *AdaptiveRules) {
+	p.adaptiveRulesMu.Lock()
+	defer p.adaptiveRulesMu.Unlock()
+	p.adaptiveRules = rules
+}
+
+// GetAdaptiveRules safely returns the false positive rules learned from reviews, nil when disabled
+func (p *Processor) GetAdaptiveRules() *AdaptiveRules {
+	p.adaptiveRulesMu.RLock()
+	defer p.adaptiveRulesMu.RUnlock()
+	return p.adaptiveRules
+}
@@ -0,0 +1,184 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// reviewOutcomeDatastore returns fixed review outcomes
+type reviewOutcomeDatastore struct {
+	outcomes  []datastore.ReviewOutcome
+	startDate string
+}
+
+func (s *reviewOutcomeDatastore) GetReviewOutcomes(startDate string) ([]datastore.ReviewOutcome, error) {
+	s.startDate = startDate
+	return s.outcomes, nil
+}
+
+func newAdaptiveRulesTestSettings() *conf.AdaptiveRulesSettings {
+	return &conf.AdaptiveRulesSettings{
+		Enabled:         true,
+		WindowDays:      90,
+		MinReviews:      4,
+		RaiseRate:       0.5,
+		SuppressRate:    0.9,
+		RefreshInterval: 60,
+	}
+}
+
+// reviewOutcomes returns reviewed detections of a species from a source at a time of day
+func reviewOutcomes(scientificName, source, clock string, correct, falsePositives int, confidence float64) []datastore.ReviewOutcome {
+	outcomes := make([]datastore.ReviewOutcome, 0, correct+falsePositives)
+	for i := range correct + falsePositives {
+		verified := "correct"
+		if i >= correct {
+			verified = "false_positive"
+		}
+		outcomes = append(outcomes, datastore.ReviewOutcome{
+			ScientificName: scientificName,
+			CommonName:     scientificName + " common",
+			SourceID:       source,
+			Date:           "2025-05-01",
+			Time:           clock,
+			Confidence:     confidence,
+			Verified:       verified,
+		})
+	}
+	return outcomes
+}
+
+// ravenOutcomes returns ravens reviewed as false positives at dawn on the first source and
+// as correct in the afternoon
+func ravenOutcomes() []datastore.ReviewOutcome {
+	outcomes := reviewOutcomes("Corvus corax", "rtsp_1", "05:10:00", 0, 5, 0.8)
+	return append(outcomes, reviewOutcomes("Corvus corax", "rtsp_1", "14:00:00", 6, 0, 0.9)...)
+}
+
+func TestLearnAdaptiveRules(t *testing.T) {
+	t.Parallel()
+
+	outcomes := ravenOutcomes()
+	outcomes = append(outcomes, reviewOutcomes("Corvus corax", "rtsp_2", "05:20:00", 2, 0, 0.9)...)
+	// Tawny owls are half false positives everywhere
+	outcomes = append(outcomes, reviewOutcomes("Strix aluco", "", "23:00:00", 3, 1, 0.6)...)
+	outcomes = append(outcomes, reviewOutcomes("Strix aluco", "", "23:30:00", 0, 1, 0.7)...)
+	outcomes = append(outcomes, reviewOutcomes("Strix aluco", "", "22:00:00", 0, 1, 0.65)...)
+
+	rules := learnAdaptiveRules(outcomes, newAdaptiveRulesTestSettings())
+
+	dawn := rules[AdaptiveRuleKey{ScientificName: "Corvus corax", Source: "rtsp_1", Hour: 5}]
+	require.NotNil(t, dawn, "false positives at dawn on the source are learned")
+	assert.Equal(t, conf.AdaptiveRuleSuppress, dawn.Action)
+	assert.Equal(t, 5, dawn.Reviews)
+	assert.InDelta(t, 1, dawn.FalsePositiveRate, 1e-9)
+	assert.Equal(t, "Corvus corax common", dawn.CommonName)
+
+	hour := rules[AdaptiveRuleKey{ScientificName: "Corvus corax", Hour: 5}]
+	require.NotNil(t, hour, "the dawn hour of all sources is learned")
+	assert.Equal(t, conf.AdaptiveRuleRaiseThreshold, hour.Action)
+	assert.InDelta(t, 0.8, hour.MinConfidence, 1e-9)
+
+	assert.NotContains(t, rules, AdaptiveRuleKey{ScientificName: "Corvus corax", Hour: AnyHour},
+		"ravens are mostly correct over the day")
+	assert.NotContains(t, rules, AdaptiveRuleKey{ScientificName: "Corvus corax", Source: "rtsp_2", Hour: 5},
+		"contexts with too few reviews get no rule")
+
+	owl := rules[AdaptiveRuleKey{ScientificName: "Strix aluco", Hour: AnyHour}]
+	require.NotNil(t, owl)
+	assert.Equal(t, conf.AdaptiveRuleRaiseThreshold, owl.Action)
+	assert.InDelta(t, 0.7, owl.MinConfidence, 1e-9, "threshold is raised to the highest false positive")
+	assert.Equal(t, 3, owl.FalsePositives)
+}
+
+func TestAdaptiveRulesMatch(t *testing.T) {
+	t.Parallel()
+
+	settings := newAdaptiveRulesTestSettings()
+	store := &reviewOutcomeDatastore{outcomes: ravenOutcomes()}
+	rules := NewAdaptiveRules(settings, store)
+	require.NoError(t, rules.Refresh())
+	assert.NotEmpty(t, store.startDate)
+
+	rule, ok := rules.Match("Corvus corax", "rtsp_1", 5)
+	require.True(t, ok)
+	assert.Equal(t, AdaptiveRuleKey{ScientificName: "Corvus corax", Source: "rtsp_1", Hour: 5}, rule.AdaptiveRuleKey,
+		"the most specific context is matched")
+	assert.True(t, rule.Suppresses(0.99))
+
+	rule, ok = rules.Match("Corvus corax", "rtsp_2", 5)
+	require.True(t, ok, "the hour rule of all sources matches other sources")
+	assert.Equal(t, "", rule.Source)
+
+	_, ok = rules.Match("Corvus corax", "rtsp_1", 12)
+	assert.False(t, ok)
+	_, ok = rules.Match("Strix aluco", "rtsp_1", 5)
+	assert.False(t, ok)
+
+	// Overrides take precedence over learned rules of any context
+	hour := 12
+	rules.SetOverrides([]conf.AdaptiveRuleOverride{
+		{Species: "Corvus corax", Action: conf.AdaptiveRuleAllow},
+		{Species: "Corvus corax", Source: "rtsp_1", Hour: &hour, Action: conf.AdaptiveRuleRaiseThreshold, MinConfidence: 0.9},
+	})
+	rule, ok = rules.Match("Corvus corax", "rtsp_1", 5)
+	require.True(t, ok)
+	assert.True(t, rule.Override)
+	assert.False(t, rule.Suppresses(0.5), "allowed species are not suppressed")
+
+	rule, ok = rules.Match("Corvus corax", "rtsp_1", 12)
+	require.True(t, ok)
+	assert.True(t, rule.Suppresses(0.9))
+	assert.False(t, rule.Suppresses(0.91))
+
+	all, learnedAt := rules.Rules()
+	assert.False(t, learnedAt.IsZero())
+	require.Len(t, all, 4)
+	assert.True(t, all[0].Override, "overrides are listed first")
+	assert.Equal(t, AnyHour, all[0].Hour)
+}
+
+func TestIsSuppressedByAdaptiveRule(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.AdaptiveRules = *newAdaptiveRulesTestSettings()
+	settings.Realtime.AdaptiveRules.Overrides = []conf.AdaptiveRuleOverride{
+		{Species: "Strix aluco", Action: conf.AdaptiveRuleRaiseThreshold, MinConfidence: 0.8},
+	}
+	store := &reviewOutcomeDatastore{outcomes: ravenOutcomes()}
+	p := &Processor{Settings: settings}
+	assert.False(t, p.isSuppressedByAdaptiveRule(&datastore.Results{Species: "Corvus corax_Common Raven", Confidence: 0.9}, "Corvus corax", "rtsp_1", 5),
+		"nothing is suppressed when adaptive rules are disabled")
+
+	rules := NewAdaptiveRules(&settings.Realtime.AdaptiveRules, store)
+	require.NoError(t, rules.Refresh())
+	p.SetAdaptiveRules(rules)
+
+	raven := datastore.Results{Species: "Corvus corax_Common Raven", Confidence: 0.95}
+	assert.True(t, p.isSuppressedByAdaptiveRule(&raven, "Corvus corax", "rtsp_1", 5))
+	assert.False(t, p.isSuppressedByAdaptiveRule(&raven, "Corvus corax", "rtsp_1", 6))
+
+	owl := datastore.Results{Species: "Strix aluco_Tawny Owl", Confidence: 0.75}
+	assert.True(t, p.isSuppressedByAdaptiveRule(&owl, "Strix aluco", "rtsp_1", 23))
+	owl.Confidence = 0.85
+	assert.False(t, p.isSuppressedByAdaptiveRule(&owl, "Strix aluco", "rtsp_1", 23))
+
+	all, _ := p.GetAdaptiveRules().Rules()
+	suppressed := make(map[string]int64)
+	for i := range all {
+		suppressed[all[i].ScientificName] += all[i].Suppressed
+	}
+	assert.Equal(t, map[string]int64{"Corvus corax": 1, "Strix aluco": 1}, suppressed)
+
+	// Detections are matched against the hour they were recorded, not the hour they are processed
+	settings.BirdNET.RangeFilter.Species = []string{"Corvus corax_Common Raven"}
+	filtered, _ := p.shouldFilterDetection(raven, "Corvus corax", "Common Raven", "corvus corax_common raven", 0.5, "rtsp_1", 5)
+	assert.True(t, filtered)
+	filtered, _ = p.shouldFilterDetection(raven, "Corvus corax", "Common Raven", "corvus corax_common raven", 0.5, "rtsp_1", 6)
+	assert.False(t, filtered)
+}
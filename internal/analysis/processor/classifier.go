@@ -0,0 +1,82 @@
+// classifier.go: post-filter of detections with the classifier trained from reviewed detections
+package processor
+
+import (
+	"log"
+
+	"github.com/tphakala/birdnet-go/internal/classifier"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// loadClassifier loads the classifier trained from reviewed detections when it is enabled.
+// A classifier that cannot be used is logged and detections are not filtered.
+func (p *Processor) loadClassifier() {
+	settings := &p.Settings.Realtime.Classifier
+	if !settings.Enabled {
+		return
+	}
+
+	c, err := classifier.Load(settings.Path)
+	if err != nil {
+		GetLogger().Warn("Failed to load classifier",
+			"error", err,
+			"path", settings.Path,
+			"operation", "classifier_load")
+		log.Printf("⚠️ Classifier not loaded: %v", err)
+		return
+	}
+	if !p.Settings.BirdNET.Embeddings {
+		GetLogger().Warn("Classifier requires embeddings, enable birdnet.embeddings",
+			"path", settings.Path,
+			"operation", "classifier_load")
+		log.Printf("⚠️ Classifier not loaded: it requires birdnet.embeddings to be enabled")
+		return
+	}
+	if p.Bn != nil && c.Model != p.Bn.ModelInfo.ID {
+		GetLogger().Warn("Classifier was trained on embeddings of another model",
+			"classifier_model", c.Model,
+			"model", p.Bn.ModelInfo.ID,
+			"operation", "classifier_load")
+		log.Printf("⚠️ Classifier not loaded: it was trained on embeddings of model %s, not %s", c.Model, p.Bn.ModelInfo.ID)
+		return
+	}
+
+	p.classifier = c
+	GetLogger().Info("Classifier loaded",
+		"version", c.Version,
+		"species", len(c.Species),
+		"operation", "classifier_load")
+	log.Printf("Classifier version %d loaded for %d species", c.Version, len(c.Species))
+}
+
+// applyClassifier scores a result with the classifier, scaling its confidence by the
+// likelihood of being correct when re-scoring is enabled. It returns false when the
+// detection is less likely to be correct than the classifier threshold and is suppressed.
+// Results of species without a head, chunks without an embedding and results of additional
+// models are kept as they are, the classifier scores BirdNET embeddings of BirdNET results.
+func (p *Processor) applyClassifier(result *datastore.Results, scientificName string, embedding []float32) bool {
+	if p.classifier == nil || len(embedding) == 0 || !p.isBirdNETModel(result.Model) {
+		return true
+	}
+	probability, ok := p.classifier.Score(scientificName, embedding)
+	if !ok {
+		return true
+	}
+
+	settings := &p.Settings.Realtime.Classifier
+	if probability < settings.Threshold {
+		if p.Settings.Debug {
+			GetLogger().Debug("Detection suppressed by classifier",
+				"species", result.Species,
+				"confidence", result.Confidence,
+				"probability_correct", probability,
+				"threshold", settings.Threshold,
+				"operation", "classifier_filter")
+		}
+		return false
+	}
+	if settings.Rescore {
+		result.Confidence *= float32(probability)
+	}
+	return true
+}
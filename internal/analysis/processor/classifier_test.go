@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/classifier"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// testClassifier returns a classifier whose head for Corvus corax scores embeddings
+// along the first dimension as correct and along the second as false positives
+func testClassifier() *classifier.Classifier {
+	return &classifier.Classifier{
+		Format:     classifier.FormatVersion,
+		Model:      "BirdNET_GLOBAL_6K_V2.4",
+		Dimensions: 2,
+		Species: map[string]*classifier.Head{
+			"Corvus corax": {Weights: []float64{4, -4}},
+		},
+	}
+}
+
+func TestApplyClassifier(t *testing.T) {
+	t.Parallel()
+
+	correct := []float32{1, 0}
+	falsePositive := []float32{0, 1}
+
+	tests := []struct {
+		name           string
+		scientificName string
+		embedding      []float32
+		model          string
+		rescore        bool
+		wantKept       bool
+		wantRescored   bool
+	}{
+		{"likely correct is kept", "Corvus corax", correct, testBirdNETModel, false, true, false},
+		{"likely false positive is suppressed", "Corvus corax", falsePositive, testBirdNETModel, false, false, false},
+		{"species without head is kept", "Strix aluco", falsePositive, testBirdNETModel, false, true, false},
+		{"chunk without embedding is kept", "Corvus corax", nil, testBirdNETModel, false, true, false},
+		{"kept detection is re-scored", "Corvus corax", correct, testBirdNETModel, true, true, true},
+		{"additional model result is kept", "Corvus corax", falsePositive, "bats-eu", true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settings := &conf.Settings{}
+			settings.Realtime.Classifier = conf.ClassifierSettings{Enabled: true, Threshold: 0.5, Rescore: tt.rescore}
+			p := &Processor{
+				Settings:   settings,
+				Bn:         &birdnet.BirdNET{Settings: settings, ModelInfo: birdnet.ModelInfo{ID: testBirdNETModel}},
+				classifier: testClassifier(),
+			}
+
+			result := datastore.Results{Species: tt.scientificName + "_Common", Confidence: 0.8, Model: tt.model}
+			kept := p.applyClassifier(&result, tt.scientificName, tt.embedding)
+			assert.Equal(t, tt.wantKept, kept)
+			if tt.wantRescored {
+				assert.Less(t, result.Confidence, float32(0.8))
+				assert.Greater(t, result.Confidence, float32(0.7))
+			} else {
+				assert.InDelta(t, 0.8, result.Confidence, 1e-6)
+			}
+		})
+	}
+}
+
+func TestLoadClassifier(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "classifiers")
+	_, err := classifier.Save(dir, testClassifier())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		embeddings bool
+		path       string
+		wantLoaded bool
+	}{
+		{"disabled", false, true, dir, false},
+		{"embeddings disabled", true, false, dir, false},
+		{"missing classifier", true, true, filepath.Join(dir, "missing"), false},
+		{"loaded", true, true, dir, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settings := &conf.Settings{}
+			settings.BirdNET.Embeddings = tt.embeddings
+			settings.Realtime.Classifier = conf.ClassifierSettings{Enabled: tt.enabled, Path: tt.path, Threshold: 0.5}
+			p := &Processor{Settings: settings}
+
+			p.loadClassifier()
+			if tt.wantLoaded {
+				require.NotNil(t, p.classifier)
+				assert.Equal(t, 1, p.classifier.Version)
+			} else {
+				assert.Nil(t, p.classifier)
+			}
+		})
+	}
+}
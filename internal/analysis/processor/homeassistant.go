@@ -0,0 +1,320 @@
+// homeassistant.go: Home Assistant MQTT discovery and state publishing for the processor
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// homeAssistantStateInterval is how often daily statistics and system health are published
+const homeAssistantStateInterval = time.Minute
+
+// HomeAssistantDatastore defines the datastore methods needed for the daily statistics
+type HomeAssistantDatastore interface {
+	GetSpeciesSummaryData(startDate, endDate string) ([]datastore.SpeciesSummaryData, error)
+}
+
+// HomeAssistantStats is the payload of the daily statistics topic
+type HomeAssistantStats struct {
+	Date            string    `json:"date"`
+	SpeciesToday    int       `json:"species_today"`
+	DetectionsToday int       `json:"detections_today"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// HomeAssistantSystemHealth is the payload of the system health topic
+type HomeAssistantSystemHealth struct {
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryPercent float64   `json:"memory_percent"`
+	DiskPercent   float64   `json:"disk_percent"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// HomeAssistantSoundLevel is the payload of a source's sound level topic
+type HomeAssistantSoundLevel struct {
+	LevelDB   float64   `json:"level_db"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HomeAssistantPublisher publishes Home Assistant discovery configs and the state topics
+// read by the discovered entities. Methods do nothing while discovery is disabled, so the
+// publisher can be used unconditionally and follows settings changes at runtime.
+type HomeAssistantPublisher struct {
+	settings  *conf.Settings
+	ds        HomeAssistantDatastore
+	startTime time.Time
+	refresh   chan struct{}
+	mu        sync.Mutex
+	announced map[string]struct{} // Sources whose discovery configs were published
+}
+
+// NewHomeAssistantPublisher creates a Home Assistant publisher
+func NewHomeAssistantPublisher(settings *conf.Settings, ds HomeAssistantDatastore) *HomeAssistantPublisher {
+	return &HomeAssistantPublisher{
+		settings:  settings,
+		ds:        ds,
+		startTime: time.Now(),
+		refresh:   make(chan struct{}, 1),
+		announced: make(map[string]struct{}),
+	}
+}
+
+// Enabled reports whether MQTT and Home Assistant discovery are enabled
+func (h *HomeAssistantPublisher) Enabled() bool {
+	return h != nil && h.settings.Realtime.MQTT.Enabled && h.settings.Realtime.MQTT.HomeAssistant.Enabled
+}
+
+// Refresh requests discovery configs and state to be republished, e.g. after the
+// MQTT client was replaced
+func (h *HomeAssistantPublisher) Refresh() {
+	if h == nil {
+		return
+	}
+	select {
+	case h.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// PublishDiscovery publishes the retained discovery configs of the node wide entities.
+// Source entities are announced again with their next state update.
+func (h *HomeAssistantPublisher) PublishDiscovery(ctx context.Context, client mqtt.Client) error {
+	if !h.Enabled() {
+		return nil
+	}
+
+	messages, err := mqtt.NewHomeAssistantDiscovery(h.settings).DeviceEntities()
+	if err != nil {
+		return err
+	}
+	if err := publishDiscoveryMessages(ctx, client, messages); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.announced = make(map[string]struct{})
+	h.mu.Unlock()
+
+	GetLogger().Info("Published Home Assistant discovery configs",
+		"entities", len(messages),
+		"discovery_prefix", h.settings.Realtime.MQTT.HomeAssistant.DiscoveryPrefix,
+		"operation", "homeassistant_discovery")
+	return nil
+}
+
+// PublishDetection publishes a detection payload to the species and source sub-topics
+func (h *HomeAssistantPublisher) PublishDetection(ctx context.Context, client mqtt.Client, note *datastore.Note, payload string) error {
+	if !h.Enabled() || client == nil {
+		return nil
+	}
+	discovery := mqtt.NewHomeAssistantDiscovery(h.settings)
+
+	if note.ScientificName != "" {
+		if err := client.Publish(ctx, discovery.SpeciesTopic(note.ScientificName), payload); err != nil {
+			return err
+		}
+	}
+
+	if note.Source.ID == "" {
+		return nil
+	}
+	if err := h.announceSource(ctx, client, discovery, note.Source.ID, note.Source.DisplayName); err != nil {
+		return err
+	}
+	return client.Publish(ctx, discovery.SourceDetectionTopic(note.Source.ID), payload)
+}
+
+// PublishSoundLevel publishes the overall sound level of a source to its sub-topic
+func (h *HomeAssistantPublisher) PublishSoundLevel(ctx context.Context, client mqtt.Client, sourceID, displayName string, levelDB float64, timestamp time.Time) error {
+	if !h.Enabled() || client == nil || sourceID == "" {
+		return nil
+	}
+	discovery := mqtt.NewHomeAssistantDiscovery(h.settings)
+
+	if err := h.announceSource(ctx, client, discovery, sourceID, displayName); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(HomeAssistantSoundLevel{
+		LevelDB:   math.Round(levelDB*10) / 10,
+		Name:      displayName,
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryMQTTPublish).
+			Context("operation", "marshal_homeassistant_sound_level").
+			Context("source", sourceID).
+			Build()
+	}
+	return client.Publish(ctx, discovery.SourceSoundLevelTopic(sourceID), string(payload))
+}
+
+// PublishState publishes the daily detection statistics and system health
+func (h *HomeAssistantPublisher) PublishState(ctx context.Context, client mqtt.Client) error {
+	if !h.Enabled() {
+		return nil
+	}
+	discovery := mqtt.NewHomeAssistantDiscovery(h.settings)
+	now := time.Now()
+
+	if h.ds != nil {
+		stats, err := h.dailyStats(now)
+		if err != nil {
+			return err
+		}
+		if err := publishJSON(ctx, client, discovery.StatsTopic(), stats); err != nil {
+			return err
+		}
+	}
+
+	return publishJSON(ctx, client, discovery.SystemTopic(), h.systemHealth(now))
+}
+
+// run publishes discovery configs whenever a new client connects and state periodically
+func (h *HomeAssistantPublisher) run(ctx context.Context, getClient func() mqtt.Client) {
+	ticker := time.NewTicker(homeAssistantStateInterval)
+	defer ticker.Stop()
+
+	var discovered mqtt.Client
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.refresh:
+			discovered = nil
+		case <-ticker.C:
+		}
+
+		client := getClient()
+		if !h.Enabled() || client == nil || !client.IsConnected() {
+			// Republish discovery once the connection is back, the broker may have lost retained messages
+			discovered = nil
+			continue
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, MQTTPublishTimeout)
+		if client != discovered {
+			if err := h.PublishDiscovery(publishCtx, client); err != nil {
+				cancel()
+				GetLogger().Warn("Failed to publish Home Assistant discovery configs",
+					"error", err,
+					"operation", "homeassistant_discovery")
+				continue
+			}
+			discovered = client
+		}
+		if err := h.PublishState(publishCtx, client); err != nil {
+			GetLogger().Warn("Failed to publish Home Assistant state",
+				"error", err,
+				"operation", "homeassistant_state")
+		}
+		cancel()
+	}
+}
+
+// announceSource publishes the discovery configs of a source the first time it is seen
+func (h *HomeAssistantPublisher) announceSource(ctx context.Context, client mqtt.Client, discovery *mqtt.HomeAssistantDiscovery, sourceID, displayName string) error {
+	h.mu.Lock()
+	_, done := h.announced[sourceID]
+	h.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	messages, err := discovery.SourceEntities(sourceID, displayName)
+	if err != nil {
+		return err
+	}
+	if err := publishDiscoveryMessages(ctx, client, messages); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.announced[sourceID] = struct{}{}
+	h.mu.Unlock()
+	return nil
+}
+
+// dailyStats counts the species and detections of the current day
+func (h *HomeAssistantPublisher) dailyStats(now time.Time) (*HomeAssistantStats, error) {
+	date := now.Format("2006-01-02")
+	summaries, err := h.ds.GetSpeciesSummaryData(date, date)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &HomeAssistantStats{
+		Date:         date,
+		SpeciesToday: len(summaries),
+		Timestamp:    now,
+	}
+	for i := range summaries {
+		stats.DetectionsToday += summaries[i].Count
+	}
+	return stats, nil
+}
+
+// systemHealth collects resource usage, readings that fail are reported as zero
+func (h *HomeAssistantPublisher) systemHealth(now time.Time) *HomeAssistantSystemHealth {
+	health := &HomeAssistantSystemHealth{
+		UptimeSeconds: int64(now.Sub(h.startTime).Seconds()),
+		Timestamp:     now,
+	}
+
+	if percent, err := cpu.Percent(0, false); err == nil && len(percent) > 0 {
+		health.CPUPercent = math.Round(percent[0]*10) / 10
+	}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		health.MemoryPercent = math.Round(vm.UsedPercent*10) / 10
+	}
+
+	// Report the disk holding the audio clips, which is the one that fills up
+	path := h.settings.Realtime.Audio.Export.Path
+	if path == "" {
+		path = "."
+	}
+	if usage, err := disk.Usage(path); err == nil {
+		health.DiskPercent = math.Round(usage.UsedPercent*10) / 10
+	}
+
+	return health
+}
+
+// publishDiscoveryMessages publishes discovery configs as retained messages
+func publishDiscoveryMessages(ctx context.Context, client mqtt.Client, messages []mqtt.DiscoveryMessage) error {
+	for _, message := range messages {
+		if err := client.PublishRetained(ctx, message.Topic, message.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishJSON marshals a state payload and publishes it
+func publishJSON(ctx context.Context, client mqtt.Client, topic string, value any) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryMQTTPublish).
+			Context("operation", "marshal_homeassistant_state").
+			Context("topic", topic).
+			Build()
+	}
+	return client.Publish(ctx, topic, string(payload))
+}
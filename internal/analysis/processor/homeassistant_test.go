@@ -0,0 +1,186 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// recordedMessage is a message published through recordingMqttClient
+type recordedMessage struct {
+	Topic    string
+	Payload  string
+	Retained bool
+}
+
+// recordingMqttClient records every published message for testing
+type recordingMqttClient struct {
+	mu       sync.Mutex
+	messages []recordedMessage
+}
+
+func (m *recordingMqttClient) Connect(_ context.Context) error { return nil }
+func (m *recordingMqttClient) Disconnect()                     {}
+func (m *recordingMqttClient) IsConnected() bool               { return true }
+func (m *recordingMqttClient) SetControlChannel(_ chan string) {}
+func (m *recordingMqttClient) TestConnection(_ context.Context, _ chan<- mqtt.TestResult) {
+}
+
+func (m *recordingMqttClient) Publish(_ context.Context, topic, payload string) error {
+	m.record(topic, payload, false)
+	return nil
+}
+
+func (m *recordingMqttClient) PublishRetained(_ context.Context, topic, payload string) error {
+	m.record(topic, payload, true)
+	return nil
+}
+
+func (m *recordingMqttClient) Subscribe(context.Context, string, mqtt.MessageHandler) error {
+	return nil
+}
+
+func (m *recordingMqttClient) record(topic, payload string, retained bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, recordedMessage{Topic: topic, Payload: payload, Retained: retained})
+}
+
+// topics returns the topics of published messages with the given prefix
+func (m *recordingMqttClient) topics(prefix string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var topics []string
+	for _, msg := range m.messages {
+		if strings.HasPrefix(msg.Topic, prefix) {
+			topics = append(topics, msg.Topic)
+		}
+	}
+	return topics
+}
+
+func (m *recordingMqttClient) last(topic string) (recordedMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Topic == topic {
+			return m.messages[i], true
+		}
+	}
+	return recordedMessage{}, false
+}
+
+// summaryDatastore returns fixed species summaries
+type summaryDatastore struct {
+	summaries []datastore.SpeciesSummaryData
+}
+
+func (s *summaryDatastore) GetSpeciesSummaryData(_, _ string) ([]datastore.SpeciesSummaryData, error) {
+	return s.summaries, nil
+}
+
+func newHomeAssistantTestSettings() *conf.Settings {
+	settings := &conf.Settings{}
+	settings.Main.Name = "node"
+	settings.Realtime.MQTT.Enabled = true
+	settings.Realtime.MQTT.Topic = "birdnet"
+	settings.Realtime.MQTT.HomeAssistant.Enabled = true
+	settings.Realtime.MQTT.HomeAssistant.DiscoveryPrefix = "homeassistant"
+	return settings
+}
+
+func TestHomeAssistantPublisherDisabled(t *testing.T) {
+	t.Parallel()
+
+	settings := newHomeAssistantTestSettings()
+	settings.Realtime.MQTT.HomeAssistant.Enabled = false
+	publisher := NewHomeAssistantPublisher(settings, nil)
+	client := &recordingMqttClient{}
+	ctx := context.Background()
+
+	require.NoError(t, publisher.PublishDiscovery(ctx, client))
+	require.NoError(t, publisher.PublishState(ctx, client))
+	require.NoError(t, publisher.PublishSoundLevel(ctx, client, "mic", "Mic", -40, time.Now()))
+	assert.Empty(t, client.messages)
+
+	// A nil publisher is a valid disabled publisher
+	var nilPublisher *HomeAssistantPublisher
+	assert.False(t, nilPublisher.Enabled())
+	require.NoError(t, nilPublisher.PublishDetection(ctx, client, &datastore.Note{}, "{}"))
+	nilPublisher.Refresh()
+}
+
+func TestHomeAssistantPublisherDetection(t *testing.T) {
+	t.Parallel()
+
+	publisher := NewHomeAssistantPublisher(newHomeAssistantTestSettings(), nil)
+	client := &recordingMqttClient{}
+	ctx := context.Background()
+
+	note := &datastore.Note{
+		CommonName:     "Eurasian Blackbird",
+		ScientificName: "Turdus merula",
+		Source:         datastore.AudioSource{ID: "rtsp_87b89761", DisplayName: "Pond"},
+	}
+	require.NoError(t, publisher.PublishDetection(ctx, client, note, `{"CommonName":"Eurasian Blackbird"}`))
+	require.NoError(t, publisher.PublishDetection(ctx, client, note, `{"CommonName":"Eurasian Blackbird"}`))
+
+	species, ok := client.last("birdnet/species/turdus_merula")
+	require.True(t, ok)
+	assert.JSONEq(t, `{"CommonName":"Eurasian Blackbird"}`, species.Payload)
+	assert.Len(t, client.topics("birdnet/sources/rtsp_87b89761/detection"), 2)
+
+	// Source entities are announced once, as retained messages
+	discovery := client.topics("homeassistant/sensor/node/source_rtsp_87b89761_")
+	assert.Len(t, discovery, 2)
+	config, ok := client.last(discovery[0])
+	require.True(t, ok)
+	assert.True(t, config.Retained)
+
+	// Announcing again after a new discovery run
+	require.NoError(t, publisher.PublishDiscovery(ctx, client))
+	require.NoError(t, publisher.PublishSoundLevel(ctx, client, "rtsp_87b89761", "Pond", -41.26, time.Now()))
+	assert.Len(t, client.topics("homeassistant/sensor/node/source_rtsp_87b89761_"), 4)
+
+	level, ok := client.last("birdnet/sources/rtsp_87b89761/soundlevel")
+	require.True(t, ok)
+	var payload HomeAssistantSoundLevel
+	require.NoError(t, json.Unmarshal([]byte(level.Payload), &payload))
+	assert.InDelta(t, -41.3, payload.LevelDB, 0.001)
+}
+
+func TestHomeAssistantPublisherState(t *testing.T) {
+	t.Parallel()
+
+	ds := &summaryDatastore{summaries: []datastore.SpeciesSummaryData{
+		{ScientificName: "Turdus merula", Count: 12},
+		{ScientificName: "Erithacus rubecula", Count: 3},
+	}}
+	publisher := NewHomeAssistantPublisher(newHomeAssistantTestSettings(), ds)
+	client := &recordingMqttClient{}
+
+	require.NoError(t, publisher.PublishState(context.Background(), client))
+
+	msg, ok := client.last("birdnet/stats")
+	require.True(t, ok)
+	var stats HomeAssistantStats
+	require.NoError(t, json.Unmarshal([]byte(msg.Payload), &stats))
+	assert.Equal(t, 2, stats.SpeciesToday)
+	assert.Equal(t, 15, stats.DetectionsToday)
+	assert.False(t, msg.Retained)
+
+	msg, ok = client.last("birdnet/system")
+	require.True(t, ok)
+	var health HomeAssistantSystemHealth
+	require.NoError(t, json.Unmarshal([]byte(msg.Payload), &health))
+	assert.GreaterOrEqual(t, health.UptimeSeconds, int64(0))
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+const testBirdNETModel = "BirdNET_GLOBAL_6K_V2.4"
+
+// newModelSetProcessor returns a processor for results of BirdNET and an additional bat model
+func newModelSetProcessor(mergeModels bool) *Processor {
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	settings.BirdNET.MergeModels = mergeModels
+	settings.BirdNET.RangeFilter.Species = []string{"Strix aluco_Tawny Owl"}
+	settings.BirdNET.Models = []conf.ModelSettings{{ID: "bats", Enabled: true, Threshold: 0.3}}
+	return &Processor{
+		Settings:          settings,
+		Bn:                &birdnet.BirdNET{Settings: settings, ModelInfo: birdnet.ModelInfo{ID: testBirdNETModel}},
+		pendingDetections: make(map[string]PendingDetection),
+	}
+}
+
+func TestProcessResultsOfModelSet(t *testing.T) {
+	p := newModelSetProcessor(true)
+	item := birdnet.Results{
+		StartTime: time.Now(),
+		Source:    datastore.AudioSource{ID: "rtsp_1"},
+		Embedding: []float32{0.1, 0.2},
+		Results: []datastore.Results{
+			{Species: "Strix aluco_Tawny Owl", Confidence: 0.9, Model: testBirdNETModel},
+			{Species: "Bubo bubo_Eurasian Eagle-Owl", Confidence: 0.9, Model: testBirdNETModel},
+			{Species: "Myotis daubentonii_Daubenton's Bat", Confidence: 0.4, Model: "bats"},
+			{Species: "Pipistrellus pipistrellus_Common Pipistrelle", Confidence: 0.2, Model: "bats"},
+		},
+	}
+
+	detections := p.processResults(item)
+	require.Len(t, detections, 2)
+
+	// BirdNET results are range filtered and use the BirdNET threshold
+	assert.Equal(t, "Tawny Owl", detections[0].Note.CommonName)
+	assert.Equal(t, testBirdNETModel, detections[0].Model)
+
+	// Additional models are not range filtered and use their own threshold
+	assert.Equal(t, "Daubenton's Bat", detections[1].Note.CommonName)
+	assert.Equal(t, "bats", detections[1].Model)
+	assert.Len(t, detections[1].Results, 4, "merged detections keep the results of every model")
+	assert.Equal(t, item.Embedding, detections[1].Embedding, "detections carry the embedding of their chunk")
+}
+
+func TestModelSetThresholds(t *testing.T) {
+	p := newModelSetProcessor(true)
+	assert.InDelta(t, 0.3, p.getModelConfidenceThreshold("daubenton's bat", "bats"), 1e-6)
+	assert.InDelta(t, 0.8, p.getModelConfidenceThreshold("tawny owl", testBirdNETModel), 1e-6)
+
+	// Custom species thresholds apply to every model
+	p.Settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{"daubenton's bat": {Threshold: 0.6}}
+	assert.InDelta(t, 0.6, p.getModelConfidenceThreshold("daubenton's bat", "bats"), 1e-6)
+}
+
+func TestModelSetSeparateDetections(t *testing.T) {
+	results := []datastore.Results{
+		{Species: "Canis lupus familiaris_Dog", Confidence: 0.9, Model: testBirdNETModel},
+		{Species: "Canis lupus familiaris_Dog", Confidence: 0.7, Model: "mammals"},
+	}
+	birdnetDetection := Detections{Note: datastore.Note{CommonName: "Dog"}, Model: testBirdNETModel}
+	mammalDetection := Detections{Note: datastore.Note{CommonName: "Dog"}, Model: "mammals"}
+
+	merged := newModelSetProcessor(true)
+	assert.Equal(t, merged.pendingDetectionKey(&birdnetDetection), merged.pendingDetectionKey(&mammalDetection))
+	assert.Len(t, merged.detectionResults(results, "mammals"), 2)
+
+	separate := newModelSetProcessor(false)
+	assert.Equal(t, "dog", separate.pendingDetectionKey(&birdnetDetection))
+	assert.Equal(t, "mammals/dog", separate.pendingDetectionKey(&mammalDetection))
+	modelResults := separate.detectionResults(results, "mammals")
+	require.Len(t, modelResults, 1)
+	assert.Equal(t, "mammals", modelResults[0].Model)
+}
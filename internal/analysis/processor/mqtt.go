@@ -22,6 +22,26 @@ func (p *Processor) SetMQTTClient(client mqtt.Client) {
 	p.mqttMutex.Lock()
 	defer p.mqttMutex.Unlock()
 	p.MqttClient = client
+
+	if client != nil {
+		// Remote commands are forwarded to the control monitor
+		if p.controlChan != nil {
+			client.SetControlChannel(p.controlChan)
+		}
+		// Announce the new client to Home Assistant
+		p.HomeAssistant.Refresh()
+	}
+}
+
+// SetControlChannel sets the control channel used by the MQTT client for remote
+// commands. Passing nil detaches the current client before the channel is closed.
+func (p *Processor) SetControlChannel(ch chan string) {
+	p.mqttMutex.Lock()
+	defer p.mqttMutex.Unlock()
+	p.controlChan = ch
+	if p.MqttClient != nil {
+		p.MqttClient.SetControlChannel(ch)
+	}
 }
 
 // DisconnectMQTTClient safely disconnects and removes the MQTT client
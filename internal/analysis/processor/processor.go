@@ -15,6 +15,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/birdweather"
+	"github.com/tphakala/birdnet-go/internal/classifier"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
@@ -38,7 +39,9 @@ type Processor struct {
 	BwClient            *birdweather.BwClient
 	bwClientMutex       sync.RWMutex // Mutex to protect BwClient access
 	MqttClient          mqtt.Client
-	mqttMutex           sync.RWMutex // Mutex to protect MQTT client access
+	mqttMutex           sync.RWMutex            // Mutex to protect MQTT client access
+	HomeAssistant       *HomeAssistantPublisher // Publishes Home Assistant discovery and state topics
+	homeAssistantCancel context.CancelFunc      // Stops the Home Assistant state publisher
 	BirdImageCache      *imageprovider.BirdImageCache
 	EventTracker        *EventTracker
 	eventTrackerMu      sync.RWMutex         // Mutex to protect EventTracker access
@@ -56,8 +59,8 @@ type Processor struct {
 	pendingMutex        sync.Mutex // Mutex to protect access to pendingDetections
 	lastDogDetectionLog map[string]time.Time
 	dogDetectionMutex   sync.Mutex
-	detectionMutex      sync.RWMutex // Mutex to protect LastDogDetection and LastHumanDetection maps
-	controlChan         chan string
+	detectionMutex      sync.RWMutex       // Mutex to protect LastDogDetection and LastHumanDetection maps
+	controlChan         chan string        // Control signal channel handed to the MQTT client for remote commands
 	JobQueue            *jobqueue.JobQueue // Queue for managing job retries
 	workerCancel        context.CancelFunc // Function to cancel worker goroutines
 	// SSE related fields
@@ -71,6 +74,12 @@ type Processor struct {
 
 	// Log deduplication (extracted to separate type for SRP)
 	logDedup *LogDeduplicator // Handles log deduplication logic
+
+	classifier *classifier.Classifier // Classifier trained from reviewed detections, nil when disabled
+
+	adaptiveRules       *AdaptiveRules     // False positive rules learned from reviews, nil when disabled
+	adaptiveRulesMu     sync.RWMutex       // Mutex to protect adaptiveRules access
+	adaptiveRulesCancel context.CancelFunc // Stops learning the adaptive rules
 }
 
 // DynamicThreshold represents the dynamic threshold configuration for a species.
@@ -86,6 +95,8 @@ type Detections struct {
 	pcmData3s []byte              // 3s PCM data containing the detection
 	Note      datastore.Note      // Note containing highest match
 	Results   []datastore.Results // Full BirdNET prediction results
+	Model     string              // ID of the model that made the detection
+	Embedding []float32           // Embedding of the audio chunk of the detection, nil when embeddings are disabled
 }
 
 // PendingDetection struct represents a single detection held in memory,
@@ -121,8 +132,8 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		DynamicThresholds:   make(map[string]*DynamicThreshold),
 		pendingDetections:   make(map[string]PendingDetection),
 		lastDogDetectionLog: make(map[string]time.Time),
-		controlChan:         make(chan string, 10),  // Buffered channel to prevent blocking
 		JobQueue:            jobqueue.NewJobQueue(), // Initialize the job queue
+		HomeAssistant:       NewHomeAssistantPublisher(settings, ds),
 	}
 
 	// Initialize log deduplicator with configuration from settings
@@ -199,6 +210,12 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		}
 	}
 
+	// Load the classifier trained from reviewed detections if enabled
+	p.loadClassifier()
+
+	// Start learning false positive rules from reviewed detections if enabled
+	p.startAdaptiveRules()
+
 	// Start the detection processor
 	p.startDetectionProcessor()
 
@@ -224,6 +241,11 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		}
 	}
 
+	// Start the Home Assistant publisher before the MQTT client so the first client is discovered
+	haCtx, haCancel := context.WithCancel(context.Background())
+	p.homeAssistantCancel = haCancel
+	go p.HomeAssistant.run(haCtx, p.GetMQTTClient)
+
 	// Initialize MQTT client if enabled in settings
 	p.initializeMQTT(settings)
 
@@ -279,11 +301,12 @@ func (p *Processor) processDetections(item birdnet.Results) {
 		detection := detectionResults[i]
 		commonName := strings.ToLower(detection.Note.CommonName)
 		confidence := detection.Note.Confidence
+		pendingKey := p.pendingDetectionKey(&detection)
 
 		// Lock the mutex to ensure thread-safe access to shared resources
 		p.pendingMutex.Lock()
 
-		if existing, exists := p.pendingDetections[commonName]; exists {
+		if existing, exists := p.pendingDetections[pendingKey]; exists {
 			// Update the existing detection if it's already in pendingDetections map
 			oldConfidence := existing.Confidence
 			if confidence > existing.Confidence {
@@ -300,7 +323,7 @@ func (p *Processor) processDetections(item birdnet.Results) {
 					"operation", "update_pending_detection")
 			}
 			existing.Count++
-			p.pendingDetections[commonName] = existing
+			p.pendingDetections[pendingKey] = existing
 		} else {
 			// Create a new pending detection if it doesn't exist
 			// Add structured logging for new pending detection
@@ -310,7 +333,7 @@ func (p *Processor) processDetections(item birdnet.Results) {
 				"source", item.Source.DisplayName,
 				"flush_deadline", item.StartTime.Add(delay),
 				"operation", "create_pending_detection")
-			p.pendingDetections[commonName] = PendingDetection{
+			p.pendingDetections[pendingKey] = PendingDetection{
 				Detection:     detection,
 				Confidence:    confidence,
 				Source:        item.Source.ID,
@@ -328,6 +351,22 @@ func (p *Processor) processDetections(item birdnet.Results) {
 	}
 }
 
+// isBirdNETModel reports whether a model ID is the BirdNET model rather than an additional model
+func (p *Processor) isBirdNETModel(model string) bool {
+	return model == "" || p.Bn == nil || model == p.Bn.ModelInfo.ID
+}
+
+// pendingDetectionKey returns the key of a detection in the pending detections. Detections
+// of a species by several models are merged unless models are kept separate, then the
+// detections of additional models are held apart from the BirdNET detections.
+func (p *Processor) pendingDetectionKey(detection *Detections) string {
+	commonName := strings.ToLower(detection.Note.CommonName)
+	if p.Settings.BirdNET.MergeModels || p.isBirdNETModel(detection.Model) {
+		return commonName
+	}
+	return detection.Model + "/" + commonName
+}
+
 // processResults processes the results from the BirdNET prediction and returns a list of detections.
 //
 //nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
@@ -367,11 +406,16 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 		p.handleDogDetection(item, speciesLowercase, result)
 		p.handleHumanDetection(item, speciesLowercase, result)
 
+		// Re-score or suppress species prone to local false positives with the trained classifier
+		if !p.applyClassifier(&result, scientificName, item.Embedding) {
+			continue
+		}
+
 		// Determine confidence threshold and check filters
-		baseThreshold := p.getBaseConfidenceThreshold(speciesLowercase)
+		baseThreshold := p.getModelConfidenceThreshold(speciesLowercase, result.Model)
 		
 		// Check if detection should be filtered
-		shouldSkip, _ := p.shouldFilterDetection(result, commonName, speciesLowercase, baseThreshold, item.Source.ID)
+		shouldSkip, _ := p.shouldFilterDetection(result, scientificName, commonName, speciesLowercase, baseThreshold, item.Source.ID, item.StartTime.Hour())
 		if shouldSkip {
 			continue
 		}
@@ -429,8 +473,9 @@ func (p *Processor) parseAndValidateSpecies(result datastore.Results, item birdn
 	return
 }
 
-// shouldFilterDetection checks if a detection should be filtered out
-func (p *Processor) shouldFilterDetection(result datastore.Results, commonName, speciesLowercase string, baseThreshold float32, source string) (shouldFilter bool, confidenceThreshold float32) {
+// shouldFilterDetection checks if a detection should be filtered out. The hour is the hour of
+// the day the detected audio was recorded, which adaptive rules are matched against.
+func (p *Processor) shouldFilterDetection(result datastore.Results, scientificName, commonName, speciesLowercase string, baseThreshold float32, source string, hour int) (shouldFilter bool, confidenceThreshold float32) {
 	// Check human detection privacy filter
 	if strings.Contains(strings.ToLower(commonName), speciesHuman) && result.Confidence > baseThreshold {
 		return true, 0 // Filter out human detections for privacy
@@ -456,8 +501,8 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		return true, confidenceThreshold
 	}
 
-	// Check species inclusion filter
-	if !p.Settings.IsSpeciesIncluded(result.Species) {
+	// Check species inclusion filter, the range filter only covers BirdNET species
+	if p.isBirdNETModel(result.Model) && !p.Settings.IsSpeciesIncluded(result.Species) {
 		if p.Settings.Debug {
 			GetLogger().Debug("Species not on included list",
 				"species", result.Species,
@@ -468,6 +513,11 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		return true, confidenceThreshold
 	}
 
+	// Check false positive rules learned from reviewed detections
+	if p.isSuppressedByAdaptiveRule(&result, scientificName, source, hour) {
+		return true, confidenceThreshold
+	}
+
 	return false, confidenceThreshold
 }
 
@@ -504,8 +554,25 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 	return Detections{
 		pcmData3s: item.PCMdata,
 		Note:      note,
-		Results:   item.Results,
+		Results:   p.detectionResults(item.Results, result.Model),
+		Model:     result.Model,
+		Embedding: item.Embedding,
+	}
+}
+
+// detectionResults returns the prediction results saved with a detection, the results of
+// every model when models are merged, otherwise only the results of the detecting model
+func (p *Processor) detectionResults(results []datastore.Results, model string) []datastore.Results {
+	if p.Settings.BirdNET.MergeModels {
+		return results
+	}
+	modelResults := make([]datastore.Results, 0, len(results))
+	for i := range results {
+		if results[i].Model == model {
+			modelResults = append(modelResults, results[i])
+		}
 	}
+	return modelResults
 }
 
 // syncSpeciesTrackerIfNeeded syncs the species tracker if conditions are met
@@ -578,6 +645,19 @@ func (p *Processor) handleHumanDetection(item birdnet.Results, speciesLowercase
 	}
 }
 
+// getModelConfidenceThreshold retrieves the confidence threshold for a result of a model. Custom
+// species thresholds apply to every model, otherwise additional models use their own threshold.
+func (p *Processor) getModelConfidenceThreshold(speciesLowercase, model string) float32 {
+	if _, exists := p.Settings.Realtime.Species.Config[speciesLowercase]; !exists && model != "" {
+		for i := range p.Settings.BirdNET.Models {
+			if settings := &p.Settings.BirdNET.Models[i]; settings.ID == model && settings.Threshold > 0 {
+				return float32(settings.Threshold)
+			}
+		}
+	}
+	return p.getBaseConfidenceThreshold(speciesLowercase)
+}
+
 // getBaseConfidenceThreshold retrieves the confidence threshold for a species, using custom or global thresholds.
 func (p *Processor) getBaseConfidenceThreshold(speciesLowercase string) float32 {
 	// Check if species has a custom threshold in the new structure
@@ -599,6 +679,12 @@ func (p *Processor) getBaseConfidenceThreshold(speciesLowercase string) float32
 
 // generateClipName generates a clip name for the given scientific name and confidence.
 func (p *Processor) generateClipName(scientificName string, confidence float32) string {
+	return GenerateClipName(p.Settings.Realtime.Audio.Export.Type, scientificName, confidence, time.Now())
+}
+
+// GenerateClipName returns the clip name of a detection at the given time, relative to the
+// clip export directory. File analysis names the clips of recordings with the detection time.
+func GenerateClipName(exportType, scientificName string, confidence float32, timestamp time.Time) string {
 	// Replace whitespaces with underscores and convert to lowercase
 	formattedName := strings.ToLower(strings.ReplaceAll(scientificName, " ", "_"))
 
@@ -606,22 +692,19 @@ func (p *Processor) generateClipName(scientificName string, confidence float32)
 	normalizedConfidence := confidence * 100
 	formattedConfidence := fmt.Sprintf("%.0fp", normalizedConfidence)
 
-	// Get the current time
-	currentTime := time.Now()
-
 	// Format the timestamp in ISO 8601 format
-	timestamp := currentTime.Format("20060102T150405Z")
+	formattedTime := timestamp.Format("20060102T150405Z")
 
 	// Extract the year and month for directory structure
-	year := currentTime.Format("2006")
-	month := currentTime.Format("01")
+	year := timestamp.Format("2006")
+	month := timestamp.Format("01")
 
 	// Get the file extension from the export settings
-	fileType := myaudio.GetFileExtension(p.Settings.Realtime.Audio.Export.Type)
+	fileType := myaudio.GetFileExtension(exportType)
 
 	// Construct the clip name with the new pattern, including year and month subdirectories
 	// Use filepath.ToSlash to convert the path to a forward slash for web URLs
-	clipName := filepath.ToSlash(filepath.Join(year, month, fmt.Sprintf("%s_%s_%s.%s", formattedName, formattedConfidence, timestamp, fileType)))
+	clipName := filepath.ToSlash(filepath.Join(year, month, fmt.Sprintf("%s_%s_%s.%s", formattedName, formattedConfidence, formattedTime, fileType)))
 
 	return clipName
 }
@@ -886,7 +969,7 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 	}
 
 	// Create DatabaseAction if database is enabled
-	if p.Settings.Output.SQLite.Enabled || p.Settings.Output.MySQL.Enabled {
+	if p.Settings.Output.SQLite.Enabled || p.Settings.Output.MySQL.Enabled || p.Settings.Output.PostgreSQL.Enabled {
 		p.speciesTrackerMu.RLock()
 		tracker := p.NewSpeciesTracker
 		p.speciesTrackerMu.RUnlock()
@@ -898,8 +981,12 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 			processor:         p, // Add processor reference for source name resolution
 			Note:              detection.Note,
 			Results:           detection.Results,
+			Embedding:         detection.Embedding,
 			Ds:                p.Ds,
 		}
+		if p.Bn != nil {
+			databaseAction.EmbeddingModel = p.Bn.ModelInfo.ID
+		}
 	}
 
 	// Create SSE action if broadcaster is available (enabled when SSE API is configured)
@@ -999,6 +1086,27 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 				Note:           detection.Note,
 				BirdImageCache: p.BirdImageCache,
 				RetryConfig:    mqttRetryConfig,
+				HomeAssistant:  p.HomeAssistant,
+			})
+		}
+	}
+
+	// Add a webhook action for every configured endpoint, filters are applied by the action
+	if p.Settings.Realtime.Webhooks.Enabled {
+		webhookRetryConfig := jobqueue.RetryConfig{
+			Enabled:      p.Settings.Realtime.Webhooks.RetrySettings.Enabled,
+			MaxRetries:   p.Settings.Realtime.Webhooks.RetrySettings.MaxRetries,
+			InitialDelay: time.Duration(p.Settings.Realtime.Webhooks.RetrySettings.InitialDelay) * time.Second,
+			MaxDelay:     time.Duration(p.Settings.Realtime.Webhooks.RetrySettings.MaxDelay) * time.Second,
+			Multiplier:   p.Settings.Realtime.Webhooks.RetrySettings.BackoffMultiplier,
+		}
+
+		for i := range p.Settings.Realtime.Webhooks.Endpoints {
+			actions = append(actions, &WebhookAction{
+				Settings:    p.Settings,
+				Endpoint:    p.Settings.Realtime.Webhooks.Endpoints[i],
+				Note:        detection.Note,
+				RetryConfig: webhookRetryConfig,
 			})
 		}
 	}
@@ -1178,6 +1286,16 @@ func (p *Processor) Shutdown() error {
 	// Disconnect BirdWeather client
 	p.DisconnectBwClient()
 
+	// Stop learning adaptive rules
+	if p.adaptiveRulesCancel != nil {
+		p.adaptiveRulesCancel()
+	}
+
+	// Stop the Home Assistant publisher before the MQTT client goes away
+	if p.homeAssistantCancel != nil {
+		p.homeAssistantCancel()
+	}
+
 	// Disconnect MQTT client if connected
 	mqttClient := p.GetMQTTClient()
 	if mqttClient != nil && mqttClient.IsConnected() {
@@ -1268,6 +1386,12 @@ func (p *Processor) NewWithSpeciesInfo(
 		}
 	}
 
+	// Persist the ID of realtime sources, analyzed recordings set their own source ID when saved
+	var sourceID string
+	if p.Settings.Input.Path == "" {
+		sourceID = privacy.SanitizeRTSPUrl(sourceStruct.ID)
+	}
+
 	// Round confidence to two decimal places
 	roundedConfidence := math.Round(confidence*100) / 100
 
@@ -1277,6 +1401,7 @@ func (p *Processor) NewWithSpeciesInfo(
 		Date:           date,                           // Use ISO 8601 date format
 		Time:           timeStr,                        // Use 24-hour time format
 		Source:         sourceStruct,                   // Proper AudioSource struct with ID, SafeString, DisplayName
+		SourceID:       sourceID,                       // Persisted source ID of realtime detections
 		BeginTime:      beginTime,                      // Start time of the observation
 		EndTime:        endTime,                        // End time of the observation
 		SpeciesCode:    speciesCode,                    // Species code from taxonomy lookup
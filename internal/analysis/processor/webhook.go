@@ -0,0 +1,284 @@
+// webhook.go: posts detections to user configured HTTP endpoints
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+const (
+	// WebhookDefaultTimeout is the request timeout used when an endpoint does not set one
+	WebhookDefaultTimeout = 10 * time.Second
+
+	// WebhookSignatureHeader carries the HMAC-SHA256 signature of the request body
+	WebhookSignatureHeader = "X-BirdNET-Signature-256"
+
+	// webhookResponseLimit is how much of an error response body is kept for logging
+	webhookResponseLimit = 512
+)
+
+// webhookHTTPClient is shared by all webhook actions, timeouts are set per request
+var webhookHTTPClient = &http.Client{}
+
+// webhookTemplates caches parsed body templates by template text, so a template is parsed
+// once and not for every detection. Text keys keep the cache valid when settings change.
+var webhookTemplates sync.Map // map[string]*template.Template
+
+// WebhookAction posts a detection to a single webhook endpoint
+type WebhookAction struct {
+	Settings    *conf.Settings
+	Endpoint    conf.WebhookEndpoint
+	Note        datastore.Note
+	RetryConfig jobqueue.RetryConfig // Configuration for retry behavior
+	Description string
+	mu          sync.Mutex // Protect concurrent access to Note
+}
+
+// GetDescription returns a human-readable description of the WebhookAction
+func (a *WebhookAction) GetDescription() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return fmt.Sprintf("Send detection to webhook %s", a.endpointName())
+}
+
+// Execute posts the note to the webhook endpoint
+func (a *WebhookAction) Execute(data interface{}) error {
+	return a.ExecuteContext(context.Background(), data)
+}
+
+// ExecuteContext implements the ContextAction interface for proper context propagation
+func (a *WebhookAction) ExecuteContext(ctx context.Context, data interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Early check if webhooks are still enabled in settings
+	if !a.Settings.Realtime.Webhooks.Enabled {
+		return nil
+	}
+
+	if !webhookAccepts(&a.Endpoint, &a.Note) {
+		if a.Settings.Debug {
+			GetLogger().Debug("Skipping webhook, detection filtered by endpoint",
+				"component", "analysis.processor.actions",
+				"endpoint", a.endpointName(),
+				"species", a.Note.CommonName,
+				"confidence", a.Note.Confidence,
+				"operation", "webhook_filter")
+		}
+		return nil
+	}
+
+	body, err := renderWebhookBody(&a.Endpoint, &a.Note)
+	if err != nil {
+		// A broken template fails the same way on every attempt
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "webhook_render").
+			Context("integration", "webhook").
+			Context("endpoint", a.endpointName()).
+			Context("retryable", false).
+			Context("config_section", "realtime.webhooks.endpoints").
+			Build()
+	}
+
+	timeout := WebhookDefaultTimeout
+	if a.Endpoint.Timeout > 0 {
+		timeout = time.Duration(a.Endpoint.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := newWebhookRequest(ctx, &a.Endpoint, body)
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "webhook_request").
+			Context("integration", "webhook").
+			Context("endpoint", a.endpointName()).
+			Context("retryable", false).
+			Build()
+	}
+
+	err = sendWebhookRequest(req)
+	if err != nil {
+		sanitizedErr := sanitizeError(err)
+		GetLogger().Error("Failed to send detection to webhook",
+			"component", "analysis.processor.actions",
+			"error", sanitizedErr,
+			"endpoint", a.endpointName(),
+			"host", req.URL.Host,
+			"species", a.Note.CommonName,
+			"scientific_name", a.Note.ScientificName,
+			"confidence", a.Note.Confidence,
+			"retry_enabled", a.RetryConfig.Enabled,
+			"operation", "webhook_send")
+		if !a.RetryConfig.Enabled {
+			notification.NotifyIntegrationFailure("Webhook "+a.endpointName(), err)
+		}
+		return err
+	}
+
+	if a.Settings.Debug {
+		GetLogger().Debug("Successfully sent detection to webhook",
+			"component", "analysis.processor.actions",
+			"endpoint", a.endpointName(),
+			"species", a.Note.CommonName,
+			"confidence", a.Note.Confidence,
+			"operation", "webhook_send_success")
+	}
+	return nil
+}
+
+// endpointName returns the name of the endpoint for logs, falling back to the host
+func (a *WebhookAction) endpointName() string {
+	if a.Endpoint.Name != "" {
+		return a.Endpoint.Name
+	}
+	if u, err := url.Parse(a.Endpoint.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "webhook"
+}
+
+// webhookAccepts applies the species and confidence filters of an endpoint to a note.
+// Species match the common or scientific name case-insensitively.
+func webhookAccepts(endpoint *conf.WebhookEndpoint, note *datastore.Note) bool {
+	if note.Confidence < endpoint.MinConfidence {
+		return false
+	}
+
+	matches := func(names []string) bool {
+		for _, name := range names {
+			if strings.EqualFold(name, note.CommonName) || strings.EqualFold(name, note.ScientificName) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if matches(endpoint.ExcludeSpecies) {
+		return false
+	}
+	return len(endpoint.Species) == 0 || matches(endpoint.Species)
+}
+
+// renderWebhookBody executes the endpoint template with the note, or encodes the
+// note as JSON when no template is configured
+func renderWebhookBody(endpoint *conf.WebhookEndpoint, note *datastore.Note) ([]byte, error) {
+	if endpoint.Template == "" {
+		return json.Marshal(note)
+	}
+
+	tmpl, err := webhookTemplate(endpoint.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, note); err != nil {
+		return nil, fmt.Errorf("failed to execute webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// webhookTemplate returns the parsed body template, parsing it on first use
+func webhookTemplate(text string) (*template.Template, error) {
+	if cached, ok := webhookTemplates.Load(text); ok {
+		return cached.(*template.Template), nil
+	}
+	tmpl, err := conf.ParseWebhookTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	webhookTemplates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// newWebhookRequest builds the POST request, signing the body when a secret is set
+func newWebhookRequest(ctx context.Context, endpoint *conf.WebhookEndpoint, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := endpoint.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "BirdNET-Go")
+	for name, value := range endpoint.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if endpoint.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, body))
+	}
+	return req, nil
+}
+
+// SignWebhookPayload returns the signature header value of a webhook body,
+// "sha256=" followed by the hex encoded HMAC-SHA256 of the body
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhookRequest sends the request and treats any non-2xx response as a failure
+func sendWebhookRequest(req *http.Request) error {
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		// Network errors and timeouts are typically transient
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryNetwork).
+			Context("operation", "webhook_send").
+			Context("integration", "webhook").
+			Context("host", req.URL.Host).
+			Context("retryable", true).
+			Build()
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	// Client errors other than rate limiting mean the endpoint rejects the request itself
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return errors.Newf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet))).
+		Component("analysis.processor").
+		Category(errors.CategoryIntegration).
+		Context("operation", "webhook_send").
+		Context("integration", "webhook").
+		Context("host", req.URL.Host).
+		Context("status_code", resp.StatusCode).
+		Context("retryable", retryable).
+		Build()
+}
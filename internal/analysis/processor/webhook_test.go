@@ -0,0 +1,220 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// webhookRequest is a request received by the test webhook server
+type webhookRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// newWebhookTestServer starts a server recording requests and answering with status
+func newWebhookTestServer(t *testing.T, status int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{Header: r.Header.Clone(), Body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func newWebhookTestAction(endpoint conf.WebhookEndpoint) *WebhookAction {
+	settings := &conf.Settings{}
+	settings.Realtime.Webhooks.Enabled = true
+	settings.Realtime.Webhooks.Endpoints = []conf.WebhookEndpoint{endpoint}
+
+	return &WebhookAction{
+		Settings: settings,
+		Endpoint: endpoint,
+		Note: datastore.Note{
+			Date:           "2025-05-01",
+			Time:           "06:30:00",
+			CommonName:     "Eurasian Blackbird",
+			ScientificName: "Turdus merula",
+			Confidence:     0.87,
+		},
+	}
+}
+
+func TestWebhookActionDefaultPayload(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookTestServer(t, http.StatusOK)
+	action := newWebhookTestAction(conf.WebhookEndpoint{
+		Name:    "test",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+	})
+
+	require.NoError(t, action.Execute(nil))
+
+	received := requests()
+	require.Len(t, received, 1)
+	assert.Equal(t, "application/json", received[0].Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer abc", received[0].Header.Get("Authorization"))
+	assert.Empty(t, received[0].Header.Get(WebhookSignatureHeader), "unsigned without a secret")
+
+	var note datastore.Note
+	require.NoError(t, json.Unmarshal(received[0].Body, &note))
+	assert.Equal(t, "Turdus merula", note.ScientificName)
+	assert.InDelta(t, 0.87, note.Confidence, 0.0001)
+}
+
+func TestWebhookActionTemplateAndSignature(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookTestServer(t, http.StatusNoContent)
+	action := newWebhookTestAction(conf.WebhookEndpoint{
+		URL:         server.URL,
+		Template:    `{"text": {{ json (printf "%s (%s%%)" .CommonName (percent .Confidence)) }}, "species": "{{ lower .ScientificName }}"}`,
+		ContentType: "application/vnd.test+json",
+		Secret:      "s3cret",
+	})
+
+	require.NoError(t, action.Execute(nil))
+
+	received := requests()
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `{"text": "Eurasian Blackbird (87%)", "species": "turdus merula"}`, string(received[0].Body))
+	assert.Equal(t, "application/vnd.test+json", received[0].Header.Get("Content-Type"))
+	assert.Equal(t, SignWebhookPayload("s3cret", received[0].Body), received[0].Header.Get(WebhookSignatureHeader))
+}
+
+func TestWebhookActionFilters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint conf.WebhookEndpoint
+		wantSent bool
+	}{
+		{"no filters", conf.WebhookEndpoint{}, true},
+		{"species by common name", conf.WebhookEndpoint{Species: []string{"eurasian blackbird"}}, true},
+		{"species by scientific name", conf.WebhookEndpoint{Species: []string{"Turdus merula"}}, true},
+		{"other species only", conf.WebhookEndpoint{Species: []string{"Erithacus rubecula"}}, false},
+		{"excluded species", conf.WebhookEndpoint{ExcludeSpecies: []string{"Turdus merula"}}, false},
+		{"confidence above minimum", conf.WebhookEndpoint{MinConfidence: 0.8}, true},
+		{"confidence below minimum", conf.WebhookEndpoint{MinConfidence: 0.9}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, requests := newWebhookTestServer(t, http.StatusOK)
+			tt.endpoint.URL = server.URL
+			action := newWebhookTestAction(tt.endpoint)
+
+			require.NoError(t, action.Execute(nil))
+			assert.Equal(t, tt.wantSent, len(requests()) == 1)
+		})
+	}
+}
+
+func TestWebhookActionErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("server error", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newWebhookTestServer(t, http.StatusInternalServerError)
+		action := newWebhookTestAction(conf.WebhookEndpoint{URL: server.URL})
+		action.RetryConfig = jobqueue.RetryConfig{Enabled: true}
+
+		err := action.Execute(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "500")
+		assert.Len(t, requests(), 1)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newWebhookTestServer(t, http.StatusOK)
+		action := newWebhookTestAction(conf.WebhookEndpoint{URL: server.URL, Template: "{{ .CommonName "})
+
+		require.Error(t, action.Execute(nil))
+		assert.Empty(t, requests())
+	})
+
+	t.Run("webhooks disabled", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newWebhookTestServer(t, http.StatusOK)
+		action := newWebhookTestAction(conf.WebhookEndpoint{URL: server.URL})
+		action.Settings.Realtime.Webhooks.Enabled = false
+
+		require.NoError(t, action.Execute(nil))
+		assert.Empty(t, requests())
+	})
+}
+
+// TestWebhookActionPermanentFailureNotRetried runs actions through a job queue, a rejected
+// request or a broken template fails the job without using the retries left
+func TestWebhookActionPermanentFailureNotRetried(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   int
+		template string
+		requests int
+	}{
+		{"client error", http.StatusBadRequest, "", 1},
+		{"invalid template", http.StatusOK, "{{ .CommonName ", 0},
+		{"server error is retried", http.StatusInternalServerError, "", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server, requests := newWebhookTestServer(t, tt.status)
+			action := newWebhookTestAction(conf.WebhookEndpoint{URL: server.URL, Template: tt.template})
+			action.RetryConfig = jobqueue.RetryConfig{Enabled: true, MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+			queue := jobqueue.NewJobQueueWithOptions(10, 10, false)
+			queue.SetProcessingInterval(time.Hour)
+			queue.Start()
+			t.Cleanup(func() { _ = queue.StopWithTimeout(time.Second) })
+
+			_, err := queue.Enqueue(context.Background(), action, nil, action.RetryConfig)
+			require.NoError(t, err)
+			for range 4 {
+				queue.ProcessImmediately(context.Background())
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			assert.Len(t, requests(), tt.requests)
+			assert.Equal(t, 1, queue.GetStats().FailedJobs)
+		})
+	}
+}
+
+func TestWebhookActionRetryConfig(t *testing.T) {
+	retryConfig := jobqueue.RetryConfig{Enabled: true, MaxRetries: 3}
+	action := &WebhookAction{RetryConfig: retryConfig}
+	assert.Equal(t, retryConfig, getJobQueueRetryConfig(action))
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 	"github.com/tphakala/birdnet-go/internal/audiocore/adapter"
 	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
@@ -40,6 +42,9 @@ import (
 const (
 	// shutdownTimeout is the maximum time allowed for graceful shutdown (9s for Docker's 10s default)
 	shutdownTimeout = 9 * time.Second
+
+	// databaseRetentionStartDelay is the delay before the first detection record retention pass
+	databaseRetentionStartDelay = 10 * time.Minute
 )
 
 // audioLevelChan is a channel to send audio level updates
@@ -186,6 +191,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 
 	// Initialize processor
 	proc := processor.New(settings, dataStore, bn, metrics, birdImageCache)
+	proc.SetControlChannel(controlChan)
 
 	// Initialize Backup system
 	backupLogger := logging.ForService("backup") // Get logger first
@@ -287,6 +293,11 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 		startClipCleanupMonitor(&wg, quitChan, dataStore)
 	}
 
+	// start pruning of old detection records
+	if settings.Output.Retention.Enabled {
+		startDatabaseRetentionMonitor(&wg, settings, quitChan, dataStore)
+	}
+
 	// start weather polling
 	if settings.Realtime.Weather.Provider != "none" {
 		startWeatherPolling(&wg, settings, dataStore, metrics, quitChan)
@@ -423,6 +434,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 				// Add structured logging
 				GetLogger().Info("Closing control channel after producers shutdown",
 					"operation", "close_control_channel")
+				proc.SetControlChannel(nil)
 				close(controlChan)
 
 				if ctx.Err() != nil {
@@ -618,6 +630,88 @@ func startClipCleanupMonitor(wg *sync.WaitGroup, quitChan chan struct{}, dataSto
 	}()
 }
 
+// startDatabaseRetentionMonitor initializes and starts the detection record retention routine in a new goroutine.
+func startDatabaseRetentionMonitor(wg *sync.WaitGroup, settings *conf.Settings, quitChan chan struct{}, dataStore datastore.Interface) {
+	policy, err := datastore.NewRetentionPolicy(&settings.Output.Retention)
+	if err != nil {
+		GetLogger().Error("Failed to initialize database retention policy",
+			"error", err,
+			"operation", "database_retention_init")
+		log.Printf("Error initializing database retention policy: %v", err)
+		return
+	}
+
+	interval := time.Duration(settings.Output.Retention.Interval) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		databaseRetentionMonitor(quitChan, dataStore, policy, interval)
+	}()
+}
+
+// databaseRetentionMonitor periodically prunes detection records according to the retention policy
+func databaseRetentionMonitor(quitChan chan struct{}, dataStore datastore.Interface, policy *datastore.RetentionPolicy, interval time.Duration) {
+	// Cancel a running retention pass when the application shuts down
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	GetLogger().Info("Database retention monitor initialized",
+		"max_age", policy.MaxAge.String(),
+		"max_rows", policy.MaxRows,
+		"min_detections", policy.MinDetections,
+		"interval", interval.String(),
+		"operation", "database_retention_init")
+	log.Printf("Database retention policy: max age %s, max rows %d, min detections per species %d",
+		policy.MaxAge, policy.MaxRows, policy.MinDetections)
+
+	// Give the application time to settle before the first pass
+	timer := time.NewTimer(databaseRetentionStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-quitChan:
+			return
+
+		case <-timer.C:
+			done := make(chan struct{})
+			go func() {
+				select {
+				case <-quitChan:
+					cancel()
+				case <-done:
+				}
+			}()
+
+			result, err := datastore.ApplyRetentionPolicy(ctx, dataStore, policy, time.Now())
+			close(done)
+			if err != nil {
+				GetLogger().Error("Database retention failed",
+					"error", err,
+					"operation", "database_retention")
+				log.Printf("Error during database retention: %v", err)
+			} else {
+				GetLogger().Info("Database retention completed",
+					"deleted_by_age", result.DeletedByAge,
+					"deleted_by_count", result.DeletedByCount,
+					"deleted_sound_levels", result.DeletedSoundLevels,
+					"optimized", result.Optimized,
+					"duration_ms", result.Duration.Milliseconds(),
+					"operation", "database_retention")
+				if result.Deleted() > 0 {
+					log.Printf("🧹 Database retention removed %d detections", result.Deleted())
+				}
+			}
+
+			timer.Reset(interval)
+		}
+	}
+}
+
 // startWeatherPolling initializes and starts the weather polling routine in a new goroutine.
 func startWeatherPolling(wg *sync.WaitGroup, settings *conf.Settings, dataStore datastore.Interface, metrics *observability.Metrics, quitChan chan struct{}) {
 	// Create new weather service
@@ -1437,6 +1531,11 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 			Context("operation", "initialize_backup_manager").
 			Build()
 	}
+
+	if settings.Backup.Enabled {
+		registerBackupComponents(settings, backupManager, backupLogger)
+	}
+
 	backupScheduler, err := backup.NewScheduler(backupManager, backupLogger, stateManager)
 	if err != nil {
 		return nil, nil, errors.New(err).
@@ -1477,6 +1576,43 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 	return backupManager, backupScheduler, nil
 }
 
+// registerBackupComponents registers the database sources and the enabled
+// backup targets with the backup manager. Failures are logged so that one
+// misconfigured target does not disable the others.
+func registerBackupComponents(settings *conf.Settings, backupManager *backup.Manager, backupLogger *slog.Logger) {
+	if settings.Output.SQLite.Enabled {
+		sqliteSource := sources.NewSQLiteSource(settings, backupLogger)
+		// The datastore stays open while running, restores are copied into the open database
+		sqliteSource.SetInUse(true)
+		if err := backupManager.RegisterSource(sqliteSource); err != nil {
+			backupLogger.Error("Failed to register SQLite backup source", "error", err)
+			// Keep restore available, it is the way out of a damaged database
+			backupManager.RegisterRestorer(sqliteSource)
+		}
+	}
+
+	if settings.Output.MySQL.Enabled {
+		if err := backupManager.RegisterSource(sources.NewMySQLSource(settings, backupLogger)); err != nil {
+			backupLogger.Error("Failed to register MySQL backup source", "error", err)
+		}
+	}
+
+	for i := range settings.Backup.Targets {
+		targetConfig := settings.Backup.Targets[i]
+		if !targetConfig.Enabled {
+			continue
+		}
+		target, err := targets.NewFromConfig(targetConfig, backupLogger)
+		if err != nil {
+			backupLogger.Error("Failed to create backup target", "type", targetConfig.Type, "error", err)
+			continue
+		}
+		if err := backupManager.RegisterTarget(target); err != nil {
+			backupLogger.Error("Failed to register backup target", "type", targetConfig.Type, "error", err)
+		}
+	}
+}
+
 // initializeSystemMonitor initializes and starts the system resource monitor if enabled
 func initializeSystemMonitor(settings *conf.Settings) *monitor.SystemMonitor {
 	logging.Info("initializeSystemMonitor called",
@@ -0,0 +1,125 @@
+// Package recording converts analysis results of recorded audio files into detections that are
+// stored like realtime detections. It is shared by file analysis and recordings uploaded through the API.
+package recording
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// sourceHashLength is the number of hex digits of the content hash in a source ID
+const sourceHashLength = 12
+
+// SourceID returns the source ID stored with the detections of a recording. The ID combines
+// the file name with a hash of the file contents, recorders like AudioMoth name files by their
+// start time, so different recordings often share a name.
+func SourceID(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash recording: %w", err)
+	}
+	return "file_" + filepath.Base(path) + "_" + hex.EncodeToString(hash.Sum(nil))[:sourceHashLength], nil
+}
+
+// Detections converts analysis results above the threshold into detections at their local time
+// of day. Result times are offsets from the zero time into a recording starting at start.
+// Overlapping chunks report a call more than once, consecutive results of a species are merged
+// into one detection with the highest confidence.
+func Detections(threshold float64, path, sourceID string, start time.Time, notes []datastore.Note) []datastore.Note {
+	candidates := make([]datastore.Note, 0, len(notes))
+	for i := range notes {
+		if notes[i].Confidence > threshold {
+			candidates = append(candidates, notes[i])
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].BeginTime.Before(candidates[j].BeginTime)
+	})
+
+	var detections []datastore.Note
+	lastBySpecies := make(map[string]int)
+	for i := range candidates {
+		note := candidates[i]
+		begin := start.Add(note.BeginTime.Sub(time.Time{}))
+		end := start.Add(note.EndTime.Sub(time.Time{}))
+
+		if last, ok := lastBySpecies[note.ScientificName]; ok && !begin.After(detections[last].EndTime) {
+			merged := &detections[last]
+			merged.EndTime = end
+			merged.Confidence = max(merged.Confidence, note.Confidence)
+			continue
+		}
+
+		// Realtime detections are dated in local time, recorders like AudioMoth name files in UTC
+		local := begin.In(time.Local)
+		note.Date = local.Format(time.DateOnly)
+		note.Time = local.Format(time.TimeOnly)
+		note.BeginTime = begin
+		note.EndTime = end
+		note.SourceID = sourceID
+		note.Source = datastore.AudioSource{
+			ID:          sourceID,
+			SafeString:  path,
+			DisplayName: filepath.Base(path),
+		}
+		lastBySpecies[note.ScientificName] = len(detections)
+		detections = append(detections, note)
+	}
+	return detections
+}
+
+// Saved reports whether detections of the source exist on the dates of the detections
+func Saved(store datastore.Interface, sourceID string, detections []datastore.Note) (bool, error) {
+	dates := make([]string, 0, 2)
+	for i := range detections {
+		if len(dates) == 0 || dates[len(dates)-1] != detections[i].Date {
+			dates = append(dates, detections[i].Date)
+		}
+	}
+
+	var count int64
+	err := store.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&datastore.Note{}).
+			Where("source_id = ? AND date IN ?", sourceID, dates).
+			Count(&count).Error
+	})
+	if err != nil {
+		return false, errors.New(err).
+			Component("analysis.recording").
+			Category(errors.CategoryDatabase).
+			Context("operation", "check_recording_detections").
+			Build()
+	}
+	return count > 0, nil
+}
+
+// Save stores detections with their species result and returns the number of saved detections
+func Save(store datastore.Interface, detections []datastore.Note) (int, error) {
+	for i := range detections {
+		note := &detections[i]
+		results := []datastore.Results{
+			{Species: note.ScientificName + "_" + note.CommonName, Confidence: float32(note.Confidence)},
+		}
+		if err := store.Save(note, results); err != nil {
+			return i, fmt.Errorf("failed to save detection: %w", err)
+		}
+	}
+	return len(detections), nil
+}
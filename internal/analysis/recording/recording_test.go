@@ -0,0 +1,124 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// chunkNote returns an analysis result of the chunk starting at offset seconds into the file
+func chunkNote(offset float64, scientificName, commonName string, confidence float64) datastore.Note {
+	begin := time.Time{}.Add(time.Duration(offset * float64(time.Second)))
+	return datastore.Note{
+		Date:           "2026-01-01", // File analysis results carry the analysis date
+		Time:           "12:00:00",
+		BeginTime:      begin,
+		EndTime:        begin.Add(1500 * time.Millisecond),
+		ScientificName: scientificName,
+		CommonName:     commonName,
+		SpeciesCode:    "code",
+		Confidence:     confidence,
+	}
+}
+
+// TestDetections tests that results are placed at the recording time and overlapping
+// results of a species are merged
+func TestDetections(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 23, 59, 57, 0, time.Local)
+
+	notes := []datastore.Note{
+		chunkNote(1.5, "Turdus merula", "Eurasian Blackbird", 0.9),
+		chunkNote(0, "Turdus merula", "Eurasian Blackbird", 0.8),
+		chunkNote(0, "Erithacus rubecula", "European Robin", 0.5), // below threshold
+		chunkNote(3, "Turdus merula", "Eurasian Blackbird", 0.75),
+		chunkNote(9, "Turdus merula", "Eurasian Blackbird", 0.85), // after a gap
+	}
+
+	detections := Detections(0.7, "/sd/20240501_235957.WAV", "file_20240501_235957.WAV_0123456789ab", start, notes)
+	require.Len(t, detections, 2)
+
+	first := detections[0]
+	assert.Equal(t, "2024-05-01", first.Date)
+	assert.Equal(t, "23:59:57", first.Time)
+	assert.Equal(t, start, first.BeginTime)
+	assert.Equal(t, start.Add(4500*time.Millisecond), first.EndTime, "merged detection covers all overlapping chunks")
+	assert.InDelta(t, 0.9, first.Confidence, 0.0001)
+	assert.Equal(t, "file_20240501_235957.WAV_0123456789ab", first.SourceID)
+	assert.Equal(t, "20240501_235957.WAV", first.Source.DisplayName)
+
+	second := detections[1]
+	assert.Equal(t, "2024-05-02", second.Date, "detections after midnight are dated by their own time")
+	assert.Equal(t, "00:00:06", second.Time)
+}
+
+// TestDetectionsLocalTime tests that detections of a recording whose start time is in another
+// zone, like the UTC file names of AudioMoth, are dated in local time like realtime detections
+func TestDetectionsLocalTime(t *testing.T) {
+	t.Parallel()
+
+	for _, loc := range []*time.Location{time.UTC, time.FixedZone("UTC+14", 14*60*60)} {
+		start := time.Date(2024, 5, 1, 22, 30, 0, 0, loc)
+		notes := []datastore.Note{chunkNote(3, "Turdus merula", "Eurasian Blackbird", 0.9)}
+
+		detections := Detections(0.7, "/sd/20240501_223000.WAV", "file_20240501_223000.WAV_0123456789ab", start, notes)
+		require.Len(t, detections, 1, loc.String())
+
+		local := start.Add(3 * time.Second).In(time.Local)
+		assert.Equal(t, local.Format(time.DateOnly), detections[0].Date, loc.String())
+		assert.Equal(t, local.Format(time.TimeOnly), detections[0].Time, loc.String())
+		assert.True(t, detections[0].BeginTime.Equal(local), loc.String())
+	}
+}
+
+// TestSavedRecordingsWithSameName tests that recordings sharing a file name, like those of two
+// recorders started at the same time, are not taken for each other
+func TestSavedRecordingsWithSameName(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	store := datastore.New(settings)
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+
+	writeRecording := func(dir string, data []byte) string {
+		t.Helper()
+		path := filepath.Join(dir, "20240501_063000.WAV")
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	first := writeRecording(t.TempDir(), []byte("first recorder"))
+	second := writeRecording(t.TempDir(), []byte("second recorder"))
+	copied := writeRecording(t.TempDir(), []byte("first recorder"))
+
+	firstID, err := SourceID(first)
+	require.NoError(t, err)
+	secondID, err := SourceID(second)
+	require.NoError(t, err)
+	copiedID, err := SourceID(copied)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstID, secondID)
+	assert.Equal(t, firstID, copiedID, "a copy of a recording is the same recording")
+
+	start := time.Date(2024, 5, 1, 6, 30, 0, 0, time.Local)
+	notes := []datastore.Note{chunkNote(0, "Turdus merula", "Eurasian Blackbird", 0.9)}
+	saved, err := Save(store, Detections(0.7, first, firstID, start, notes))
+	require.NoError(t, err)
+	require.Equal(t, 1, saved)
+
+	exists, err := Saved(store, secondID, Detections(0.7, second, secondID, start, notes))
+	require.NoError(t, err)
+	assert.False(t, exists, "a different recording with the same name is not skipped")
+
+	exists, err = Saved(store, copiedID, Detections(0.7, copied, copiedID, start, notes))
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
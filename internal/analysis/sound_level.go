@@ -334,6 +334,18 @@ type CompactBandData struct {
 	Mean float64 `json:"m"` // Mean dB (1 decimal)
 }
 
+// overallSoundLevel returns the energy sum of the octave band mean levels
+func overallSoundLevel(data *myaudio.SoundLevelData) float64 {
+	var energy float64
+	for _, band := range data.OctaveBands {
+		energy += math.Pow(10, band.Mean/10)
+	}
+	if energy <= 0 {
+		return 0
+	}
+	return 10 * math.Log10(energy)
+}
+
 // toCompactFormat converts sound level data to compact format for MQTT
 func toCompactFormat(data myaudio.SoundLevelData) CompactSoundLevelData {
 	compact := CompactSoundLevelData{
@@ -475,6 +487,17 @@ func publishSoundLevelToMQTT(soundData myaudio.SoundLevelData, proc *processor.P
 
 	LogSoundLevelMQTTPublished(topic, soundData.Source, len(soundData.OctaveBands))
 
+	// Publish the overall level to the per-source topic read by Home Assistant
+	if proc.HomeAssistant.Enabled() {
+		if err := proc.HomeAssistant.PublishSoundLevel(ctx, proc.GetMQTTClient(), sanitizedData.Source, sanitizedData.Name,
+			overallSoundLevel(&sanitizedData), sanitizedData.Timestamp); err != nil {
+			getSoundLevelLogger().Warn("Failed to publish sound level to Home Assistant topic",
+				"source", soundData.Source,
+				"error", err,
+				"operation", "publish_homeassistant")
+		}
+	}
+
 	// Log detailed sound level data if debug is enabled
 	// These logs are for publishing events, not realtime processing
 	if settings.Realtime.Audio.SoundLevel.Debug {
@@ -519,19 +542,48 @@ func startSoundLevelPublishers(wg *sync.WaitGroup, doneChan chan struct{}, proc
 		close(mergedQuitChan)
 	}()
 
+	enableMQTT := settings.Realtime.MQTT.Enabled
+	enableSSE := httpServer != nil && httpServer.APIV2 != nil
+	enableMetrics := proc != nil && proc.Metrics != nil && proc.Metrics.SoundLevel != nil
+	enableStore := settings.Realtime.Audio.SoundLevel.Store && proc != nil && proc.Ds != nil
+
+	outputs := 0
+	for _, enabled := range []bool{enableMQTT, enableSSE, enableMetrics, enableStore} {
+		if enabled {
+			outputs++
+		}
+	}
+	if outputs == 0 {
+		return
+	}
+
+	// Every publisher receives its own copy of each measurement
+	channels := fanOutSoundLevels(wg, mergedQuitChan, soundLevelChan, outputs)
+	next := func() chan myaudio.SoundLevelData {
+		ch := channels[0]
+		channels = channels[1:]
+		return ch
+	}
+
 	// Start MQTT publisher if enabled
-	if settings.Realtime.MQTT.Enabled {
-		startSoundLevelMQTTPublisherWithDone(wg, mergedQuitChan, proc, soundLevelChan)
+	if enableMQTT {
+		startSoundLevelMQTTPublisherWithDone(wg, mergedQuitChan, proc, next())
 	}
 
 	// Start SSE publisher if API is available
-	if httpServer != nil && httpServer.APIV2 != nil {
-		startSoundLevelSSEPublisherWithDone(wg, mergedQuitChan, httpServer.APIV2, soundLevelChan)
+	if enableSSE {
+		startSoundLevelSSEPublisherWithDone(wg, mergedQuitChan, httpServer.APIV2, next())
 	}
 
 	// Start metrics publisher
-	if proc != nil && proc.Metrics != nil && proc.Metrics.SoundLevel != nil {
-		startSoundLevelMetricsPublisherWithDone(wg, mergedQuitChan, proc.Metrics, soundLevelChan)
+	if enableMetrics {
+		startSoundLevelMetricsPublisherWithDone(wg, mergedQuitChan, proc.Metrics, next())
+	}
+
+	// Start datastore publisher if sound level history is stored
+	if enableStore {
+		storeInterval := time.Duration(settings.Realtime.Audio.SoundLevel.StoreInterval) * time.Second
+		startSoundLevelStorePublisherWithDone(wg, mergedQuitChan, proc.Ds, next(), storeInterval)
 	}
 }
 
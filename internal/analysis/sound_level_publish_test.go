@@ -1592,6 +1592,15 @@ func (m *mockMQTTClient) Publish(ctx context.Context, topic, payload string) err
 	return nil
 }
 
+func (m *mockMQTTClient) PublishRetained(ctx context.Context, topic, payload string) error {
+	return m.Publish(ctx, topic, payload)
+}
+
+func (m *mockMQTTClient) Subscribe(ctx context.Context, topic string, handler mqtt.MessageHandler) error {
+	// Not needed for our tests
+	return nil
+}
+
 func (m *mockMQTTClient) TestConnection(ctx context.Context, resultChan chan<- mqtt.TestResult) {
 	// Not needed for our tests
 }
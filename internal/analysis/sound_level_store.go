@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// soundLevelFanOutBuffer is the channel size of each sound level publisher
+const soundLevelFanOutBuffer = 10
+
+// fanOutSoundLevels copies every measurement from in to the given number of output channels,
+// so each publisher sees all measurements instead of competing for them. Measurements are
+// dropped for publishers that do not keep up.
+func fanOutSoundLevels(wg *sync.WaitGroup, doneChan <-chan struct{}, in <-chan myaudio.SoundLevelData, outputs int) []chan myaudio.SoundLevelData {
+	outs := make([]chan myaudio.SoundLevelData, outputs)
+	for i := range outs {
+		outs[i] = make(chan myaudio.SoundLevelData, soundLevelFanOutBuffer)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-doneChan:
+				return
+			case data, ok := <-in:
+				if !ok {
+					return
+				}
+				for _, out := range outs {
+					select {
+					case out <- data:
+					default:
+						// Publisher is busy, drop the measurement for it
+					}
+				}
+			}
+		}
+	}()
+
+	return outs
+}
+
+// soundLevelAggregator merges measurements per source until they span the store interval
+type soundLevelAggregator struct {
+	interval time.Duration
+	pending  map[string][]datastore.SoundLevel
+}
+
+func newSoundLevelAggregator(interval time.Duration) *soundLevelAggregator {
+	return &soundLevelAggregator{
+		interval: interval,
+		pending:  make(map[string][]datastore.SoundLevel),
+	}
+}
+
+// add adds a measurement and returns the merged record once the source's pending
+// measurements cover the store interval
+func (a *soundLevelAggregator) add(data *myaudio.SoundLevelData) (datastore.SoundLevel, bool) {
+	level := toStoredSoundLevel(data)
+	pending := append(a.pending[level.Source], level)
+
+	var covered int
+	for i := range pending {
+		covered += pending[i].Interval
+	}
+	if time.Duration(covered)*time.Second < a.interval {
+		a.pending[level.Source] = pending
+		return datastore.SoundLevel{}, false
+	}
+
+	delete(a.pending, level.Source)
+	return datastore.MergeSoundLevels(pending), true
+}
+
+// flush returns the merged pending measurements of all sources
+func (a *soundLevelAggregator) flush() []datastore.SoundLevel {
+	levels := make([]datastore.SoundLevel, 0, len(a.pending))
+	for source, pending := range a.pending {
+		levels = append(levels, datastore.MergeSoundLevels(pending))
+		delete(a.pending, source)
+	}
+	return levels
+}
+
+// toStoredSoundLevel converts a measurement to its stored form. The measurement timestamp
+// marks the end of the measured interval, stored records use the start.
+func toStoredSoundLevel(data *myaudio.SoundLevelData) datastore.SoundLevel {
+	level := datastore.SoundLevel{
+		Source:    data.Source,
+		Name:      data.Name,
+		Timestamp: data.Timestamp.Add(-time.Duration(data.Duration) * time.Second).UTC(),
+		Interval:  data.Duration,
+		Bands:     make(datastore.SoundLevelBands, len(data.OctaveBands)),
+	}
+	for key, band := range data.OctaveBands {
+		level.Bands[key] = datastore.SoundLevelBand{
+			CenterFreq: band.CenterFreq,
+			Leq:        band.Mean,
+			Min:        band.Min,
+			Max:        band.Max,
+		}
+	}
+	return level
+}
+
+// startSoundLevelStorePublisherWithDone stores sound level measurements in the datastore,
+// aggregated per source over the store interval
+func startSoundLevelStorePublisherWithDone(wg *sync.WaitGroup, doneChan <-chan struct{}, ds datastore.Interface, soundLevelChan <-chan myaudio.SoundLevelData, storeInterval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		getSoundLevelLogger().Info("Started sound level store publisher",
+			"store_interval", storeInterval.String())
+
+		aggregator := newSoundLevelAggregator(storeInterval)
+		save := func(levels []datastore.SoundLevel) {
+			if len(levels) == 0 {
+				return
+			}
+			if err := ds.SaveSoundLevels(levels); err != nil {
+				getSoundLevelLogger().Error("Failed to store sound level data",
+					"error", err,
+					"count", len(levels))
+			}
+		}
+
+		for {
+			select {
+			case <-doneChan:
+				// Keep the partial interval rather than losing it on shutdown
+				save(aggregator.flush())
+				getSoundLevelLogger().Info("Stopping sound level store publisher")
+				return
+			case soundData, ok := <-soundLevelChan:
+				if !ok {
+					save(aggregator.flush())
+					return
+				}
+				if err := validateSoundLevelData(&soundData); err != nil {
+					continue
+				}
+				sanitized := sanitizeSoundLevelData(soundData)
+				if level, ready := aggregator.add(&sanitized); ready {
+					save([]datastore.SoundLevel{level})
+				}
+			}
+		}
+	}()
+}
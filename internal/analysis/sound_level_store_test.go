@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+func testSoundLevelData(source string, end time.Time, mean float64) myaudio.SoundLevelData {
+	return myaudio.SoundLevelData{
+		Timestamp: end,
+		Source:    source,
+		Name:      source,
+		Duration:  10,
+		OctaveBands: map[string]myaudio.OctaveBandData{
+			"1.0_kHz": {CenterFreq: 1000, Min: mean - 3, Max: mean + 3, Mean: mean},
+		},
+	}
+}
+
+// TestSoundLevelAggregator tests that measurements are merged per source over the store interval
+func TestSoundLevelAggregator(t *testing.T) {
+	t.Parallel()
+
+	aggregator := newSoundLevelAggregator(30 * time.Second)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 2; i++ {
+		data := testSoundLevelData("mic", start.Add(time.Duration(i)*10*time.Second), -40)
+		_, ready := aggregator.add(&data)
+		assert.False(t, ready, "interval not yet covered after %d measurements", i)
+	}
+	other := testSoundLevelData("rtsp_1", start.Add(10*time.Second), -50)
+	_, ready := aggregator.add(&other)
+	assert.False(t, ready)
+
+	data := testSoundLevelData("mic", start.Add(30*time.Second), -40)
+	level, ready := aggregator.add(&data)
+	require.True(t, ready)
+	assert.Equal(t, "mic", level.Source)
+	assert.True(t, level.Timestamp.Equal(start), "stored record starts at the first measured interval")
+	assert.Equal(t, 30, level.Interval)
+	assert.InDelta(t, -40, level.Bands["1.0_kHz"].Leq, 0.001)
+	assert.InDelta(t, -43, level.Bands["1.0_kHz"].Min, 0.001)
+
+	// The partial interval of the other source is returned on flush
+	pending := aggregator.flush()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "rtsp_1", pending[0].Source)
+	assert.Equal(t, 10, pending[0].Interval)
+	assert.Empty(t, aggregator.flush())
+}
+
+// TestFanOutSoundLevels tests that every publisher receives each measurement
+func TestFanOutSoundLevels(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	in := make(chan myaudio.SoundLevelData)
+
+	outs := fanOutSoundLevels(&wg, done, in, 3)
+	require.Len(t, outs, 3)
+
+	data := testSoundLevelData("mic", time.Now(), -40)
+	in <- data
+
+	for i, out := range outs {
+		select {
+		case got := <-out:
+			assert.Equal(t, "mic", got.Source, "output %d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("output %d did not receive the measurement", i)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,228 @@
+// internal/api/v2/adaptive_rules.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// AdaptiveRuleResponse represents a false positive rule learned from reviews or set by hand
+type AdaptiveRuleResponse struct {
+	ScientificName    string  `json:"scientificName"`
+	CommonName        string  `json:"commonName,omitempty"`
+	Source            string  `json:"source,omitempty"` // empty for all sources
+	Hour              *int    `json:"hour,omitempty"`   // omitted for all hours
+	Action            string  `json:"action"`
+	MinConfidence     float64 `json:"minConfidence,omitempty"`
+	Reviews           int     `json:"reviews"`
+	FalsePositives    int     `json:"falsePositives"`
+	FalsePositiveRate float64 `json:"falsePositiveRate"`
+	Override          bool    `json:"override"`
+	Suppressed        int64   `json:"suppressed"`
+}
+
+// AdaptiveRulesResponse represents the adaptive rules and when they were last learned
+type AdaptiveRulesResponse struct {
+	LearnedAt *time.Time             `json:"learnedAt,omitempty"`
+	Rules     []AdaptiveRuleResponse `json:"rules"`
+}
+
+// initAdaptiveRuleRoutes registers the adaptive rule endpoints
+func (c *Controller) initAdaptiveRuleRoutes() {
+	rulesGroup := c.Group.Group("/adaptive-rules", c.AuthMiddleware)
+
+	rulesGroup.GET("", c.GetAdaptiveRules)
+	rulesGroup.POST("/refresh", c.RefreshAdaptiveRules, auth.RequireRole(auth.RoleAdmin))
+	rulesGroup.PUT("/overrides", c.SetAdaptiveRuleOverride, auth.RequireRole(auth.RoleAdmin))
+	rulesGroup.DELETE("/overrides", c.DeleteAdaptiveRuleOverride, auth.RequireRole(auth.RoleAdmin))
+}
+
+// adaptiveRules returns the adaptive rules of the processor, nil when they are not enabled
+func (c *Controller) adaptiveRules() *processor.AdaptiveRules {
+	if c.Processor == nil {
+		return nil
+	}
+	return c.Processor.GetAdaptiveRules()
+}
+
+// GetAdaptiveRules handles GET /api/v2/adaptive-rules
+// Lists the overrides and the rules learned from reviewed detections.
+func (c *Controller) GetAdaptiveRules(ctx echo.Context) error {
+	rules := c.adaptiveRules()
+	if rules == nil {
+		return c.HandleError(ctx, nil, "Adaptive rules are not enabled", http.StatusServiceUnavailable)
+	}
+	return ctx.JSON(http.StatusOK, adaptiveRulesResponse(rules))
+}
+
+// RefreshAdaptiveRules handles POST /api/v2/adaptive-rules/refresh
+// Learns the rules again without waiting for the refresh interval.
+func (c *Controller) RefreshAdaptiveRules(ctx echo.Context) error {
+	rules := c.adaptiveRules()
+	if rules == nil {
+		return c.HandleError(ctx, nil, "Adaptive rules are not enabled", http.StatusServiceUnavailable)
+	}
+	if err := rules.Refresh(); err != nil {
+		return c.HandleError(ctx, err, "Failed to learn adaptive rules", http.StatusInternalServerError)
+	}
+	return ctx.JSON(http.StatusOK, adaptiveRulesResponse(rules))
+}
+
+// SetAdaptiveRuleOverride handles PUT /api/v2/adaptive-rules/overrides
+// Adds an override, or replaces the override of the same species, source and hour.
+func (c *Controller) SetAdaptiveRuleOverride(ctx echo.Context) error {
+	rules := c.adaptiveRules()
+	if rules == nil {
+		return c.HandleError(ctx, nil, "Adaptive rules are not enabled", http.StatusServiceUnavailable)
+	}
+
+	var override conf.AdaptiveRuleOverride
+	if err := ctx.Bind(&override); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if err := conf.ValidateAdaptiveRuleOverride(&override); err != nil {
+		return c.HandleError(ctx, err, "Invalid adaptive rule override", http.StatusBadRequest)
+	}
+
+	key := processor.OverrideKey(&override)
+	err := c.updateAdaptiveRuleOverrides(rules, func(overrides []conf.AdaptiveRuleOverride) ([]conf.AdaptiveRuleOverride, bool) {
+		for i := range overrides {
+			if processor.OverrideKey(&overrides[i]) == key {
+				overrides[i] = override
+				return overrides, true
+			}
+		}
+		return append(overrides, override), true
+	})
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to save adaptive rule override", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Adaptive rule override set",
+			"species", override.Species,
+			"source", override.Source,
+			"action", override.Action,
+			"ip", ctx.RealIP(),
+		)
+	}
+	return ctx.JSON(http.StatusOK, adaptiveRulesResponse(rules))
+}
+
+// DeleteAdaptiveRuleOverride handles DELETE /api/v2/adaptive-rules/overrides
+// Query parameters:
+//   - species: scientific name of the override
+//   - source: audio source ID, the override of all sources when empty
+//   - hour: hour of day 0-23, the override of all hours when empty
+func (c *Controller) DeleteAdaptiveRuleOverride(ctx echo.Context) error {
+	rules := c.adaptiveRules()
+	if rules == nil {
+		return c.HandleError(ctx, nil, "Adaptive rules are not enabled", http.StatusServiceUnavailable)
+	}
+
+	key := processor.AdaptiveRuleKey{
+		ScientificName: ctx.QueryParam("species"),
+		Source:         ctx.QueryParam("source"),
+		Hour:           processor.AnyHour,
+	}
+	if key.ScientificName == "" {
+		return c.HandleError(ctx, nil, "Species is required", http.StatusBadRequest)
+	}
+	if value := ctx.QueryParam("hour"); value != "" {
+		hour, err := strconv.Atoi(value)
+		if err != nil || hour < 0 || hour > 23 {
+			return c.HandleError(ctx, err, "Hour must be between 0 and 23", http.StatusBadRequest)
+		}
+		key.Hour = hour
+	}
+
+	err := c.updateAdaptiveRuleOverrides(rules, func(overrides []conf.AdaptiveRuleOverride) ([]conf.AdaptiveRuleOverride, bool) {
+		for i := range overrides {
+			if processor.OverrideKey(&overrides[i]) == key {
+				return append(overrides[:i], overrides[i+1:]...), true
+			}
+		}
+		return overrides, false
+	})
+	if errors.Is(err, errAdaptiveRuleOverrideNotFound) {
+		return c.HandleError(ctx, err, "Adaptive rule override not found", http.StatusNotFound)
+	}
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to delete adaptive rule override", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Adaptive rule override deleted",
+			"species", key.ScientificName,
+			"source", key.Source,
+			"ip", ctx.RealIP(),
+		)
+	}
+	return ctx.JSON(http.StatusOK, adaptiveRulesResponse(rules))
+}
+
+// errAdaptiveRuleOverrideNotFound is returned when an update finds no override to change
+var errAdaptiveRuleOverrideNotFound = errors.NewStd("adaptive rule override not found")
+
+// updateAdaptiveRuleOverrides applies an update to a copy of the overrides, saves the
+// settings and hands the overrides to the adaptive rules. The update reports whether it
+// found the override to change.
+func (c *Controller) updateAdaptiveRuleOverrides(rules *processor.AdaptiveRules, update func([]conf.AdaptiveRuleOverride) ([]conf.AdaptiveRuleOverride, bool)) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	settings := &c.Settings.Realtime.AdaptiveRules
+	previous := settings.Overrides
+	overrides, found := update(append([]conf.AdaptiveRuleOverride(nil), previous...))
+	if !found {
+		return errAdaptiveRuleOverrideNotFound
+	}
+	settings.Overrides = overrides
+
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			settings.Overrides = previous
+			return fmt.Errorf("failed to save settings: %w", err)
+		}
+	}
+	rules.SetOverrides(settings.Overrides)
+	return nil
+}
+
+// adaptiveRulesResponse converts the adaptive rules to their response
+func adaptiveRulesResponse(rules *processor.AdaptiveRules) AdaptiveRulesResponse {
+	all, learnedAt := rules.Rules()
+	response := AdaptiveRulesResponse{Rules: make([]AdaptiveRuleResponse, 0, len(all))}
+	if !learnedAt.IsZero() {
+		response.LearnedAt = &learnedAt
+	}
+	for i := range all {
+		rule := &all[i]
+		item := AdaptiveRuleResponse{
+			ScientificName:    rule.ScientificName,
+			CommonName:        rule.CommonName,
+			Source:            rule.Source,
+			Action:            rule.Action,
+			MinConfidence:     rule.MinConfidence,
+			Reviews:           rule.Reviews,
+			FalsePositives:    rule.FalsePositives,
+			FalsePositiveRate: rule.FalsePositiveRate,
+			Override:          rule.Override,
+			Suppressed:        rule.Suppressed,
+		}
+		if rule.Hour != processor.AnyHour {
+			hour := rule.Hour
+			item.Hour = &hour
+		}
+		response.Rules = append(response.Rules, item)
+	}
+	return response
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestAdaptiveRulesEndpoints(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+	controller.initAdaptiveRuleRoutes()
+	controller.DisableSaveSettings = true
+	service := &fakeAuthService{username: "admin", role: auth.RoleAdmin}
+	controller.AuthService = service
+
+	// Rules are not available until the processor has them
+	rec := serveAs(controller, http.MethodGet, "/api/v2/adaptive-rules", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	outcomes := make([]datastore.ReviewOutcome, 0, 10)
+	for range 10 {
+		outcomes = append(outcomes, datastore.ReviewOutcome{
+			ScientificName: "Corvus corax",
+			CommonName:     "Common Raven",
+			SourceID:       "rtsp_1",
+			Time:           "05:10:00",
+			Confidence:     0.8,
+			Verified:       "false_positive",
+		})
+	}
+	mockDS.On("GetReviewOutcomes", mock.AnythingOfType("string")).Return(outcomes, nil)
+
+	settings := &controller.Settings.Realtime.AdaptiveRules
+	*settings = conf.AdaptiveRulesSettings{
+		Enabled:         true,
+		WindowDays:      90,
+		MinReviews:      10,
+		RaiseRate:       0.5,
+		SuppressRate:    0.9,
+		RefreshInterval: 60,
+	}
+	controller.Processor = &processor.Processor{Settings: controller.Settings}
+	controller.Processor.SetAdaptiveRules(processor.NewAdaptiveRules(settings, mockDS))
+
+	rec = serveAs(controller, http.MethodPost, "/api/v2/adaptive-rules/refresh", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response AdaptiveRulesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotNil(t, response.LearnedAt)
+	require.Len(t, response.Rules, 4, "species, hour, source and source at hour rules")
+	assert.Equal(t, conf.AdaptiveRuleSuppress, response.Rules[0].Action)
+	assert.Nil(t, response.Rules[0].Hour)
+	assert.InDelta(t, 1, response.Rules[0].FalsePositiveRate, 1e-9)
+
+	// Overrides are validated, saved in the settings and listed first
+	rec = serveAs(controller, http.MethodPut, "/api/v2/adaptive-rules/overrides", map[string]any{
+		"species": "Corvus corax", "action": "ignore",
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	hour := 5
+	override := conf.AdaptiveRuleOverride{Species: "Corvus corax", Source: "rtsp_1", Hour: &hour, Action: conf.AdaptiveRuleAllow}
+	rec = serveAs(controller, http.MethodPut, "/api/v2/adaptive-rules/overrides", override)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, settings.Overrides, 1)
+	rule, ok := controller.Processor.GetAdaptiveRules().Match("Corvus corax", "rtsp_1", 5)
+	require.True(t, ok)
+	assert.True(t, rule.Override)
+	assert.False(t, rule.Suppresses(0.99))
+
+	// Setting the override of the same context replaces it
+	override.Action = conf.AdaptiveRuleSuppress
+	rec = serveAs(controller, http.MethodPut, "/api/v2/adaptive-rules/overrides", override)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, settings.Overrides, 1)
+	assert.Equal(t, conf.AdaptiveRuleSuppress, settings.Overrides[0].Action)
+
+	rec = serveAs(controller, http.MethodDelete, "/api/v2/adaptive-rules/overrides?species=Corvus+corax&source=rtsp_1", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "the override of all hours does not exist")
+	rec = serveAs(controller, http.MethodDelete, "/api/v2/adaptive-rules/overrides?species=Corvus+corax&source=rtsp_1&hour=24", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveAs(controller, http.MethodDelete, "/api/v2/adaptive-rules/overrides?species=Corvus+corax&source=rtsp_1&hour=5", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, settings.Overrides)
+	rule, ok = controller.Processor.GetAdaptiveRules().Match("Corvus corax", "rtsp_1", 5)
+	require.True(t, ok)
+	assert.False(t, rule.Override, "the learned rule applies again")
+
+	// Only admins change the rules
+	service.role = auth.RoleReviewer
+	rec = serveAs(controller, http.MethodGet, "/api/v2/adaptive-rules", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serveAs(controller, http.MethodPut, "/api/v2/adaptive-rules/overrides", override)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
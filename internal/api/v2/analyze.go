@@ -0,0 +1,717 @@
+// internal/api/v2/analyze.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/analysis/recording"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Upload analysis configuration
+const (
+	analyzeEndpoint          = "/api/v2/analyze"
+	analyzeUploadOverhead    = 1 << 20          // Multipart encoding overhead allowed on top of the recording size
+	analyzeEventBufferSize   = 64               // Buffer for progress events of a subscriber
+	analyzeCleanupInterval   = 10 * time.Minute // Interval for removing expired analyses
+	analyzeDefaultMaxSizeMB  = 200
+	analyzeDefaultQueueSize  = 10
+	analyzeDefaultKeepHours  = 24
+	analyzeStreamMaxDuration = 6 * time.Hour // Maximum duration of a progress stream
+)
+
+// AnalysisStatus is the state of an uploaded recording analysis
+type AnalysisStatus string
+
+// Analysis states
+const (
+	AnalysisQueued    AnalysisStatus = "queued"
+	AnalysisRunning   AnalysisStatus = "running"
+	AnalysisCompleted AnalysisStatus = "completed"
+	AnalysisFailed    AnalysisStatus = "failed"
+	AnalysisCanceled  AnalysisStatus = "canceled"
+)
+
+// finished reports whether the analysis has ended
+func (s AnalysisStatus) finished() bool {
+	return s == AnalysisCompleted || s == AnalysisFailed || s == AnalysisCanceled
+}
+
+var errAnalysisQueueFull = errors.NewStd("analysis queue is full")
+
+// AnalysisPrediction is a species predicted in a chunk of an uploaded recording
+type AnalysisPrediction struct {
+	ScientificName string  `json:"scientificName"`
+	CommonName     string  `json:"commonName"`
+	SpeciesCode    string  `json:"speciesCode,omitempty"`
+	Confidence     float64 `json:"confidence"`
+}
+
+// AnalysisChunkResult holds the predictions above the threshold for a chunk of an uploaded recording
+type AnalysisChunkResult struct {
+	Start       float64              `json:"start"` // Seconds from the start of the recording
+	End         float64              `json:"end"`
+	Predictions []AnalysisPrediction `json:"predictions"`
+}
+
+// AnalysisJob describes the analysis of an uploaded recording
+type AnalysisJob struct {
+	ID          string                `json:"id"`
+	FileName    string                `json:"fileName"`
+	Status      AnalysisStatus        `json:"status"`
+	Save        bool                  `json:"save"`
+	Progress    float64               `json:"progress"` // Share of analyzed chunks from 0 to 1
+	ChunksDone  int                   `json:"chunksDone"`
+	ChunksTotal int                   `json:"chunksTotal"`
+	Results     []AnalysisChunkResult `json:"results"`
+	Saved       int                   `json:"saved"`
+	Error       string                `json:"error,omitempty"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	StartedAt   *time.Time            `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time            `json:"finishedAt,omitempty"`
+}
+
+// AnalysisProgress is the progress event sent for every analyzed chunk
+type AnalysisProgress struct {
+	ID          string              `json:"id"`
+	Progress    float64             `json:"progress"`
+	ChunksDone  int                 `json:"chunksDone"`
+	ChunksTotal int                 `json:"chunksTotal"`
+	Chunk       AnalysisChunkResult `json:"chunk"`
+}
+
+// chunkAnalyzer predicts species in a 3 second chunk, implemented by the shared BirdNET instance
+type chunkAnalyzer interface {
+	ProcessChunkWithContext(ctx context.Context, chunk []float32, predStart time.Time) ([]datastore.Note, error)
+}
+
+// analysisJob is an uploaded recording waiting for or under analysis
+type analysisJob struct {
+	mu             sync.Mutex
+	job            AnalysisJob
+	dir            string         // Temporary directory of the upload
+	path           string         // Uploaded recording
+	recordingStart time.Time      // Recording start given with the upload, zero when read from the file
+	nameTimezone   *time.Location // Time zone of a timestamp in the file name, nil for the zone of the recorder
+	owner          string         // Username of the submitter, empty when authentication was bypassed
+	cancel         context.CancelFunc
+	subscribers    map[chan AnalysisProgress]struct{}
+}
+
+// snapshot returns a copy of the job state
+func (j *analysisJob) snapshot() AnalysisJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	snapshot := j.job
+	snapshot.Results = append([]AnalysisChunkResult(nil), j.job.Results...)
+	return snapshot
+}
+
+// subscribe returns a channel receiving progress events, it is closed when the analysis ends
+func (j *analysisJob) subscribe() (events <-chan AnalysisProgress, unsubscribe func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ch := make(chan AnalysisProgress, analyzeEventBufferSize)
+	if j.job.Status.finished() {
+		close(ch)
+		return ch, func() {}
+	}
+	j.subscribers[ch] = struct{}{}
+	return ch, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if _, ok := j.subscribers[ch]; ok {
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// addChunk records the results of an analyzed chunk and notifies subscribers,
+// events are dropped for subscribers that do not keep up
+func (j *analysisJob) addChunk(result AnalysisChunkResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.ChunksDone++
+	if j.job.ChunksDone > j.job.ChunksTotal {
+		j.job.ChunksTotal = j.job.ChunksDone
+	}
+	j.job.Progress = float64(j.job.ChunksDone) / float64(j.job.ChunksTotal)
+	if len(result.Predictions) > 0 {
+		j.job.Results = append(j.job.Results, result)
+	}
+
+	event := AnalysisProgress{
+		ID:          j.job.ID,
+		Progress:    j.job.Progress,
+		ChunksDone:  j.job.ChunksDone,
+		ChunksTotal: j.job.ChunksTotal,
+		Chunk:       result,
+	}
+	for ch := range j.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// finish sets the final state of the analysis and ends the progress streams
+func (j *analysisJob) finish(status AnalysisStatus, saved int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.job.Status = status
+	j.job.Saved = saved
+	j.job.FinishedAt = &now
+	if status == AnalysisCompleted {
+		j.job.Progress = 1
+	}
+	if err != nil {
+		j.job.Error = err.Error()
+	}
+	for ch := range j.subscribers {
+		close(ch)
+	}
+	clear(j.subscribers)
+}
+
+// analysisPool analyzes uploaded recordings with a fixed number of workers sharing the
+// BirdNET instance of realtime analysis. Waiting recordings are held in a bounded queue.
+type analysisPool struct {
+	settings *conf.Settings
+	analyzer chunkAnalyzer
+	store    datastore.Interface
+	logger   *slog.Logger
+	queue    chan *analysisJob
+
+	mu   sync.RWMutex
+	jobs map[string]*analysisJob
+}
+
+// newAnalysisPool starts the analysis workers, they stop when ctx is canceled
+func newAnalysisPool(ctx context.Context, wg *sync.WaitGroup, settings *conf.Settings, analyzer chunkAnalyzer, store datastore.Interface, logger *slog.Logger) *analysisPool {
+	queueSize := settings.WebServer.Analyze.QueueSize
+	if queueSize <= 0 {
+		queueSize = analyzeDefaultQueueSize
+	}
+	p := &analysisPool{
+		settings: settings,
+		analyzer: analyzer,
+		store:    store,
+		logger:   logger,
+		queue:    make(chan *analysisJob, queueSize),
+		jobs:     make(map[string]*analysisJob),
+	}
+
+	workers := max(settings.WebServer.Analyze.Workers, 1)
+	wg.Add(workers + 1)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	go func() {
+		defer wg.Done()
+		p.cleanup(ctx)
+	}()
+	return p
+}
+
+// submit queues an uploaded recording for analysis
+func (p *analysisPool) submit(job *analysisJob) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case p.queue <- job:
+		p.jobs[job.job.ID] = job
+		return nil
+	default:
+		return errAnalysisQueueFull
+	}
+}
+
+// get returns an analysis by ID
+func (p *analysisPool) get(id string) (*analysisJob, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	job, ok := p.jobs[id]
+	return job, ok
+}
+
+// remove cancels an analysis and forgets it
+func (p *analysisPool) remove(id string) bool {
+	p.mu.Lock()
+	job, ok := p.jobs[id]
+	delete(p.jobs, id)
+	p.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	job.mu.Lock()
+	cancel := job.cancel
+	queued := job.job.Status == AnalysisQueued
+	job.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if queued {
+		job.finish(AnalysisCanceled, 0, nil)
+	}
+	return true
+}
+
+// work analyzes queued recordings until ctx is canceled, recordings left in the queue are discarded
+func (p *analysisPool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case job := <-p.queue:
+					job.finish(AnalysisCanceled, 0, ctx.Err())
+					removeUpload(job)
+				default:
+					return
+				}
+			}
+		case job := <-p.queue:
+			p.run(ctx, job)
+		}
+	}
+}
+
+// cleanup removes finished analyses after the configured retention
+func (p *analysisPool) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(analyzeCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.removeExpired(time.Now())
+		}
+	}
+}
+
+// removeExpired forgets analyses that finished before the retention period
+func (p *analysisPool) removeExpired(now time.Time) {
+	keepHours := p.settings.WebServer.Analyze.KeepHours
+	if keepHours <= 0 {
+		keepHours = analyzeDefaultKeepHours
+	}
+	cutoff := now.Add(-time.Duration(keepHours) * time.Hour)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, job := range p.jobs {
+		snapshot := job.snapshot()
+		if snapshot.FinishedAt != nil && snapshot.FinishedAt.Before(cutoff) {
+			delete(p.jobs, id)
+		}
+	}
+}
+
+// run analyzes an uploaded recording and saves its detections when requested
+func (p *analysisPool) run(ctx context.Context, job *analysisJob) {
+	defer removeUpload(job)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	job.mu.Lock()
+	if job.job.Status != AnalysisQueued {
+		// Removed while waiting in the queue
+		job.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	job.job.Status = AnalysisRunning
+	job.job.StartedAt = &now
+	job.cancel = cancel
+	job.mu.Unlock()
+
+	notes, duration, err := p.analyze(jobCtx, job)
+	if err != nil {
+		if jobCtx.Err() != nil {
+			job.finish(AnalysisCanceled, 0, nil)
+			return
+		}
+		p.logger.Warn("Recording analysis failed", "job_id", job.job.ID, "file", job.job.FileName, "error", err.Error())
+		job.finish(AnalysisFailed, 0, err)
+		return
+	}
+
+	saved := 0
+	if job.job.Save {
+		if saved, err = p.save(job, notes, duration); err != nil {
+			p.logger.Warn("Saving analyzed recording failed", "job_id", job.job.ID, "file", job.job.FileName, "error", err.Error())
+			job.finish(AnalysisFailed, saved, err)
+			return
+		}
+	}
+	p.logger.Info("Recording analysis completed", "job_id", job.job.ID, "file", job.job.FileName, "saved", saved)
+	job.finish(AnalysisCompleted, saved, nil)
+}
+
+// analyze runs BirdNET on every chunk of the recording and returns the results and recording length
+func (p *analysisPool) analyze(ctx context.Context, job *analysisJob) ([]datastore.Note, time.Duration, error) {
+	info, err := myaudio.GetAudioInfo(job.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.TotalSamples == 0 || info.SampleRate == 0 {
+		return nil, 0, fmt.Errorf("recording contains no audio")
+	}
+	duration := time.Duration(float64(info.TotalSamples) / float64(info.SampleRate) * float64(time.Second))
+
+	overlap := p.settings.BirdNET.Overlap
+	job.mu.Lock()
+	job.job.ChunksTotal = max(myaudio.GetTotalChunks(info.SampleRate, info.TotalSamples, overlap), 1)
+	job.mu.Unlock()
+
+	readSettings := &conf.Settings{}
+	readSettings.Input.Path = job.path
+	readSettings.BirdNET.Overlap = overlap
+	readSettings.Realtime.Audio.FfmpegPath = p.settings.Realtime.Audio.FfmpegPath
+
+	// Uploads take turns with the realtime sources for the shared BirdNET interpreters
+	ctx = birdnet.WithSource(ctx, "upload")
+
+	step := time.Duration((3 - overlap) * float64(time.Second))
+	var position time.Duration
+	var notes []datastore.Note
+	err = myaudio.ReadAudioFileBuffered(readSettings, func(chunk []float32, isEOF bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+		chunkNotes, err := p.analyzer.ProcessChunkWithContext(ctx, chunk, time.Time{}.Add(position))
+		if err != nil {
+			return err
+		}
+
+		result := AnalysisChunkResult{
+			Start:       position.Seconds(),
+			End:         (position + 3*time.Second).Seconds(),
+			Predictions: []AnalysisPrediction{},
+		}
+		for i := range chunkNotes {
+			note := &chunkNotes[i]
+			if note.Confidence <= p.settings.BirdNET.Threshold || !p.settings.IsSpeciesIncluded(note.ScientificName) {
+				continue
+			}
+			result.Predictions = append(result.Predictions, AnalysisPrediction{
+				ScientificName: note.ScientificName,
+				CommonName:     note.CommonName,
+				SpeciesCode:    note.SpeciesCode,
+				Confidence:     note.Confidence,
+			})
+			notes = append(notes, *note)
+		}
+		job.addChunk(result)
+		position += step
+		return nil
+	})
+	return notes, duration, err
+}
+
+// save stores the detections of an analyzed recording like file analysis, recordings already
+// saved are not saved again
+func (p *analysisPool) save(job *analysisJob, notes []datastore.Note, duration time.Duration) (int, error) {
+	if p.store == nil {
+		return 0, fmt.Errorf("datastore is not available")
+	}
+	start := job.recordingStart
+	if start.IsZero() {
+		var err error
+		if start, _, err = myaudio.RecordingStartTime(job.path, duration, job.nameTimezone); err != nil {
+			return 0, fmt.Errorf("failed to determine recording start time: %w", err)
+		}
+	}
+
+	sourceID, err := recording.SourceID(job.path)
+	if err != nil {
+		return 0, err
+	}
+	detections := recording.Detections(p.settings.BirdNET.Threshold, job.job.FileName, sourceID, start, notes)
+	if len(detections) == 0 {
+		return 0, nil
+	}
+	exists, err := recording.Saved(p.store, sourceID, detections)
+	if err != nil || exists {
+		return 0, err
+	}
+	return recording.Save(p.store, detections)
+}
+
+// removeUpload deletes the uploaded recording
+func removeUpload(job *analysisJob) {
+	if job.dir != "" {
+		_ = os.RemoveAll(job.dir)
+	}
+}
+
+// initAnalyzeRoutes registers the recording upload and analysis endpoints
+func (c *Controller) initAnalyzeRoutes() {
+	// Analysis uses the shared BirdNET instance and disk space and may save detections,
+	// uploading and removing recordings requires a reviewer. Results are only shown to the
+	// submitter and reviewers, see lookupAnalysis.
+	authMiddleware := c.getEffectiveAuthMiddleware()
+	c.Group.POST("/analyze", c.SubmitAnalysis, authMiddleware, auth.RequireRole(auth.RoleReviewer))
+	c.Group.GET("/analyze/:id", c.GetAnalysis, authMiddleware)
+	c.Group.GET("/analyze/:id/events", c.StreamAnalysis, authMiddleware)
+	c.Group.DELETE("/analyze/:id", c.DeleteAnalysis, authMiddleware, auth.RequireRole(auth.RoleReviewer))
+}
+
+// isAnalyzeUpload reports whether the request uploads a recording, uploads have their own size limit
+func isAnalyzeUpload(ctx echo.Context) bool {
+	return ctx.Request().Method == http.MethodPost && ctx.Request().URL.Path == analyzeEndpoint
+}
+
+// getAnalysisPool returns the analysis pool, starting it on first use once realtime
+// analysis has loaded BirdNET
+func (c *Controller) getAnalysisPool() *analysisPool {
+	c.analysisPoolMutex.Lock()
+	defer c.analysisPoolMutex.Unlock()
+	if c.analysisPool != nil {
+		return c.analysisPool
+	}
+	if c.Processor == nil || c.Processor.Bn == nil {
+		return nil
+	}
+	c.analysisPool = newAnalysisPool(c.ctx, &c.wg, c.Settings, c.Processor.Bn, c.DS, c.apiLogger)
+	return c.analysisPool
+}
+
+// SubmitAnalysis handles POST /api/v2/analyze
+// Accepts a recording as the multipart form field "file" and queues it for analysis.
+// Form fields:
+//   - save: true to save the detections to the database like file analysis
+//   - recording_start: RFC 3339 start time of the recording, read from the file when omitted
+//
+// Responds with 202 and the queued analysis, progress is streamed from /api/v2/analyze/:id/events
+func (c *Controller) SubmitAnalysis(ctx echo.Context) error {
+	settings := c.Settings.WebServer.Analyze
+	if !settings.Enabled {
+		return c.HandleError(ctx, nil, "Recording analysis is disabled", http.StatusServiceUnavailable)
+	}
+	pool := c.getAnalysisPool()
+	if pool == nil {
+		return c.HandleError(ctx, nil, "BirdNET is not available for analysis", http.StatusServiceUnavailable)
+	}
+
+	maxSizeMB := settings.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = analyzeDefaultMaxSizeMB
+	}
+	maxSize := int64(maxSizeMB) << 20
+	ctx.Request().Body = http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxSize+analyzeUploadOverhead)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return c.HandleError(ctx, err, fmt.Sprintf("Recording exceeds the maximum size of %d MB", maxSizeMB), http.StatusRequestEntityTooLarge)
+		}
+		return c.HandleError(ctx, err, "Missing recording in form field \"file\"", http.StatusBadRequest)
+	}
+	if fileHeader.Size > maxSize {
+		return c.HandleError(ctx, nil, fmt.Sprintf("Recording exceeds the maximum size of %d MB", maxSizeMB), http.StatusRequestEntityTooLarge)
+	}
+	fileName := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(fileHeader.Filename, "\\", "/")))
+	if !myaudio.IsSupportedAudioFile(fileName) {
+		return c.HandleError(ctx, nil, "Unsupported audio format, supported formats are WAV, FLAC, MP3, OGG, Opus and M4A", http.StatusBadRequest)
+	}
+
+	save := false
+	if value := ctx.FormValue("save"); value != "" {
+		if save, err = strconv.ParseBool(value); err != nil {
+			return c.HandleError(ctx, err, "Invalid save value, expected true or false", http.StatusBadRequest)
+		}
+	}
+	if save && c.DS == nil {
+		return c.HandleError(ctx, nil, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+	var recordingStart time.Time
+	if value := ctx.FormValue("recording_start"); value != "" {
+		if recordingStart, err = time.Parse(time.RFC3339, value); err != nil {
+			return c.HandleError(ctx, err, "Invalid recording_start, expected an RFC 3339 time", http.StatusBadRequest)
+		}
+	}
+
+	nameTimezone, err := myaudio.ParseRecordingTimezone(ctx.FormValue("timezone"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid timezone, expected Local or an IANA time zone name", http.StatusBadRequest)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to read the uploaded recording", http.StatusBadRequest)
+	}
+	job, err := storeUpload(src, fileName)
+	_ = src.Close()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to store the uploaded recording", http.StatusInternalServerError)
+	}
+	job.job.Save = save
+	job.recordingStart = recordingStart
+	job.nameTimezone = nameTimezone
+	job.owner = stringFromCtx(ctx, "username", "")
+
+	if err := pool.submit(job); err != nil {
+		removeUpload(job)
+		return c.HandleError(ctx, err, "Too many recordings waiting for analysis, try again later", http.StatusServiceUnavailable)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Recording queued for analysis",
+		"job_id", job.job.ID, "file", fileName, "size", fileHeader.Size, "save", save)
+	ctx.Response().Header().Set(echo.HeaderLocation, analyzeEndpoint+"/"+job.job.ID)
+	return ctx.JSON(http.StatusAccepted, job.snapshot())
+}
+
+// storeUpload copies an uploaded recording into a temporary directory under its own name,
+// the name is kept for reading the recording time from it
+func storeUpload(src io.Reader, fileName string) (*analysisJob, error) {
+	dir, err := os.MkdirTemp("", "birdnet-go-analyze-")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, fileName)
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err == nil {
+		_, err = io.Copy(dst, src)
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &analysisJob{
+		job: AnalysisJob{
+			ID:        generateCorrelationID(),
+			FileName:  fileName,
+			Status:    AnalysisQueued,
+			Results:   []AnalysisChunkResult{},
+			CreatedAt: time.Now(),
+		},
+		dir:         dir,
+		path:        path,
+		subscribers: make(map[chan AnalysisProgress]struct{}),
+	}, nil
+}
+
+// lookupAnalysis returns the analysis of the id path parameter and its pool, the job is nil
+// when the analysis does not exist or the user may not access it and the error response was sent
+func (c *Controller) lookupAnalysis(ctx echo.Context) (*analysisPool, *analysisJob, error) {
+	c.analysisPoolMutex.Lock()
+	pool := c.analysisPool
+	c.analysisPoolMutex.Unlock()
+	if pool == nil {
+		return nil, nil, c.HandleError(ctx, nil, "Analysis not found", http.StatusNotFound)
+	}
+	job, ok := pool.get(ctx.Param("id"))
+	if !ok {
+		return nil, nil, c.HandleError(ctx, nil, "Analysis not found", http.StatusNotFound)
+	}
+	if !canAccessAnalysis(ctx, job) {
+		return nil, nil, c.HandleError(ctx, nil, "Access to this analysis is denied", http.StatusForbidden)
+	}
+	return pool, job, nil
+}
+
+// canAccessAnalysis reports whether the user of the request submitted the analysis or is a reviewer
+func canAccessAnalysis(ctx echo.Context, job *analysisJob) bool {
+	if auth.HasRole(ctx, auth.RoleReviewer) {
+		return true
+	}
+	username := stringFromCtx(ctx, "username", "")
+	return job.owner != "" && job.owner == username
+}
+
+// GetAnalysis handles GET /api/v2/analyze/:id
+// Returns the state of an analysis with the results of the chunks analyzed so far
+func (c *Controller) GetAnalysis(ctx echo.Context) error {
+	_, job, err := c.lookupAnalysis(ctx)
+	if job == nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, job.snapshot())
+}
+
+// DeleteAnalysis handles DELETE /api/v2/analyze/:id
+// Cancels a queued or running analysis and removes its results
+func (c *Controller) DeleteAnalysis(ctx echo.Context) error {
+	pool, job, err := c.lookupAnalysis(ctx)
+	if job == nil {
+		return err
+	}
+	pool.remove(job.job.ID)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// StreamAnalysis handles GET /api/v2/analyze/:id/events
+// Streams a "progress" event for every analyzed chunk, followed by an event named after
+// the final status with the complete analysis
+func (c *Controller) StreamAnalysis(ctx echo.Context) error {
+	_, job, err := c.lookupAnalysis(ctx)
+	if job == nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx.Request().Context(), analyzeStreamMaxDuration)
+	defer cancel()
+	ctx.SetRequest(ctx.Request().WithContext(timeoutCtx))
+	setSSEHeaders(ctx)
+
+	events, unsubscribe := job.subscribe()
+	defer unsubscribe()
+	if err := c.sendSSEMessage(ctx, "status", job.snapshot()); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-timeoutCtx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.sendSSEMessage(ctx, "heartbeat", map[string]any{"timestamp": time.Now().Unix()}); err != nil {
+				return nil
+			}
+		case event, ok := <-events:
+			if !ok {
+				final := job.snapshot()
+				_ = c.sendSSEMessage(ctx, string(final.Status), final)
+				return nil
+			}
+			if err := c.sendSSEMessage(ctx, "progress", event); err != nil {
+				return nil
+			}
+		}
+	}
+}
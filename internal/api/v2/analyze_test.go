@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// fakeChunkAnalyzer predicts a blackbird in the chunk starting 3 seconds into a recording
+type fakeChunkAnalyzer struct{}
+
+func (fakeChunkAnalyzer) ProcessChunkWithContext(ctx context.Context, chunk []float32, predStart time.Time) ([]datastore.Note, error) {
+	confidence := 0.2
+	if predStart.Sub(time.Time{}) == 3*time.Second {
+		confidence = 0.9
+	}
+	return []datastore.Note{{
+		BeginTime:      predStart,
+		EndTime:        predStart.Add(3 * time.Second),
+		ScientificName: "Turdus merula",
+		CommonName:     "Eurasian Blackbird",
+		SpeciesCode:    "eurbla",
+		Confidence:     confidence,
+	}}, nil
+}
+
+// testRecording returns a silent 16-bit mono WAV recording of the given length
+func testRecording(t *testing.T, seconds int) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	require.NoError(t, myaudio.SavePCMDataToWAV(path, make([]byte, seconds*conf.SampleRate*2)))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+// analysisTestSettings returns settings for analyzing uploads without overlap where
+// blackbirds pass the range filter
+func analysisTestSettings() *conf.Settings {
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.5
+	settings.BirdNET.RangeFilter.Species = []string{"Turdus merula_Eurasian Blackbird"}
+	settings.WebServer.Analyze.Enabled = true
+	settings.WebServer.Analyze.MaxSizeMB = 1
+	return settings
+}
+
+// waitForAnalysis waits until the analysis has finished and returns its final state
+func waitForAnalysis(t *testing.T, job *analysisJob) AnalysisJob {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return job.snapshot().Status.finished()
+	}, 10*time.Second, 10*time.Millisecond)
+	return job.snapshot()
+}
+
+func TestAnalysisPoolSavesDetections(t *testing.T) {
+	t.Parallel()
+	settings := analysisTestSettings()
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	store := datastore.New(settings)
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	pool := newAnalysisPool(ctx, &wg, settings, fakeChunkAnalyzer{}, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	job, err := storeUpload(bytes.NewReader(testRecording(t, 7)), "AM01_20240501_063000.wav")
+	require.NoError(t, err)
+	job.job.Save = true
+	require.NoError(t, pool.submit(job))
+
+	final := waitForAnalysis(t, job)
+	require.Equal(t, AnalysisCompleted, final.Status, final.Error)
+	assert.Equal(t, 3, final.ChunksDone)
+	assert.InDelta(t, 1.0, final.Progress, 0.0001)
+	require.Len(t, final.Results, 1, "only chunks with predictions above the threshold are kept")
+	assert.InDelta(t, 3.0, final.Results[0].Start, 0.0001)
+	assert.Equal(t, "eurbla", final.Results[0].Predictions[0].SpeciesCode)
+	assert.Equal(t, 1, final.Saved)
+	assert.NoDirExists(t, job.dir, "the upload is removed after analysis")
+
+	var stored []datastore.Note
+	require.NoError(t, store.Transaction(func(tx *gorm.DB) error {
+		return tx.Find(&stored).Error
+	}))
+	require.Len(t, stored, 1)
+	assert.Equal(t, "2024-05-01", stored[0].Date)
+	assert.Equal(t, "06:30:03", stored[0].Time)
+	assert.True(t, strings.HasPrefix(stored[0].SourceID, "file_AM01_20240501_063000.wav_"), stored[0].SourceID)
+}
+
+func TestAnalysisPoolQueueFull(t *testing.T) {
+	t.Parallel()
+	pool := &analysisPool{queue: make(chan *analysisJob, 1), jobs: make(map[string]*analysisJob)}
+	require.NoError(t, pool.submit(&analysisJob{job: AnalysisJob{ID: "a"}}))
+	assert.ErrorIs(t, pool.submit(&analysisJob{job: AnalysisJob{ID: "b"}}), errAnalysisQueueFull)
+	_, ok := pool.get("b")
+	assert.False(t, ok, "rejected recordings are not tracked")
+}
+
+// postRecording uploads a recording to the analyze endpoint
+func postRecording(t *testing.T, controller *Controller, fileName string, data []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, analyzeEndpoint, &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.SubmitAnalysis(controller.Echo.NewContext(req, rec)))
+	return rec
+}
+
+// callAnalysis calls an analysis endpoint handler as a reviewer with the id path parameter
+func callAnalysis(t *testing.T, controller *Controller, method, path, id string, handler func(echo.Context) error) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, http.NoBody)
+	rec := httptest.NewRecorder()
+	ctx := controller.Echo.NewContext(req, rec)
+	ctx.Set(auth.RoleContextKey, auth.RoleReviewer)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(id)
+	require.NoError(t, handler(ctx))
+	return rec
+}
+
+func TestSubmitAnalysis(t *testing.T) {
+	t.Parallel()
+	_, _, controller := setupTestEnvironment(t)
+	controller.Settings.WebServer.Analyze = analysisTestSettings().WebServer.Analyze
+	recording := testRecording(t, 4)
+
+	// BirdNET is loaded by realtime analysis
+	rec := postRecording(t, controller, "dawn.wav", recording, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	controller.analysisPool = newAnalysisPool(controller.ctx, &controller.wg, analysisTestSettings(), fakeChunkAnalyzer{}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	rec = postRecording(t, controller, "notes.txt", []byte("not audio"), nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = postRecording(t, controller, "dawn.wav", recording, map[string]string{"recording_start": "yesterday"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = postRecording(t, controller, "dawn.wav", recording, map[string]string{"timezone": "Mars/Olympus_Mons"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = postRecording(t, controller, "dawn.wav", make([]byte, 2<<20), nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = postRecording(t, controller, "../../dawn.wav", recording, nil)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var queued AnalysisJob
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &queued))
+	assert.Equal(t, "dawn.wav", queued.FileName, "directories in the upload name are dropped")
+	assert.Equal(t, analyzeEndpoint+"/"+queued.ID, rec.Header().Get("Location"))
+
+	job, ok := controller.analysisPool.get(queued.ID)
+	require.True(t, ok)
+	final := waitForAnalysis(t, job)
+	assert.Equal(t, AnalysisCompleted, final.Status)
+	assert.Equal(t, 2, final.ChunksTotal)
+
+	// The progress stream of a finished analysis sends its state and the final event
+	path := analyzeEndpoint + "/" + queued.ID
+	rec = callAnalysis(t, controller, http.MethodGet, path+"/events", queued.ID, controller.StreamAnalysis)
+	assert.Contains(t, rec.Body.String(), "event: status\n")
+	assert.Contains(t, rec.Body.String(), "event: completed\n")
+
+	// Deleted analyses are not found
+	rec = callAnalysis(t, controller, http.MethodDelete, path, queued.ID, controller.DeleteAnalysis)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = callAnalysis(t, controller, http.MethodGet, path, queued.ID, controller.GetAnalysis)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestAnalysisAccess tests that analysis results are only shown to the submitter and reviewers
+func TestAnalysisAccess(t *testing.T) {
+	t.Parallel()
+	_, _, controller := setupTestEnvironment(t)
+	controller.initAnalyzeRoutes()
+	controller.analysisPool = &analysisPool{queue: make(chan *analysisJob, 1), jobs: make(map[string]*analysisJob)}
+	job := &analysisJob{
+		job:         AnalysisJob{ID: "a", Status: AnalysisCompleted, Results: []AnalysisChunkResult{}},
+		owner:       "alice",
+		subscribers: make(map[chan AnalysisProgress]struct{}),
+	}
+	controller.analysisPool.jobs[job.job.ID] = job
+
+	tests := []struct {
+		username string
+		role     auth.Role
+		want     int
+	}{
+		{"alice", auth.RoleViewer, http.StatusOK}, // the submitter after losing the reviewer role
+		{"bob", auth.RoleViewer, http.StatusForbidden},
+		{"carol", auth.RoleReviewer, http.StatusOK},
+	}
+	for _, tt := range tests {
+		controller.AuthService = &fakeAuthService{username: tt.username, role: tt.role}
+		rec := serveAs(controller, http.MethodGet, analyzeEndpoint+"/a", nil)
+		assert.Equal(t, tt.want, rec.Code, "%s as %s", tt.username, tt.role)
+		rec = serveAs(controller, http.MethodGet, analyzeEndpoint+"/a/events", nil)
+		assert.Equal(t, tt.want, rec.Code, "%s as %s streaming", tt.username, tt.role)
+	}
+}
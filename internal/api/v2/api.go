@@ -69,6 +69,10 @@ type Controller struct {
 	// SSE related fields
 	sseManager *SSEManager // Manager for Server-Sent Events connections
 
+	// Recording upload analysis, started on first upload
+	analysisPool      *analysisPool
+	analysisPoolMutex sync.Mutex
+
 	// Cleanup related fields
 	ctx    context.Context    // Context for managing goroutines
 	cancel context.CancelFunc // Cancel function for graceful shutdown
@@ -278,7 +282,12 @@ func NewWithOptions(e *echo.Echo, ds datastore.Interface, settings *conf.Setting
 		// Create and store the auth service instance directly.
 		// This single instance is shared across requests handled by this controller.
 		// Concurrency safety is handled within the auth.Service implementation.
-		c.AuthService = auth.NewSecurityAdapter(oauth2Server, c.apiLogger)
+		// User accounts are looked up in the datastore when it is available.
+		var users auth.UserStore
+		if ds != nil {
+			users = ds
+		}
+		c.AuthService = auth.NewSecurityAdapter(oauth2Server, users, c.apiLogger)
 
 		// Create the middleware provider using the stored service
 		authMiddlewareProvider := auth.NewMiddleware(c.AuthService, c.apiLogger)
@@ -299,7 +308,10 @@ func NewWithOptions(e *echo.Echo, ds datastore.Interface, settings *conf.Setting
 	c.Group.Use(c.TunnelDetectionMiddleware()) // Add tunnel detection **before** logging
 	// c.Group.Use(middleware.Logger())        // Removed: Use custom LoggingMiddleware below for structured logging
 	c.Group.Use(middleware.CORS())     // CORS handling
-	c.Group.Use(middleware.BodyLimit("1M")) // Limit request body to 1MB to prevent DoS attacks
+	c.Group.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit:   "1M", // Limit request body to 1MB to prevent DoS attacks
+		Skipper: isAnalyzeUpload, // Recording uploads are limited by webserver.analyze.maxsizemb
+	}))
 	c.Group.Use(c.LoggingMiddleware()) // Use custom structured logging middleware
 
 	// NOTE: CSRF Protection Consideration
@@ -417,6 +429,8 @@ func (c *Controller) initRoutes() {
 		{"search routes", c.initSearchRoutes},
 		{"detection routes", c.initDetectionRoutes},
 		{"analytics routes", c.initAnalyticsRoutes},
+		{"sound level routes", c.initSoundLevelRoutes},
+		{"export routes", c.initExportRoutes},
 		{"weather routes", c.initWeatherRoutes},
 		{"system routes", c.initSystemRoutes},
 		{"settings routes", c.initSettingsRoutes},
@@ -424,7 +438,9 @@ func (c *Controller) initRoutes() {
 		{"stream routes", c.initStreamRoutes},
 		{"integration routes", c.initIntegrationsRoutes},
 		{"control routes", c.initControlRoutes},
+		{"backup routes", c.initBackupRoutes},
 		{"auth routes", c.initAuthRoutes},
+		{"user routes", c.initUserRoutes},
 		{"media routes", c.initMediaRoutes},
 		{"range routes", c.initRangeRoutes},
 		{"sse routes", c.initSSERoutes},
@@ -432,6 +448,8 @@ func (c *Controller) initRoutes() {
 		{"support routes", c.initSupportRoutes},
 		{"debug routes", c.initDebugRoutes},
 		{"species routes", c.initSpeciesRoutes},
+		{"analyze routes", c.initAnalyzeRoutes},
+		{"adaptive rule routes", c.initAdaptiveRuleRoutes},
 	}
 
 	for _, initializer := range routeInitializers {
@@ -888,6 +906,7 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			}
 			ctx.Set("isAuthenticated", false)
 			ctx.Set("authMethod", auth.AuthMethodUnknown) // Use defined enum for 'none'
+			ctx.Set(auth.RoleContextKey, auth.RoleAdmin)
 			return next(ctx)
 		}
 
@@ -898,18 +917,26 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			}
 			ctx.Set("isAuthenticated", false)
 			ctx.Set("authMethod", auth.AuthMethodUnknown) // Use defined enum for 'none'
+			ctx.Set(auth.RoleContextKey, auth.RoleAdmin)
 			return next(ctx)
 		}
 
 		// Try token authentication first
 		authenticated, tokenErr := c.handleTokenAuth(ctx)
+		var role auth.Role
+		if authenticated {
+			// A valid token of a deleted user account has no role
+			if role = authService.GetRole(ctx); role == "" {
+				authenticated, tokenErr = false, errInvalidAuthToken
+			}
+		}
 		if authenticated {
 			// Token auth successful
 			ctx.Set("isAuthenticated", true)
-			// NOTE: Cannot reliably get username from opaque token via current AuthService.
-			// Username will be empty unless the session also exists and contains it.
-			// ctx.Set("username", authService.GetUsername(ctx)) // Removed this line
+			// Tokens issued to user accounts carry the username, tokens of the configured password have none
+			ctx.Set("username", authService.GetUsername(ctx))
 			ctx.Set("authMethod", auth.AuthMethodToken) // Store enum directly
+			ctx.Set(auth.RoleContextKey, role)
 			return next(ctx)
 		}
 
@@ -938,10 +965,13 @@ func (c *Controller) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 
 		// Token auth not attempted (no header) or failed, try session auth
 		if c.handleSessionAuth(ctx) {
-			ctx.Set("isAuthenticated", true)
-			ctx.Set("username", authService.GetUsername(ctx))
-			ctx.Set("authMethod", auth.AuthMethodBrowserSession) // Use defined enum for session
-			return next(ctx)
+			if role := authService.GetRole(ctx); role != "" {
+				ctx.Set("isAuthenticated", true)
+				ctx.Set("username", authService.GetUsername(ctx))
+				ctx.Set("authMethod", auth.AuthMethodBrowserSession) // Use defined enum for session
+				ctx.Set(auth.RoleContextKey, role)
+				return next(ctx)
+			}
 		}
 
 		// Authentication failed completely, handle unauthorized response
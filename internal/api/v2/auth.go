@@ -47,6 +47,7 @@ type AuthStatus struct {
 	Authenticated bool   `json:"authenticated"`
 	Username      string `json:"username,omitempty"`
 	Method        string `json:"auth_method,omitempty"`
+	Role          string `json:"role,omitempty"`
 }
 
 // initAuthRoutes registers all authentication-related API endpoints
@@ -326,6 +327,7 @@ func (c *Controller) GetAuthStatus(ctx echo.Context) error {
 		Authenticated: isAuthenticated,
 		Username:      username,
 		Method:        authMethod,
+		Role:          string(auth.RoleFromContext(ctx)),
 	}
 
 	if c.apiLogger != nil {
@@ -333,6 +335,7 @@ func (c *Controller) GetAuthStatus(ctx echo.Context) error {
 			"authenticated", status.Authenticated,
 			"username", status.Username,
 			"method", status.Method,
+			"role", status.Role,
 			"ip", ctx.RealIP(),
 			"path", ctx.Request().URL.Path,
 			"user_agent", ctx.Request().Header.Get("User-Agent"),
@@ -4,24 +4,34 @@ package auth
 import (
 	"crypto/sha256"
 	"crypto/subtle"
+	"errors"
 	"log/slog"
 	"reflect"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/markbates/goth/gothic"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/security"
 )
 
+// UserStore looks up the user accounts stored in the datastore
+type UserStore interface {
+	GetUser(username string) (*datastore.User, error)
+}
+
 // SecurityAdapter adapts the security package to our API auth interface
 type SecurityAdapter struct {
 	OAuth2Server *security.OAuth2Server
+	Users        UserStore // User accounts, nil when only the configured credentials are used
 	logger       *slog.Logger
 }
 
 // NewSecurityAdapter creates a new adapter for the security package
-func NewSecurityAdapter(oauth2Server *security.OAuth2Server, logger *slog.Logger) *SecurityAdapter {
+func NewSecurityAdapter(oauth2Server *security.OAuth2Server, users UserStore, logger *slog.Logger) *SecurityAdapter {
 	return &SecurityAdapter{
 		OAuth2Server: oauth2Server,
+		Users:        users,
 		logger:       logger,
 	}
 }
@@ -50,15 +60,21 @@ func (a *SecurityAdapter) GetUsername(c echo.Context) string {
 		}
 	}
 
-	// 2. Fallback: Try to get username from session (for cases where middleware might not have set it, though it should)
-	//    NOTE: Removed the redundant token validation logic that was here.
-	//    If authentication succeeded, the username should already be in the context.
-	userId, err := gothic.GetFromSession("userId", c.Request())
-	if err == nil && userId != "" {
-		if a.logger != nil {
-			a.logger.Debug("Retrieved username from session as fallback", "path", c.Request().URL.Path, "ip", c.RealIP())
+	// 2. Access tokens issued to user accounts carry the username, from the Authorization header
+	//    or the browser session
+	if username := a.tokenUsername(c); username != "" {
+		return username
+	}
+
+	// 3. Fallback: Try to get username from session (for cases where middleware might not have set it, though it should)
+	for _, key := range []string{"userId", "userEmail"} {
+		userId, err := gothic.GetFromSession(key, c.Request())
+		if err == nil && userId != "" {
+			if a.logger != nil {
+				a.logger.Debug("Retrieved username from session as fallback", "path", c.Request().URL.Path, "ip", c.RealIP())
+			}
+			return userId
 		}
-		return userId
 	}
 
 	// No username found in context or session
@@ -68,6 +84,59 @@ func (a *SecurityAdapter) GetUsername(c echo.Context) string {
 	return ""
 }
 
+// tokenUsername returns the user account of the bearer token or the access token of the session
+func (a *SecurityAdapter) tokenUsername(c echo.Context) string {
+	var token string
+	if parts := strings.SplitN(c.Request().Header.Get("Authorization"), " ", 2); len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+		token = strings.TrimSpace(parts[1])
+	} else if sessionToken, err := gothic.GetFromSession("access_token", c.Request()); err == nil {
+		token = sessionToken
+	}
+	if token == "" {
+		return ""
+	}
+	username, err := a.OAuth2Server.GetTokenUsername(token)
+	if err != nil {
+		return ""
+	}
+	return username
+}
+
+// GetRole returns the role of the authenticated user. Users with an account in the datastore have
+// the role of the account, the credentials configured in the settings have full access.
+// Returns an empty role for users without either, e.g. when the account was deleted.
+func (a *SecurityAdapter) GetRole(c echo.Context) Role {
+	username := a.GetUsername(c)
+
+	if username != "" && a.Users != nil {
+		user, err := a.Users.GetUser(username)
+		switch {
+		case err == nil:
+			role, err := ParseRole(user.Role)
+			if err != nil {
+				if a.logger != nil {
+					a.logger.Error("User account has an invalid role", "username", username, "role", user.Role)
+				}
+				return ""
+			}
+			return role
+		case !errors.Is(err, datastore.ErrUserNotFound):
+			if a.logger != nil {
+				a.logger.Error("Failed to look up user account", "username", username, "error", err.Error())
+			}
+			return ""
+		}
+	}
+
+	if a.OAuth2Server.IsConfiguredUser(username) {
+		return RoleAdmin
+	}
+	if a.logger != nil {
+		a.logger.Warn("Authenticated user has no account", "username", username, "path", c.Request().URL.Path, "ip", c.RealIP())
+	}
+	return ""
+}
+
 // GetAuthMethod returns the authentication method used as a defined constant.
 // It prioritizes context values set by the middleware if available.
 func (a *SecurityAdapter) GetAuthMethod(c echo.Context) AuthMethod {
@@ -143,10 +212,9 @@ func (a *SecurityAdapter) ValidateToken(token string) error {
 }
 
 // AuthenticateBasic handles basic authentication with username/password.
-// NOTE: This application does not support multiple user accounts or authorization levels.
-// Basic authentication relies on a single, fixed username/password combination
-// configured in settings (Security.BasicAuth.ClientID and Security.BasicAuth.Password).
-// The provided username MUST match the configured ClientID.
+// The username is checked against the user accounts in the datastore first, then against the
+// single username/password combination configured in settings (Security.BasicAuth.ClientID
+// and Security.BasicAuth.Password), which has full access.
 // Returns auth code on success, error on failure.
 func (a *SecurityAdapter) AuthenticateBasic(c echo.Context, username, password string) (string, error) {
 	// For basic auth, check against configured ClientID and Password
@@ -155,10 +223,10 @@ func (a *SecurityAdapter) AuthenticateBasic(c echo.Context, username, password s
 
 	// Log basic auth attempt
 	security.LogInfo("Basic authentication login attempt", "username", username)
-	
+
 	// Temporary debug logging to diagnose auth issues
 	if a.logger != nil {
-		a.logger.Debug("BasicAuth configuration check", 
+		a.logger.Debug("BasicAuth configuration check",
 			"provided_username", username,
 			"configured_clientid", storedClientID,
 			"clientid_match", username == storedClientID)
@@ -173,6 +241,21 @@ func (a *SecurityAdapter) AuthenticateBasic(c echo.Context, username, password s
 		return "", ErrBasicAuthDisabled // Return the specific error for disabled basic auth
 	}
 
+	// User accounts in the datastore
+	if a.Users != nil && !strings.EqualFold(username, storedClientID) {
+		user, err := a.Users.GetUser(username)
+		switch {
+		case err == nil:
+			return a.authenticateUser(user, password)
+		case !errors.Is(err, datastore.ErrUserNotFound):
+			if a.logger != nil {
+				a.logger.Error("Failed to look up user account during basic auth", "username", username, "error", err.Error())
+			}
+			security.LogError("Basic authentication failed: Internal error", "username", username, "error", "user lookup failed")
+			return "", ErrInvalidCredentials
+		}
+	}
+
 	// Hash inputs and stored values before comparison to ensure fixed length for ConstantTimeCompare.
 	usernameHash := sha256.Sum256([]byte(username))
 	passwordHash := sha256.Sum256([]byte(password))
@@ -218,6 +301,26 @@ func (a *SecurityAdapter) AuthenticateBasic(c echo.Context, username, password s
 	return "", ErrInvalidCredentials // Failure
 }
 
+// authenticateUser checks the password of a user account and returns an auth code for the account
+func (a *SecurityAdapter) authenticateUser(user *datastore.User, password string) (string, error) {
+	if !security.CheckPasswordHash(user.PasswordHash, password) {
+		security.LogWarn("Basic authentication failed: Invalid password", "username", user.Username)
+		return "", ErrInvalidCredentials
+	}
+
+	authCode, err := a.OAuth2Server.GenerateAuthCodeForUser(user.Username)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("Failed to generate auth code during basic auth", "error", err.Error())
+		}
+		security.LogError("Basic authentication failed: Internal error", "username", user.Username, "error", "auth code generation failed")
+		return "", ErrInvalidCredentials
+	}
+
+	security.LogInfo("Basic authentication successful", "username", user.Username, "role", user.Role)
+	return authCode, nil
+}
+
 // Logout invalidates the current session/token
 func (a *SecurityAdapter) Logout(c echo.Context) error {
 	// Clear all session values
@@ -225,6 +328,7 @@ func (a *SecurityAdapter) Logout(c echo.Context) error {
 	gothic.StoreInSession("access_token", "", c.Request(), c.Response()) //nolint:errcheck // Error checking not critical during logout
 	gothic.StoreInSession("google", "", c.Request(), c.Response())       //nolint:errcheck // Error checking not critical during logout
 	gothic.StoreInSession("github", "", c.Request(), c.Response())       //nolint:errcheck // Error checking not critical during logout
+	gothic.StoreInSession("userEmail", "", c.Request(), c.Response())    //nolint:errcheck // Error checking not critical during logout
 
 	// Log out from gothic session
 	return gothic.Logout(c.Response().Writer, c.Request())
@@ -2,6 +2,7 @@
 package auth
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -58,6 +59,7 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			// Set context to indicate bypass
 			c.Set("isAuthenticated", false)
 			c.Set("authMethod", AuthMethodNone)
+			c.Set(RoleContextKey, RoleAdmin)
 			return next(c)
 		}
 
@@ -72,19 +74,23 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 				token := strings.TrimSpace(parts[1]) // Trim whitespace from token
 
 				// Validate the token, check if the returned error is nil
+				// Tokens of deleted user accounts have no role and are rejected like invalid tokens
 				if err := m.AuthService.ValidateToken(token); err == nil {
-					// Token is valid
-					if m.logger != nil {
-						m.logger.Debug("Token authentication successful", "path", path, "ip", ip)
+					if role := m.AuthService.GetRole(c); role != "" {
+						// Token is valid
+						if m.logger != nil {
+							m.logger.Debug("Token authentication successful", "path", path, "ip", ip, "role", role)
+						}
+						// Set context values on successful authentication
+						c.Set("isAuthenticated", true)
+						c.Set("username", m.AuthService.GetUsername(c))
+						c.Set("authMethod", AuthMethodToken)
+						c.Set(RoleContextKey, role)
+						return next(c)
 					}
-					// Set context values on successful authentication
-					c.Set("isAuthenticated", true)
-					c.Set("username", m.AuthService.GetUsername(c))
-					c.Set("authMethod", AuthMethodToken)
-					return next(c)
 				}
 
-				// Token validation failed (err != nil)
+				// Token validation failed or the user no longer has access
 				if m.logger != nil {
 					m.logger.Warn("Token validation failed", "path", path, "ip", ip)
 				}
@@ -114,15 +120,18 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 
 		// Check session access, check if the returned error is nil
 		if err := m.AuthService.CheckAccess(c); err == nil {
-			// Session is valid
-			if m.logger != nil {
-				m.logger.Debug("Session authentication successful", "path", path, "ip", ip)
+			if role := m.AuthService.GetRole(c); role != "" {
+				// Session is valid
+				if m.logger != nil {
+					m.logger.Debug("Session authentication successful", "path", path, "ip", ip, "role", role)
+				}
+				// Set context values on successful authentication
+				c.Set("isAuthenticated", true)
+				c.Set("authMethod", m.AuthService.GetAuthMethod(c))
+				c.Set("username", m.AuthService.GetUsername(c))
+				c.Set(RoleContextKey, role)
+				return next(c)
 			}
-			// Set context values on successful authentication
-			c.Set("isAuthenticated", true)
-			c.Set("authMethod", m.AuthService.GetAuthMethod(c))
-			c.Set("username", m.AuthService.GetUsername(c))
-			return next(c)
 		}
 
 		// Authentication failed, determine appropriate response
@@ -130,6 +139,21 @@ func (m *Middleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// RequireRole returns middleware that allows requests only from users with at least the required role.
+// It must run after authentication, which stores the role of the user in the context.
+func RequireRole(required Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasRole(c, required) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": fmt.Sprintf("This action requires the %s role", required),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
 // handleUnauthenticated determines the appropriate response for unauthenticated requests
 func (m *Middleware) handleUnauthenticated(c echo.Context) error {
 	ip := c.RealIP()
@@ -0,0 +1,60 @@
+// internal/api/v2/auth/roles.go
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Role is the access level of an authenticated user
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Browses detections, statistics and settings
+	RoleReviewer Role = "reviewer" // Also verifies, comments and locks detections
+	RoleAdmin    Role = "admin"    // Also changes settings, deletes detections and manages users
+)
+
+// RoleContextKey is the context key the authentication middleware stores the role of the user in
+const RoleContextKey = "role"
+
+// roleRanks orders the roles, each role includes the permissions of the lower ranked roles
+var roleRanks = map[Role]int{
+	RoleViewer:   1,
+	RoleReviewer: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole converts a stored or requested role name to a Role
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("invalid role %q, expected viewer, reviewer or admin", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role includes the permissions of the required role
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// RoleFromContext returns the role stored by the authentication middleware, empty if there is none
+func RoleFromContext(c echo.Context) Role {
+	switch role := c.Get(RoleContextKey).(type) {
+	case Role:
+		return role
+	case string:
+		return Role(role)
+	default:
+		return ""
+	}
+}
+
+// HasRole reports whether the user of the request has at least the required role
+func HasRole(c echo.Context, required Role) bool {
+	return RoleFromContext(c).Allows(required)
+}
@@ -49,6 +49,10 @@ type Service interface {
 	// GetAuthMethod returns the authentication method used as a defined constant.
 	GetAuthMethod(c echo.Context) AuthMethod
 
+	// GetRole returns the role of the authenticated user.
+	// Returns an empty role if the user no longer has access, e.g. the account was deleted.
+	GetRole(c echo.Context) Role
+
 	// ValidateToken checks if a bearer token is valid.
 	// Returns nil on success, or ErrInvalidToken on failure.
 	ValidateToken(token string) error
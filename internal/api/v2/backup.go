@@ -0,0 +1,440 @@
+// internal/api/v2/backup.go
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/backup"
+)
+
+// RestoreBackupRequest represents a request to restore a backup
+type RestoreBackupRequest struct {
+	ID     string `json:"id"`     // Backup ID or archive file name
+	Target string `json:"target"` // Name of the backup target holding the backup
+}
+
+// RestoreBackupResponse represents the result of a restore request
+type RestoreBackupResponse struct {
+	Success         bool                  `json:"success"`
+	Message         string                `json:"message"`
+	Result          *backup.RestoreResult `json:"result,omitempty"`
+	RestartRequired bool                  `json:"restart_required"`
+	Timestamp       time.Time             `json:"timestamp"`
+}
+
+// BackupEntry describes a single backup stored on a target
+type BackupEntry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+	Type      string    `json:"type"`
+	Source    string    `json:"source"`
+	IsDaily   bool      `json:"is_daily"`
+	IsWeekly  bool      `json:"is_weekly"`
+	Encrypted bool      `json:"encrypted"`
+	Target    string    `json:"target"`
+}
+
+// BackupTargetStats summarizes the backups stored on a target
+type BackupTargetStats struct {
+	TotalBackups     int       `json:"total_backups"`
+	DailyBackups     int       `json:"daily_backups"`
+	WeeklyBackups    int       `json:"weekly_backups"`
+	TotalSize        int64     `json:"total_size"`
+	OldestBackup     time.Time `json:"oldest_backup,omitzero"`
+	NewestBackup     time.Time `json:"newest_backup,omitzero"`
+	LastBackupStatus string    `json:"last_backup_status,omitempty"`
+	LastBackupTime   time.Time `json:"last_backup_time,omitzero"`
+}
+
+// BackupTargetListing groups the backups of a single target with its statistics
+type BackupTargetListing struct {
+	Stats   BackupTargetStats `json:"stats"`
+	Backups []BackupEntry     `json:"backups"`
+}
+
+// BackupListResponse represents the response of the backup listing endpoint
+type BackupListResponse struct {
+	Targets   map[string]*BackupTargetListing `json:"targets"`
+	Errors    []string                        `json:"errors,omitempty"`
+	Timestamp time.Time                       `json:"timestamp"`
+}
+
+// BackupActionResponse represents the result of a backup run or delete request
+type BackupActionResponse struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BackupScheduleEntry describes a configured backup schedule
+type BackupScheduleEntry struct {
+	Type    string    `json:"type"` // "daily" or "weekly"
+	Hour    int       `json:"hour"`
+	Minute  int       `json:"minute"`
+	Weekday string    `json:"weekday,omitempty"`
+	NextRun time.Time `json:"next_run,omitzero"`
+	LastRun time.Time `json:"last_run,omitzero"`
+}
+
+// BackupScheduleResponse represents the state of the backup scheduler
+type BackupScheduleResponse struct {
+	SchedulerRunning bool                  `json:"scheduler_running"`
+	BackupInProgress bool                  `json:"backup_in_progress"`
+	Schedules        []BackupScheduleEntry `json:"schedules"`
+	MissedRuns       []time.Time           `json:"missed_runs"`
+	MissedBackups    []backup.MissedBackup `json:"missed_backups"`
+	Timestamp        time.Time             `json:"timestamp"`
+}
+
+// initBackupRoutes registers all backup-related API endpoints
+func (c *Controller) initBackupRoutes() {
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Initializing backup routes")
+	}
+
+	// All backup operations require an authenticated admin
+	backupGroup := c.Group.Group("/backup", c.AuthMiddleware, auth.RequireRole(auth.RoleAdmin))
+
+	backupGroup.GET("", c.ListBackups)
+	backupGroup.POST("/run", c.RunBackup)
+	backupGroup.GET("/schedule", c.GetBackupSchedule)
+	backupGroup.GET("/events", c.StreamBackupEvents)
+	backupGroup.GET("/:id/download", c.DownloadBackup)
+	backupGroup.DELETE("/:id", c.DeleteBackup)
+	backupGroup.POST("/restore", c.RestoreBackup)
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Backup routes initialized successfully")
+	}
+}
+
+// getBackupManager returns the backup manager of the running processor, or nil
+func (c *Controller) getBackupManager() *backup.Manager {
+	if c.Processor == nil {
+		return nil
+	}
+	manager, _ := c.Processor.GetBackupManager().(*backup.Manager)
+	return manager
+}
+
+// RestoreBackup handles POST /api/v2/backup/restore
+// Restores the data of a backup stored on a configured target. The SQLite database is restored
+// into the open database, a restart is still required so that cached state is reloaded.
+func (c *Controller) RestoreBackup(ctx echo.Context) error {
+	var req RestoreBackupRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid restore request", http.StatusBadRequest)
+	}
+
+	req.ID = strings.TrimSpace(req.ID)
+	req.Target = strings.TrimSpace(req.Target)
+	if req.ID == "" || req.Target == "" {
+		return c.HandleError(ctx, nil, "Both id and target are required", http.StatusBadRequest)
+	}
+
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Restoring backup", "backup_id", req.ID, "target", req.Target)
+
+	result, err := manager.Restore(ctx.Request().Context(), req.ID, req.Target)
+	if err != nil {
+		switch {
+		case backup.IsErrorCode(err, backup.ErrValidation):
+			return c.HandleError(ctx, err, "Invalid backup id or no restorer for the backup source", http.StatusBadRequest)
+		case backup.IsErrorCode(err, backup.ErrLocked):
+			return c.HandleError(ctx, err, "Another restore is already running", http.StatusConflict)
+		case backup.IsErrorCode(err, backup.ErrNotFound):
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		default:
+			return c.HandleError(ctx, err, "Failed to restore backup", http.StatusInternalServerError)
+		}
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Backup restored",
+		"backup_id", result.BackupID,
+		"target", result.Target,
+		"previous_data_path", result.PreviousDataPath)
+
+	return ctx.JSON(http.StatusOK, RestoreBackupResponse{
+		Success:         true,
+		Message:         "Backup restored, restart BirdNET-Go to load the restored database",
+		Result:          result,
+		RestartRequired: true,
+		Timestamp:       time.Now(),
+	})
+}
+
+// getBackupScheduler returns the backup scheduler of the running processor, or nil
+func (c *Controller) getBackupScheduler() *backup.Scheduler {
+	if c.Processor == nil {
+		return nil
+	}
+	scheduler, _ := c.Processor.GetBackupScheduler().(*backup.Scheduler)
+	return scheduler
+}
+
+// ListBackups handles GET /api/v2/backup
+// Lists the backups of every configured target together with per-target statistics.
+func (c *Controller) ListBackups(ctx echo.Context) error {
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	reqCtx := ctx.Request().Context()
+	response := BackupListResponse{
+		Targets:   make(map[string]*BackupTargetListing),
+		Timestamp: time.Now(),
+	}
+
+	// A failing target does not prevent listing the others
+	backups, err := manager.ListBackups(reqCtx)
+	if err != nil {
+		response.Errors = append(response.Errors, err.Error())
+	}
+
+	stats, err := manager.GetBackupStats(reqCtx)
+	if err != nil {
+		response.Errors = append(response.Errors, err.Error())
+	}
+
+	// Stats include targets without any backups
+	for name := range stats {
+		s := stats[name]
+		response.Targets[name] = &BackupTargetListing{
+			Stats: BackupTargetStats{
+				TotalBackups:     s.TotalBackups,
+				DailyBackups:     s.DailyBackups,
+				WeeklyBackups:    s.WeeklyBackups,
+				TotalSize:        s.TotalSize,
+				OldestBackup:     s.OldestBackup,
+				NewestBackup:     s.NewestBackup,
+				LastBackupStatus: s.LastBackupStatus,
+				LastBackupTime:   s.LastBackupTime,
+			},
+			Backups: []BackupEntry{},
+		}
+	}
+
+	for i := range backups {
+		b := &backups[i]
+		listing, ok := response.Targets[b.Target]
+		if !ok {
+			listing = &BackupTargetListing{Backups: []BackupEntry{}}
+			response.Targets[b.Target] = listing
+		}
+		listing.Backups = append(listing.Backups, BackupEntry{
+			ID:        b.ID,
+			Timestamp: b.Timestamp,
+			Size:      b.Size,
+			Type:      b.Type,
+			Source:    b.Source,
+			IsDaily:   b.IsDaily,
+			IsWeekly:  b.IsWeekly,
+			Encrypted: b.Encrypted,
+			Target:    b.Target,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// RunBackup handles POST /api/v2/backup/run
+// Starts a backup of all sources in the background. Progress is reported on /api/v2/backup/events.
+func (c *Controller) RunBackup(ctx echo.Context) error {
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	if manager.IsBackupRunning() {
+		return c.HandleError(ctx, nil, "A backup is already in progress", http.StatusConflict)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Starting manual backup")
+
+	// The backup must outlive the request, but not the controller
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := manager.RunBackup(c.ctx); err != nil && c.apiLogger != nil {
+			c.apiLogger.Error("Manual backup failed", "error", err.Error())
+		}
+	}()
+
+	return ctx.JSON(http.StatusAccepted, BackupActionResponse{
+		Success:   true,
+		Message:   "Backup started",
+		Timestamp: time.Now(),
+	})
+}
+
+// GetBackupSchedule handles GET /api/v2/backup/schedule
+// Returns the configured schedules, their next and last runs, and any missed runs.
+func (c *Controller) GetBackupSchedule(ctx echo.Context) error {
+	scheduler := c.getBackupScheduler()
+	if scheduler == nil {
+		return c.HandleError(ctx, nil, "Backup scheduler is not available", http.StatusServiceUnavailable)
+	}
+
+	response := BackupScheduleResponse{
+		SchedulerRunning: scheduler.IsRunning(),
+		Schedules:        []BackupScheduleEntry{},
+		MissedRuns:       scheduler.GetMissedRuns(),
+		MissedBackups:    scheduler.GetMissedBackups(),
+		Timestamp:        time.Now(),
+	}
+	if manager := c.getBackupManager(); manager != nil {
+		response.BackupInProgress = manager.IsBackupRunning()
+	}
+
+	for _, s := range scheduler.GetSchedules() {
+		entry := BackupScheduleEntry{
+			Type:    "daily",
+			Hour:    s.Hour,
+			Minute:  s.Minute,
+			NextRun: s.NextRun,
+			LastRun: s.LastRun,
+		}
+		if s.IsWeekly {
+			entry.Type = "weekly"
+			entry.Weekday = s.Weekday.String()
+		}
+		response.Schedules = append(response.Schedules, entry)
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// StreamBackupEvents handles GET /api/v2/backup/events
+// Streams progress of running backup and restore operations as Server-Sent Events.
+func (c *Controller) StreamBackupEvents(ctx echo.Context) error {
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	events, unsubscribe := manager.SubscribeProgress(0)
+	defer unsubscribe()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx.Request().Context(), maxSSEStreamDuration)
+	defer cancel()
+
+	setSSEHeaders(ctx)
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	if err := c.sendSSEMessage(ctx, "connected", map[string]string{
+		"message": "Connected to backup progress stream",
+		"type":    "backup",
+	}); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-timeoutCtx.Done():
+			return nil
+		case <-c.ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := c.sendSSEMessage(ctx, "backup_progress", event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := c.sendSSEMessage(ctx, "heartbeat", map[string]any{
+				"timestamp": time.Now().Unix(),
+				"type":      "backup",
+			}); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// DownloadBackup handles GET /api/v2/backup/:id/download?target=name
+// Sends the archive of a backup as stored on the target, encrypted archives are not decrypted.
+func (c *Controller) DownloadBackup(ctx echo.Context) error {
+	id := strings.TrimSpace(ctx.Param("id"))
+	targetName := strings.TrimSpace(ctx.QueryParam("target"))
+	if id == "" || targetName == "" {
+		return c.HandleError(ctx, nil, "Both backup id and target are required", http.StatusBadRequest)
+	}
+
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	tempDir, err := os.MkdirTemp("", "birdnet-go-download-")
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to prepare backup download", http.StatusInternalServerError)
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil && c.apiLogger != nil {
+			c.apiLogger.Warn("Failed to remove backup download directory", "path", tempDir, "error", err.Error())
+		}
+	}()
+
+	archivePath, err := manager.RetrieveBackup(ctx.Request().Context(), id, targetName, tempDir)
+	if err != nil {
+		switch {
+		case backup.IsErrorCode(err, backup.ErrValidation):
+			return c.HandleError(ctx, err, "Invalid backup id", http.StatusBadRequest)
+		case backup.IsErrorCode(err, backup.ErrNotFound):
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		default:
+			return c.HandleError(ctx, err, "Failed to retrieve backup", http.StatusInternalServerError)
+		}
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Downloading backup", "backup_id", id, "target", targetName)
+
+	return ctx.Attachment(archivePath, filepath.Base(archivePath))
+}
+
+// DeleteBackup handles DELETE /api/v2/backup/:id
+// Deletes a backup from the target that stores it.
+func (c *Controller) DeleteBackup(ctx echo.Context) error {
+	id := strings.TrimSpace(ctx.Param("id"))
+	if id == "" {
+		return c.HandleError(ctx, nil, "Backup id is required", http.StatusBadRequest)
+	}
+
+	manager := c.getBackupManager()
+	if manager == nil {
+		return c.HandleError(ctx, nil, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	if err := manager.DeleteBackup(ctx.Request().Context(), id); err != nil {
+		if backup.IsErrorCode(err, backup.ErrNotFound) {
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to delete backup", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Backup deleted", "backup_id", id)
+
+	return ctx.JSON(http.StatusOK, BackupActionResponse{
+		Success:   true,
+		Message:   "Backup deleted",
+		Timestamp: time.Now(),
+	})
+}
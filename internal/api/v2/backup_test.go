@@ -0,0 +1,232 @@
+// backup_test.go: Package api provides tests for API v2 backup endpoints.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// backupTestSource is a backup source returning fixed data
+type backupTestSource struct{}
+
+func (s *backupTestSource) Name() string { return "birdnet" }
+
+func (s *backupTestSource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("SQLite format 3\x00 backup api test")), nil
+}
+
+func (s *backupTestSource) Validate() error { return nil }
+
+// setupBackupTestController creates a controller with a backup manager storing backups in a local target
+func setupBackupTestController(t *testing.T) (*echo.Echo, *Controller, *backup.Manager) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	settings := &conf.Settings{}
+	settings.Backup.Enabled = true
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stateManager, err := backup.NewStateManager(logger)
+	require.NoError(t, err)
+	manager, err := backup.NewManager(settings, logger, stateManager, "test")
+	require.NoError(t, err)
+
+	target, err := targets.NewLocalTarget(targets.LocalTargetConfig{Path: t.TempDir()}, nil)
+	require.NoError(t, err)
+	require.NoError(t, manager.RegisterTarget(target))
+	require.NoError(t, manager.RegisterSource(&backupTestSource{}))
+
+	e := echo.New()
+	controller := getTestController(t, e)
+	ctx, cancel := context.WithCancel(context.Background())
+	controller.ctx = ctx
+	controller.cancel = cancel
+	t.Cleanup(controller.Shutdown)
+
+	controller.Processor = &processor.Processor{}
+	controller.Processor.SetBackupManager(manager)
+
+	return e, controller, manager
+}
+
+func TestBackupEndpointsWithoutManager(t *testing.T) {
+	e := echo.New()
+	controller := getTestController(t, e)
+
+	handlers := map[string]echo.HandlerFunc{
+		"list":     controller.ListBackups,
+		"run":      controller.RunBackup,
+		"schedule": controller.GetBackupSchedule,
+		"events":   controller.StreamBackupEvents,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/backup", http.NoBody)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler(e.NewContext(req, rec)))
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		})
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	e, controller, manager := setupBackupTestController(t)
+	require.NoError(t, manager.RunBackup(context.Background()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/backup", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ListBackups(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response BackupListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Contains(t, response.Targets, "local")
+	listing := response.Targets["local"]
+	require.Len(t, listing.Backups, 1)
+	assert.Equal(t, 1, listing.Stats.TotalBackups)
+	assert.Equal(t, "birdnet", listing.Backups[0].Source)
+	assert.Equal(t, "local", listing.Backups[0].Target)
+	assert.Empty(t, response.Errors)
+}
+
+func TestDownloadAndDeleteBackup(t *testing.T) {
+	e, controller, manager := setupBackupTestController(t)
+	require.NoError(t, manager.RunBackup(context.Background()))
+
+	backups, err := manager.ListBackups(context.Background())
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	id := backups[0].ID
+
+	// Download the archive
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/backup/"+id+"/download?target=local", http.NoBody)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	require.NoError(t, controller.DownloadBackup(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), id+".tar")
+	assert.NotZero(t, rec.Body.Len())
+
+	// Unknown targets are reported as not found
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/backup/"+id+"/download?target=missing", http.NoBody)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	require.NoError(t, controller.DownloadBackup(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Delete the backup
+	req = httptest.NewRequest(http.MethodDelete, "/api/v2/backup/"+id, http.NoBody)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	require.NoError(t, controller.DeleteBackup(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	backups, err = manager.ListBackups(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	// Deleting it again reports not found
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	require.NoError(t, controller.DeleteBackup(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDownloadBackupInvalidID(t *testing.T) {
+	e, controller, _ := setupBackupTestController(t)
+
+	for _, id := range []string{"..", "..backup", "a\\b"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/backup/x/download?target=local", http.NoBody)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, controller.DownloadBackup(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, id)
+	}
+}
+
+func TestRunBackup(t *testing.T) {
+	e, controller, manager := setupBackupTestController(t)
+
+	events, unsubscribe := manager.SubscribeProgress(0)
+	defer unsubscribe()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/backup/run", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.RunBackup(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	// Wait for the background backup to finish
+	for event := range events {
+		if event.Stage == backup.StageCompleted {
+			break
+		}
+		require.NotEqual(t, backup.StageFailed, event.Stage, event.Error)
+	}
+
+	backups, err := manager.ListBackups(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestStreamBackupEvents(t *testing.T) {
+	e, controller, manager := setupBackupTestController(t)
+	e.GET("/api/v2/backup/events", controller.StreamBackupEvents)
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/api/v2/backup/events", http.NoBody)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	backupStarted := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// The stream has subscribed once the connected event arrives
+		if line == "event: connected" && !backupStarted {
+			backupStarted = true
+			go func() {
+				_ = manager.RunBackup(context.Background())
+			}()
+		}
+		if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"stage":"completed"`) {
+			var event backup.ProgressEvent
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			assert.Equal(t, backup.OperationBackup, event.Operation)
+			return
+		}
+	}
+	t.Fatalf("stream ended without a completed event: %v", scanner.Err())
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 )
 
 // ControlAction represents a control action request
@@ -43,8 +44,8 @@ func (c *Controller) initControlRoutes() {
 		c.apiLogger.Info("Initializing control routes")
 	}
 
-	// Create control API group with auth middleware
-	controlGroup := c.Group.Group("/control", c.AuthMiddleware)
+	// Create control API group with auth middleware, controlling analysis requires an admin
+	controlGroup := c.Group.Group("/control", c.AuthMiddleware, auth.RequireRole(auth.RoleAdmin))
 
 	// Control routes
 	controlGroup.POST("/restart", c.RestartAnalysis)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/notification"
@@ -52,8 +53,8 @@ func (c *Controller) initDebugRoutes() {
 		return
 	}
 
-	// Debug endpoints require authentication
-	debugGroup := c.Group.Group("/debug", c.getEffectiveAuthMiddleware())
+	// Debug endpoints require an authenticated admin
+	debugGroup := c.Group.Group("/debug", c.getEffectiveAuthMiddleware(), auth.RequireRole(auth.RoleAdmin))
 	
 	debugGroup.POST("/trigger-error", c.DebugTriggerError)
 	debugGroup.POST("/trigger-notification", c.DebugTriggerNotification)
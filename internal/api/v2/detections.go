@@ -13,6 +13,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/patrickmn/go-cache"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
@@ -62,13 +63,15 @@ func (c *Controller) initDetectionRoutes() {
 	c.Group.GET("/detections/:id", c.GetDetection)
 	c.Group.GET("/detections/recent", c.GetRecentDetections)
 	c.Group.GET("/detections/:id/time-of-day", c.GetDetectionTimeOfDay)
+	c.Group.GET("/detections/:id/similar", c.GetSimilarDetections)
 
-	// Protected detection management endpoints
+	// Protected detection management endpoints, reviewers verify, comment and lock detections,
+	// deleting detections and changing the ignored species require an admin
 	detectionGroup := c.Group.Group("/detections", c.AuthMiddleware)
-	detectionGroup.DELETE("/:id", c.DeleteDetection)
-	detectionGroup.POST("/:id/review", c.ReviewDetection)
-	detectionGroup.POST("/:id/lock", c.LockDetection)
-	detectionGroup.POST("/ignore", c.IgnoreSpecies)
+	detectionGroup.DELETE("/:id", c.DeleteDetection, auth.RequireRole(auth.RoleAdmin))
+	detectionGroup.POST("/:id/review", c.ReviewDetection, auth.RequireRole(auth.RoleReviewer))
+	detectionGroup.POST("/:id/lock", c.LockDetection, auth.RequireRole(auth.RoleReviewer))
+	detectionGroup.POST("/ignore", c.IgnoreSpecies, auth.RequireRole(auth.RoleAdmin))
 }
 
 // DetectionResponse represents a detection in the API response
@@ -471,11 +474,17 @@ func (c *Controller) convertNotesToDetectionResponses(notes []datastore.Note, in
 
 // noteToDetectionResponse converts a single note to a detection response
 func (c *Controller) noteToDetectionResponse(note *datastore.Note, includeWeather bool, weatherCache map[string][]datastore.HourlyWeather) DetectionResponse {
+	// Realtime sources exist only at runtime, detections from analyzed recordings store theirs
+	source := note.Source.SafeString
+	if source == "" {
+		source = note.SourceID
+	}
+
 	detection := DetectionResponse{
 		ID:             note.ID,
 		Date:           note.Date,
 		Time:           note.Time,
-		Source:         note.Source.SafeString,
+		Source:         source,
 		BeginTime:      note.BeginTime.Format(time.RFC3339),
 		EndTime:        note.EndTime.Format(time.RFC3339),
 		SpeciesCode:    note.SpeciesCode,
@@ -977,6 +986,51 @@ func (c *Controller) GetDetection(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, detection)
 }
 
+// SimilarDetectionResponse is a detection found by similarity search
+type SimilarDetectionResponse struct {
+	DetectionResponse
+	Similarity float64 `json:"similarity"` // Cosine similarity of the detection embeddings, from -1 to 1
+}
+
+// GetSimilarDetections returns the detections most acoustically similar to a detection,
+// most similar first. Detections are compared by the embeddings saved when
+// birdnet.embeddings is enabled.
+// Query parameters:
+// - limit: number of detections to return (default: 10, max: 100)
+func (c *Controller) GetSimilarDetections(ctx echo.Context) error {
+	id := ctx.Param("id")
+	if _, err := c.DS.Get(id); err != nil {
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	}
+
+	limit := 10
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			return c.HandleError(ctx, fmt.Errorf("invalid limit %q", limitStr), "Limit must be between 1 and 100", http.StatusBadRequest)
+		}
+		limit = parsed
+	}
+
+	similar, err := c.DS.FindSimilarNotes(id, limit)
+	if err != nil {
+		if errors.Is(err, datastore.ErrEmbeddingNotFound) {
+			return c.HandleError(ctx, err, "Detection has no embedding for similarity search", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to find similar detections", http.StatusInternalServerError)
+	}
+
+	weatherCache := make(map[string][]datastore.HourlyWeather)
+	responses := make([]SimilarDetectionResponse, 0, len(similar))
+	for i := range similar {
+		responses = append(responses, SimilarDetectionResponse{
+			DetectionResponse: c.noteToDetectionResponse(&similar[i].Note, false, weatherCache),
+			Similarity:        similar[i].Similarity,
+		})
+	}
+	return ctx.JSON(http.StatusOK, responses)
+}
+
 // GetRecentDetections returns the most recent detections
 // Query parameters:
 // - limit: number of detections to return (default: 10)
@@ -1080,6 +1134,11 @@ func (c *Controller) ReviewDetection(ctx echo.Context) error {
 		return c.HandleError(ctx, err, "Invalid request format", http.StatusBadRequest)
 	}
 
+	// Adding the species to the ignored species changes settings
+	if req.Verified == "false_positive" && req.IgnoreSpecies != "" && !auth.HasRole(ctx, auth.RoleAdmin) {
+		return c.HandleError(ctx, fmt.Errorf("ignoring species requires the admin role"), "Ignoring species requires the admin role", http.StatusForbidden)
+	}
+
 	// If detection was already locked when modal opened, prevent review state change unless we're unlocking
 	if note.Locked {
 		return c.HandleError(ctx, fmt.Errorf("detection is locked"), "Detection is locked and status cannot be changed", http.StatusConflict)
@@ -1096,10 +1155,13 @@ func (c *Controller) ReviewDetection(ctx echo.Context) error {
 		return c.HandleError(ctx, fmt.Errorf("detection is locked"), "Detection is locked and status cannot be changed", http.StatusConflict)
 	}
 
+	// Comments and reviews record the authenticated user
+	author := stringFromCtx(ctx, "username", "")
+
 	// Handle comment if provided
 	if req.Comment != "" {
 		// Save comment using the datastore method for adding comments
-		err = c.AddComment(note.ID, req.Comment)
+		err = c.AddComment(note.ID, req.Comment, author)
 		if err != nil {
 			return c.HandleError(ctx, err, fmt.Sprintf("Failed to add comment: %v", err), http.StatusInternalServerError)
 		}
@@ -1118,7 +1180,7 @@ func (c *Controller) ReviewDetection(ctx echo.Context) error {
 		}
 
 		// Save review using the datastore method for reviews
-		err = c.AddReview(note.ID, verified)
+		err = c.AddReview(note.ID, verified, author)
 		if err != nil {
 			return c.HandleError(ctx, err, fmt.Sprintf("Failed to update verification: %v", err), http.StatusInternalServerError)
 		}
@@ -1272,8 +1334,8 @@ func (c *Controller) addSpeciesToIgnoredList(species string) error {
 	return nil
 }
 
-// AddComment creates a comment for a note
-func (c *Controller) AddComment(noteID uint, commentText string) error {
+// AddComment creates a comment for a note by the author, empty when authentication is not used
+func (c *Controller) AddComment(noteID uint, commentText, author string) error {
 	if commentText == "" {
 		return nil // No comment to add
 	}
@@ -1281,6 +1343,7 @@ func (c *Controller) AddComment(noteID uint, commentText string) error {
 	comment := &datastore.NoteComment{
 		NoteID:    noteID,
 		Entry:     commentText,
+		Author:    author,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -1288,8 +1351,8 @@ func (c *Controller) AddComment(noteID uint, commentText string) error {
 	return c.DS.SaveNoteComment(comment)
 }
 
-// AddReview creates or updates a review for a note
-func (c *Controller) AddReview(noteID uint, verified bool) error {
+// AddReview creates or updates a review for a note by the author, empty when authentication is not used
+func (c *Controller) AddReview(noteID uint, verified bool, author string) error {
 	// Convert bool to string value
 	verifiedStr := map[bool]string{
 		true:  "correct",
@@ -1299,6 +1362,7 @@ func (c *Controller) AddReview(noteID uint, verified bool) error {
 	review := &datastore.NoteReview{
 		NoteID:    noteID,
 		Verified:  verifiedStr,
+		Author:    author,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -427,6 +427,88 @@ func TestGetDetection(t *testing.T) {
 	}
 }
 
+// TestGetSimilarDetections tests the GetSimilarDetections endpoint
+func TestGetSimilarDetections(t *testing.T) {
+	// Setup
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	queryNote := datastore.Note{ID: 1, Date: "2025-03-07", Time: "08:15:00", Source: testRealtimeSource(),
+		ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.9}
+	similarNote := datastore.Note{ID: 7, Date: "2025-03-09", Time: "22:40:00", Source: testRealtimeSource(),
+		ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.8, Verified: "correct"}
+
+	testCases := []struct {
+		name           string
+		query          string
+		mockSetup      func(*mock.Mock)
+		expectedStatus int
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Similar detections",
+			query: "?limit=5",
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "1").Return(queryNote, nil)
+				m.On("FindSimilarNotes", "1", 5).Return([]datastore.SimilarNote{{Note: similarNote, Similarity: 0.93}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				t.Helper()
+				var response []SimilarDetectionResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				require.Len(t, response, 1)
+				assert.Equal(t, uint(7), response[0].ID)
+				assert.Equal(t, "correct", response[0].Verified)
+				assert.InDelta(t, 0.93, response[0].Similarity, 1e-9)
+			},
+		},
+		{
+			name: "Detection without embedding",
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "1").Return(queryNote, nil)
+				m.On("FindSimilarNotes", "1", 10).Return(nil, datastore.ErrEmbeddingNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:  "Invalid limit",
+			query: "?limit=1000",
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "1").Return(queryNote, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Detection not found",
+			mockSetup: func(m *mock.Mock) {
+				m.On("Get", "1").Return(datastore.Note{}, errors.New("record not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDS.ExpectedCalls = nil
+			tc.mockSetup(&mockDS.Mock)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/1/similar"+tc.query, http.NoBody)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			_ = controller.GetSimilarDetections(c)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.checkResponse != nil {
+				tc.checkResponse(t, rec)
+			}
+			mockDS.AssertExpectations(t)
+		})
+	}
+}
+
 // TestGetRecentDetections tests the GetRecentDetections endpoint
 func TestGetRecentDetections(t *testing.T) {
 	// Setup
@@ -953,7 +1035,7 @@ func TestAddCommentMethod(t *testing.T) {
 			tc.mockSetup(&mockDS.Mock)
 
 			// Call method directly
-			err := controller.AddComment(tc.noteID, tc.commentText)
+			err := controller.AddComment(tc.noteID, tc.commentText, "")
 
 			// Check result
 			if tc.expectError {
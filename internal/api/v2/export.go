@@ -0,0 +1,289 @@
+// internal/api/v2/export.go
+package api
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+// exportBatchSize is the number of detections read from the datastore per query
+const exportBatchSize = 1000
+
+// exportRequest holds the validated parameters of an export
+type exportRequest struct {
+	format        observation.ExportFormat
+	start         string // YYYY-MM-DD, empty for no lower bound
+	end           string // YYYY-MM-DD, empty for no upper bound
+	species       []string
+	minConfidence float64
+	includeClips  bool
+}
+
+// initExportRoutes registers the detection export endpoint
+func (c *Controller) initExportRoutes() {
+	// Exports read the whole detection history and optionally the clips, require authentication
+	c.Group.GET("/export", c.ExportDetections, c.getEffectiveAuthMiddleware())
+}
+
+// ExportDetections handles GET /api/v2/export
+// Streams detections as CSV, JSON Lines, a Darwin Core Archive or a Raven Pro selection table.
+// Query parameters:
+//   - format: csv (default), jsonl, dwca or raven
+//   - start, end: date range in YYYY-MM-DD, both optional and inclusive
+//   - species: scientific names or species codes, comma separated or repeated
+//   - min_confidence: lowest confidence from 0 to 1
+//   - clips: true to bundle the audio clips into a zip archive with the data file
+func (c *Controller) ExportDetections(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, nil, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	req, err := parseExportRequest(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	if req.includeClips && c.SFS == nil {
+		return c.HandleError(ctx, nil, "Audio clips are not available", http.StatusServiceUnavailable)
+	}
+
+	filters := req.filters()
+	notes, _, err := c.DS.SearchNotesAdvanced(filters)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to query detections", http.StatusInternalServerError)
+	}
+
+	archive := req.format == observation.ExportDarwinCore || req.includeClips
+	resp := ctx.Response()
+	if archive {
+		resp.Header().Set(echo.HeaderContentType, "application/zip")
+	} else {
+		resp.Header().Set(echo.HeaderContentType, req.format.ContentType())
+	}
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", req.fileName(archive)))
+	resp.WriteHeader(http.StatusOK)
+
+	// The status is sent, failures from here on can only end the stream early
+	if err := c.streamExport(ctx, req, filters, notes, archive); err != nil {
+		c.logAPIRequest(ctx, slog.LevelWarn, "Detection export aborted", "error", err.Error(), "format", string(req.format))
+	}
+	return nil
+}
+
+// streamExport writes the detections of all pages, and the clips and archive metadata
+// when the export is an archive
+func (c *Controller) streamExport(ctx echo.Context, req *exportRequest, filters *datastore.AdvancedSearchFilters, notes []datastore.Note, archive bool) error {
+	var zw *zip.Writer
+	var data io.Writer = ctx.Response()
+	if archive {
+		zw = zip.NewWriter(ctx.Response())
+		var err error
+		if data, err = zw.Create(req.format.FileName()); err != nil {
+			return err
+		}
+	}
+
+	ew, err := observation.NewExportWriter(req.format, data)
+	if err != nil {
+		return err
+	}
+
+	var clips []string
+	for {
+		for i := range notes {
+			clipPath := ""
+			if req.includeClips {
+				if clip := c.exportableClip(&notes[i]); clip != "" {
+					clips = append(clips, clip)
+					clipPath = path.Join("clips", filepath.ToSlash(clip))
+				}
+			}
+			if err := ew.WriteNote(&notes[i], clipPath); err != nil {
+				return err
+			}
+		}
+		if len(notes) < filters.Limit {
+			break
+		}
+		if err := ctx.Request().Context().Err(); err != nil {
+			return err
+		}
+
+		filters.AfterID = notes[len(notes)-1].ID
+		if notes, _, err = c.DS.SearchNotesAdvanced(filters); err != nil {
+			return err
+		}
+	}
+	if err := ew.Flush(); err != nil {
+		return err
+	}
+	if zw == nil {
+		return nil
+	}
+
+	for _, clip := range clips {
+		if err := c.addClipToArchive(zw, clip); err != nil {
+			return err
+		}
+	}
+	if req.format == observation.ExportDarwinCore {
+		meta, err := zw.Create("meta.xml")
+		if err != nil {
+			return err
+		}
+		if err := observation.WriteDarwinCoreMeta(meta); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// exportableClip returns the validated clip path of a detection relative to the clip
+// directory, empty when the detection has no clip on disk
+func (c *Controller) exportableClip(note *datastore.Note) string {
+	if note.ClipName == "" {
+		return ""
+	}
+	clip, err := c.SFS.ValidateRelativePath(note.ClipName)
+	if err != nil {
+		return ""
+	}
+	if info, err := c.SFS.StatRel(clip); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return clip
+}
+
+// addClipToArchive copies a clip into the clips directory of the archive. Audio is already
+// compressed, the clip is stored without deflating it again.
+func (c *Controller) addClipToArchive(zw *zip.Writer, clip string) error {
+	f, err := c.SFS.Open(filepath.Join(c.SFS.BaseDir(), clip))
+	if err != nil {
+		// The clip may have been removed by retention since the data file was written
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	header := &zip.FileHeader{
+		Name:     path.Join("clips", filepath.ToSlash(clip)),
+		Method:   zip.Store,
+		Modified: info.ModTime(),
+	}
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// parseExportRequest validates the export query parameters
+func parseExportRequest(ctx echo.Context) (*exportRequest, error) {
+	req := &exportRequest{format: observation.ExportCSV}
+
+	if format := ctx.QueryParam("format"); format != "" {
+		var err error
+		if req.format, err = observation.ParseExportFormat(format); err != nil {
+			return nil, err
+		}
+	}
+
+	req.start = ctx.QueryParam("start")
+	req.end = ctx.QueryParam("end")
+	if err := validateDateParam(req.start, "start"); err != nil {
+		return nil, err
+	}
+	if err := validateDateParam(req.end, "end"); err != nil {
+		return nil, err
+	}
+	if req.start != "" && req.end != "" && req.end < req.start {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	for _, value := range ctx.QueryParams()["species"] {
+		for _, species := range strings.Split(value, ",") {
+			if species = strings.TrimSpace(species); species != "" {
+				req.species = append(req.species, species)
+			}
+		}
+	}
+
+	if value := ctx.QueryParam("min_confidence"); value != "" {
+		confidence, err := strconv.ParseFloat(value, 64)
+		if err != nil || confidence < 0 || confidence > 1 {
+			return nil, fmt.Errorf("min_confidence must be a number from 0 to 1")
+		}
+		req.minConfidence = confidence
+	}
+
+	if value := ctx.QueryParam("clips"); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("clips must be true or false")
+		}
+		req.includeClips = include
+	}
+
+	return req, nil
+}
+
+// filters returns the datastore filters of the first page of the export, oldest first.
+// Pages are read by ID without counting the total, streamExport continues after the last ID.
+func (req *exportRequest) filters() *datastore.AdvancedSearchFilters {
+	filters := &datastore.AdvancedSearchFilters{
+		Species:       req.species,
+		SortAscending: true,
+		Limit:         exportBatchSize,
+		SkipCount:     true,
+	}
+	if req.minConfidence > 0 {
+		filters.Confidence = &datastore.ConfidenceFilter{Operator: ">=", Value: req.minConfidence}
+	}
+	if req.start != "" || req.end != "" {
+		// Dates are validated, the zero time and today stand in for open ends
+		dateRange := &datastore.DateRange{End: time.Now()}
+		if req.start != "" {
+			dateRange.Start, _ = time.Parse(time.DateOnly, req.start)
+		}
+		if req.end != "" {
+			dateRange.End, _ = time.Parse(time.DateOnly, req.end)
+		}
+		filters.DateRange = dateRange
+	}
+	return filters
+}
+
+// fileName returns the download file name of the export
+func (req *exportRequest) fileName(archive bool) string {
+	start, end := req.start, req.end
+	if start == "" {
+		start = "begin"
+	}
+	if end == "" {
+		end = time.Now().Format(time.DateOnly)
+	}
+
+	ext := path.Ext(req.format.FileName())
+	if req.format == observation.ExportRaven {
+		ext = ".txt"
+	}
+	if archive {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("birdnet-go-%s_%s_%s%s", req.format, start, end, ext)
+}
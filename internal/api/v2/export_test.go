@@ -0,0 +1,232 @@
+// export_test.go: Package api provides tests for API v2 detection export endpoint.
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+// exportTestNotes returns detections as SearchNotesAdvanced populates them
+func exportTestNotes() []datastore.Note {
+	begin := time.Date(2024, 5, 1, 6, 30, 0, 0, time.Local)
+	return []datastore.Note{
+		{
+			ID: 1, SourceNode: "garden", Date: "2024-05-01", Time: "06:30:00",
+			BeginTime: begin, EndTime: begin.Add(3 * time.Second),
+			ScientificName: "Troglodytes troglodytes", CommonName: "Eurasian Wren", SpeciesCode: "winwre4",
+			Confidence: 0.91, Latitude: 60.17, Longitude: 24.94, ClipName: "2024/05/wren.wav", Verified: "correct",
+		},
+		{
+			ID: 2, SourceNode: "garden", Date: "2024-05-01", Time: "07:15:00",
+			ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird, common", SpeciesCode: "eurbla",
+			Confidence: 0.75, Latitude: 60.17, Longitude: 24.94, Verified: "false_positive",
+		},
+	}
+}
+
+// runExport calls the export handler and returns the response
+func runExport(t *testing.T, controller *Controller, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := controller.Echo
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/export?"+query, http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.ExportDetections(e.NewContext(req, rec)))
+	return rec
+}
+
+// readZip returns the files of a zip archive by name
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestExportDetectionsCSV(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+
+	mockDS.On("SearchNotesAdvanced", mock.MatchedBy(func(f *datastore.AdvancedSearchFilters) bool {
+		return f.SortAscending &&
+			f.Limit == exportBatchSize &&
+			f.SkipCount &&
+			f.DateRange != nil && f.DateRange.Start.Format(time.DateOnly) == "2024-05-01" &&
+			f.DateRange.End.Format(time.DateOnly) == "2024-05-31" &&
+			f.Confidence != nil && f.Confidence.Operator == ">=" && f.Confidence.Value == 0.7 &&
+			assert.ObjectsAreEqual([]string{"Troglodytes troglodytes", "eurbla", "Turdus merula"}, f.Species)
+	})).Return(exportTestNotes(), int64(2), nil)
+
+	rec := runExport(t, controller,
+		"start=2024-05-01&end=2024-05-31&min_confidence=0.7&species=Troglodytes%20troglodytes,eurbla&species=Turdus%20merula")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "birdnet-go-csv_2024-05-01_2024-05-31.csv")
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "scientific_name", rows[0][5])
+	assert.Equal(t, "Troglodytes troglodytes", rows[1][5])
+	assert.Equal(t, "Eurasian Blackbird, common", rows[2][6], "commas must be quoted")
+	assert.Equal(t, "false_positive", rows[2][13])
+
+	mockDS.AssertExpectations(t)
+}
+
+func TestExportDetectionsPaging(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+
+	page := make([]datastore.Note, exportBatchSize)
+	for i := range page {
+		page[i] = datastore.Note{ID: uint(i + 1), Date: "2024-05-01", Time: "06:00:00", ScientificName: "Turdus merula"}
+	}
+	mockDS.On("SearchNotesAdvanced", mock.MatchedBy(func(f *datastore.AdvancedSearchFilters) bool {
+		return f.AfterID == 0
+	})).Return(page, int64(0), nil).Once()
+	mockDS.On("SearchNotesAdvanced", mock.MatchedBy(func(f *datastore.AdvancedSearchFilters) bool {
+		return f.AfterID == exportBatchSize && f.Offset == 0
+	})).Return(exportTestNotes()[:1], int64(0), nil).Once()
+
+	rec := runExport(t, controller, "format=jsonl")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, exportBatchSize+1)
+
+	var last observation.ExportRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+	assert.Equal(t, "Eurasian Wren", last.CommonName)
+	assert.Equal(t, "correct", last.Verified)
+
+	mockDS.AssertExpectations(t)
+}
+
+func TestExportDetectionsDarwinCore(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("SearchNotesAdvanced", mock.Anything).Return(exportTestNotes(), int64(2), nil)
+
+	rec := runExport(t, controller, "format=dwca")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	files := readZip(t, rec.Body.Bytes())
+	require.Contains(t, files, "occurrence.txt")
+	require.Contains(t, files, "meta.xml")
+
+	var meta struct {
+		Core struct {
+			RowType  string `xml:"rowType,attr"`
+			Location string `xml:"files>location"`
+			Fields   []struct {
+				Index int    `xml:"index,attr"`
+				Term  string `xml:"term,attr"`
+			} `xml:"field"`
+		} `xml:"core"`
+	}
+	require.NoError(t, xml.Unmarshal(files["meta.xml"], &meta))
+	assert.Equal(t, "http://rs.tdwg.org/dwc/terms/Occurrence", meta.Core.RowType)
+	assert.Equal(t, "occurrence.txt", meta.Core.Location)
+
+	lines := strings.Split(strings.TrimSpace(string(files["occurrence.txt"])), "\n")
+	require.Len(t, lines, 3)
+	header := strings.Split(lines[0], "\t")
+	require.Len(t, meta.Core.Fields, len(header), "meta.xml must describe every column")
+
+	wren := strings.Split(lines[1], "\t")
+	column := func(row []string, name string) string {
+		for i, h := range header {
+			if h == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("column %s not found", name)
+		return ""
+	}
+	assert.Equal(t, "urn:birdnet-go:garden:1", column(wren, "occurrenceID"))
+	assert.Equal(t, "MachineObservation", column(wren, "basisOfRecord"))
+	assert.Equal(t, "verified", column(wren, "identificationVerificationStatus"))
+	assert.Contains(t, column(wren, "eventDate"), "/", "event date should be an interval")
+	assert.Equal(t, "rejected", column(strings.Split(lines[2], "\t"), "identificationVerificationStatus"))
+	assert.Empty(t, column(wren, "associatedMedia"), "clips are not bundled")
+}
+
+func TestExportDetectionsRavenWithClips(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("SearchNotesAdvanced", mock.Anything).Return(exportTestNotes(), int64(2), nil)
+
+	clipDir := filepath.Join(controller.Settings.Realtime.Audio.Export.Path, "2024", "05")
+	require.NoError(t, os.MkdirAll(clipDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(clipDir, "wren.wav"), []byte("RIFF audio"), 0o600))
+
+	rec := runExport(t, controller, "format=raven&clips=true")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".zip")
+
+	files := readZip(t, rec.Body.Bytes())
+	assert.Equal(t, []byte("RIFF audio"), files["clips/2024/05/wren.wav"])
+
+	lines := strings.Split(strings.TrimSpace(string(files["detections.selections.txt"])), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "Selection\tView\tChannel\tBegin File\tBegin Time (s)"))
+	wren := strings.Split(lines[1], "\t")
+	assert.Equal(t, "clips/2024/05/wren.wav", wren[3])
+	assert.Equal(t, "23400.000", wren[4], "begin time is seconds since midnight")
+	assert.Equal(t, "23403.000", wren[5])
+	blackbird := strings.Split(lines[2], "\t")
+	assert.Empty(t, blackbird[3], "detections without a clip have no file")
+	assert.Equal(t, "26100.000", blackbird[4], "begin time falls back to the date and time columns")
+}
+
+func TestExportDetectionsValidation(t *testing.T) {
+	t.Parallel()
+	_, _, controller := setupTestEnvironment(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown format", "format=xlsx"},
+		{"invalid start", "start=05/01/2024"},
+		{"end before start", "start=2024-05-02&end=2024-05-01"},
+		{"confidence out of range", "min_confidence=70"},
+		{"invalid clips flag", "clips=maybe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := runExport(t, controller, tt.query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
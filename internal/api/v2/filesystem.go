@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 )
 
 // FileSystemItem represents a file or directory for the frontend file browser
@@ -39,8 +40,8 @@ func (c *Controller) initFileSystemRoutes() {
 		c.apiLogger.Info("Initializing filesystem routes")
 	}
 
-	// Create filesystem API group with authentication
-	fsGroup := c.Group.Group("/filesystem", c.getEffectiveAuthMiddleware())
+	// Create filesystem API group with authentication, browsing is used to pick paths for settings
+	fsGroup := c.Group.Group("/filesystem", c.getEffectiveAuthMiddleware(), auth.RequireRole(auth.RoleAdmin))
 
 	// GET /api/v2/filesystem/browse - Browse files and directories
 	fsGroup.GET("/browse", c.BrowseFileSystem)
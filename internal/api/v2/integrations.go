@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/birdweather"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/mqtt"
@@ -48,8 +49,8 @@ func (c *Controller) initIntegrationsRoutes() {
 		c.apiLogger.Info("Initializing integrations routes")
 	}
 
-	// Create integrations API group with auth middleware
-	integrationsGroup := c.Group.Group("/integrations", c.AuthMiddleware)
+	// Create integrations API group with auth middleware, integrations use the credentials in settings
+	integrationsGroup := c.Group.Group("/integrations", c.AuthMiddleware, auth.RequireRole(auth.RoleAdmin))
 
 	// MQTT routes
 	mqttGroup := integrationsGroup.Group("/mqtt")
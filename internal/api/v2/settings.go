@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
@@ -47,9 +49,9 @@ func (c *Controller) initSettingsRoutes() {
 	// GET /api/v2/settings/:section - Retrieves settings for a specific section (e.g., birdnet, webserver)
 	settingsGroup.GET("/:section", c.GetSectionSettings)
 	// PUT /api/v2/settings - Updates multiple settings sections with complete replacement
-	settingsGroup.PUT("", c.UpdateSettings)
+	settingsGroup.PUT("", c.UpdateSettings, auth.RequireRole(auth.RoleAdmin))
 	// PATCH /api/v2/settings/:section - Updates a specific settings section with partial replacement
-	settingsGroup.PATCH("/:section", c.UpdateSectionSettings)
+	settingsGroup.PATCH("/:section", c.UpdateSectionSettings, auth.RequireRole(auth.RoleAdmin))
 
 	if c.apiLogger != nil {
 		c.apiLogger.Info("Settings routes initialized successfully")
@@ -696,6 +698,8 @@ func getSettingsSectionValue(settings *conf.Settings, section string) (any, erro
 		return &settings.Realtime.Weather, nil
 	case "mqtt":
 		return &settings.Realtime.MQTT, nil
+	case "webhooks":
+		return &settings.Realtime.Webhooks, nil
 	case "birdweather":
 		return &settings.Realtime.Birdweather, nil
 	case "species":
@@ -1162,6 +1166,8 @@ func getSettingsSection(settings *conf.Settings, section string) (any, error) {
 		return settings.Realtime.Weather, nil
 	case "mqtt":
 		return settings.Realtime.MQTT, nil
+	case "webhooks":
+		return settings.Realtime.Webhooks, nil
 	case "birdweather":
 		return settings.Realtime.Birdweather, nil
 	case "species":
@@ -1329,6 +1335,13 @@ func (c *Controller) handleSettingsChanges(oldSettings, currentSettings *conf.Se
 		_ = c.SendToast("Reconfiguring telemetry settings...", "info", 3000)
 	}
 
+	// Apply adaptive rule overrides edited in the settings
+	if !reflect.DeepEqual(oldSettings.Realtime.AdaptiveRules.Overrides, currentSettings.Realtime.AdaptiveRules.Overrides) {
+		if rules := c.adaptiveRules(); rules != nil {
+			rules.SetOverrides(currentSettings.Realtime.AdaptiveRules.Overrides)
+		}
+	}
+
 	// Handle audio settings changes
 	audioActions, err := c.handleAudioSettingsChanges(oldSettings, currentSettings)
 	if err != nil {
@@ -1363,6 +1376,11 @@ func birdnetSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		return true
 	}
 
+	// Check for changes in BirdNET interpreter pool size
+	if oldSettings.BirdNET.Interpreters != currentSettings.BirdNET.Interpreters {
+		return true
+	}
+
 	// Check for changes in BirdNET model path
 	if oldSettings.BirdNET.ModelPath != currentSettings.BirdNET.ModelPath {
 		return true
@@ -1419,7 +1437,12 @@ func mqttSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		oldMQTT.TLS.InsecureSkipVerify != newMQTT.TLS.InsecureSkipVerify ||
 		oldMQTT.TLS.CACert != newMQTT.TLS.CACert ||
 		oldMQTT.TLS.ClientCert != newMQTT.TLS.ClientCert ||
-		oldMQTT.TLS.ClientKey != newMQTT.TLS.ClientKey
+		oldMQTT.TLS.ClientKey != newMQTT.TLS.ClientKey ||
+		oldMQTT.Commands.Enabled != newMQTT.Commands.Enabled ||
+		oldMQTT.Commands.Topic != newMQTT.Commands.Topic ||
+		oldMQTT.Commands.StatusTopic != newMQTT.Commands.StatusTopic ||
+		oldMQTT.Commands.Token != newMQTT.Commands.Token ||
+		!slices.Equal(oldMQTT.Commands.AllowedCommands, newMQTT.Commands.AllowedCommands)
 }
 
 // rtspSettingsChanged checks if RTSP settings have changed
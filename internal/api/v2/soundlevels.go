@@ -0,0 +1,209 @@
+// internal/api/v2/soundlevels.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	defaultSoundLevelRange = 24 * time.Hour       // Range returned when no start is given
+	maxSoundLevelRange     = 366 * 24 * time.Hour // Longest range served by a single request
+	maxSoundLevelPoints    = 500                  // Target number of points per source when downsampling automatically
+)
+
+// soundLevelResolutions are the bucket sizes used when choosing a resolution automatically
+var soundLevelResolutions = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// SoundLevelPoint represents the levels of a source over one interval
+type SoundLevelPoint struct {
+	Timestamp time.Time                 `json:"timestamp"`
+	Interval  int                       `json:"interval_seconds"`
+	Bands     datastore.SoundLevelBands `json:"octave_bands"`
+}
+
+// SoundLevelSeries represents the sound level history of a single source
+type SoundLevelSeries struct {
+	Source string            `json:"source"`
+	Name   string            `json:"name"`
+	Points []SoundLevelPoint `json:"points"`
+}
+
+// SoundLevelHistoryResponse represents the response of the sound level history endpoint
+type SoundLevelHistoryResponse struct {
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Resolution int                `json:"resolution_seconds"` // 0 when measurements are returned as stored
+	Series     []SoundLevelSeries `json:"series"`
+}
+
+// initSoundLevelRoutes registers the sound level history endpoints
+func (c *Controller) initSoundLevelRoutes() {
+	soundLevelGroup := c.Group.Group("/soundlevels")
+
+	soundLevelGroup.GET("/sources", c.GetSoundLevelSources)
+	soundLevelGroup.GET("/history", c.GetSoundLevelHistory)
+}
+
+// GetSoundLevelSources handles GET /api/v2/soundlevels/sources
+// Lists the audio sources with stored sound level history.
+func (c *Controller) GetSoundLevelSources(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, nil, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	sources, err := c.DS.GetSoundLevelSources()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get sound level sources", http.StatusInternalServerError)
+	}
+	if sources == nil {
+		sources = []datastore.SoundLevelSource{}
+	}
+
+	return ctx.JSON(http.StatusOK, sources)
+}
+
+// GetSoundLevelHistory handles GET /api/v2/soundlevels/history
+// Query parameters:
+//   - source: audio source ID, all sources when empty
+//   - start, end: RFC3339 timestamps or YYYY-MM-DD dates, defaults to the last 24 hours
+//   - resolution: bucket size such as "5m" or seconds, "raw" for stored measurements,
+//     chosen automatically when empty
+func (c *Controller) GetSoundLevelHistory(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, nil, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	end := time.Now().UTC()
+	if value := ctx.QueryParam("end"); value != "" {
+		parsed, err := parseSoundLevelTime(value, true)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid end time", http.StatusBadRequest)
+		}
+		end = parsed
+	}
+
+	start := end.Add(-defaultSoundLevelRange)
+	if value := ctx.QueryParam("start"); value != "" {
+		parsed, err := parseSoundLevelTime(value, false)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid start time", http.StatusBadRequest)
+		}
+		start = parsed
+	}
+
+	if !end.After(start) {
+		return c.HandleError(ctx, nil, "End time must be after start time", http.StatusBadRequest)
+	}
+	if end.Sub(start) > maxSoundLevelRange {
+		return c.HandleError(ctx, nil, fmt.Sprintf("Time range must not exceed %d days", int(maxSoundLevelRange.Hours()/24)), http.StatusBadRequest)
+	}
+
+	resolution, err := parseSoundLevelResolution(ctx.QueryParam("resolution"), end.Sub(start))
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid resolution", http.StatusBadRequest)
+	}
+
+	levels, err := c.DS.GetSoundLevels(&datastore.SoundLevelQuery{
+		Source:     ctx.QueryParam("source"),
+		Start:      start,
+		End:        end,
+		Resolution: resolution,
+	})
+	if errors.Is(err, datastore.ErrTooManySoundLevels) {
+		return c.HandleError(ctx, err, "Too many measurements in range, use a shorter range or coarser resolution", http.StatusBadRequest)
+	}
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get sound level history", http.StatusInternalServerError)
+	}
+
+	response := SoundLevelHistoryResponse{
+		Start:      start,
+		End:        end,
+		Resolution: int(resolution / time.Second),
+		Series:     []SoundLevelSeries{},
+	}
+
+	// Levels are ordered by source, start a new series whenever the source changes
+	for i := range levels {
+		if n := len(response.Series); n == 0 || response.Series[n-1].Source != levels[i].Source {
+			response.Series = append(response.Series, SoundLevelSeries{
+				Source: levels[i].Source,
+				Name:   levels[i].Name,
+				Points: []SoundLevelPoint{},
+			})
+		}
+		series := &response.Series[len(response.Series)-1]
+		series.Points = append(series.Points, SoundLevelPoint{
+			Timestamp: levels[i].Timestamp,
+			Interval:  levels[i].Interval,
+			Bands:     levels[i].Bands,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// parseSoundLevelTime parses an RFC3339 timestamp or a YYYY-MM-DD date. Dates used as
+// the end of a range include the whole day.
+func parseSoundLevelTime(value string, isEnd bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 timestamp or YYYY-MM-DD date, got %q", value)
+	}
+	if isEnd {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date.UTC(), nil
+}
+
+// parseSoundLevelResolution parses the resolution parameter. Without a value the smallest
+// resolution keeping the range within maxSoundLevelPoints is used.
+func parseSoundLevelResolution(value string, span time.Duration) (time.Duration, error) {
+	switch strings.ToLower(value) {
+	case "":
+		for _, resolution := range soundLevelResolutions {
+			if span/resolution <= maxSoundLevelPoints {
+				return resolution, nil
+			}
+		}
+		return soundLevelResolutions[len(soundLevelResolutions)-1], nil
+	case "raw":
+		return 0, nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, fmt.Errorf("resolution must be positive, got %d", seconds)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	resolution, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration such as 5m or a number of seconds, got %q", value)
+	}
+	if resolution < time.Second {
+		return 0, fmt.Errorf("resolution must be at least one second, got %s", resolution)
+	}
+	return resolution, nil
+}
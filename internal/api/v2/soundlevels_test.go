@@ -0,0 +1,135 @@
+// soundlevels_test.go: Package api provides tests for API v2 sound level history endpoints.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestGetSoundLevelHistory(t *testing.T) {
+	t.Parallel()
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	bands := datastore.SoundLevelBands{"1.0_kHz": {CenterFreq: 1000, Leq: -40, Min: -50, Max: -30}}
+	levels := []datastore.SoundLevel{
+		{Source: "mic", Name: "Garden", Timestamp: start, Interval: 300, Bands: bands},
+		{Source: "mic", Name: "Garden", Timestamp: start.Add(5 * time.Minute), Interval: 300, Bands: bands},
+		{Source: "rtsp_1", Name: "Pond", Timestamp: start, Interval: 300, Bands: bands},
+	}
+
+	mockDS.On("GetSoundLevels", mock.MatchedBy(func(q *datastore.SoundLevelQuery) bool {
+		return q.Source == "" &&
+			q.Start.Equal(start) &&
+			q.End.Equal(start.Add(time.Hour)) &&
+			q.Resolution == 5*time.Minute
+	})).Return(levels, nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v2/soundlevels/history?start=2024-05-01T00:00:00Z&end=2024-05-01T01:00:00Z&resolution=5m", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSoundLevelHistory(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response SoundLevelHistoryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 300, response.Resolution)
+	require.Len(t, response.Series, 2)
+	assert.Equal(t, "mic", response.Series[0].Source)
+	assert.Equal(t, "Garden", response.Series[0].Name)
+	assert.Len(t, response.Series[0].Points, 2)
+	assert.Equal(t, "rtsp_1", response.Series[1].Source)
+	assert.InDelta(t, -40, response.Series[1].Points[0].Bands["1.0_kHz"].Leq, 0.001)
+
+	mockDS.AssertExpectations(t)
+}
+
+func TestGetSoundLevelHistoryValidation(t *testing.T) {
+	t.Parallel()
+	e, _, controller := setupTestEnvironment(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid start", "start=yesterday"},
+		{"end before start", "start=2024-05-02&end=2024-05-01"},
+		{"range too long", "start=2020-01-01&end=2024-01-01"},
+		{"invalid resolution", "start=2024-05-01&end=2024-05-02&resolution=often"},
+		{"negative resolution", "start=2024-05-01&end=2024-05-02&resolution=-60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/history?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+			require.NoError(t, controller.GetSoundLevelHistory(e.NewContext(req, rec)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestGetSoundLevelHistoryTooManyMeasurements(t *testing.T) {
+	t.Parallel()
+	e, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("GetSoundLevels", mock.Anything).Return(nil, datastore.ErrTooManySoundLevels)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/history?start=2024-01-01&end=2024-12-31&resolution=raw", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSoundLevelHistory(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "the history is rejected rather than truncated")
+}
+
+func TestGetSoundLevelSources(t *testing.T) {
+	t.Parallel()
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mockDS.On("GetSoundLevelSources").Return([]datastore.SoundLevelSource{
+		{Source: "mic", Name: "Garden", First: now.Add(-time.Hour), Last: now, Count: 12},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/sources", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSoundLevelSources(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var sources []datastore.SoundLevelSource
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sources))
+	require.Len(t, sources, 1)
+	assert.Equal(t, int64(12), sources[0].Count)
+	assert.True(t, sources[0].Last.Equal(now))
+}
+
+func TestParseSoundLevelResolution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		span  time.Duration
+		want  time.Duration
+	}{
+		{"", 6 * time.Hour, time.Minute},
+		{"", 24 * time.Hour, 5 * time.Minute},
+		{"", 30 * 24 * time.Hour, 3 * time.Hour},
+		{"", 365 * 24 * time.Hour, 24 * time.Hour},
+		{"raw", 24 * time.Hour, 0},
+		{"900", 24 * time.Hour, 15 * time.Minute},
+		{"1h", 24 * time.Hour, time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSoundLevelResolution(tt.value, tt.span)
+		require.NoError(t, err, "value %q", tt.value)
+		assert.Equal(t, tt.want, got, "value %q span %s", tt.value, tt.span)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/support"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
@@ -257,9 +258,9 @@ func (c *Controller) GetSupportStatus(ctx echo.Context) error {
 
 // initSupportRoutes registers support-related routes
 func (c *Controller) initSupportRoutes() {
-	// Support endpoints require authentication
-	c.Group.POST("/support/generate", c.GenerateSupportDump, c.authMiddlewareFn)
-	c.Group.GET("/support/download/:id", c.DownloadSupportDump, c.authMiddlewareFn)
+	// Support endpoints require authentication, support dumps include the configuration and require an admin
+	c.Group.POST("/support/generate", c.GenerateSupportDump, c.authMiddlewareFn, auth.RequireRole(auth.RoleAdmin))
+	c.Group.GET("/support/download/:id", c.DownloadSupportDump, c.authMiddlewareFn, auth.RequireRole(auth.RoleAdmin))
 	c.Group.GET("/support/status", c.GetSupportStatus, c.authMiddlewareFn)
 
 	// Start cleanup goroutine for old support dumps with proper context
@@ -308,6 +308,75 @@ func (m *MockDataStore) SearchDetections(filters *datastore.SearchFilters) ([]da
 	return safeSlice[datastore.DetectionRecord](args, 0), args.Int(1), args.Error(2)
 }
 
+// SaveSoundLevels implements the datastore.Interface SaveSoundLevels method
+func (m *MockDataStore) SaveSoundLevels(levels []datastore.SoundLevel) error {
+	args := m.Called(levels)
+	return args.Error(0)
+}
+
+// GetSoundLevels implements the datastore.Interface GetSoundLevels method
+func (m *MockDataStore) GetSoundLevels(query *datastore.SoundLevelQuery) ([]datastore.SoundLevel, error) {
+	args := m.Called(query)
+	return safeSlice[datastore.SoundLevel](args, 0), args.Error(1)
+}
+
+// GetSoundLevelSources implements the datastore.Interface GetSoundLevelSources method
+func (m *MockDataStore) GetSoundLevelSources() ([]datastore.SoundLevelSource, error) {
+	args := m.Called()
+	return safeSlice[datastore.SoundLevelSource](args, 0), args.Error(1)
+}
+
+// GetUser implements the datastore.Interface GetUser method
+func (m *MockDataStore) GetUser(username string) (*datastore.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*datastore.User), args.Error(1)
+}
+
+// GetUsers implements the datastore.Interface GetUsers method
+func (m *MockDataStore) GetUsers() ([]datastore.User, error) {
+	args := m.Called()
+	return safeSlice[datastore.User](args, 0), args.Error(1)
+}
+
+// SaveUser implements the datastore.Interface SaveUser method
+func (m *MockDataStore) SaveUser(user *datastore.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+// DeleteUser implements the datastore.Interface DeleteUser method
+func (m *MockDataStore) DeleteUser(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+// SaveEmbedding implements the datastore.Interface SaveEmbedding method
+func (m *MockDataStore) SaveEmbedding(embedding *datastore.Embedding) error {
+	args := m.Called(embedding)
+	return args.Error(0)
+}
+
+// FindSimilarNotes implements the datastore.Interface FindSimilarNotes method
+func (m *MockDataStore) FindSimilarNotes(noteID string, limit int) ([]datastore.SimilarNote, error) {
+	args := m.Called(noteID, limit)
+	return safeSlice[datastore.SimilarNote](args, 0), args.Error(1)
+}
+
+// GetReviewedEmbeddings implements the datastore.Interface GetReviewedEmbeddings method
+func (m *MockDataStore) GetReviewedEmbeddings() ([]datastore.ReviewedEmbedding, error) {
+	args := m.Called()
+	return safeSlice[datastore.ReviewedEmbedding](args, 0), args.Error(1)
+}
+
+// GetReviewOutcomes implements the datastore.Interface GetReviewOutcomes method
+func (m *MockDataStore) GetReviewOutcomes(startDate string) ([]datastore.ReviewOutcome, error) {
+	args := m.Called(startDate)
+	return safeSlice[datastore.ReviewOutcome](args, 0), args.Error(1)
+}
+
 // GetNewSpeciesDetections implements the datastore.Interface GetNewSpeciesDetections method
 func (m *MockDataStore) GetNewSpeciesDetections(startDate, endDate string, limit, offset int) ([]datastore.NewSpeciesData, error) {
 	args := m.Called(startDate, endDate, limit, offset)
@@ -617,6 +686,75 @@ func (m *MockDataStoreV2) SearchDetections(filters *datastore.SearchFilters) ([]
 	return safeSlice[datastore.DetectionRecord](args, 0), args.Int(1), args.Error(2)
 }
 
+// SaveSoundLevels implements the datastore.Interface SaveSoundLevels method
+func (m *MockDataStoreV2) SaveSoundLevels(levels []datastore.SoundLevel) error {
+	args := m.Called(levels)
+	return args.Error(0)
+}
+
+// GetSoundLevels implements the datastore.Interface GetSoundLevels method
+func (m *MockDataStoreV2) GetSoundLevels(query *datastore.SoundLevelQuery) ([]datastore.SoundLevel, error) {
+	args := m.Called(query)
+	return safeSlice[datastore.SoundLevel](args, 0), args.Error(1)
+}
+
+// GetSoundLevelSources implements the datastore.Interface GetSoundLevelSources method
+func (m *MockDataStoreV2) GetSoundLevelSources() ([]datastore.SoundLevelSource, error) {
+	args := m.Called()
+	return safeSlice[datastore.SoundLevelSource](args, 0), args.Error(1)
+}
+
+// GetUser implements the datastore.Interface GetUser method
+func (m *MockDataStoreV2) GetUser(username string) (*datastore.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*datastore.User), args.Error(1)
+}
+
+// GetUsers implements the datastore.Interface GetUsers method
+func (m *MockDataStoreV2) GetUsers() ([]datastore.User, error) {
+	args := m.Called()
+	return safeSlice[datastore.User](args, 0), args.Error(1)
+}
+
+// SaveUser implements the datastore.Interface SaveUser method
+func (m *MockDataStoreV2) SaveUser(user *datastore.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+// DeleteUser implements the datastore.Interface DeleteUser method
+func (m *MockDataStoreV2) DeleteUser(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+// SaveEmbedding implements the datastore.Interface SaveEmbedding method
+func (m *MockDataStoreV2) SaveEmbedding(embedding *datastore.Embedding) error {
+	args := m.Called(embedding)
+	return args.Error(0)
+}
+
+// FindSimilarNotes implements the datastore.Interface FindSimilarNotes method
+func (m *MockDataStoreV2) FindSimilarNotes(noteID string, limit int) ([]datastore.SimilarNote, error) {
+	args := m.Called(noteID, limit)
+	return safeSlice[datastore.SimilarNote](args, 0), args.Error(1)
+}
+
+// GetReviewedEmbeddings implements the datastore.Interface GetReviewedEmbeddings method
+func (m *MockDataStoreV2) GetReviewedEmbeddings() ([]datastore.ReviewedEmbedding, error) {
+	args := m.Called()
+	return safeSlice[datastore.ReviewedEmbedding](args, 0), args.Error(1)
+}
+
+// GetReviewOutcomes implements the datastore.Interface GetReviewOutcomes method
+func (m *MockDataStoreV2) GetReviewOutcomes(startDate string) ([]datastore.ReviewOutcome, error) {
+	args := m.Called(startDate)
+	return safeSlice[datastore.ReviewOutcome](args, 0), args.Error(1)
+}
+
 // MockImageProvider is a mock implementation of imageprovider.ImageProvider interface
 // that uses testify/mock for expectations and verification.
 // Use this when you need to verify specific method calls and arguments.
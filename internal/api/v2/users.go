@@ -0,0 +1,186 @@
+// internal/api/v2/users.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/security"
+)
+
+// maxUsernameLength matches the size of the username column
+const maxUsernameLength = 100
+
+// UserResponse represents a user account in the API response, without the password hash
+type UserResponse struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UserRequest represents the request body for creating or updating a user account. The password
+// is required when creating an account, when updating empty fields keep their current value.
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Role     string `json:"role"`
+}
+
+// initUserRoutes registers the user account management endpoints, available to admins only
+func (c *Controller) initUserRoutes() {
+	userGroup := c.Group.Group("/users", c.getEffectiveAuthMiddleware(), auth.RequireRole(auth.RoleAdmin))
+	userGroup.GET("", c.GetUsers)
+	userGroup.POST("", c.CreateUser)
+	userGroup.PUT("/:username", c.UpdateUser)
+	userGroup.DELETE("/:username", c.DeleteUser)
+}
+
+// GetUsers handles GET /api/v2/users
+func (c *Controller) GetUsers(ctx echo.Context) error {
+	users, err := c.DS.GetUsers()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get users", http.StatusInternalServerError)
+	}
+
+	response := make([]UserResponse, 0, len(users))
+	for i := range users {
+		response = append(response, newUserResponse(&users[i]))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// CreateUser handles POST /api/v2/users
+func (c *Controller) CreateUser(ctx echo.Context) error {
+	var req UserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request format", http.StatusBadRequest)
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if err := c.validateUsername(username); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	switch _, err := c.DS.GetUser(username); {
+	case err == nil:
+		return c.HandleError(ctx, fmt.Errorf("user %s already exists", username), "User already exists", http.StatusConflict)
+	case !errors.Is(err, datastore.ErrUserNotFound):
+		return c.HandleError(ctx, err, "Failed to check user", http.StatusInternalServerError)
+	}
+
+	// Accounts log in with their password, social logins are limited to the configured user IDs
+	if req.Password == "" {
+		return c.HandleError(ctx, errors.New("password is required"), "password is required", http.StatusBadRequest)
+	}
+	user := &datastore.User{Username: username, Role: string(role)}
+	if user.PasswordHash, err = security.HashPassword(req.Password); err != nil {
+		return c.handlePasswordError(ctx, err)
+	}
+
+	if err := c.DS.SaveUser(user); err != nil {
+		return c.HandleError(ctx, err, "Failed to create user", http.StatusInternalServerError)
+	}
+	c.logUserChange(ctx, "User created", user)
+	return ctx.JSON(http.StatusCreated, newUserResponse(user))
+}
+
+// UpdateUser handles PUT /api/v2/users/:username
+func (c *Controller) UpdateUser(ctx echo.Context) error {
+	user, err := c.DS.GetUser(ctx.Param("username"))
+	if err != nil {
+		if errors.Is(err, datastore.ErrUserNotFound) {
+			return c.HandleError(ctx, err, "User not found", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to get user", http.StatusInternalServerError)
+	}
+
+	var req UserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request format", http.StatusBadRequest)
+	}
+	if req.Role != "" {
+		role, err := auth.ParseRole(req.Role)
+		if err != nil {
+			return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+		}
+		user.Role = string(role)
+	}
+	if req.Password != "" {
+		if user.PasswordHash, err = security.HashPassword(req.Password); err != nil {
+			return c.handlePasswordError(ctx, err)
+		}
+	}
+
+	if err := c.DS.SaveUser(user); err != nil {
+		return c.HandleError(ctx, err, "Failed to update user", http.StatusInternalServerError)
+	}
+	c.logUserChange(ctx, "User updated", user)
+	return ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// DeleteUser handles DELETE /api/v2/users/:username. Sessions and tokens of the
+// user lose access with the account.
+func (c *Controller) DeleteUser(ctx echo.Context) error {
+	username := ctx.Param("username")
+	if err := c.DS.DeleteUser(username); err != nil {
+		if errors.Is(err, datastore.ErrUserNotFound) {
+			return c.HandleError(ctx, err, "User not found", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to delete user", http.StatusInternalServerError)
+	}
+	c.logUserChange(ctx, "User deleted", &datastore.User{Username: username})
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// validateUsername checks a new username. The username of the configured password is reserved,
+// it always has full access.
+func (c *Controller) validateUsername(username string) error {
+	switch {
+	case username == "":
+		return errors.New("username is required")
+	case len(username) > maxUsernameLength:
+		return fmt.Errorf("username must be at most %d characters", maxUsernameLength)
+	case strings.EqualFold(username, c.Settings.Security.BasicAuth.ClientID):
+		return errors.New("username is reserved for the configured password")
+	}
+	return nil
+}
+
+// handlePasswordError returns a bad request for passwords that are too short
+func (c *Controller) handlePasswordError(ctx echo.Context, err error) error {
+	if errors.Is(err, security.ErrPasswordTooShort) {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	return c.HandleError(ctx, err, "Failed to set password", http.StatusInternalServerError)
+}
+
+// logUserChange logs changes to user accounts with the admin who made them
+func (c *Controller) logUserChange(ctx echo.Context, message string, user *datastore.User) {
+	security.LogInfo(message,
+		"username", user.Username,
+		"role", user.Role,
+		"changed_by", stringFromCtx(ctx, "username", ""),
+		"ip", ctx.RealIP(),
+	)
+}
+
+// newUserResponse converts a user account to its API response
+func newUserResponse(user *datastore.User) UserResponse {
+	return UserResponse{
+		Username:  user.Username,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
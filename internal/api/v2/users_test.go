@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/api/v2/auth"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/security"
+)
+
+// fakeAuthService authenticates bearer tokens as a single user with a fixed role
+type fakeAuthService struct {
+	username string
+	role     auth.Role
+}
+
+func (f *fakeAuthService) CheckAccess(c echo.Context) error             { return auth.ErrSessionNotFound }
+func (f *fakeAuthService) IsAuthRequired(c echo.Context) bool           { return true }
+func (f *fakeAuthService) GetUsername(c echo.Context) string            { return f.username }
+func (f *fakeAuthService) GetAuthMethod(c echo.Context) auth.AuthMethod { return auth.AuthMethodToken }
+func (f *fakeAuthService) GetRole(c echo.Context) auth.Role             { return f.role }
+func (f *fakeAuthService) ValidateToken(token string) error             { return nil }
+func (f *fakeAuthService) Logout(c echo.Context) error                  { return nil }
+func (f *fakeAuthService) AuthenticateBasic(c echo.Context, username, password string) (string, error) {
+	return "", auth.ErrInvalidCredentials
+}
+
+// serveAs sends a request through the registered routes with the token of the fake user
+func serveAs(controller *Controller, method, path string, body any) *httptest.ResponseRecorder {
+	var reader bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&reader).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer token")
+	rec := httptest.NewRecorder()
+	controller.Echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestParseRole(t *testing.T) {
+	t.Parallel()
+	role, err := auth.ParseRole(" Reviewer ")
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleReviewer, role)
+	_, err = auth.ParseRole("owner")
+	require.Error(t, err)
+
+	assert.True(t, auth.RoleAdmin.Allows(auth.RoleReviewer))
+	assert.True(t, auth.RoleReviewer.Allows(auth.RoleViewer))
+	assert.False(t, auth.RoleReviewer.Allows(auth.RoleAdmin))
+	assert.False(t, auth.Role("").Allows(auth.RoleViewer))
+}
+
+func TestRoleEnforcement(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+	controller.initDetectionRoutes()
+	controller.initUserRoutes()
+	service := &fakeAuthService{username: "alice"}
+	controller.AuthService = service
+
+	mockDS.On("Get", "1").Return(nil, assert.AnError)
+	mockDS.On("GetUsers").Return([]datastore.User{}, nil)
+
+	tests := []struct {
+		role   auth.Role
+		method string
+		path   string
+		want   int
+	}{
+		// Viewers browse
+		{auth.RoleViewer, http.MethodPost, "/api/v2/detections/1/review", http.StatusForbidden},
+		{auth.RoleViewer, http.MethodPost, "/api/v2/detections/1/lock", http.StatusForbidden},
+		{auth.RoleViewer, http.MethodGet, "/api/v2/users", http.StatusForbidden},
+		// Reviewers verify and lock, the missing detection is reported by the handler
+		{auth.RoleReviewer, http.MethodPost, "/api/v2/detections/1/review", http.StatusNotFound},
+		{auth.RoleReviewer, http.MethodPost, "/api/v2/detections/1/lock", http.StatusConflict},
+		{auth.RoleReviewer, http.MethodDelete, "/api/v2/detections/1", http.StatusForbidden},
+		{auth.RoleReviewer, http.MethodPost, "/api/v2/detections/ignore", http.StatusForbidden},
+		// Admins delete detections and manage users
+		{auth.RoleAdmin, http.MethodDelete, "/api/v2/detections/1", http.StatusNotFound},
+		{auth.RoleAdmin, http.MethodGet, "/api/v2/users", http.StatusOK},
+		// Deleted accounts have no role and lose access
+		{"", http.MethodGet, "/api/v2/users", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		service.role = tt.role
+		rec := serveAs(controller, tt.method, tt.path, map[string]string{})
+		assert.Equal(t, tt.want, rec.Code, "%s %s as %q: %s", tt.method, tt.path, tt.role, rec.Body.String())
+	}
+}
+
+func TestReviewDetectionRecordsAuthor(t *testing.T) {
+	t.Parallel()
+	_, mockDS, controller := setupTestEnvironment(t)
+	controller.initDetectionRoutes()
+	controller.AuthService = &fakeAuthService{username: "alice", role: auth.RoleReviewer}
+
+	mockDS.On("Get", "1").Return(datastore.Note{ID: 1}, nil)
+	mockDS.On("IsNoteLocked", "1").Return(false, nil)
+	mockDS.On("SaveNoteComment", mock.MatchedBy(func(comment *datastore.NoteComment) bool {
+		return comment.Author == "alice" && comment.Entry == "Song, not call"
+	})).Return(nil)
+	mockDS.On("SaveNoteReview", mock.MatchedBy(func(review *datastore.NoteReview) bool {
+		return review.Author == "alice" && review.Verified == "correct"
+	})).Return(nil)
+
+	rec := serveAs(controller, http.MethodPost, "/api/v2/detections/1/review", DetectionRequest{
+		Comment:  "Song, not call",
+		Verified: "correct",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	mockDS.AssertExpectations(t)
+
+	// Ignoring the species of a false positive changes settings
+	rec = serveAs(controller, http.MethodPost, "/api/v2/detections/1/review", DetectionRequest{
+		Verified:      "false_positive",
+		IgnoreSpecies: "Eurasian Blackbird",
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestUserManagement(t *testing.T) {
+	t.Parallel()
+	_, _, controller := setupTestEnvironment(t)
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	store := datastore.New(settings)
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+	controller.DS = store
+	controller.Settings.Security.BasicAuth.ClientID = "birdnet-client"
+	controller.initUserRoutes()
+	controller.AuthService = &fakeAuthService{username: "birdnet-client", role: auth.RoleAdmin}
+
+	// Invalid accounts
+	for _, req := range []UserRequest{
+		{Username: "bob", Password: "correct horse", Role: "owner"},
+		{Username: "bob", Password: "short", Role: "viewer"},
+		{Username: "", Password: "correct horse", Role: "viewer"},
+		{Username: "Birdnet-Client", Password: "correct horse", Role: "viewer"},
+		{Username: "carol@example.com", Role: "admin"},
+	} {
+		rec := serveAs(controller, http.MethodPost, "/api/v2/users", req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "%+v", req)
+	}
+
+	rec := serveAs(controller, http.MethodPost, "/api/v2/users", UserRequest{Username: "bob", Password: "correct horse", Role: "viewer"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "correct horse")
+	rec = serveAs(controller, http.MethodPost, "/api/v2/users", UserRequest{Username: "BOB", Role: "viewer"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = serveAs(controller, http.MethodPost, "/api/v2/users", UserRequest{Username: "carol@example.com", Password: "battery staple", Role: "admin"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// Promote bob, the password is kept
+	rec = serveAs(controller, http.MethodPut, "/api/v2/users/bob", UserRequest{Role: "reviewer"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	user, err := store.GetUser("bob")
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", user.Role)
+	assert.True(t, security.CheckPasswordHash(user.PasswordHash, "correct horse"))
+
+	rec = serveAs(controller, http.MethodGet, "/api/v2/users", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var users []UserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &users))
+	require.Len(t, users, 2)
+	assert.Equal(t, UserResponse{Username: "bob", Role: "reviewer", CreatedAt: users[0].CreatedAt, UpdatedAt: users[0].UpdatedAt}, users[0])
+	assert.Equal(t, "carol@example.com", users[1].Username)
+
+	rec = serveAs(controller, http.MethodDelete, "/api/v2/users/bob", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = serveAs(controller, http.MethodPut, "/api/v2/users/bob", UserRequest{Role: "admin"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -16,6 +16,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
@@ -52,10 +53,23 @@ type Target interface {
 	List(ctx context.Context) ([]BackupInfo, error)
 	// Delete deletes a backup from storage
 	Delete(ctx context.Context, id string) error
+	// Retrieve downloads a stored backup archive by file name to destPath.
+	// It returns an ErrNotFound error if the archive does not exist.
+	Retrieve(ctx context.Context, name, destPath string) error
 	// Validate validates the target configuration
 	Validate() error
 }
 
+// Restorer represents a data source that can be restored from a backup
+type Restorer interface {
+	// Name returns the name of the source, matching Metadata.Source of its backups
+	Name() string
+	// Restore verifies the backup data at dataPath and swaps it in place of the live data.
+	// It returns the path where the previous data was kept aside, or an empty string if
+	// there was no previous data.
+	Restore(ctx context.Context, dataPath string) (string, error)
+}
+
 // Metadata contains information about a backup
 type Metadata struct {
 	Version      int       `json:"version"`                 // Version of the metadata format
@@ -156,19 +170,44 @@ func sanitizeConfig(config *conf.Settings) *conf.Settings {
 	sanitized.Security.GithubAuth.ClientSecret = ""
 	sanitized.Security.SessionSecret = ""
 	sanitized.Output.MySQL.Password = ""
+	sanitized.Output.PostgreSQL.Password = ""
 	sanitized.Realtime.MQTT.Password = ""
+	sanitized.Realtime.MQTT.Commands.Token = ""
 	sanitized.Realtime.Weather.OpenWeather.APIKey = ""
+	for i := range sanitized.Realtime.Webhooks.Endpoints {
+		endpoint := &sanitized.Realtime.Webhooks.Endpoints[i]
+		endpoint.Secret = ""
+		blankHeaderValues(endpoint.Headers)
+	}
+	for i := range sanitized.Notification.Push.Channels {
+		channel := &sanitized.Notification.Push.Channels[i]
+		channel.Token = ""
+		channel.SMTP.Password = ""
+		blankHeaderValues(channel.Headers)
+	}
 
 	return &sanitized
 }
 
+// blankHeaderValues blanks the values of request headers, which often carry credentials,
+// keeping the header names
+func blankHeaderValues(headers map[string]string) {
+	for name := range headers {
+		headers[name] = ""
+	}
+}
+
 // Manager handles the backup operations
 type Manager struct {
 	config       *conf.BackupConfig
 	fullConfig   *conf.Settings // Store the full config for hashing
 	sources      map[string]Source
 	targets      map[string]Target
+	restorers    map[string]Restorer
 	mu           sync.RWMutex
+	restoreMu    sync.Mutex  // Serializes restore operations
+	running      atomic.Bool // Set while RunBackup is in progress
+	progress     progressBroadcaster
 	logger       *slog.Logger // Use slog logger
 	stateManager *StateManager
 	appVersion   string // Store app version
@@ -189,6 +228,7 @@ func NewManager(fullConfig *conf.Settings, logger *slog.Logger, stateManager *St
 		fullConfig:   fullConfig,         // Keep the full config
 		sources:      make(map[string]Source),
 		targets:      make(map[string]Target),
+		restorers:    make(map[string]Restorer),
 		logger:       logger.With("service", "backup_manager"), // Add service context
 		stateManager: stateManager,
 		appVersion:   appVersion,
@@ -210,9 +250,22 @@ func (m *Manager) RegisterSource(source Source) error {
 	}
 
 	m.sources[source.Name()] = source
+	if restorer, ok := source.(Restorer); ok {
+		m.restorers[restorer.Name()] = restorer
+	}
 	return nil
 }
 
+// RegisterRestorer registers a restore handler without registering it as a backup source.
+// Unlike RegisterSource, no validation is performed, so data can be restored even when
+// the live data is missing or damaged.
+func (m *Manager) RegisterRestorer(restorer Restorer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.restorers[restorer.Name()] = restorer
+}
+
 // RegisterTarget registers a backup target
 func (m *Manager) RegisterTarget(target Target) error {
 	m.mu.Lock()
@@ -263,8 +316,28 @@ func (m *Manager) Start() error {
 	return nil
 }
 
-// RunBackup performs an immediate backup of all sources
-func (m *Manager) RunBackup(ctx context.Context) error {
+// IsBackupRunning reports whether a backup is currently in progress
+func (m *Manager) IsBackupRunning() bool {
+	return m.running.Load()
+}
+
+// RunBackup performs an immediate backup of all sources.
+// It returns an ErrLocked error if another backup is already running.
+func (m *Manager) RunBackup(ctx context.Context) (err error) {
+	if !m.running.CompareAndSwap(false, true) {
+		return NewError(ErrLocked, "another backup is already in progress", nil)
+	}
+	defer m.running.Store(false)
+
+	m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageStarted})
+	defer func() {
+		if err != nil {
+			m.emitFailure(OperationBackup, "", "", "", err)
+			return
+		}
+		m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageCompleted})
+	}()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -393,6 +466,8 @@ func (m *Manager) processBackupSource(ctx context.Context, sourceName string, so
 		metadata.ConfigHash = configHash
 	}
 
+	m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageArchiving, Source: sourceName, BackupID: metadata.ID})
+
 	// 4. Create the archive file path
 	archiveFileName := metadata.ID + ".tar"
 	// Compression logic removed as it's not in BackupConfig
@@ -413,6 +488,7 @@ func (m *Manager) processBackupSource(ctx context.Context, sourceName string, so
 	finalArchivePath := archivePath
 	if m.config.Encryption {
 		m.logger.Debug("Starting encryption", "source_name", sourceName, "archive_path", archivePath)
+		m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageEncrypting, Source: sourceName, BackupID: metadata.ID})
 		encryptedArchivePath := archivePath + ".enc" // Convention for encrypted file
 		err := m.encryptArchive(ctx, archivePath, encryptedArchivePath)
 		if err != nil {
@@ -551,10 +627,12 @@ func (m *Manager) storeBackupInTargets(ctx context.Context, archivePath string,
 			targetName := t.Name()
 			startTargetTime := time.Now()
 			m.logger.Info("Storing backup in target", "backup_id", metadata.ID, "target_name", targetName)
+			m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageStoring, Source: metadata.Source, Target: targetName, BackupID: metadata.ID})
 
 			if err := t.Store(storeCtx, archivePath, metadata); err != nil {
 				wrappedErr := fmt.Errorf("target %s: %w", targetName, err)
 				m.logger.Error("Failed to store backup in target", "backup_id", metadata.ID, "target_name", targetName, "error", err)
+				m.emitFailure(OperationBackup, metadata.Source, targetName, metadata.ID, err)
 				errChan <- wrappedErr
 				// Update state for this specific target failure
 				if m.stateManager != nil {
@@ -567,6 +645,7 @@ func (m *Manager) storeBackupInTargets(ctx context.Context, archivePath string,
 					"backup_id", metadata.ID,
 					"target_name", targetName,
 					"duration_ms", time.Since(startTargetTime).Milliseconds())
+				m.emitProgress(ProgressEvent{Operation: OperationBackup, Stage: StageStored, Source: metadata.Source, Target: targetName, BackupID: metadata.ID})
 				// Update state for this specific target success
 				if m.stateManager != nil {
 					if err := m.stateManager.UpdateTargetState(targetName, metadata, "success"); err != nil {
@@ -767,29 +846,29 @@ func (m *Manager) addBackupDataToArchive(ctx context.Context, tw *tar.Writer, re
 	// Example: Use source name with a common extension
 	backupFilename := fmt.Sprintf("backup.%s", strings.ToLower(metadata.Source)) // e.g., backup.sqlite
 
-	// We don't know the size beforehand for streaming backup.
-	// Write the data directly. TAR format supports this.
-	// Create TAR header for the backup data
-	hdr := &tar.Header{
-		Name:    backupFilename,
-		Mode:    0o644, // Standard file permissions
-		ModTime: metadata.Timestamp,
-		// Size is unknown for streaming, tar writer handles this.
-	}
-
-	// Write header
-	if err := tw.WriteHeader(hdr); err != nil {
+	// The tar format needs the entry size in the header, which is not known for a
+	// streaming backup. Spool the stream to a temporary file first to learn the size.
+	spoolFile, err := os.CreateTemp("", "birdnet-go-backup-data-*")
+	if err != nil {
 		return errors.New(err).
 			Component("backup").
 			Category(errors.CategoryFileIO).
-			Context("operation", "write_backup_data_tar_header").
+			Context("operation", "create_backup_data_spool").
 			Build()
 	}
+	defer func() {
+		if err := spoolFile.Close(); err != nil {
+			m.logger.Debug("Failed to close backup data spool file", "path", spoolFile.Name(), "error", err)
+		}
+		if err := os.Remove(spoolFile.Name()); err != nil {
+			m.logger.Warn("Failed to remove backup data spool file", "path", spoolFile.Name(), "error", err)
+		}
+	}()
 
-	// Copy data from source reader to tar writer
+	// Copy data from source reader to the spool file
 	// Wrap the reader with a context checker if possible/needed,
 	// although source.Backup should handle context internally.
-	copiedBytes, err := io.Copy(tw, reader)
+	copiedBytes, err := io.Copy(spoolFile, reader)
 	if err != nil {
 		// Check for context cancellation specifically if possible
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
@@ -803,10 +882,43 @@ func (m *Manager) addBackupDataToArchive(ctx context.Context, tw *tar.Writer, re
 		return errors.New(err).
 			Component("backup").
 			Category(errors.CategoryFileIO).
-			Context("operation", "stream_backup_data_to_tar").
+			Context("operation", "stream_backup_data_to_spool").
 			Context("bytes_copied", copiedBytes).
 			Build()
 	}
+	if _, err := spoolFile.Seek(0, io.SeekStart); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "rewind_backup_data_spool").
+			Build()
+	}
+
+	// Create TAR header for the backup data
+	hdr := &tar.Header{
+		Name:    backupFilename,
+		Mode:    0o644, // Standard file permissions
+		Size:    copiedBytes,
+		ModTime: metadata.Timestamp,
+	}
+
+	// Write header
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_backup_data_tar_header").
+			Build()
+	}
+
+	if _, err := io.Copy(tw, spoolFile); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_backup_data_to_tar").
+			Context("bytes_expected", copiedBytes).
+			Build()
+	}
 
 	m.logger.Debug("Finished adding backup data stream",
 		"backup_id", metadata.ID,
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestSanitizeConfig(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Output.MySQL.Password = "mysql-password"
+	settings.Realtime.MQTT.Password = "mqtt-password"
+	settings.Realtime.MQTT.Commands.Token = "command-token"
+	settings.Realtime.MQTT.Commands.Topic = "birdnet/command"
+	settings.Realtime.Webhooks.Endpoints = []conf.WebhookEndpoint{{
+		URL:     "https://example.com/hook",
+		Secret:  "signing-key",
+		Headers: map[string]string{"Authorization": "Bearer webhook-token"},
+	}}
+	settings.Notification.Push.Channels = []conf.PushChannelSettings{
+		{Type: "ntfy", URL: "https://ntfy.sh/birds", Token: "ntfy-token"},
+		{Type: "smtp", SMTP: conf.SMTPSettings{Host: "mail.example.com", Username: "birdnet", Password: "smtp-password"}},
+		{Type: "webhook", Headers: map[string]string{"X-Api-Key": "push-key"}},
+	}
+
+	sanitized := sanitizeConfig(settings)
+
+	assert.Empty(t, sanitized.Output.MySQL.Password)
+	assert.Empty(t, sanitized.Realtime.MQTT.Password)
+	assert.Empty(t, sanitized.Realtime.MQTT.Commands.Token, "command token must not be stored in backups")
+	endpoint := sanitized.Realtime.Webhooks.Endpoints[0]
+	assert.Empty(t, endpoint.Secret)
+	assert.Equal(t, map[string]string{"Authorization": ""}, endpoint.Headers, "header values are blanked, names kept")
+	assert.Equal(t, "https://example.com/hook", endpoint.URL)
+	assert.Equal(t, "Bearer webhook-token", settings.Realtime.Webhooks.Endpoints[0].Headers["Authorization"])
+	channels := sanitized.Notification.Push.Channels
+	assert.Empty(t, channels[0].Token)
+	assert.Equal(t, "https://ntfy.sh/birds", channels[0].URL)
+	assert.Empty(t, channels[1].SMTP.Password)
+	assert.Equal(t, "birdnet", channels[1].SMTP.Username)
+	assert.Equal(t, map[string]string{"X-Api-Key": ""}, channels[2].Headers)
+	assert.Equal(t, "birdnet/command", sanitized.Realtime.MQTT.Commands.Topic, "non-secret settings are kept")
+	assert.Equal(t, "command-token", settings.Realtime.MQTT.Commands.Token, "the live settings are not modified")
+}
@@ -98,7 +98,37 @@ func (m *Manager) getEncryptionKey() ([]byte, error) {
 		return key, nil
 	}
 
-	// Decode existing key from hex
+	return decodeEncryptionKey(keyBytes)
+}
+
+// loadEncryptionKey reads the existing encryption key without generating a new one.
+// Restoring an encrypted backup must work even if encryption has since been disabled,
+// and a freshly generated key could never decrypt an existing archive anyway.
+func (m *Manager) loadEncryptionKey() ([]byte, error) {
+	keyPath, err := m.getEncryptionKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	secureOp := NewSecureFileOp("backup")
+	keyBytes, cleanKeyPath, err := secureOp.SecureReadFile(keyPath)
+	if err != nil {
+		if _, statErr := os.Stat(cleanKeyPath); os.IsNotExist(statErr) {
+			return nil, errors.Newf("encryption key file not found, import the key used to create the backup first").
+				Component("backup").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "load_encryption_key").
+				Context("key_path", cleanKeyPath).
+				Build()
+		}
+		return nil, err
+	}
+
+	return decodeEncryptionKey(keyBytes)
+}
+
+// decodeEncryptionKey decodes and validates a hex-encoded encryption key
+func decodeEncryptionKey(keyBytes []byte) ([]byte, error) {
 	keyStr := strings.TrimSpace(string(keyBytes))
 	key, err := hex.DecodeString(keyStr)
 	if err != nil {
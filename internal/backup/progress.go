@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress operations
+const (
+	OperationBackup  = "backup"
+	OperationRestore = "restore"
+)
+
+// Progress stages reported while a backup or restore is running
+const (
+	StageStarted    = "started"
+	StageArchiving  = "archiving"
+	StageEncrypting = "encrypting"
+	StageStoring    = "storing"
+	StageStored     = "stored"
+	StageRetrieving = "retrieving"
+	StageDecrypting = "decrypting"
+	StageVerifying  = "verifying"
+	StageRestoring  = "restoring"
+	StageCompleted  = "completed"
+	StageFailed     = "failed"
+)
+
+// defaultProgressBuffer is the channel size used for progress subscribers
+const defaultProgressBuffer = 64
+
+// ProgressEvent describes a step of a running backup or restore operation
+type ProgressEvent struct {
+	Operation string    `json:"operation"`
+	Stage     string    `json:"stage"`
+	Source    string    `json:"source,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	BackupID  string    `json:"backup_id,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// progressBroadcaster fans out progress events to subscribers.
+// Slow subscribers miss events instead of blocking the backup.
+type progressBroadcaster struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]chan ProgressEvent
+}
+
+func (b *progressBroadcaster) subscribe(buffer int) (<-chan ProgressEvent, func()) {
+	if buffer <= 0 {
+		buffer = defaultProgressBuffer
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[int]chan ProgressEvent)
+	}
+	id := b.nextID
+	b.nextID++
+	ch := make(chan ProgressEvent, buffer)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (b *progressBroadcaster) publish(event ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscribeProgress returns a channel receiving progress events of backup and
+// restore operations, and a function that cancels the subscription. Events are
+// dropped for subscribers that do not keep up.
+func (m *Manager) SubscribeProgress(buffer int) (events <-chan ProgressEvent, unsubscribe func()) {
+	return m.progress.subscribe(buffer)
+}
+
+// emitProgress publishes a progress event to all subscribers
+func (m *Manager) emitProgress(event ProgressEvent) {
+	event.Timestamp = time.Now()
+	m.progress.publish(event)
+}
+
+// emitFailure publishes a failed stage for an operation
+func (m *Manager) emitFailure(operation, source, target, backupID string, err error) {
+	event := ProgressEvent{
+		Operation: operation,
+		Stage:     StageFailed,
+		Source:    source,
+		Target:    target,
+		BackupID:  backupID,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	m.emitProgress(event)
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectProgress drains events until the operation completes or fails
+func collectProgress(t *testing.T, events <-chan ProgressEvent) []ProgressEvent {
+	t.Helper()
+	var collected []ProgressEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			collected = append(collected, event)
+			if isFinalEvent(&event) {
+				return collected
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for progress events, got %d", len(collected))
+		}
+	}
+}
+
+// isFinalEvent reports whether the event ends an operation. Failures of a single
+// backup target are reported with the target name and do not end the backup.
+func isFinalEvent(event *ProgressEvent) bool {
+	switch event.Stage {
+	case StageCompleted:
+		return true
+	case StageFailed:
+		return event.Operation == OperationRestore || event.Target == ""
+	default:
+		return false
+	}
+}
+
+func stagesOf(events []ProgressEvent) []string {
+	stages := make([]string, len(events))
+	for i := range events {
+		stages[i] = events[i].Stage
+	}
+	return stages
+}
+
+func TestProgress_BackupEvents(t *testing.T) {
+	manager, target, _ := newRestoreTestManager(t, true)
+	require.NoError(t, manager.RegisterSource(&staticSource{name: "birdnet", data: []byte("data")}))
+
+	events, unsubscribe := manager.SubscribeProgress(0)
+	defer unsubscribe()
+
+	require.NoError(t, manager.RunBackup(context.Background()))
+
+	collected := collectProgress(t, events)
+	assert.Equal(t, []string{StageStarted, StageArchiving, StageEncrypting, StageStoring, StageStored, StageCompleted}, stagesOf(collected))
+	for i := range collected {
+		assert.Equal(t, OperationBackup, collected[i].Operation)
+		assert.False(t, collected[i].Timestamp.IsZero())
+	}
+	assert.Equal(t, target.Name(), collected[4].Target)
+	assert.NotEmpty(t, collected[4].BackupID)
+}
+
+func TestProgress_RestoreEvents(t *testing.T) {
+	manager, target, _ := newRestoreTestManager(t, false)
+	require.NoError(t, manager.RegisterSource(&staticSource{name: "birdnet", data: []byte("data")}))
+	require.NoError(t, manager.RunBackup(context.Background()))
+
+	backups, err := target.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	events, unsubscribe := manager.SubscribeProgress(0)
+	defer unsubscribe()
+
+	_, err = manager.Restore(context.Background(), backups[0].ID, target.Name())
+	require.NoError(t, err)
+
+	collected := collectProgress(t, events)
+	assert.Equal(t, []string{StageStarted, StageRetrieving, StageVerifying, StageRestoring, StageCompleted}, stagesOf(collected))
+
+	_, err = manager.Restore(context.Background(), "birdnet-20240101-000000", target.Name())
+	require.Error(t, err)
+	collected = collectProgress(t, events)
+	assert.Equal(t, StageFailed, collected[len(collected)-1].Stage)
+	assert.NotEmpty(t, collected[len(collected)-1].Error)
+}
+
+func TestProgress_UnsubscribeClosesChannel(t *testing.T) {
+	t.Parallel()
+
+	var b progressBroadcaster
+	events, unsubscribe := b.subscribe(1)
+	unsubscribe()
+	unsubscribe() // must be safe to call twice
+
+	_, ok := <-events
+	assert.False(t, ok)
+
+	// Publishing without subscribers or to a full channel must not block
+	full, unsubscribeFull := b.subscribe(1)
+	defer unsubscribeFull()
+	b.publish(ProgressEvent{Stage: StageStarted})
+	b.publish(ProgressEvent{Stage: StageCompleted})
+	assert.Equal(t, StageStarted, (<-full).Stage)
+}
+
+func TestRunBackup_RejectsConcurrentRun(t *testing.T) {
+	manager, _, _ := newRestoreTestManager(t, false)
+	require.NoError(t, manager.RegisterSource(&staticSource{name: "birdnet", data: []byte("data")}))
+
+	manager.running.Store(true)
+	err := manager.RunBackup(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrLocked))
+	assert.True(t, manager.IsBackupRunning())
+
+	manager.running.Store(false)
+	require.NoError(t, manager.RunBackup(context.Background()))
+	assert.False(t, manager.IsBackupRunning())
+}
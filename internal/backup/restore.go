@@ -0,0 +1,516 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	archiveMetadataName   = "metadata.json"
+	archiveConfigName     = "config.yml"
+	archiveDataPrefix     = "backup."
+	archiveExt            = ".tar"
+	encryptedArchiveExt   = ".enc"
+	maxArchiveMetadataLen = 1024 * 1024      // metadata.json is a small JSON document
+	maxArchiveConfigLen   = 10 * 1024 * 1024 // config.yml is a sanitized YAML file
+	currentMetadataFormat = 1                // Highest metadata version this build can restore
+)
+
+// RestoreResult describes the outcome of a restore operation
+type RestoreResult struct {
+	BackupID         string        `json:"backup_id"`
+	Target           string        `json:"target"`
+	Source           string        `json:"source"`
+	Metadata         Metadata      `json:"metadata"`
+	PreviousDataPath string        `json:"previous_data_path,omitempty"` // Where the replaced data was kept aside
+	ConfigChanged    bool          `json:"config_changed"`               // Whether the current config differs from the backed up config
+	Duration         time.Duration `json:"duration"`
+}
+
+// archiveContents holds the files extracted from a backup archive
+type archiveContents struct {
+	metadata   *Metadata
+	configData []byte
+	dataPath   string
+}
+
+// Restore fetches the backup identified by id from the named target, decrypts it if needed,
+// verifies the archive metadata and config hash, and hands the backup data to the restorer
+// registered for the backup source. The id may be either the backup ID or the archive file name.
+func (m *Manager) Restore(ctx context.Context, id, targetName string) (result *RestoreResult, err error) {
+	if !m.restoreMu.TryLock() {
+		return nil, NewError(ErrLocked, "another restore operation is already in progress", nil)
+	}
+	defer m.restoreMu.Unlock()
+
+	start := time.Now()
+
+	m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageStarted, Target: targetName, BackupID: id})
+	defer func() {
+		if err != nil {
+			m.emitFailure(OperationRestore, "", targetName, id, err)
+			return
+		}
+		m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageCompleted, Source: result.Source, Target: targetName, BackupID: result.BackupID})
+	}()
+
+	if err := validateBackupID(id); err != nil {
+		return nil, err
+	}
+
+	target, err := m.getTarget(targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("Starting restore", "backup_id", id, "target_name", targetName)
+
+	tempDir, err := os.MkdirTemp("", "birdnet-go-restore-*")
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_temp_directory").
+			Build()
+	}
+	defer m.cleanupTempDirectories([]string{tempDir})
+
+	// 1. Fetch the archive from the target
+	m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageRetrieving, Target: targetName, BackupID: id})
+	archivePath, err := m.fetchArchive(ctx, target, id, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Decrypt the archive if it was encrypted
+	if strings.HasSuffix(archivePath, encryptedArchiveExt) {
+		decryptedPath := strings.TrimSuffix(archivePath, encryptedArchiveExt)
+		m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageDecrypting, Target: targetName, BackupID: id})
+		if err := m.decryptArchive(ctx, archivePath, decryptedPath); err != nil {
+			return nil, fmt.Errorf("failed to decrypt archive: %w", err)
+		}
+		if err := os.Remove(archivePath); err != nil {
+			m.logger.Warn("Failed to remove encrypted archive after decryption", "path", archivePath, "error", err)
+		}
+		archivePath = decryptedPath
+	}
+
+	// 3. Extract metadata, config and backup data
+	m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageVerifying, Target: targetName, BackupID: id})
+	contents, err := m.extractArchive(ctx, archivePath, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	// 4. Verify that the archive is what we asked for and has not been tampered with
+	if err := verifyArchiveContents(id, contents); err != nil {
+		return nil, err
+	}
+
+	result = &RestoreResult{
+		BackupID: contents.metadata.ID,
+		Target:   targetName,
+		Source:   contents.metadata.Source,
+		Metadata: *contents.metadata,
+	}
+
+	if currentHash, err := m.hashConfig(); err != nil {
+		m.logger.Warn("Failed to hash current configuration", "error", err)
+	} else if contents.metadata.ConfigHash != "" && currentHash != contents.metadata.ConfigHash {
+		result.ConfigChanged = true
+		m.logger.Info("Configuration has changed since the backup was created",
+			"backup_id", contents.metadata.ID)
+	}
+
+	// 5. Hand the data over to the source specific restorer
+	restorer, err := m.findRestorer(contents.metadata.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategorySystem).
+			Context("operation", "restore_backup").
+			Context("error_type", "cancelled").
+			Build()
+	}
+
+	m.emitProgress(ProgressEvent{Operation: OperationRestore, Stage: StageRestoring, Source: result.Source, Target: targetName, BackupID: result.BackupID})
+	previousPath, err := restorer.Restore(ctx, contents.dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore source %s: %w", restorer.Name(), err)
+	}
+	result.PreviousDataPath = previousPath
+	result.Duration = time.Since(start)
+
+	m.logger.Info("Restore completed successfully",
+		"backup_id", result.BackupID,
+		"target_name", targetName,
+		"source", result.Source,
+		"previous_data_path", previousPath,
+		"duration_ms", result.Duration.Milliseconds())
+
+	return result, nil
+}
+
+// RetrieveBackup downloads the archive of a backup from the named target into destDir
+// as stored, without decrypting or verifying it, and returns the path of the archive.
+// The id may be either the backup ID or the archive file name.
+func (m *Manager) RetrieveBackup(ctx context.Context, id, targetName, destDir string) (string, error) {
+	if err := validateBackupID(id); err != nil {
+		return "", err
+	}
+
+	target, err := m.getTarget(targetName)
+	if err != nil {
+		return "", err
+	}
+
+	return m.fetchArchive(ctx, target, id, destDir)
+}
+
+// getTarget returns the registered target with the given name
+func (m *Manager) getTarget(name string) (Target, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	target, ok := m.targets[name]
+	if !ok {
+		return nil, NewError(ErrNotFound, fmt.Sprintf("backup target %q is not registered", name), nil)
+	}
+	return target, nil
+}
+
+// validateBackupID rejects IDs that could escape the target storage location
+func validateBackupID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return NewError(ErrValidation, fmt.Sprintf("invalid backup id %q", id), nil)
+	}
+	return nil
+}
+
+// archiveFileName returns the name of the archive file created for a backup
+func archiveFileName(metadata *Metadata) string {
+	name := metadata.ID + archiveExt
+	if metadata.Encrypted {
+		name += encryptedArchiveExt
+	}
+	return name
+}
+
+// archiveCandidates returns the archive file names that may hold the given backup.
+// Not all targets report the backup ID in their listings, so when the ID is not listed
+// both the plain and encrypted archive names are tried.
+func (m *Manager) archiveCandidates(ctx context.Context, target Target, id string) []string {
+	if strings.HasSuffix(id, archiveExt) || strings.HasSuffix(id, archiveExt+encryptedArchiveExt) {
+		return []string{id}
+	}
+
+	backups, err := target.List(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to list backups in target, trying default archive names",
+			"target_name", target.Name(),
+			"error", err)
+	}
+	for i := range backups {
+		if backups[i].ID == id {
+			return []string{archiveFileName(&backups[i].Metadata)}
+		}
+	}
+
+	return []string{id + archiveExt + encryptedArchiveExt, id + archiveExt}
+}
+
+// fetchArchive downloads the archive for the given backup into destDir
+func (m *Manager) fetchArchive(ctx context.Context, target Target, id, destDir string) (string, error) {
+	for _, name := range m.archiveCandidates(ctx, target, id) {
+		destPath := filepath.Join(destDir, name)
+		m.logger.Debug("Retrieving archive from target", "target_name", target.Name(), "archive", name)
+
+		err := target.Retrieve(ctx, name, destPath)
+		if err == nil {
+			return destPath, nil
+		}
+		if IsErrorCode(err, ErrNotFound) {
+			continue
+		}
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "retrieve_archive").
+			Context("target", target.Name()).
+			Context("archive", name).
+			Build()
+	}
+
+	return "", NewError(ErrNotFound, fmt.Sprintf("backup %s not found in target %s", id, target.Name()), nil)
+}
+
+// decryptArchive decrypts an archive created by encryptArchive
+func (m *Manager) decryptArchive(ctx context.Context, sourcePath, destPath string) error {
+	start := time.Now()
+
+	secureOp := NewSecureFileOp("backup")
+	ciphertext, cleanSourcePath, err := secureOp.SecureReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Debug("Decrypting archive", "source", cleanSourcePath, "destination", destPath)
+
+	key, err := m.loadEncryptionKey()
+	if err != nil {
+		return fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	plaintext, err := decryptData(ciphertext, key)
+	if err != nil {
+		return fmt.Errorf("failed during data decryption, the archive may have been created with a different key: %w", err)
+	}
+
+	if err := os.WriteFile(destPath, plaintext, 0o600); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_decrypted_archive").
+			Context("dest_path", destPath).
+			Build()
+	}
+
+	m.logger.Debug("Decryption successful",
+		"source", sourcePath,
+		"destination", destPath,
+		"duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// extractArchive reads the tar archive created by createArchive and writes the backup
+// data to a file in destDir. Only the expected top-level entries are accepted.
+func (m *Manager) extractArchive(ctx context.Context, archivePath, destDir string) (*archiveContents, error) {
+	secureOp := NewSecureFileOp("backup")
+	archiveFile, cleanPath, err := secureOp.SecureOpen(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := archiveFile.Close(); err != nil {
+			m.logger.Warn("Failed to close archive file", "archive_path", cleanPath, "error", err)
+		}
+	}()
+
+	contents := &archiveContents{}
+	tr := tar.NewReader(archiveFile)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategorySystem).
+				Context("operation", "extract_archive").
+				Context("error_type", "cancelled").
+				Build()
+		}
+
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "read_tar_header").
+				Build()
+		}
+
+		if hdr.Typeflag != tar.TypeReg || hdr.Name != filepath.Base(hdr.Name) {
+			return nil, errors.Newf("unexpected entry in backup archive: %s", hdr.Name).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "extract_archive").
+				Build()
+		}
+
+		switch {
+		case hdr.Name == archiveMetadataName:
+			data, err := readArchiveEntry(tr, hdr, maxArchiveMetadataLen)
+			if err != nil {
+				return nil, err
+			}
+			var metadata Metadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				return nil, errors.New(err).
+					Component("backup").
+					Category(errors.CategoryValidation).
+					Context("operation", "parse_archive_metadata").
+					Build()
+			}
+			contents.metadata = &metadata
+		case hdr.Name == archiveConfigName:
+			data, err := readArchiveEntry(tr, hdr, maxArchiveConfigLen)
+			if err != nil {
+				return nil, err
+			}
+			contents.configData = data
+		case strings.HasPrefix(hdr.Name, archiveDataPrefix):
+			if contents.dataPath != "" {
+				return nil, errors.Newf("backup archive contains more than one data file").
+					Component("backup").
+					Category(errors.CategoryValidation).
+					Context("operation", "extract_archive").
+					Build()
+			}
+			dataPath := filepath.Join(destDir, hdr.Name)
+			if err := m.extractArchiveData(tr, dataPath); err != nil {
+				return nil, err
+			}
+			contents.dataPath = dataPath
+		default:
+			m.logger.Warn("Ignoring unknown entry in backup archive", "name", hdr.Name)
+		}
+	}
+
+	if contents.metadata == nil || contents.dataPath == "" {
+		return nil, errors.Newf("backup archive is incomplete: metadata or backup data missing").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "extract_archive").
+			Build()
+	}
+
+	return contents, nil
+}
+
+// readArchiveEntry reads a small archive entry into memory enforcing a size limit
+func readArchiveEntry(r io.Reader, hdr *tar.Header, maxSize int64) ([]byte, error) {
+	if hdr.Size > maxSize {
+		return nil, errors.Newf("archive entry %s too large: %d bytes (max %d bytes)", hdr.Name, hdr.Size, maxSize).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "read_archive_entry").
+			Build()
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(r, maxSize)); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_archive_entry").
+			Context("name", hdr.Name).
+			Build()
+	}
+	return buf.Bytes(), nil
+}
+
+// extractArchiveData streams the backup data entry to dataPath
+func (m *Manager) extractArchiveData(r io.Reader, dataPath string) error {
+	secureOp := NewSecureFileOp("backup")
+	dataFile, cleanPath, err := secureOp.SecureCreate(dataPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dataFile.Close(); err != nil {
+			m.logger.Debug("Failed to close extracted data file", "path", cleanPath, "error", err)
+		}
+	}()
+
+	// #nosec G110 -- archive is produced by createArchive and is not compressed
+	if _, err := io.Copy(dataFile, r); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "extract_backup_data").
+			Context("path", cleanPath).
+			Build()
+	}
+
+	if err := dataFile.Sync(); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "sync_backup_data").
+			Context("path", cleanPath).
+			Build()
+	}
+	return nil
+}
+
+// verifyArchiveContents checks the extracted metadata against the requested backup
+// and verifies the embedded configuration against the recorded config hash
+func verifyArchiveContents(id string, contents *archiveContents) error {
+	metadata := contents.metadata
+
+	if metadata.ID == "" || (metadata.ID != id && !strings.HasPrefix(id, metadata.ID+".")) {
+		return errors.Newf("backup metadata mismatch: requested %s, archive contains %s", id, metadata.ID).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_archive_metadata").
+			Build()
+	}
+
+	if metadata.Version > currentMetadataFormat {
+		return errors.Newf("unsupported backup metadata version %d", metadata.Version).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_archive_metadata").
+			Context("version", metadata.Version).
+			Build()
+	}
+
+	if metadata.Source == "" {
+		return errors.Newf("backup metadata does not name a source").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_archive_metadata").
+			Build()
+	}
+
+	if metadata.ConfigHash != "" {
+		if contents.configData == nil {
+			return errors.Newf("backup archive is missing %s", archiveConfigName).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "verify_config_hash").
+				Build()
+		}
+		hash := sha256.Sum256(contents.configData)
+		if hex.EncodeToString(hash[:]) != metadata.ConfigHash {
+			return errors.Newf("config hash mismatch, backup archive may be corrupted").
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "verify_config_hash").
+				Context("backup_id", metadata.ID).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// findRestorer returns the restorer registered for the given source. A backup is
+// only restored by the restorer of the source that created it.
+func (m *Manager) findRestorer(source string) (Restorer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if restorer, ok := m.restorers[source]; ok {
+		return restorer, nil
+	}
+
+	return nil, NewError(ErrValidation, fmt.Sprintf("no restorer registered for backup source %q", source), nil)
+}
\ No newline at end of file
@@ -0,0 +1,307 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// memoryTarget is an in-memory backup target
+type memoryTarget struct {
+	mu       sync.Mutex
+	files    map[string][]byte
+	metadata map[string]Metadata
+}
+
+func newMemoryTarget() *memoryTarget {
+	return &memoryTarget{
+		files:    make(map[string][]byte),
+		metadata: make(map[string]Metadata),
+	}
+}
+
+func (t *memoryTarget) Name() string { return "memory" }
+
+func (t *memoryTarget) Store(ctx context.Context, sourcePath string, metadata *Metadata) error {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	name := filepath.Base(sourcePath)
+	t.files[name] = data
+	t.metadata[name] = *metadata
+	return nil
+}
+
+func (t *memoryTarget) List(ctx context.Context) ([]BackupInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	backups := make([]BackupInfo, 0, len(t.metadata))
+	for _, md := range t.metadata {
+		backups = append(backups, BackupInfo{Metadata: md, Target: t.Name()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID < backups[j].ID })
+	return backups, nil
+}
+
+func (t *memoryTarget) Delete(ctx context.Context, id string) error { return nil }
+
+func (t *memoryTarget) Retrieve(ctx context.Context, name, destPath string) error {
+	t.mu.Lock()
+	data, ok := t.files[name]
+	t.mu.Unlock()
+	if !ok {
+		return NewError(ErrNotFound, "backup file not found", nil)
+	}
+	return os.WriteFile(destPath, data, 0o600)
+}
+
+func (t *memoryTarget) Validate() error { return nil }
+
+// put stores a raw archive under the given name
+func (t *memoryTarget) put(name string, data []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.files[name] = data
+}
+
+// staticSource is a backup source returning fixed data
+type staticSource struct {
+	name string
+	data []byte
+}
+
+func (s *staticSource) Name() string { return s.name }
+
+func (s *staticSource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+func (s *staticSource) Validate() error { return nil }
+
+// recordingRestorer records the data it was asked to restore
+type recordingRestorer struct {
+	name     string
+	restored []byte
+	calls    int
+}
+
+func (r *recordingRestorer) Name() string { return r.name }
+
+func (r *recordingRestorer) Restore(ctx context.Context, dataPath string) (string, error) {
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return "", err
+	}
+	r.calls++
+	r.restored = data
+	return "/data/" + r.name + ".db.pre-restore", nil
+}
+
+// newRestoreTestManager creates a manager with a memory target and a recording restorer.
+// HOME is redirected so state and encryption keys stay inside the test directory.
+func newRestoreTestManager(t *testing.T, encrypt bool) (*Manager, *memoryTarget, *recordingRestorer) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	settings := &conf.Settings{}
+	settings.Backup.Enabled = true
+	settings.Backup.Encryption = encrypt
+	settings.Backup.SanitizeConfig = true
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stateManager, err := NewStateManager(logger)
+	require.NoError(t, err)
+	manager, err := NewManager(settings, logger, stateManager, "test")
+	require.NoError(t, err)
+
+	target := newMemoryTarget()
+	require.NoError(t, manager.RegisterTarget(target))
+
+	restorer := &recordingRestorer{name: "birdnet"}
+	manager.RegisterRestorer(restorer)
+
+	return manager, target, restorer
+}
+
+// buildTestArchive creates a tar archive from the given entries in order
+func buildTestArchive(t *testing.T, entries map[string][]byte, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range order {
+		data := entries[name]
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(data)),
+		}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func testMetadataJSON(t *testing.T, md *Metadata) []byte {
+	t.Helper()
+	data, err := json.Marshal(md)
+	require.NoError(t, err)
+	return data
+}
+
+func TestRestore_RoundTrip(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		t.Run(fmt.Sprintf("encrypted=%v", encrypt), func(t *testing.T) {
+			manager, target, restorer := newRestoreTestManager(t, encrypt)
+			data := []byte("SQLite format 3\x00 test database contents")
+			require.NoError(t, manager.RegisterSource(&staticSource{name: "birdnet", data: data}))
+
+			require.NoError(t, manager.RunBackup(context.Background()))
+
+			backups, err := target.List(context.Background())
+			require.NoError(t, err)
+			require.Len(t, backups, 1)
+			assert.Equal(t, encrypt, backups[0].Encrypted)
+
+			result, err := manager.Restore(context.Background(), backups[0].ID, target.Name())
+			require.NoError(t, err)
+
+			assert.Equal(t, data, restorer.restored)
+			assert.Equal(t, backups[0].ID, result.BackupID)
+			assert.Equal(t, "birdnet", result.Source)
+			assert.Equal(t, "memory", result.Target)
+			assert.Equal(t, "/data/birdnet.db.pre-restore", result.PreviousDataPath)
+			assert.False(t, result.ConfigChanged)
+		})
+	}
+}
+
+func TestRestore_ByArchiveNameWithoutListing(t *testing.T) {
+	manager, target, restorer := newRestoreTestManager(t, false)
+
+	data := []byte("database")
+	md := &Metadata{Version: 1, ID: "birdnet-20240102-030405", Source: "birdnet", Timestamp: time.Now()}
+	archive := buildTestArchive(t, map[string][]byte{
+		archiveMetadataName: testMetadataJSON(t, md),
+		"backup.birdnet":    data,
+	}, []string{archiveMetadataName, "backup.birdnet"})
+	// Stored without metadata, like targets whose listings do not carry backup IDs
+	target.put(md.ID+archiveExt, archive)
+
+	_, err := manager.Restore(context.Background(), md.ID, target.Name())
+	require.NoError(t, err)
+	assert.Equal(t, data, restorer.restored)
+}
+
+func TestRestore_RejectsInvalidArchives(t *testing.T) {
+	const id = "birdnet-20240102-030405"
+	config := []byte("main:\n  name: test\n")
+
+	tests := []struct {
+		name    string
+		entries map[string][]byte
+		order   []string
+		want    string
+	}{
+		{
+			name: "tampered config",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 1, ID: id, Source: "birdnet", ConfigHash: "deadbeef"}),
+				archiveConfigName:   config,
+				"backup.birdnet":    []byte("db"),
+			},
+			order: []string{archiveMetadataName, archiveConfigName, "backup.birdnet"},
+			want:  "config hash mismatch",
+		},
+		{
+			name: "mismatched id",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 1, ID: "birdnet-20230101-000000", Source: "birdnet"}),
+				"backup.birdnet":    []byte("db"),
+			},
+			order: []string{archiveMetadataName, "backup.birdnet"},
+			want:  "metadata mismatch",
+		},
+		{
+			name: "path traversal entry",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 1, ID: id, Source: "birdnet"}),
+				"../backup.birdnet": []byte("db"),
+			},
+			order: []string{archiveMetadataName, "../backup.birdnet"},
+			want:  "unexpected entry",
+		},
+		{
+			name: "missing data",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 1, ID: id, Source: "birdnet"}),
+			},
+			order: []string{archiveMetadataName},
+			want:  "incomplete",
+		},
+		{
+			name: "future metadata version",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 99, ID: id, Source: "birdnet"}),
+				"backup.birdnet":    []byte("db"),
+			},
+			order: []string{archiveMetadataName, "backup.birdnet"},
+			want:  "unsupported backup metadata version",
+		},
+		{
+			name: "unknown source",
+			entries: map[string][]byte{
+				archiveMetadataName: testMetadataJSON(t, &Metadata{Version: 1, ID: id, Source: "renamed"}),
+				"backup.renamed":    []byte("db"),
+			},
+			order: []string{archiveMetadataName, "backup.renamed"},
+			want:  "no restorer registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, target, restorer := newRestoreTestManager(t, false)
+			target.put(id+archiveExt, buildTestArchive(t, tt.entries, tt.order))
+
+			_, err := manager.Restore(context.Background(), id, target.Name())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.Zero(t, restorer.calls, "restorer must not run for an invalid archive")
+		})
+	}
+}
+
+func TestRestore_Errors(t *testing.T) {
+	manager, target, _ := newRestoreTestManager(t, false)
+
+	_, err := manager.Restore(context.Background(), "birdnet-20240102-030405", target.Name())
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrNotFound), "missing backup should be reported as not found: %v", err)
+
+	_, err = manager.Restore(context.Background(), "../etc/passwd", target.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid backup id")
+	assert.True(t, IsErrorCode(err, ErrValidation), "invalid ids are validation errors: %v", err)
+
+	_, err = manager.Restore(context.Background(), "birdnet-20240102-030405", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not registered")
+}
@@ -123,6 +123,16 @@ func (s *Scheduler) IsRunning() bool {
 	return s.isRunning
 }
 
+// GetSchedules returns a copy of the configured backup schedules
+func (s *Scheduler) GetSchedules() []BackupSchedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := make([]BackupSchedule, len(s.schedules))
+	copy(schedules, s.schedules)
+	return schedules
+}
+
 // GetMissedRuns returns a list of missed backup times
 func (s *Scheduler) GetMissedRuns() []time.Time {
 	missed := s.state.GetMissedBackups()
@@ -0,0 +1,574 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	defaultMySQLPort       = "3306"
+	mysqlConnectTimeout    = 10 * time.Second
+	mysqlMaxInsertBytes    = 1024 * 1024 // Flush extended INSERT statements at about 1 MiB, like mysqldump
+	mysqlDumpBufferSize    = 64 * 1024
+	mysqlDumpFormatVersion = 1
+)
+
+// mysqlBackupTables lists the tables included in a MySQL backup. Parent tables
+// come first so the dump also loads cleanly with foreign key checks enabled.
+var mysqlBackupTables = []string{
+	"daily_events",
+	"hourly_weather",
+	"notes",
+	"results",
+	"note_reviews",
+	"note_comments",
+	"note_locks",
+	"image_caches",
+	"sound_levels",
+	"users",
+	"embeddings",
+}
+
+// mysqlTableNameRegex matches table names that are safe to quote with backticks
+var mysqlTableNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{1,64}$`)
+
+// columnKind determines how a column value is written to the dump
+type columnKind int
+
+const (
+	columnKindString columnKind = iota
+	columnKindNumeric
+	columnKindBinary
+)
+
+// MySQLSource implements the backup.Source interface for MySQL databases.
+// It writes a logical SQL dump taken from a single consistent snapshot.
+type MySQLSource struct {
+	config *conf.Settings
+	logger *slog.Logger
+}
+
+// NewMySQLSource creates a new MySQL backup source
+func NewMySQLSource(config *conf.Settings, logger *slog.Logger) *MySQLSource {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MySQLSource{
+		config: config,
+		logger: logger.With("backup_source", "mysql"),
+	}
+}
+
+// Name returns the name of this source
+func (s *MySQLSource) Name() string {
+	if name := s.config.Output.MySQL.Database; name != "" {
+		return name
+	}
+	return "mysql"
+}
+
+// Validate checks if the source configuration is valid and the database is reachable
+func (s *MySQLSource) Validate() error {
+	dsn, err := s.validateConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mysqlConnectTimeout)
+	defer cancel()
+
+	db, err := s.openDatabase(ctx, dsn)
+	if err != nil {
+		s.logger.Error("MySQL source validation failed", "error", err)
+		return err
+	}
+	if err := db.Close(); err != nil {
+		s.logger.Debug("Failed to close MySQL connection", "error", err)
+	}
+
+	s.logger.Info("MySQL source validation successful", "database", s.config.Output.MySQL.Database)
+	return nil
+}
+
+// Backup streams a logical dump of the BirdNET-Go tables. All tables are read
+// inside one read-only transaction with a consistent snapshot, so the dump
+// reflects a single point in time without locking the tables for writers.
+func (s *MySQLSource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	start := time.Now()
+	s.logger.Info("Starting MySQL streaming backup operation")
+
+	dsn, err := s.validateConfig()
+	if err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	db, err := s.openDatabase(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer func() {
+			if err := db.Close(); err != nil {
+				s.logger.Debug("Failed to close MySQL connection", "error", err)
+			}
+		}()
+
+		backupErr := s.dumpDatabase(ctx, db, pw)
+		if backupErr != nil {
+			s.logger.Error("MySQL backup failed in goroutine", "error", backupErr, "duration_ms", time.Since(start).Milliseconds())
+			if closeErr := pw.CloseWithError(backupErr); closeErr != nil {
+				s.logger.Warn("Error closing pipe writer with error", "error", closeErr)
+			}
+			return
+		}
+
+		s.logger.Info("MySQL backup completed successfully", "duration_ms", time.Since(start).Milliseconds())
+		if err := pw.Close(); err != nil {
+			s.logger.Warn("Error closing pipe writer in goroutine", "error", err)
+		}
+	}()
+
+	return pr, nil
+}
+
+// validateConfig checks if MySQL output is enabled and returns the connection DSN
+func (s *MySQLSource) validateConfig() (string, error) {
+	cfg := s.config.Output.MySQL
+	if !cfg.Enabled {
+		return "", errors.Newf("mysql is not enabled").
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "validate_config").
+			Build()
+	}
+
+	var missing []string
+	if cfg.Host == "" {
+		missing = append(missing, "host")
+	}
+	if cfg.Username == "" {
+		missing = append(missing, "username")
+	}
+	if cfg.Database == "" {
+		missing = append(missing, "database")
+	}
+	if len(missing) > 0 {
+		return "", errors.Newf("mysql configuration is incomplete, missing: %s", strings.Join(missing, ", ")).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "validate_config").
+			Build()
+	}
+
+	port := cfg.Port
+	if port == "" {
+		port = defaultMySQLPort
+	}
+
+	driverConfig := mysql.NewConfig()
+	driverConfig.User = cfg.Username
+	driverConfig.Passwd = cfg.Password
+	driverConfig.Net = "tcp"
+	driverConfig.Addr = net.JoinHostPort(cfg.Host, port)
+	driverConfig.DBName = cfg.Database
+	driverConfig.Timeout = mysqlConnectTimeout
+	// Values are dumped as the server formats them, so keep DATETIME as text
+	driverConfig.ParseTime = false
+	driverConfig.Params = map[string]string{"charset": "utf8mb4"}
+
+	return driverConfig.FormatDSN(), nil
+}
+
+// openDatabase opens and verifies a connection to the MySQL server
+func (s *MySQLSource) openDatabase(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "open_database").
+			Context("db_type", "mysql").
+			Build()
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "verify_database_connection").
+			Context("db_type", "mysql").
+			Context("host", s.config.Output.MySQL.Host).
+			Build()
+	}
+
+	return db, nil
+}
+
+// dumpDatabase writes the dump of all backup tables to w from a consistent snapshot
+func (s *MySQLSource) dumpDatabase(ctx context.Context, db *sql.DB, w io.Writer) error {
+	// The snapshot is bound to a session, so every statement must use the same connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return s.databaseError(err, "acquire_connection")
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			s.logger.Debug("Failed to release MySQL connection", "error", err)
+		}
+	}()
+
+	sessionSetup := []string{
+		"SET SESSION time_zone = '+00:00'",
+		"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY",
+	}
+	for _, stmt := range sessionSetup {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return s.databaseError(err, "start_snapshot")
+		}
+	}
+	defer func() {
+		// Read-only transaction, rollback simply releases the snapshot
+		if _, err := conn.ExecContext(context.Background(), "ROLLBACK"); err != nil {
+			s.logger.Debug("Failed to end snapshot transaction", "error", err)
+		}
+	}()
+
+	tables, err := s.existingTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriterSize(w, mysqlDumpBufferSize)
+	dw := &mysqlDumpWriter{w: bw, maxInsertBytes: mysqlMaxInsertBytes}
+
+	dw.writeHeader(s.config.Output.MySQL.Database, time.Now().UTC())
+	for _, table := range tables {
+		if err := ctx.Err(); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryCancellation).
+				Context("operation", "dump_database").
+				Build()
+		}
+
+		tableStart := time.Now()
+		rowCount, err := s.dumpTable(ctx, conn, dw, table)
+		if err != nil {
+			return err
+		}
+		s.logger.Debug("Dumped table", "table", table, "rows", rowCount, "duration_ms", time.Since(tableStart).Milliseconds())
+	}
+	dw.writeFooter()
+
+	if dw.err != nil {
+		return s.writeError(dw.err)
+	}
+	if err := bw.Flush(); err != nil {
+		return s.writeError(err)
+	}
+	return nil
+}
+
+// existingTables returns the backup tables that exist in the database, in dump order
+func (s *MySQLSource) existingTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx,
+		"SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'")
+	if err != nil {
+		return nil, s.databaseError(err, "list_tables")
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			s.logger.Debug("Failed to close rows", "error", err)
+		}
+	}()
+
+	present := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, s.databaseError(err, "list_tables")
+		}
+		present[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.databaseError(err, "list_tables")
+	}
+
+	tables := make([]string, 0, len(mysqlBackupTables))
+	for _, table := range mysqlBackupTables {
+		if !present[table] {
+			s.logger.Warn("Table not found in database, skipping", "table", table)
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	if len(tables) == 0 {
+		return nil, errors.Newf("no BirdNET-Go tables found in database %s", s.config.Output.MySQL.Database).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "list_tables").
+			Build()
+	}
+
+	return tables, nil
+}
+
+// dumpTable writes the schema and all rows of a table and returns the number of rows
+func (s *MySQLSource) dumpTable(ctx context.Context, conn *sql.Conn, dw *mysqlDumpWriter, table string) (int64, error) {
+	if !mysqlTableNameRegex.MatchString(table) {
+		return 0, errors.Newf("invalid table name: %s", table).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "dump_table").
+			Build()
+	}
+
+	var name, createStmt string
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE TABLE `%s`", table)).Scan(&name, &createStmt); err != nil {
+		return 0, s.databaseError(err, "show_create_table")
+	}
+	dw.writeTableSchema(table, createStmt)
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT * FROM `%s`", table)) // #nosec G201 -- table name is validated above
+	if err != nil {
+		return 0, s.databaseError(err, "select_rows")
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			s.logger.Debug("Failed to close rows", "table", table, "error", err)
+		}
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, s.databaseError(err, "column_types")
+	}
+	columns := make([]string, len(columnTypes))
+	kinds := make([]columnKind, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+		kinds[i] = mysqlColumnKind(ct.DatabaseTypeName())
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	dw.beginTableData(table, columns)
+	var rowCount int64
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return rowCount, s.databaseError(err, "scan_row")
+		}
+		dw.writeRow(values, kinds)
+		if dw.err != nil {
+			return rowCount, s.writeError(dw.err)
+		}
+		rowCount++
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, s.databaseError(err, "read_rows")
+	}
+	dw.endTableData(table)
+
+	return rowCount, nil
+}
+
+// databaseError wraps a MySQL error with backup context
+func (s *MySQLSource) databaseError(err error, operation string) error {
+	return errors.New(err).
+		Component("backup").
+		Category(errors.CategoryDatabase).
+		Context("operation", operation).
+		Context("db_type", "mysql").
+		Build()
+}
+
+// writeError wraps an error writing the dump stream
+func (s *MySQLSource) writeError(err error) error {
+	return errors.New(err).
+		Component("backup").
+		Category(errors.CategoryFileIO).
+		Context("operation", "write_dump").
+		Context("db_type", "mysql").
+		Build()
+}
+
+// mysqlColumnKind maps a driver column type name to the way its values are written
+func mysqlColumnKind(typeName string) columnKind {
+	switch strings.TrimPrefix(strings.ToUpper(typeName), "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT",
+		"DECIMAL", "FLOAT", "DOUBLE", "YEAR":
+		return columnKindNumeric
+	case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+		return columnKindBinary
+	default:
+		return columnKindString
+	}
+}
+
+// mysqlDumpWriter writes a mysqldump compatible SQL script. The first write
+// error is kept in err and all later writes are skipped.
+type mysqlDumpWriter struct {
+	w              io.Writer
+	err            error
+	maxInsertBytes int
+
+	insertPrefix string
+	statementLen int // Bytes written for the current INSERT statement, 0 if none is open
+}
+
+func (d *mysqlDumpWriter) write(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.WriteString(d.w, s)
+}
+
+// writeHeader writes the session settings needed to load the dump
+func (d *mysqlDumpWriter) writeHeader(database string, created time.Time) {
+	d.write(fmt.Sprintf("-- BirdNET-Go MySQL dump (format %d)\n", mysqlDumpFormatVersion))
+	d.write(fmt.Sprintf("-- Database: %s\n", database))
+	d.write(fmt.Sprintf("-- Created: %s\n\n", created.Format(time.RFC3339)))
+	d.write("SET NAMES utf8mb4;\n")
+	d.write("SET TIME_ZONE='+00:00';\n")
+	d.write("SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;\n")
+	d.write("SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;\n")
+	d.write("SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO';\n\n")
+}
+
+// writeFooter restores the session settings changed by the header
+func (d *mysqlDumpWriter) writeFooter() {
+	d.write("SET SQL_MODE=@OLD_SQL_MODE;\n")
+	d.write("SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;\n")
+	d.write("SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;\n")
+	d.write("\n-- Dump completed\n")
+}
+
+// writeTableSchema writes statements recreating the table
+func (d *mysqlDumpWriter) writeTableSchema(table, createStmt string) {
+	d.write(fmt.Sprintf("--\n-- Table structure for table `%s`\n--\n\n", table))
+	d.write(fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n", table))
+	d.write(createStmt)
+	d.write(";\n\n")
+}
+
+// beginTableData starts the data section of a table
+func (d *mysqlDumpWriter) beginTableData(table string, columns []string) {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = "`" + strings.ReplaceAll(c, "`", "``") + "`"
+	}
+	d.insertPrefix = fmt.Sprintf("INSERT INTO `%s` (%s) VALUES ", table, strings.Join(quoted, ","))
+	d.statementLen = 0
+
+	d.write(fmt.Sprintf("--\n-- Dumping data for table `%s`\n--\n\n", table))
+	d.write(fmt.Sprintf("LOCK TABLES `%s` WRITE;\n", table))
+	d.write(fmt.Sprintf("ALTER TABLE `%s` DISABLE KEYS;\n", table))
+}
+
+// writeRow appends a row to the current extended INSERT statement
+func (d *mysqlDumpWriter) writeRow(values []sql.RawBytes, kinds []columnKind) {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(formatMySQLValue(v, kinds[i]))
+	}
+	b.WriteByte(')')
+	tuple := b.String()
+
+	if d.statementLen > 0 && d.statementLen+len(tuple)+1 > d.maxInsertBytes {
+		d.write(";\n")
+		d.statementLen = 0
+	}
+	if d.statementLen == 0 {
+		d.write(d.insertPrefix)
+		d.statementLen = len(d.insertPrefix)
+	} else {
+		d.write(",")
+		d.statementLen++
+	}
+	d.write(tuple)
+	d.statementLen += len(tuple)
+}
+
+// endTableData closes the data section of a table
+func (d *mysqlDumpWriter) endTableData(table string) {
+	if d.statementLen > 0 {
+		d.write(";\n")
+		d.statementLen = 0
+	}
+	d.write(fmt.Sprintf("ALTER TABLE `%s` ENABLE KEYS;\n", table))
+	d.write("UNLOCK TABLES;\n\n")
+}
+
+// formatMySQLValue returns the SQL literal for a raw column value
+func formatMySQLValue(v sql.RawBytes, kind columnKind) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch kind {
+	case columnKindNumeric:
+		return string(v)
+	case columnKindBinary:
+		if len(v) == 0 {
+			return "''"
+		}
+		return "0x" + hex.EncodeToString(v)
+	default:
+		return quoteMySQLString(v)
+	}
+}
+
+// quoteMySQLString quotes and escapes a string the same way mysql_real_escape_string does
+func quoteMySQLString(v []byte) string {
+	var b strings.Builder
+	b.Grow(len(v) + 2)
+	b.WriteByte('\'')
+	for _, c := range v {
+		switch c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package sources
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestFormatMySQLValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value sql.RawBytes
+		kind  columnKind
+		want  string
+	}{
+		{"null", nil, columnKindString, "NULL"},
+		{"null numeric", nil, columnKindNumeric, "NULL"},
+		{"numeric", sql.RawBytes("0.8123"), columnKindNumeric, "0.8123"},
+		{"empty string", sql.RawBytes(""), columnKindString, "''"},
+		{"datetime", sql.RawBytes("2024-05-01 06:30:00"), columnKindString, "'2024-05-01 06:30:00'"},
+		{"quotes", sql.RawBytes(`it's "odd"`), columnKindString, `'it\'s \"odd\"'`},
+		{"control characters", sql.RawBytes("a\x00b\nc\rd\\e\x1a"), columnKindString, `'a\0b\nc\rd\\e\Z'`},
+		{"utf8", sql.RawBytes("Mésange bleue"), columnKindString, "'Mésange bleue'"},
+		{"binary", sql.RawBytes{0x00, 0xff, 0x10}, columnKindBinary, "0x00ff10"},
+		{"empty binary", sql.RawBytes{}, columnKindBinary, "''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, formatMySQLValue(tt.value, tt.kind))
+		})
+	}
+}
+
+func TestMySQLColumnKind(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, columnKindNumeric, mysqlColumnKind("BIGINT"))
+	assert.Equal(t, columnKindNumeric, mysqlColumnKind("UNSIGNED INT"))
+	assert.Equal(t, columnKindNumeric, mysqlColumnKind("double"))
+	assert.Equal(t, columnKindBinary, mysqlColumnKind("LONGBLOB"))
+	assert.Equal(t, columnKindBinary, mysqlColumnKind("VARBINARY"))
+	assert.Equal(t, columnKindString, mysqlColumnKind("VARCHAR"))
+	assert.Equal(t, columnKindString, mysqlColumnKind("DATETIME"))
+	assert.Equal(t, columnKindString, mysqlColumnKind("JSON"))
+}
+
+func TestMySQLDumpWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	dw := &mysqlDumpWriter{w: &buf, maxInsertBytes: 80}
+
+	dw.writeHeader("birdnet", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	dw.writeTableSchema("notes", "CREATE TABLE `notes` (`id` bigint NOT NULL, `common_name` longtext)")
+	dw.beginTableData("notes", []string{"id", "common_name"})
+	kinds := []columnKind{columnKindNumeric, columnKindString}
+	for _, row := range [][]sql.RawBytes{
+		{sql.RawBytes("1"), sql.RawBytes("Eurasian Wren")},
+		{sql.RawBytes("2"), sql.RawBytes("Common Blackbird")},
+		{sql.RawBytes("3"), nil},
+	} {
+		dw.writeRow(row, kinds)
+	}
+	dw.endTableData("notes")
+	dw.beginTableData("results", []string{"id"})
+	dw.endTableData("results")
+	dw.writeFooter()
+	require.NoError(t, dw.err)
+
+	dump := buf.String()
+	assert.Contains(t, dump, "SET NAMES utf8mb4;")
+	assert.Contains(t, dump, "DROP TABLE IF EXISTS `notes`;\nCREATE TABLE `notes`")
+	// The small statement limit splits the rows over two INSERT statements
+	assert.Contains(t, dump, "INSERT INTO `notes` (`id`,`common_name`) VALUES (1,'Eurasian Wren');\n")
+	assert.Contains(t, dump, "INSERT INTO `notes` (`id`,`common_name`) VALUES (2,'Common Blackbird'),(3,NULL);\n")
+	assert.Equal(t, 2, strings.Count(dump, "INSERT INTO"), "empty tables must not produce INSERT statements")
+	assert.Contains(t, dump, "SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;")
+	assert.True(t, strings.HasSuffix(dump, "-- Dump completed\n"))
+}
+
+func TestMySQLSourceValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	source := NewMySQLSource(settings, nil)
+
+	_, err := source.validateConfig()
+	require.Error(t, err, "disabled MySQL output must be rejected")
+
+	settings.Output.MySQL.Enabled = true
+	settings.Output.MySQL.Username = "birdnet"
+	_, err = source.validateConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "host")
+	assert.Contains(t, err.Error(), "database")
+
+	settings.Output.MySQL.Host = "db.local"
+	settings.Output.MySQL.Database = "birdnet"
+	settings.Output.MySQL.Password = "p@ss:word/"
+	dsn, err := source.validateConfig()
+	require.NoError(t, err)
+
+	parsed, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, "db.local:3306", parsed.Addr)
+	assert.Equal(t, "p@ss:word/", parsed.Passwd)
+	assert.Equal(t, "birdnet", parsed.DBName)
+	assert.False(t, parsed.ParseTime)
+	assert.Equal(t, "birdnet", source.Name())
+}
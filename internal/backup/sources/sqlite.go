@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 type SQLiteSource struct {
 	config *conf.Settings
 	logger *slog.Logger
+	inUse  atomic.Bool // Set while the application holds the database open
 }
 
 // NewSQLiteSource creates a new SQLite backup source
@@ -45,6 +47,13 @@ func (s *SQLiteSource) Name() string {
 	return strings.TrimSuffix(baseName, filepath.Ext(baseName))
 }
 
+// SetInUse marks whether the running application holds the database open. Restore swaps the
+// database files, which open connections and their WAL state would not notice, so while the
+// database is in use it copies the restored pages into the live database instead.
+func (s *SQLiteSource) SetInUse(inUse bool) {
+	s.inUse.Store(inUse)
+}
+
 // DatabaseConnection represents a managed database connection
 type DatabaseConnection struct {
 	db     *sql.DB
@@ -68,13 +77,13 @@ func (dc *DatabaseConnection) Close() error {
 // openDatabase opens a database connection with the given path
 func (s *SQLiteSource) openDatabase(dbPath string, readOnly bool) (*DatabaseConnection, error) {
 	// Build DSN with additional safety parameters
-	dsn := dbPath
+	dsn := dbPath + "?"
 	if readOnly {
-		dsn += "?mode=ro"
+		dsn += "mode=ro&"
 	}
-	dsn += "&_busy_timeout=30000" // 30 second timeout
-	dsn += "&_journal_mode=WAL"   // Ensure WAL mode
-	dsn += "&_sync=NORMAL"        // Less aggressive syncing for better performance
+	dsn += "_busy_timeout=30000" // 30 second timeout
+	dsn += "&_journal_mode=WAL"  // Ensure WAL mode
+	dsn += "&_sync=NORMAL"       // Less aggressive syncing for better performance
 
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
@@ -442,60 +451,12 @@ func (s *SQLiteSource) copyBackupToWriter(tempPath string, w io.Writer) error {
 	return nil
 }
 
-// streamBackupToWriter performs a streaming backup of the SQLite database to the provided writer
-func (s *SQLiteSource) streamBackupToWriter(ctx context.Context, db *sql.DB, w io.Writer) error {
-	start := time.Now()
-	defer func() {
-		s.logger.Debug("Finished streamBackupToWriter", "duration_ms", time.Since(start).Milliseconds())
-	}()
-	s.logger.Debug("Starting streamBackupToWriter")
-
-	// Get database info needed later
-	_, pageCount, _, err := s.getDatabaseInfo(db)
-	if err != nil {
-		return fmt.Errorf("failed to get database info before backup: %w", err)
-	}
-	s.logger.Debug("Retrieved database info for backup", "page_count", pageCount)
-
-	// Create a temporary file for the backup
-	tempFile, err := os.CreateTemp("", "birdnet-go-backup-*.db")
-	if err != nil {
-		return errors.New(err).
-			Component("backup").
-			Category(errors.CategoryFileIO).
-			Context("operation", "create_temp_file").
-			Build()
-	}
-	tempPath := tempFile.Name()
-	s.logger.Debug("Created temporary backup file", "temp_path", tempPath)
-	// Close the handle immediately, it's just needed for the path
-	if errClose := tempFile.Close(); errClose != nil {
-		s.logger.Warn("Failed to close temporary file handle after creation (continuing)", "temp_path", tempPath, "error", errClose)
-	}
-	defer func() {
-		s.logger.Debug("Removing temporary backup file", "temp_path", tempPath)
-		if errRemove := os.Remove(tempPath); errRemove != nil {
-			s.logger.Warn("Failed to remove temporary backup file", "temp_path", tempPath, "error", errRemove)
-		}
-	}()
-
-	// Open the destination database (using the temp path)
-	destDB, err := sql.Open("sqlite3", tempPath+"?_journal_mode=WAL&_sync=OFF") // Turn off sync for backup target
-	if err != nil {
-		return errors.New(err).
-			Component("backup").
-			Category(errors.CategoryDatabase).
-			Context("operation", "open_temp_database").
-			Build()
-	}
-	defer func() {
-		if err := destDB.Close(); err != nil {
-			slog.Debug("Failed to close destination database", "error", err)
-		}
-	}()
-
+// copyDatabase copies every page of srcDB into destDB using the SQLite online
+// backup API. sourcePages is the page count reported by getDatabaseInfo and is
+// used to sanity-check the count reported by the backup connection.
+func (s *SQLiteSource) copyDatabase(ctx context.Context, srcDB, destDB *sql.DB, sourcePages int) error {
 	// Get the SQLite connection objects using the internal driver connection
-	srcConn, err := db.Conn(ctx)
+	srcConn, err := srcDB.Conn(ctx)
 	if err != nil {
 		return errors.New(err).
 			Component("backup").
@@ -578,8 +539,7 @@ func (s *SQLiteSource) streamBackupToWriter(ctx context.Context, db *sql.DB, w i
 	s.logger.Debug("Initialized SQLite backup connection", "total_pages", totalPages)
 
 	// Validate and adjust page counts
-	// Use pageCount from getDatabaseInfo as the sourcePages reference
-	validatedTotal, _, err := s.validatePageCount(totalPages, pageCount)
+	validatedTotal, _, err := s.validatePageCount(totalPages, sourcePages)
 	if err != nil {
 		return err // Return the error from validatePageCount
 	}
@@ -590,6 +550,65 @@ func (s *SQLiteSource) streamBackupToWriter(ctx context.Context, db *sql.DB, w i
 		return err
 	}
 
+	return nil
+}
+
+// streamBackupToWriter performs a streaming backup of the SQLite database to the provided writer
+func (s *SQLiteSource) streamBackupToWriter(ctx context.Context, db *sql.DB, w io.Writer) error {
+	start := time.Now()
+	defer func() {
+		s.logger.Debug("Finished streamBackupToWriter", "duration_ms", time.Since(start).Milliseconds())
+	}()
+	s.logger.Debug("Starting streamBackupToWriter")
+
+	// Get database info needed later
+	_, pageCount, _, err := s.getDatabaseInfo(db)
+	if err != nil {
+		return fmt.Errorf("failed to get database info before backup: %w", err)
+	}
+	s.logger.Debug("Retrieved database info for backup", "page_count", pageCount)
+
+	// Create a temporary file for the backup
+	tempFile, err := os.CreateTemp("", "birdnet-go-backup-*.db")
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_temp_file").
+			Build()
+	}
+	tempPath := tempFile.Name()
+	s.logger.Debug("Created temporary backup file", "temp_path", tempPath)
+	// Close the handle immediately, it's just needed for the path
+	if errClose := tempFile.Close(); errClose != nil {
+		s.logger.Warn("Failed to close temporary file handle after creation (continuing)", "temp_path", tempPath, "error", errClose)
+	}
+	defer func() {
+		s.logger.Debug("Removing temporary backup file", "temp_path", tempPath)
+		if errRemove := os.Remove(tempPath); errRemove != nil {
+			s.logger.Warn("Failed to remove temporary backup file", "temp_path", tempPath, "error", errRemove)
+		}
+	}()
+
+	// Open the destination database (using the temp path)
+	destDB, err := sql.Open("sqlite3", tempPath+"?_journal_mode=WAL&_sync=OFF") // Turn off sync for backup target
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "open_temp_database").
+			Build()
+	}
+	defer func() {
+		if err := destDB.Close(); err != nil {
+			slog.Debug("Failed to close destination database", "error", err)
+		}
+	}()
+
+	if err := s.copyDatabase(ctx, db, destDB, pageCount); err != nil {
+		return err
+	}
+
 	// Open the temporary backup file for reading with secure path validation
 	secureOp := backup.NewSecureFileOp("backup")
 	backupFile, cleanTempPath, err := secureOp.SecureOpen(tempPath)
@@ -750,3 +769,299 @@ func (s *SQLiteSource) validateConfig() (string, error) {
 
 	return dbPath, nil
 }
+
+// Restore replaces the configured SQLite database with the database file at
+// dataPath. The restored file is staged next to the live database and
+// verified before anything is touched, and the previous database together
+// with its WAL and SHM files is renamed aside rather than deleted. It returns
+// the path of the previous database, or an empty string if there was none.
+// While the application holds the database open the files are not swapped;
+// the previous database is snapshotted aside and the restored pages are
+// copied into the live database through the SQLite backup API instead.
+func (s *SQLiteSource) Restore(ctx context.Context, dataPath string) (string, error) {
+	dbPath, err := s.validateConfig()
+	if err != nil {
+		return "", fmt.Errorf("configuration validation failed: %w", err)
+	}
+	s.logger.Info("Starting SQLite restore", "db_path", dbPath, "data_path", dataPath)
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_create_dir").
+			Context("db_path", dbPath).
+			Build()
+	}
+
+	// Stage the restored database in the same directory so the final swap is
+	// a rename on the same filesystem
+	stagedPath := dbPath + ".restore-tmp"
+	if err := copyFileSync(ctx, dataPath, stagedPath); err != nil {
+		return "", err
+	}
+	defer func() {
+		for _, p := range []string{stagedPath, stagedPath + "-wal", stagedPath + "-shm"} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				s.logger.Warn("Failed to remove staged restore file", "path", p, "error", err)
+			}
+		}
+	}()
+
+	if err := s.verifyRestoredDatabase(stagedPath); err != nil {
+		return "", fmt.Errorf("restored database verification failed: %w", err)
+	}
+	s.logger.Info("Restored database verified successfully", "staged_path", stagedPath)
+
+	if err := ctx.Err(); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryCancellation).
+			Context("operation", "restore").
+			Build()
+	}
+
+	if s.inUse.Load() {
+		return s.restoreLiveDatabase(ctx, dbPath, stagedPath)
+	}
+
+	asidePath, err := s.moveDatabaseAside(dbPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(stagedPath, dbPath); err != nil {
+		// Put the previous database back so the application keeps working
+		if asidePath != "" {
+			if rbErr := restoreDatabaseFiles(asidePath, dbPath); rbErr != nil {
+				s.logger.Error("Failed to roll back database after failed restore",
+					"db_path", dbPath, "aside_path", asidePath, "error", rbErr)
+			}
+		}
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_swap_database").
+			Context("db_path", dbPath).
+			Build()
+	}
+
+	s.logger.Info("SQLite restore completed", "db_path", dbPath, "previous_db_path", asidePath)
+	return asidePath, nil
+}
+
+// restoreLiveDatabase restores stagedPath into the database at dbPath while
+// other connections hold it open. The current contents are written to a
+// timestamped pre-restore file with VACUUM INTO and the staged database is
+// then copied over the live one with the backup API, which takes the same
+// locks as any other writer so open connections see the restored data.
+func (s *SQLiteSource) restoreLiveDatabase(ctx context.Context, dbPath, stagedPath string) (string, error) {
+	liveDB, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=30000")
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "restore_open_live_database").
+			Context("db_path", dbPath).
+			Build()
+	}
+	defer func() {
+		if err := liveDB.Close(); err != nil {
+			s.logger.Debug("Failed to close live database", "error", err)
+		}
+	}()
+
+	asidePath := fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().Format("20060102-150405"))
+	if _, err := liveDB.ExecContext(ctx, "VACUUM INTO ?", asidePath); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "restore_snapshot_live_database").
+			Context("db_path", dbPath).
+			Build()
+	}
+	s.logger.Info("Saved previous database before live restore", "aside_path", asidePath)
+
+	stagedDB, err := sql.Open("sqlite3", stagedPath)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryDatabase).
+			Context("operation", "restore_open_staged_database").
+			Build()
+	}
+	defer func() {
+		if err := stagedDB.Close(); err != nil {
+			s.logger.Debug("Failed to close staged database", "error", err)
+		}
+	}()
+
+	_, pageCount, _, err := s.getDatabaseInfo(stagedDB)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged database info: %w", err)
+	}
+	if err := s.copyDatabase(ctx, stagedDB, liveDB, pageCount); err != nil {
+		return "", err
+	}
+
+	s.logger.Info("SQLite live restore completed", "db_path", dbPath, "previous_db_path", asidePath)
+	return asidePath, nil
+}
+
+// verifyRestoredDatabase checks the integrity of a database file that is about
+// to be restored and makes sure it looks like a BirdNET-Go database
+func (s *SQLiteSource) verifyRestoredDatabase(dbPath string) error {
+	return s.withDatabase(dbPath, false, func(conn *DatabaseConnection) error {
+		if err := s.verifyDatabaseIntegrity(conn.db); err != nil {
+			return err
+		}
+
+		var tableCount int
+		if err := conn.db.QueryRow(
+			"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'notes'",
+		).Scan(&tableCount); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "verify_restored_schema").
+				Build()
+		}
+		if tableCount == 0 {
+			return errors.Newf("database does not contain a notes table").
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "verify_restored_schema").
+				Build()
+		}
+
+		return nil
+	})
+}
+
+// moveDatabaseAside renames the live database and its WAL and SHM files to a
+// timestamped pre-restore name. It returns an empty path if there is no
+// database to move.
+func (s *SQLiteSource) moveDatabaseAside(dbPath string) (string, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_stat_database").
+			Context("db_path", dbPath).
+			Build()
+	}
+
+	asidePath := fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(dbPath, asidePath); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_move_database_aside").
+			Context("db_path", dbPath).
+			Build()
+	}
+
+	// A stale WAL must not be replayed against the restored database
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(dbPath+suffix, asidePath+suffix); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to move database side file aside", "path", dbPath+suffix, "error", err)
+			if rmErr := os.Remove(dbPath + suffix); rmErr != nil && !os.IsNotExist(rmErr) {
+				if rbErr := restoreDatabaseFiles(asidePath, dbPath); rbErr != nil {
+					s.logger.Error("Failed to roll back database move", "db_path", dbPath, "error", rbErr)
+				}
+				return "", errors.New(rmErr).
+					Component("backup").
+					Category(errors.CategoryFileIO).
+					Context("operation", "restore_move_database_aside").
+					Context("path", dbPath+suffix).
+					Build()
+			}
+		}
+	}
+
+	return asidePath, nil
+}
+
+// restoreDatabaseFiles moves a database and its side files from src back to dst
+func restoreDatabaseFiles(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(src+suffix, dst+suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFileSync copies src to dst and flushes dst to disk
+func copyFileSync(ctx context.Context, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_open_data").
+			Context("path", src).
+			Build()
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			slog.Debug("Failed to close restore data file", "error", err)
+		}
+	}()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_stage_database").
+			Context("path", dst).
+			Build()
+	}
+
+	_, copyErr := io.Copy(out, &contextReader{ctx: ctx, r: in})
+	if copyErr == nil {
+		copyErr = out.Sync()
+	}
+	if closeErr := out.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = os.Remove(dst)
+		if isMediaError(copyErr) {
+			return errors.New(copyErr).
+				Component("backup").
+				Category(errors.CategoryDiskUsage).
+				Context("operation", "restore_stage_database").
+				Context("error_type", "media_error").
+				Build()
+		}
+		return errors.New(copyErr).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "restore_stage_database").
+			Context("path", dst).
+			Build()
+	}
+
+	return nil
+}
+
+// contextReader aborts reads once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
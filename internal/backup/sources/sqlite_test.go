@@ -0,0 +1,158 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// createTestDatabase creates a SQLite database with a notes table holding the given species
+func createTestDatabase(t *testing.T, path string, species ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, common_name TEXT)")
+	require.NoError(t, err)
+	for _, name := range species {
+		_, err = db.Exec("INSERT INTO notes (common_name) VALUES (?)", name)
+		require.NoError(t, err)
+	}
+}
+
+func readSpecies(t *testing.T, path string) []string {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	rows, err := db.Query("SELECT common_name FROM notes ORDER BY id")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, rows.Close()) }()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	return names
+}
+
+func newTestSQLiteSource(dbPath string) *SQLiteSource {
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = dbPath
+	return NewSQLiteSource(settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestSQLiteSourceRestore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "birdnet.db")
+	createTestDatabase(t, dbPath, "Eurasian Wren")
+	require.NoError(t, os.WriteFile(dbPath+"-wal", []byte("stale wal"), 0o600))
+
+	backupPath := filepath.Join(dir, "backup.birdnet")
+	createTestDatabase(t, backupPath, "Common Blackbird", "European Robin")
+
+	source := newTestSQLiteSource(dbPath)
+	asidePath, err := source.Restore(context.Background(), backupPath)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, asidePath)
+	assert.FileExists(t, asidePath+"-wal", "stale WAL should be moved aside with the database")
+	assert.NoFileExists(t, dbPath+"-wal")
+	assert.Equal(t, []string{"Common Blackbird", "European Robin"}, readSpecies(t, dbPath))
+	assert.Equal(t, []string{"Eurasian Wren"}, readSpecies(t, asidePath))
+	assert.NoFileExists(t, dbPath+".restore-tmp")
+}
+
+func TestSQLiteSourceRestore_NoExistingDatabase(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "birdnet.db")
+	backupPath := filepath.Join(dir, "backup.birdnet")
+	createTestDatabase(t, backupPath, "Great Tit")
+
+	asidePath, err := newTestSQLiteSource(dbPath).Restore(context.Background(), backupPath)
+	require.NoError(t, err)
+	assert.Empty(t, asidePath)
+	assert.Equal(t, []string{"Great Tit"}, readSpecies(t, dbPath))
+}
+
+func TestSQLiteSourceRestore_DatabaseInUse(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "birdnet.db")
+	createTestDatabase(t, dbPath, "Eurasian Wren")
+	backupPath := filepath.Join(dir, "backup.birdnet")
+	createTestDatabase(t, backupPath, "Common Blackbird", "European Robin")
+
+	// Hold the database open in WAL mode like the running application does
+	liveDB, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, liveDB.Close()) }()
+	liveDB.SetMaxOpenConns(1)
+	var count int
+	require.NoError(t, liveDB.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+	require.Equal(t, 1, count)
+
+	source := newTestSQLiteSource(dbPath)
+	source.SetInUse(true)
+	asidePath, err := source.Restore(context.Background(), backupPath)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, asidePath)
+	assert.Equal(t, []string{"Eurasian Wren"}, readSpecies(t, asidePath))
+	assert.NoFileExists(t, dbPath+".restore-tmp")
+
+	// The connection that stayed open sees the restored data
+	require.NoError(t, liveDB.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"Common Blackbird", "European Robin"}, readSpecies(t, dbPath))
+}
+
+func TestSQLiteSourceRestore_RejectsInvalidData(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "birdnet.db")
+	createTestDatabase(t, dbPath, "Eurasian Wren")
+
+	notSQLite := filepath.Join(dir, "garbage")
+	require.NoError(t, os.WriteFile(notSQLite, []byte("this is not a database"), 0o600))
+
+	noNotes := filepath.Join(dir, "other.db")
+	db, err := sql.Open("sqlite3", noNotes)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE something (id INTEGER)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	source := newTestSQLiteSource(dbPath)
+	for _, dataPath := range []string{notSQLite, noNotes} {
+		_, err := source.Restore(context.Background(), dataPath)
+		require.Error(t, err, dataPath)
+	}
+
+	// The live database must be untouched
+	assert.Equal(t, []string{"Eurasian Wren"}, readSpecies(t, dbPath))
+	matches, err := filepath.Glob(dbPath + ".pre-restore-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.NoFileExists(t, dbPath+".restore-tmp")
+}
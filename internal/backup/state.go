@@ -115,14 +115,12 @@ func (sm *StateManager) loadState() error {
 	return json.Unmarshal(data, sm.state)
 }
 
-// saveState saves the current backup state to disk
+// saveState saves the current backup state to disk.
+// The caller must hold sm.mu.
 func (sm *StateManager) saveState() error {
 	start := time.Now()
 
-	// Copy state under lock
-	sm.mu.Lock()
 	stateSnapshot := *sm.state
-	sm.mu.Unlock()
 
 	// Update last update time (on the snapshot)
 	stateSnapshot.LastUpdate = time.Now()
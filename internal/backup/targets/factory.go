@@ -0,0 +1,47 @@
+package targets
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// NewFromConfig creates a backup target from a configured backup target entry.
+// The entry's Enabled flag is not checked; callers decide which targets to use.
+func NewFromConfig(cfg conf.BackupTarget, logger *slog.Logger) (backup.Target, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	settings := cfg.Settings
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case "local":
+		path, _ := settings["path"].(string)
+		debug, _ := settings["debug"].(bool)
+		return NewLocalTarget(LocalTargetConfig{Path: path, Debug: debug}, nil)
+	case "ftp":
+		return NewFTPTargetFromMap(settings)
+	case "sftp":
+		return NewSFTPTarget(settings, logger)
+	case "s3":
+		return NewS3Target(settings, logger)
+	case "rsync":
+		return NewRsyncTarget(settings)
+	case "gdrive", "googledrive":
+		return NewGDriveTargetFromMap(settings)
+	default:
+		return nil, errors.Newf("unsupported backup target type: %q", cfg.Type).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_target").
+			Context("target_type", cfg.Type).
+			Build()
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/textproto"
 	"os"
 	"path"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/jlaffaye/ftp"
 	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
 )
 
 const (
@@ -502,6 +504,44 @@ func (t *FTPTarget) Delete(ctx context.Context, target string) error {
 	})
 }
 
+// Retrieve implements the backup.Target interface
+func (t *FTPTarget) Retrieve(ctx context.Context, name, destPath string) error {
+	if t.config.Debug {
+		t.logger.Printf("🔄 FTP: Retrieving backup %s from %s", name, t.config.Host)
+	}
+
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	return t.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		backupPath := path.Join(t.config.BasePath, name)
+		resp, err := conn.Retr(backupPath)
+		if err != nil {
+			var protoErr *textproto.Error
+			if errors.As(err, &protoErr) && protoErr.Code == ftp.StatusFileUnavailable {
+				return backup.NewError(backup.ErrNotFound, fmt.Sprintf("ftp: backup %s not found", name), err)
+			}
+			return backup.NewError(backup.ErrIO, "ftp: failed to retrieve backup", err)
+		}
+		defer func() {
+			if err := resp.Close(); err != nil {
+				t.logger.Printf("ftp: failed to close retrieve response: %v", err)
+			}
+		}()
+
+		if err := writeRetrievedFile(ctx, destPath, resp); err != nil {
+			return backup.NewError(backup.ErrIO, "ftp: failed to download backup", err)
+		}
+
+		if t.config.Debug {
+			t.logger.Printf("✅ FTP: Successfully retrieved backup %s", name)
+		}
+
+		return nil
+	})
+}
+
 // Validate performs comprehensive validation of the FTP target
 func (t *FTPTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
@@ -731,6 +731,61 @@ func (t *GDriveTarget) Delete(ctx context.Context, id string) error {
 	})
 }
 
+// Retrieve implements the backup.Target interface
+func (t *GDriveTarget) Retrieve(ctx context.Context, name, destPath string) error {
+	if t.config.Debug {
+		t.logger.Printf("🔄 GDrive: Retrieving backup %s", name)
+	}
+
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	if err := t.refreshTokenIfNeeded(ctx); err != nil {
+		return err
+	}
+
+	if err := t.rateLimiter.acquire(ctx); err != nil {
+		return backup.NewError(backup.ErrCanceled, "gdrive: operation canceled while waiting for rate limit", err)
+	}
+
+	return t.withRetry(ctx, func() error {
+		folderId, err := t.ensureFolder(ctx, t.config.BasePath)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("name='%s' and '%s' in parents and trashed=false", name, folderId)
+		files, err := t.service.Files.List().Q(query).Fields("files(id)").Context(ctx).Do()
+		if err != nil {
+			return backup.NewError(backup.ErrIO, "gdrive: failed to look up backup file", err)
+		}
+		if len(files.Files) == 0 {
+			return backup.NewError(backup.ErrNotFound, fmt.Sprintf("gdrive: backup file %s not found", name), nil)
+		}
+
+		resp, err := t.service.Files.Get(files.Files[0].Id).Context(ctx).Download()
+		if err != nil {
+			return backup.NewError(backup.ErrIO, "gdrive: failed to download backup file", err)
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				t.logger.Printf("gdrive: failed to close download of %s: %v", name, err)
+			}
+		}()
+
+		if err := writeRetrievedFile(ctx, destPath, resp.Body); err != nil {
+			return err
+		}
+
+		if t.config.Debug {
+			t.logger.Printf("✅ GDrive: Successfully retrieved backup %s", name)
+		}
+
+		return nil
+	})
+}
+
 // Validate performs comprehensive validation of the Google Drive target
 func (t *GDriveTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
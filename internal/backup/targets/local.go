@@ -159,6 +159,42 @@ func copyFile(dst, src *os.File) error {
 	return err
 }
 
+// validateArchiveName checks that a backup archive name is a plain file name
+func validateArchiveName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return errors.Newf("invalid backup archive name: %q", name).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_archive_name").
+			Build()
+	}
+	return nil
+}
+
+// writeRetrievedFile atomically writes a downloaded backup archive to destPath
+func writeRetrievedFile(ctx context.Context, destPath string, src io.Reader) error {
+	return atomicWriteFile(destPath, "retrieve-*.tmp", filePermissions, func(tempFile *os.File) error {
+		buf := make([]byte, copyBufferSize)
+		if _, err := io.CopyBuffer(tempFile, &contextReader{ctx: ctx, r: src}, buf); err != nil {
+			return err
+		}
+		return tempFile.Sync()
+	})
+}
+
+// contextReader aborts a copy when the context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
 // validatePath performs comprehensive path validation
 func validatePath(path string) error {
 	if path == "" {
@@ -570,7 +606,7 @@ func (t *LocalTarget) Delete(ctx context.Context, backupID string) error {
 	}
 
 	// Delete both the backup file and its metadata
-	backupPath := filepath.Join(t.path, backupID)
+	backupPath := t.resolveArchivePath(backupID)
 	metadataPath := backupPath + ".meta"
 
 	// Delete backup file
@@ -602,6 +638,61 @@ func (t *LocalTarget) Delete(ctx context.Context, backupID string) error {
 	return nil
 }
 
+// resolveArchivePath returns the path of the archive stored for a backup.
+// Backups are addressed either by archive file name or by backup ID, in which
+// case the archive carries a .tar or .tar.enc extension.
+func (t *LocalTarget) resolveArchivePath(backupID string) string {
+	backupPath := filepath.Join(t.path, backupID)
+	for _, candidate := range []string{backupPath, backupPath + ".tar", backupPath + ".tar.enc"} {
+		if _, err := os.Stat(candidate + ".meta"); err == nil {
+			return candidate
+		}
+	}
+	return backupPath
+}
+
+// Retrieve copies a stored backup archive to destPath
+func (t *LocalTarget) Retrieve(ctx context.Context, name, destPath string) error {
+	if t.debug {
+		t.logger.Printf("🔄 Retrieving backup %s from local target", name)
+	}
+
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	backupPath := filepath.Join(t.path, name)
+	secureOp := backup.NewSecureFileOp("backup")
+	srcFile, cleanBackupPath, err := secureOp.SecureOpen(backupPath)
+	if err != nil {
+		if _, statErr := os.Stat(backupPath); os.IsNotExist(statErr) {
+			return backup.NewError(backup.ErrNotFound, fmt.Sprintf("local: backup %s not found", name), statErr)
+		}
+		return err
+	}
+	defer func() {
+		if err := srcFile.Close(); err != nil {
+			t.logger.Printf("local: failed to close backup file %s: %v", cleanBackupPath, err)
+		}
+	}()
+
+	if err := writeRetrievedFile(ctx, destPath, srcFile); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "retrieve_backup_file").
+			Context("source_path", cleanBackupPath).
+			Context("dest_path", destPath).
+			Build()
+	}
+
+	if t.debug {
+		t.logger.Printf("✅ Successfully retrieved backup %s", name)
+	}
+
+	return nil
+}
+
 // Validate checks if the target configuration is valid
 func (t *LocalTarget) Validate() error {
 	// Check if path is absolute
@@ -0,0 +1,59 @@
+package targets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/backup"
+)
+
+func TestLocalTargetRetrieve(t *testing.T) {
+	t.Parallel()
+
+	backupDir := t.TempDir()
+	target, err := NewLocalTarget(LocalTargetConfig{Path: backupDir}, nil)
+	require.NoError(t, err)
+
+	content := []byte("archive contents")
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "birdnet-20240102-030405.tar"), content, 0o600))
+
+	destPath := filepath.Join(t.TempDir(), "retrieved.tar")
+	require.NoError(t, target.Retrieve(context.Background(), "birdnet-20240102-030405.tar", destPath))
+
+	got, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	err = target.Retrieve(context.Background(), "missing.tar", filepath.Join(t.TempDir(), "missing.tar"))
+	require.Error(t, err)
+	assert.True(t, backup.IsErrorCode(err, backup.ErrNotFound), "expected not found error, got %v", err)
+
+	for _, name := range []string{"", "../escape.tar", "sub/dir.tar"} {
+		assert.Error(t, target.Retrieve(context.Background(), name, destPath), "name %q should be rejected", name)
+	}
+}
+
+func TestLocalTargetDeleteByBackupID(t *testing.T) {
+	t.Parallel()
+
+	backupDir := t.TempDir()
+	target, err := NewLocalTarget(LocalTargetConfig{Path: backupDir}, nil)
+	require.NoError(t, err)
+
+	archives := []string{"birdnet-20240102-030405.tar", "birdnet-20240103-030405.tar.enc"}
+	for _, name := range archives {
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, name), []byte("archive"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, name+".meta"), []byte("{}"), 0o600))
+	}
+
+	require.NoError(t, target.Delete(context.Background(), "birdnet-20240102-030405"))
+	require.NoError(t, target.Delete(context.Background(), "birdnet-20240103-030405"))
+
+	entries, err := os.ReadDir(backupDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "archives and metadata should be removed")
+}
@@ -0,0 +1,724 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	defaultS3MaxRetries   = 3
+	defaultS3RetryBackoff = time.Second
+	defaultS3Timeout      = 30 * time.Second
+	defaultS3PartSize     = 16 * 1024 * 1024 // 16 MiB parts for multipart uploads
+	minS3PartSize         = 5 * 1024 * 1024  // S3 rejects non-final parts smaller than 5 MiB
+	s3MetadataFileExt     = ".meta"
+	s3MaxMetadataSize     = 1024 * 1024 // Metadata objects are small JSON documents
+)
+
+// Server-side encryption modes supported by the S3 target
+const (
+	S3EncryptionNone = "none"
+	S3EncryptionS3   = "sse-s3"
+	S3EncryptionKMS  = "sse-kms"
+)
+
+// S3TargetConfig holds configuration for the S3 target
+type S3TargetConfig struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Prefix          string
+	UseSSL          bool
+	ForcePathStyle  bool
+	Encryption      string // One of S3EncryptionNone, S3EncryptionS3 or S3EncryptionKMS
+	KMSKeyID        string // Key ID used with S3EncryptionKMS
+	PartSize        uint64 // Part size for multipart uploads in bytes
+	Timeout         time.Duration
+	Debug           bool
+	MaxRetries      int
+	RetryBackoff    time.Duration
+}
+
+// S3Target implements the backup.Target interface for S3-compatible object storage
+// such as AWS S3, MinIO, Garage or the Backblaze B2 S3 API.
+type S3Target struct {
+	config S3TargetConfig
+	client *minio.Client
+	sse    encrypt.ServerSide
+	logger *slog.Logger
+}
+
+// NewS3Target creates a new S3 target with the given configuration
+func NewS3Target(settings map[string]any, logger *slog.Logger) (*S3Target, error) {
+	config := S3TargetConfig{
+		UseSSL:       true,
+		Encryption:   S3EncryptionNone,
+		PartSize:     defaultS3PartSize,
+		Timeout:      defaultS3Timeout,
+		MaxRetries:   defaultS3MaxRetries,
+		RetryBackoff: defaultS3RetryBackoff,
+	}
+
+	// Required settings
+	bucket, ok := settings["bucket"].(string)
+	if !ok || bucket == "" {
+		return nil, errors.Newf("s3: bucket is required").
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_s3_target").
+			Build()
+	}
+	config.Bucket = bucket
+
+	region, ok := settings["region"].(string)
+	if !ok || region == "" {
+		return nil, errors.Newf("s3: region is required").
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_s3_target").
+			Build()
+	}
+	config.Region = region
+
+	// Optional settings
+	if endpoint, ok := settings["endpoint"].(string); ok {
+		config.Endpoint = endpoint
+	}
+	if accessKeyID, ok := settings["access_key_id"].(string); ok {
+		config.AccessKeyID = accessKeyID
+	}
+	if secretAccessKey, ok := settings["secret_access_key"].(string); ok {
+		config.SecretAccessKey = secretAccessKey
+	}
+	if prefix, ok := settings["prefix"].(string); ok {
+		config.Prefix = strings.Trim(prefix, "/")
+	}
+	if useSSL, ok := settings["use_ssl"].(bool); ok {
+		config.UseSSL = useSSL
+	}
+	if forcePathStyle, ok := settings["force_path_style"].(bool); ok {
+		config.ForcePathStyle = forcePathStyle
+	}
+	if encryption, ok := settings["encryption"].(string); ok && encryption != "" {
+		config.Encryption = strings.ToLower(encryption)
+	}
+	if kmsKeyID, ok := settings["kms_key_id"].(string); ok {
+		config.KMSKeyID = kmsKeyID
+	}
+	if value, ok := settings["part_size_mb"]; ok {
+		partSizeMB, err := parseS3PartSizeMB(value)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "create_s3_target").
+				Build()
+		}
+		config.PartSize = partSizeMB * 1024 * 1024
+	}
+	if timeout, ok := settings["timeout"].(string); ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "parse_timeout").
+				Build()
+		}
+		config.Timeout = duration
+	}
+	if debug, ok := settings["debug"].(bool); ok {
+		config.Debug = debug
+	}
+
+	return newS3Target(&config, logger)
+}
+
+// parseS3PartSizeMB parses the part_size_mb setting. YAML decodes whole numbers as int and
+// JSON as float64, fractional or non-positive sizes are rejected rather than ignored.
+func parseS3PartSizeMB(value any) (uint64, error) {
+	var size float64
+	switch v := value.(type) {
+	case int:
+		size = float64(v)
+	case int64:
+		size = float64(v)
+	case uint64:
+		size = float64(v)
+	case float64:
+		size = v
+	default:
+		return 0, fmt.Errorf("s3: part_size_mb must be a whole number of megabytes, got %v", value)
+	}
+	if size <= 0 || size != math.Trunc(size) || size > math.MaxUint32 {
+		return 0, fmt.Errorf("s3: part_size_mb must be a whole number of megabytes, got %v", value)
+	}
+	return uint64(size), nil
+}
+
+// newS3Target validates the configuration and creates the underlying S3 client
+func newS3Target(config *S3TargetConfig, logger *slog.Logger) (*S3Target, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if config.PartSize < minS3PartSize {
+		return nil, errors.Newf("s3: part size must be at least %d bytes", minS3PartSize).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "create_s3_target").
+			Context("part_size", config.PartSize).
+			Build()
+	}
+
+	sse, err := newS3ServerSideEncryption(config.Encryption, config.KMSKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, secure, err := parseS3Endpoint(config.Endpoint, config.Region, config.UseSSL)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketLookup := minio.BucketLookupAuto
+	if config.ForcePathStyle {
+		bucketLookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure:       secure,
+		Region:       config.Region,
+		BucketLookup: bucketLookup,
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_s3_client").
+			Context("endpoint", endpoint).
+			Build()
+	}
+
+	return &S3Target{
+		config: *config,
+		client: client,
+		sse:    sse,
+		logger: logger,
+	}, nil
+}
+
+// parseS3Endpoint normalizes the configured endpoint into the host[:port] form expected
+// by the S3 client. An empty endpoint defaults to AWS S3 in the configured region.
+// A scheme in the endpoint URL takes precedence over the use_ssl setting.
+func parseS3Endpoint(endpoint, region string, useSSL bool) (host string, secure bool, err error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return fmt.Sprintf("s3.%s.amazonaws.com", region), true, nil
+	}
+
+	secure = useSSL
+	switch {
+	case strings.HasPrefix(endpoint, "https://"):
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+		secure = true
+	case strings.HasPrefix(endpoint, "http://"):
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+		secure = false
+	}
+	endpoint = strings.TrimRight(endpoint, "/")
+
+	if endpoint == "" || strings.Contains(endpoint, "/") {
+		return "", false, errors.Newf("s3: invalid endpoint %q, expected host[:port]", endpoint).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_s3_endpoint").
+			Build()
+	}
+
+	return endpoint, secure, nil
+}
+
+// newS3ServerSideEncryption returns the server-side encryption settings for the given mode
+func newS3ServerSideEncryption(mode, kmsKeyID string) (encrypt.ServerSide, error) {
+	switch mode {
+	case "", S3EncryptionNone:
+		return nil, nil
+	case S3EncryptionS3:
+		return encrypt.NewSSE(), nil
+	case S3EncryptionKMS:
+		if kmsKeyID == "" {
+			return nil, errors.Newf("s3: kms_key_id is required for %s encryption", S3EncryptionKMS).
+				Component("backup").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "configure_s3_encryption").
+				Build()
+		}
+		sse, err := encrypt.NewSSEKMS(kmsKeyID, nil)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "configure_s3_encryption").
+				Build()
+		}
+		return sse, nil
+	default:
+		return nil, errors.Newf("s3: unsupported encryption mode %q", mode).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "configure_s3_encryption").
+			Context("encryption", mode).
+			Build()
+	}
+}
+
+// Name returns the name of this target
+func (t *S3Target) Name() string {
+	return "s3"
+}
+
+// objectKey returns the full object key for the given file name
+func (t *S3Target) objectKey(name string) string {
+	if t.config.Prefix == "" {
+		return name
+	}
+	return path.Join(t.config.Prefix, name)
+}
+
+// listPrefix returns the prefix used to list objects belonging to this target
+func (t *S3Target) listPrefix() string {
+	if t.config.Prefix == "" {
+		return ""
+	}
+	return t.config.Prefix + "/"
+}
+
+// isTransientError checks if an error is likely temporary
+func (t *S3Target) isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// S3 error responses carry a code that tells us if retrying makes sense
+	resp := minio.ToErrorResponse(err)
+	switch resp.Code {
+	case "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout", "RequestTimeTooSkewed":
+		return true
+	}
+	if resp.StatusCode >= 500 {
+		return true
+	}
+
+	errStr := err.Error()
+	return strings.Contains(errStr, "connection reset") ||
+		strings.Contains(errStr, "timeout") ||
+		strings.Contains(errStr, "temporary") ||
+		strings.Contains(errStr, "broken pipe") ||
+		strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "EOF")
+}
+
+// withRetry executes an operation with retry logic
+func (t *S3Target) withRetry(ctx context.Context, operation string, op func() error) error {
+	var lastErr error
+	for i := 0; i < t.config.MaxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategorySystem).
+				Context("operation", operation).
+				Context("error_type", "cancelled").
+				Build()
+		}
+
+		err := op()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !t.isTransientError(err) {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", operation).
+				Context("bucket", t.config.Bucket).
+				Build()
+		}
+
+		if t.config.Debug {
+			t.logger.Debug("S3: Retrying operation after error",
+				"operation", operation,
+				"error", err,
+				"attempt", i+1,
+				"max_retries", t.config.MaxRetries)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(t.config.RetryBackoff * time.Duration(i+1)):
+		}
+	}
+
+	return errors.New(lastErr).
+		Component("backup").
+		Category(errors.CategoryNetwork).
+		Context("operation", operation).
+		Context("bucket", t.config.Bucket).
+		Context("max_retries", t.config.MaxRetries).
+		Build()
+}
+
+// putOptions returns the upload options shared by archive and metadata uploads
+func (t *S3Target) putOptions(contentType string) minio.PutObjectOptions {
+	return minio.PutObjectOptions{
+		ContentType:          contentType,
+		PartSize:             t.config.PartSize,
+		ServerSideEncryption: t.sse,
+	}
+}
+
+// Store implements the backup.Target interface. Archives larger than the configured
+// part size are uploaded with S3 multipart upload.
+func (t *S3Target) Store(ctx context.Context, sourcePath string, metadata *backup.Metadata) error {
+	archiveName := filepath.Base(sourcePath)
+	archiveKey := t.objectKey(archiveName)
+
+	if t.config.Debug {
+		t.logger.Debug("S3: Storing backup",
+			"bucket", t.config.Bucket,
+			"key", archiveKey)
+	}
+
+	// Marshal metadata
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "marshal_metadata").
+			Build()
+	}
+
+	// Validate source file with secure path validation
+	secureOp := backup.NewSecureFileOp("backup")
+	cleanSourcePath, err := secureOp.ValidatePath(sourcePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(cleanSourcePath); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "stat_source_file").
+			Context("source_path", cleanSourcePath).
+			Build()
+	}
+
+	// Upload the archive first; the metadata object is what makes a backup visible to List,
+	// so a failed or partial archive upload never shows up as a valid backup.
+	err = t.withRetry(ctx, "s3_upload_archive", func() error {
+		_, err := t.client.FPutObject(ctx, t.config.Bucket, archiveKey, cleanSourcePath,
+			t.putOptions("application/octet-stream"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	metadataKey := archiveKey + s3MetadataFileExt
+	err = t.withRetry(ctx, "s3_upload_metadata", func() error {
+		_, err := t.client.PutObject(ctx, t.config.Bucket, metadataKey,
+			bytes.NewReader(metadataBytes), int64(len(metadataBytes)),
+			t.putOptions("application/json"))
+		return err
+	})
+	if err != nil {
+		// Don't leave an archive without metadata behind
+		if rmErr := t.client.RemoveObject(ctx, t.config.Bucket, archiveKey, minio.RemoveObjectOptions{}); rmErr != nil {
+			t.logger.Warn("S3: Failed to remove archive after metadata upload failure",
+				"key", archiveKey,
+				"error", rmErr)
+		}
+		return err
+	}
+
+	if t.config.Debug {
+		t.logger.Debug("S3: Successfully stored backup with metadata",
+			"bucket", t.config.Bucket,
+			"key", archiveKey)
+	}
+
+	return nil
+}
+
+// List implements the backup.Target interface. Backups are discovered through their
+// metadata objects under the configured prefix.
+func (t *S3Target) List(ctx context.Context) ([]backup.BackupInfo, error) {
+	if t.config.Debug {
+		t.logger.Debug("S3: Listing backups",
+			"bucket", t.config.Bucket,
+			"prefix", t.listPrefix())
+	}
+
+	var backups []backup.BackupInfo
+	err := t.withRetry(ctx, "s3_list_backups", func() error {
+		backups = backups[:0]
+
+		objects, err := t.listObjects(ctx)
+		if err != nil {
+			return err
+		}
+
+		for key := range objects {
+			if !strings.HasSuffix(key, s3MetadataFileExt) {
+				continue
+			}
+
+			// Skip metadata whose archive is missing
+			archiveKey := strings.TrimSuffix(key, s3MetadataFileExt)
+			archive, ok := objects[archiveKey]
+			if !ok {
+				if t.config.Debug {
+					t.logger.Debug("S3: Skipping orphaned metadata object", "key", key)
+				}
+				continue
+			}
+
+			metadata, err := t.readMetadata(ctx, key)
+			if err != nil {
+				t.logger.Warn("S3: Skipping backup with invalid metadata",
+					"key", key,
+					"error", err)
+				continue
+			}
+			if metadata.Size == 0 {
+				metadata.Size = archive.Size
+			}
+
+			backups = append(backups, backup.BackupInfo{
+				Metadata: *metadata,
+				Target:   t.Name(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort backups by timestamp (newest first)
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+
+	return backups, nil
+}
+
+// listObjects returns all objects directly under the target prefix keyed by object key
+func (t *S3Target) listObjects(ctx context.Context) (map[string]minio.ObjectInfo, error) {
+	prefix := t.listPrefix()
+	objects := make(map[string]minio.ObjectInfo)
+
+	for object := range t.client.ListObjects(ctx, t.config.Bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: false,
+	}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		// Skip "directories" and anything nested below the prefix
+		if strings.HasSuffix(object.Key, "/") || strings.Contains(strings.TrimPrefix(object.Key, prefix), "/") {
+			continue
+		}
+		objects[object.Key] = object
+	}
+
+	return objects, nil
+}
+
+// readMetadata downloads and decodes a metadata object
+func (t *S3Target) readMetadata(ctx context.Context, key string) (*backup.Metadata, error) {
+	obj, err := t.client.GetObject(ctx, t.config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := obj.Close(); err != nil && t.config.Debug {
+			t.logger.Debug("S3: Failed to close metadata object", "key", key, "error", err)
+		}
+	}()
+
+	var metadata backup.Metadata
+	if err := json.NewDecoder(io.LimitReader(obj, s3MaxMetadataSize)).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// resolveKeys returns the archive and metadata object keys belonging to a backup.
+// The id may be either the backup ID from the metadata (as used by the retention
+// policy) or the archive file name.
+func (t *S3Target) resolveKeys(ctx context.Context, id string) ([]string, error) {
+	objects, err := t.listObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := t.listPrefix()
+	var keys []string
+	for key := range objects {
+		name := strings.TrimPrefix(key, prefix)
+		if name == id || name == id+s3MetadataFileExt || strings.HasPrefix(name, id+".") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete implements the backup.Target interface
+func (t *S3Target) Delete(ctx context.Context, id string) error {
+	if t.config.Debug {
+		t.logger.Debug("S3: Deleting backup",
+			"bucket", t.config.Bucket,
+			"id", id)
+	}
+
+	if id == "" || strings.Contains(id, "/") {
+		return errors.Newf("s3: invalid backup id %q", id).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "delete_backup").
+			Build()
+	}
+
+	return t.withRetry(ctx, "s3_delete_backup", func() error {
+		keys, err := t.resolveKeys(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return backup.NewError(backup.ErrNotFound, fmt.Sprintf("s3: backup %s not found", id), nil)
+		}
+
+		// Remove metadata last so an interrupted delete stays visible and can be retried
+		sort.SliceStable(keys, func(i, j int) bool {
+			return !strings.HasSuffix(keys[i], s3MetadataFileExt) && strings.HasSuffix(keys[j], s3MetadataFileExt)
+		})
+		for _, key := range keys {
+			if err := t.client.RemoveObject(ctx, t.config.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+				return err
+			}
+		}
+
+		if t.config.Debug {
+			t.logger.Debug("S3: Successfully deleted backup",
+				"id", id,
+				"objects", len(keys))
+		}
+		return nil
+	})
+}
+
+// Retrieve implements the backup.Target interface
+func (t *S3Target) Retrieve(ctx context.Context, name, destPath string) error {
+	if t.config.Debug {
+		t.logger.Debug("S3: Retrieving backup",
+			"bucket", t.config.Bucket,
+			"name", name)
+	}
+
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+	key := t.objectKey(name)
+
+	return t.withRetry(ctx, "s3_retrieve_backup", func() error {
+		obj, err := t.client.GetObject(ctx, t.config.Bucket, key, minio.GetObjectOptions{})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := obj.Close(); err != nil && t.config.Debug {
+				t.logger.Debug("S3: Failed to close object", "key", key, "error", err)
+			}
+		}()
+
+		// GetObject is lazy, Stat surfaces a missing object before anything is written
+		if _, err := obj.Stat(); err != nil {
+			if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+				return backup.NewError(backup.ErrNotFound, fmt.Sprintf("s3: backup %s not found", name), err)
+			}
+			return err
+		}
+
+		if err := writeRetrievedFile(ctx, destPath, obj); err != nil {
+			return err
+		}
+
+		if t.config.Debug {
+			t.logger.Debug("S3: Successfully retrieved backup",
+				"key", key,
+				"dest_path", destPath)
+		}
+		return nil
+	})
+}
+
+// Validate checks that the bucket is reachable and writable
+func (t *S3Target) Validate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+
+	return t.withRetry(ctx, "s3_validate", func() error {
+		exists, err := t.client.BucketExists(ctx, t.config.Bucket)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Newf("s3: bucket %s does not exist", t.config.Bucket).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "validate_bucket").
+				Build()
+		}
+
+		// Test write and delete permissions
+		testKey := t.objectKey(fmt.Sprintf(".write_test_%d", time.Now().UnixNano()))
+		testData := []byte("test")
+		if _, err := t.client.PutObject(ctx, t.config.Bucket, testKey, bytes.NewReader(testData),
+			int64(len(testData)), t.putOptions("text/plain")); err != nil {
+			return err
+		}
+		if err := t.client.RemoveObject(ctx, t.config.Bucket, testKey, minio.RemoveObjectOptions{}); err != nil {
+			t.logger.Warn("S3: Failed to delete test object",
+				"key", testKey,
+				"error", err)
+		}
+
+		return nil
+	})
+}
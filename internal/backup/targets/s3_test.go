@@ -0,0 +1,509 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501 -- S3 ETags are MD5 based
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/backup"
+)
+
+// fakeS3Object is an object stored in the fake S3 server
+type fakeS3Object struct {
+	data    []byte
+	headers http.Header
+	modTime time.Time
+}
+
+// fakeS3Server is a minimal in-memory stand-in for an S3-compatible server such as MinIO.
+// It implements just enough of the S3 REST API (path-style) for the S3 target.
+type fakeS3Server struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string]*fakeS3Object
+	uploads map[string]map[int][]byte // uploadID -> part number -> data
+	nextID  int
+
+	completedMultipart int
+	putHeaders         []http.Header
+}
+
+func newFakeS3Server(t *testing.T, bucket string) (*fakeS3Server, *httptest.Server) {
+	t.Helper()
+	fake := &fakeS3Server{
+		t:       t,
+		bucket:  bucket,
+		objects: make(map[string]*fakeS3Object),
+		uploads: make(map[string]map[int][]byte),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodGet:
+		f.listObjects(w, query.Get("prefix"), query.Get("delimiter"))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		uploadID := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[uploadID] = make(map[int][]byte)
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadID string `xml:"UploadId"`
+		}{Bucket: bucket, Key: key, UploadID: uploadID})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		require.NoError(f.t, err)
+		data := f.readBody(r)
+		parts[partNumber] = data
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, n := range numbers {
+			data = append(data, parts[n]...)
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.objects[key] = &fakeS3Object{data: data, headers: r.Header.Clone(), modTime: time.Now()}
+		f.completedMultipart++
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: etag(data)})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data := f.readBody(r)
+		f.objects[key] = &fakeS3Object{data: data, headers: r.Header.Clone(), modTime: time.Now()}
+		f.putHeaders = append(f.putHeaders, r.Header.Clone())
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", etag(obj.data))
+		w.Header().Set("Last-Modified", obj.modTime.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(obj.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// readBody reads a request body, decoding aws-chunked streaming uploads
+func (f *fakeS3Server) readBody(r *http.Request) []byte {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(f.t, err)
+		return data
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(f.t, err)
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		require.NoError(f.t, err)
+		if size == 0 {
+			return data
+		}
+		chunk := make([]byte, size)
+		_, err = io.ReadFull(reader, chunk)
+		require.NoError(f.t, err)
+		data = append(data, chunk...)
+		_, err = reader.Discard(2) // trailing CRLF
+		require.NoError(f.t, err)
+	}
+}
+
+func (f *fakeS3Server) listObjects(w http.ResponseWriter, prefix, delimiter string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+		StorageClass string
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	result := struct {
+		XMLName        xml.Name `xml:"ListBucketResult"`
+		Name           string
+		Prefix         string
+		Delimiter      string
+		MaxKeys        int
+		KeyCount       int
+		IsTruncated    bool
+		Contents       []content
+		CommonPrefixes []commonPrefix
+	}{Name: f.bucket, Prefix: prefix, Delimiter: delimiter, MaxKeys: 1000}
+
+	seenPrefixes := make(map[string]bool)
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, prefix)
+		if delimiter != "" {
+			if idx := strings.Index(rest, delimiter); idx >= 0 {
+				p := prefix + rest[:idx+len(delimiter)]
+				if !seenPrefixes[p] {
+					seenPrefixes[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: p})
+				}
+				continue
+			}
+		}
+		obj := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.modTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(obj.data),
+			Size:         len(obj.data),
+			StorageClass: "STANDARD",
+		})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	writeXML(w, result)
+}
+
+func (f *fakeS3Server) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeS3Server) object(key string) *fakeS3Object {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[key]
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data) // #nosec G401 -- S3 ETags are MD5 based
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: code})
+}
+
+// newTestS3Target creates an S3 target pointing at the fake server
+func newTestS3Target(t *testing.T, server *httptest.Server, extra map[string]any) *S3Target {
+	t.Helper()
+	settings := map[string]any{
+		"endpoint":          server.URL,
+		"region":            "us-east-1",
+		"bucket":            "backups",
+		"access_key_id":     "test-access-key",
+		"secret_access_key": "test-secret-key",
+		"prefix":            "birdnet-go",
+		"force_path_style":  true,
+	}
+	for k, v := range extra {
+		settings[k] = v
+	}
+	target, err := NewS3Target(settings, nil)
+	require.NoError(t, err)
+	target.config.RetryBackoff = time.Millisecond
+	return target
+}
+
+// writeTestArchive writes a fake archive of the given size and returns its path
+func writeTestArchive(t *testing.T, name string, size int) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), name)
+	data := bytes.Repeat([]byte("birdnet"), size/7+1)[:size]
+	require.NoError(t, os.WriteFile(archivePath, data, 0o600))
+	return archivePath
+}
+
+func TestS3Target_StoreListDelete(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeS3Server(t, "backups")
+	target := newTestS3Target(t, server, nil)
+	ctx := context.Background()
+
+	require.NoError(t, target.Validate())
+
+	older := &backup.Metadata{Version: 1, ID: "sqlite-20250101-020000", Timestamp: time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC), Source: "sqlite", IsDaily: true}
+	newer := &backup.Metadata{Version: 1, ID: "sqlite-20250102-020000", Timestamp: time.Date(2025, 1, 2, 2, 0, 0, 0, time.UTC), Source: "sqlite", IsDaily: true}
+
+	require.NoError(t, target.Store(ctx, writeTestArchive(t, older.ID+".tar", 1024), older))
+	require.NoError(t, target.Store(ctx, writeTestArchive(t, newer.ID+".tar.enc", 2048), newer))
+
+	assert.Equal(t, []string{
+		"birdnet-go/sqlite-20250101-020000.tar",
+		"birdnet-go/sqlite-20250101-020000.tar.meta",
+		"birdnet-go/sqlite-20250102-020000.tar.enc",
+		"birdnet-go/sqlite-20250102-020000.tar.enc.meta",
+	}, fake.keys(), "validation test object should be cleaned up")
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, newer.ID, backups[0].ID, "backups should be sorted newest first")
+	assert.Equal(t, older.ID, backups[1].ID)
+	assert.Equal(t, "s3", backups[0].Target)
+	assert.Equal(t, int64(2048), backups[0].Size, "size should fall back to object size")
+
+	// Retention deletes by metadata ID
+	require.NoError(t, target.Delete(ctx, older.ID))
+	assert.Equal(t, []string{
+		"birdnet-go/sqlite-20250102-020000.tar.enc",
+		"birdnet-go/sqlite-20250102-020000.tar.enc.meta",
+	}, fake.keys())
+
+	backups, err = target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, newer.ID, backups[0].ID)
+
+	err = target.Delete(ctx, "does-not-exist")
+	require.Error(t, err)
+	assert.True(t, backup.IsErrorCode(err, backup.ErrNotFound))
+}
+
+func TestS3Target_MultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeS3Server(t, "backups")
+	target := newTestS3Target(t, server, map[string]any{"part_size_mb": 5})
+
+	metadata := &backup.Metadata{Version: 1, ID: "sqlite-20250103-020000", Timestamp: time.Now().UTC(), Source: "sqlite"}
+	archivePath := writeTestArchive(t, metadata.ID+".tar", 11*1024*1024)
+	require.NoError(t, target.Store(context.Background(), archivePath, metadata))
+
+	assert.Equal(t, 1, fake.completedMultipart, "archive larger than part size should use multipart upload")
+
+	want, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+	obj := fake.object("birdnet-go/" + metadata.ID + ".tar")
+	require.NotNil(t, obj)
+	assert.Equal(t, want, obj.data, "multipart upload should reassemble the archive")
+}
+
+func TestS3Target_ListIgnoresForeignObjects(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeS3Server(t, "backups")
+	target := newTestS3Target(t, server, nil)
+	ctx := context.Background()
+
+	metadata := &backup.Metadata{Version: 1, ID: "sqlite-20250104-020000", Timestamp: time.Now().UTC(), Source: "sqlite"}
+	require.NoError(t, target.Store(ctx, writeTestArchive(t, metadata.ID+".tar", 64), metadata))
+
+	// Objects outside the prefix, nested below it or orphaned metadata must be ignored
+	fake.mu.Lock()
+	fake.objects["other/sqlite-20250105-020000.tar.meta"] = &fakeS3Object{data: []byte(`{"id":"x"}`), modTime: time.Now()}
+	fake.objects["birdnet-go/nested/a.tar.meta"] = &fakeS3Object{data: []byte(`{"id":"y"}`), modTime: time.Now()}
+	fake.objects["birdnet-go/orphan.tar.meta"] = &fakeS3Object{data: []byte(`{"id":"z"}`), modTime: time.Now()}
+	fake.mu.Unlock()
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, metadata.ID, backups[0].ID)
+}
+
+func TestS3Target_ServerSideEncryptionHeaders(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeS3Server(t, "backups")
+	target := newTestS3Target(t, server, map[string]any{"encryption": "sse-s3"})
+
+	metadata := &backup.Metadata{Version: 1, ID: "sqlite-20250106-020000", Timestamp: time.Now().UTC(), Source: "sqlite"}
+	require.NoError(t, target.Store(context.Background(), writeTestArchive(t, metadata.ID+".tar", 64), metadata))
+
+	require.NotEmpty(t, fake.putHeaders)
+	for _, h := range fake.putHeaders {
+		assert.Equal(t, "AES256", h.Get("X-Amz-Server-Side-Encryption"))
+	}
+}
+
+func TestNewS3Target_Config(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "missing bucket",
+			settings: map[string]any{"region": "us-east-1"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing region",
+			settings: map[string]any{"bucket": "backups"},
+			wantErr:  true,
+		},
+		{
+			name:     "default AWS endpoint",
+			settings: map[string]any{"bucket": "backups", "region": "eu-north-1"},
+		},
+		{
+			name:     "endpoint with path",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "endpoint": "http://minio:9000/bucket"},
+			wantErr:  true,
+		},
+		{
+			name:     "kms without key id",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "encryption": "sse-kms"},
+			wantErr:  true,
+		},
+		{
+			name:     "kms with key id",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "encryption": "SSE-KMS", "kms_key_id": "alias/backups"},
+		},
+		{
+			name:     "unknown encryption",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "encryption": "rot13"},
+			wantErr:  true,
+		},
+		{
+			name:     "part size below S3 minimum",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "part_size_mb": 1},
+			wantErr:  true,
+		},
+		{
+			name:     "part size from JSON",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "part_size_mb": float64(32)},
+		},
+		{
+			name:     "fractional part size",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "part_size_mb": 7.5},
+			wantErr:  true,
+		},
+		{
+			name:     "part size as string",
+			settings: map[string]any{"bucket": "backups", "region": "us-east-1", "part_size_mb": "16"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewS3Target(tt.settings, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseS3Endpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint   string
+		useSSL     bool
+		wantHost   string
+		wantSecure bool
+	}{
+		{endpoint: "", useSSL: false, wantHost: "s3.eu-north-1.amazonaws.com", wantSecure: true},
+		{endpoint: "minio.local:9000", useSSL: false, wantHost: "minio.local:9000", wantSecure: false},
+		{endpoint: "minio.local:9000", useSSL: true, wantHost: "minio.local:9000", wantSecure: true},
+		{endpoint: "http://garage.lan:3900/", useSSL: true, wantHost: "garage.lan:3900", wantSecure: false},
+		{endpoint: "https://s3.us-west-004.backblazeb2.com", useSSL: false, wantHost: "s3.us-west-004.backblazeb2.com", wantSecure: true},
+	}
+
+	for _, tt := range tests {
+		host, secure, err := parseS3Endpoint(tt.endpoint, "eu-north-1", tt.useSSL)
+		require.NoError(t, err, tt.endpoint)
+		assert.Equal(t, tt.wantHost, host, tt.endpoint)
+		assert.Equal(t, tt.wantSecure, secure, tt.endpoint)
+	}
+}
@@ -835,6 +835,59 @@ func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
 	})
 }
 
+// Retrieve implements the backup.Target interface
+func (t *SFTPTarget) Retrieve(ctx context.Context, name, destPath string) error {
+	if t.config.Debug {
+		t.logger.Debug("SFTP: Retrieving backup",
+			"name", name,
+			"host", t.config.Host)
+	}
+
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+	backupPath := path.Join(t.config.BasePath, name)
+	if err := t.validatePath(backupPath); err != nil {
+		return err
+	}
+
+	return t.withRetry(ctx, func(client *sftp.Client) error {
+		remoteFile, err := client.Open(backupPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return backup.NewError(backup.ErrNotFound, fmt.Sprintf("sftp: backup %s not found", name), err)
+			}
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "open_remote_backup").
+				Context("target", name).
+				Build()
+		}
+		defer func() {
+			if err := remoteFile.Close(); err != nil {
+				t.logger.Debug("SFTP: Failed to close remote file", "path", backupPath, "error", err)
+			}
+		}()
+
+		if err := writeRetrievedFile(ctx, destPath, remoteFile); err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryNetwork).
+				Context("operation", "download_backup").
+				Context("target", name).
+				Build()
+		}
+
+		if t.config.Debug {
+			t.logger.Debug("SFTP: Successfully retrieved backup",
+				"name", name)
+		}
+
+		return nil
+	})
+}
+
 // Validate checks if the target configuration is valid
 func (t *SFTPTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
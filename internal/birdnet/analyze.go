@@ -30,6 +30,13 @@ func (bn *BirdNET) Predict(sample [][]float32) ([]datastore.Results, error) {
 
 // PredictWithContext performs inference with tracing support
 func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) ([]datastore.Results, error) {
+	results, _, err := bn.predict(ctx, sample, false)
+	return results, err
+}
+
+// predict performs inference on a sample and returns the top results, and the embedding of
+// the sample when withEmbedding is set and embeddings are enabled for a model exposing them
+func (bn *BirdNET) predict(ctx context.Context, sample [][]float32, withEmbedding bool) ([]datastore.Results, []float32, error) {
 	span, _ := StartSpan(ctx, "birdnet.predict", "Species prediction")
 	defer span.Finish()
 
@@ -40,13 +47,26 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 		span.SetData("sample_size", len(sample[0]))
 	}
 
-	// implement locking to prevent concurrent access to the interpreter, not
-	// necessarily best way to manage multiple audio sources but works for now
-	bn.mu.Lock()
-	defer bn.mu.Unlock()
+	// Hold the read lock so a model reload waits for ongoing predictions, then take a free
+	// interpreter from the pool. Predictions of different sources run in parallel.
+	bn.mu.RLock()
+	defer bn.mu.RUnlock()
+
+	source := sourceFromContext(ctx)
+	pi, err := bn.pool.acquire(ctx, source)
+	if err != nil {
+		return nil, nil, errors.New(err).
+			Category(errors.CategoryAudio).
+			ModelContext(bn.Settings.BirdNET.ModelPath, bn.ModelInfo.ID).
+			Context("source", source).
+			Context("operation", "interpreter_acquire").
+			Build()
+	}
+	defer bn.pool.release(pi)
+	span.SetData("interpreter_wait_ms", time.Since(start).Milliseconds())
 
 	// Get the input tensor from the interpreter
-	inputTensor := bn.AnalysisInterpreter.GetInputTensor(0)
+	inputTensor := pi.interpreter.GetInputTensor(0)
 	if inputTensor == nil {
 		err := errors.New(fmt.Errorf("cannot get input tensor")).
 			Category(errors.CategoryModelInit).
@@ -63,7 +83,7 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 			globalMetrics.RecordPrediction(bn.ModelInfo.ID, time.Since(start).Seconds(), err)
 		}
 
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Preparing input tensor with the sample data
@@ -74,7 +94,7 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 
 	// Invoke the interpreter to perform inference
 	invokeStart := time.Now()
-	if status := bn.AnalysisInterpreter.Invoke(); status != tflite.OK {
+	if status := pi.interpreter.Invoke(); status != tflite.OK {
 		err := errors.Newf("tensor invoke failed: %v", status).
 			Category(errors.CategoryAudio).
 			ModelContext(bn.Settings.BirdNET.ModelPath, bn.ModelInfo.ID).
@@ -92,7 +112,7 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 			globalMetrics.RecordPrediction(bn.ModelInfo.ID, time.Since(start).Seconds(), err)
 		}
 
-		return nil, err
+		return nil, nil, err
 	}
 
 	invokeDuration := time.Since(invokeStart)
@@ -104,14 +124,14 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 	}
 
 	// Read the results from the output tensor
-	outputTensor := bn.AnalysisInterpreter.GetOutputTensor(0)
+	outputTensor := pi.interpreter.GetOutputTensor(0)
 	predictions := extractPredictions(outputTensor)
 
 	// Use optimized sigmoid function with buffer reuse
-	confidence := applySigmoidToPredictionsReuse(predictions, bn.Settings.BirdNET.Sensitivity, bn.confidenceBuffer)
+	confidence := applySigmoidToPredictionsReuse(predictions, bn.Settings.BirdNET.Sensitivity, pi.confidenceBuffer)
 
 	// Use the pre-allocated buffer to reduce memory allocations
-	results, err := pairLabelsAndConfidenceReuse(bn.Settings.BirdNET.Labels, confidence, bn.resultsBuffer)
+	results, err := pairLabelsAndConfidenceReuse(bn.Settings.BirdNET.Labels, confidence, pi.resultsBuffer)
 	if err != nil {
 		err = errors.New(err).
 			Category(errors.CategoryValidation).
@@ -128,11 +148,12 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 			globalMetrics.RecordPrediction(bn.ModelInfo.ID, time.Since(start).Seconds(), err)
 		}
 
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Use optimized top-k algorithm instead of full sort + trim
-	topResults := getTopKResults(results, 10)
+	// Use optimized top-k algorithm instead of full sort + trim, and copy the top results out
+	// of the interpreter buffer which the next prediction reuses
+	topResults := tagResults(getTopKResults(results, 10), bn.ModelInfo.ID)
 
 	// Log prediction timing for performance monitoring
 	duration := time.Since(start)
@@ -145,8 +166,14 @@ func (bn *BirdNET) PredictWithContext(ctx context.Context, sample [][]float32) (
 
 	// The span.Finish() will automatically record the prediction metrics
 
+	// Copy the embedding out of the interpreter before it is released to the next prediction
+	var embedding []float32
+	if withEmbedding && bn.extractsEmbeddings() {
+		embedding = extractEmbedding(pi.interpreter, bn.embeddingOutput)
+	}
+
 	// Return the top 10 results
-	return topResults, nil
+	return topResults, embedding, nil
 }
 
 // AnalyzeAudio processes audio data in chunks and predicts species using the BirdNET model.
@@ -15,9 +15,9 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/cpuspec"
-	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/telemetry"
 	tflite "github.com/tphakala/go-tflite"
@@ -39,16 +39,17 @@ type speciesCacheEntry struct {
 
 // BirdNET struct represents the BirdNET model with interpreters and configuration.
 type BirdNET struct {
-	AnalysisInterpreter *tflite.Interpreter
+	AnalysisInterpreter *tflite.Interpreter // First interpreter of the analysis interpreter pool
 	RangeInterpreter    *tflite.Interpreter
 	Settings            *conf.Settings
 	ModelInfo           ModelInfo           // Information about the current model
 	TaxonomyMap         TaxonomyMap         // Mapping of species codes to names and vice versa
 	ScientificIndex     ScientificNameIndex // Index for fast scientific name lookups
 	TaxonomyPath        string              // Path to custom taxonomy file, if used
-	mu                  sync.Mutex
-	resultsBuffer       []datastore.Results // Pre-allocated buffer for results to reduce allocations
-	confidenceBuffer    []float32           // Pre-allocated buffer for confidence values to reduce allocations
+	mu                  sync.RWMutex        // Held for reading by predictions and for writing by model reload
+	pool                *interpreterPool    // Analysis interpreters shared by concurrent predictions
+	models              []*additionalModel  // Additional models analyzing the same audio
+	embeddingOutput     int                 // Index of the embeddings output tensor, 0 when the model has none
 	
 	// Species occurrence cache to avoid repeated GetProbableSpecies calls within same day
 	speciesCacheMu      sync.RWMutex
@@ -144,6 +145,15 @@ func NewBirdNET(settings *conf.Settings) (*BirdNET, error) {
 			Build()
 	}
 
+	if err := bn.initializeAdditionalModels(); err != nil {
+		bn.Delete()
+		return nil, errors.New(fmt.Errorf("BirdNET: failed to initialize additional models: %w", err)).
+			Component("birdnet").
+			Category(errors.CategoryModelInit).
+			Context("model_count", len(settings.BirdNET.Models)).
+			Build()
+	}
+
 	return bn, nil
 }
 
@@ -171,41 +181,25 @@ func (bn *BirdNET) initializeModel() error {
 			Build()
 	}
 
-	// Determine the number of threads for the interpreter based on settings and system capacity.
+	// Determine the number of threads based on settings and system capacity, and share them
+	// between the interpreters of the pool.
 	threads := bn.determineThreadCount(bn.Settings.BirdNET.Threads)
+	count := bn.determineInterpreterCount(threads, len(modelData))
+	interpreterThreads := max(1, threads/count)
 
-	// Configure interpreter options.
-	options := tflite.NewInterpreterOptions()
-
-	// Try to use XNNPACK delegate if enabled in settings
-	if bn.Settings.BirdNET.UseXNNPACK {
-		delegate := xnnpack.New(xnnpack.DelegateOptions{NumThreads: int32(max(1, threads-1))}) //nolint:gosec // G115: thread count bounded by CPU count, safe conversion
-		if delegate == nil {
-			fmt.Println("⚠️ Failed to create XNNPACK delegate, falling back to default CPU")
-			fmt.Println("Please download updated tensorflow lite C API library from:")
-			fmt.Println("https://github.com/tphakala/tflite_c/releases/tag/v2.17.1")
-			fmt.Println("and install it to enable use of XNNPACK delegate")
-			options.SetNumThread(threads)
-		} else {
-			options.AddDelegate(delegate)
-			options.SetNumThread(1)
+	interpreters := make([]*tflite.Interpreter, 0, count)
+	for range count {
+		interpreter, err := bn.newAnalysisInterpreter(model, interpreterThreads)
+		if err != nil {
+			for _, created := range interpreters {
+				created.Delete()
+			}
+			return err
 		}
-	} else {
-		options.SetNumThread(threads)
-	}
-
-	options.SetErrorReporter(func(msg string, user_data interface{}) {
-		fmt.Println(msg)
-	}, nil)
-
-	// Create and allocate the TensorFlow Lite interpreter.
-	bn.AnalysisInterpreter = tflite.NewInterpreter(model, options)
-	if bn.AnalysisInterpreter == nil {
-		return fmt.Errorf("cannot create interpreter")
-	}
-	if status := bn.AnalysisInterpreter.AllocateTensors(); status != tflite.OK {
-		return fmt.Errorf("tensor allocation failed")
+		interpreters = append(interpreters, interpreter)
 	}
+	bn.pool = newInterpreterPool(interpreters, true)
+	bn.AnalysisInterpreter = bn.pool.first()
 	
 	// Force garbage collection to reclaim memory from model loading
 	// The model data is no longer needed as TFLite has created its own internal copy
@@ -239,10 +233,73 @@ func (bn *BirdNET) initializeModel() error {
 		initMessage = fmt.Sprintf("%s model initialized, using configured %v threads of available %v CPUs",
 			modelVersion, threads, runtime.NumCPU())
 	}
+	if count > 1 {
+		initMessage += fmt.Sprintf(", %v interpreters with %v threads each", count, interpreterThreads)
+	}
 	fmt.Println(initMessage)
 	return nil
 }
 
+// newAnalysisInterpreter creates and allocates an analysis interpreter for the model.
+func (bn *BirdNET) newAnalysisInterpreter(model *tflite.Model, threads int) (*tflite.Interpreter, error) {
+	// Configure interpreter options.
+	options := tflite.NewInterpreterOptions()
+
+	// Try to use XNNPACK delegate if enabled in settings
+	if bn.Settings.BirdNET.UseXNNPACK {
+		delegate := xnnpack.New(xnnpack.DelegateOptions{NumThreads: int32(max(1, threads-1))}) //nolint:gosec // G115: thread count bounded by CPU count, safe conversion
+		if delegate == nil {
+			fmt.Println("⚠️ Failed to create XNNPACK delegate, falling back to default CPU")
+			fmt.Println("Please download updated tensorflow lite C API library from:")
+			fmt.Println("https://github.com/tphakala/tflite_c/releases/tag/v2.17.1")
+			fmt.Println("and install it to enable use of XNNPACK delegate")
+			options.SetNumThread(threads)
+		} else {
+			options.AddDelegate(delegate)
+			options.SetNumThread(1)
+		}
+	} else {
+		options.SetNumThread(threads)
+	}
+
+	options.SetErrorReporter(func(msg string, user_data interface{}) {
+		fmt.Println(msg)
+	}, nil)
+
+	// Create and allocate the TensorFlow Lite interpreter.
+	interpreter := tflite.NewInterpreter(model, options)
+	if interpreter == nil {
+		return nil, fmt.Errorf("cannot create interpreter")
+	}
+	if status := interpreter.AllocateTensors(); status != tflite.OK {
+		interpreter.Delete()
+		return nil, fmt.Errorf("tensor allocation failed")
+	}
+	return interpreter, nil
+}
+
+// determineInterpreterCount returns the number of analysis interpreters, one per audio source
+// unless configured, limited by the thread count and the available memory.
+func (bn *BirdNET) determineInterpreterCount(threads, modelSize int) int {
+	sources := len(bn.Settings.Realtime.RTSP.URLs)
+	if bn.Settings.Input.Path == "" && bn.Settings.Realtime.Audio.Source != "" {
+		sources++
+	}
+
+	var availableMemory uint64
+	if memInfo, err := mem.VirtualMemory(); err == nil {
+		availableMemory = memInfo.Available
+	}
+	interpreterMemory := uint64(modelSize) * interpreterMemoryFactor //nolint:gosec // G115: model size is non-negative
+
+	count := interpreterCount(bn.Settings.BirdNET.Interpreters, sources, threads, availableMemory, interpreterMemory)
+	if configured := bn.Settings.BirdNET.Interpreters; configured > count {
+		log.Printf("⚠️ Using %d of %d configured BirdNET interpreters, limited by %d threads and %d MB available memory",
+			count, configured, threads, availableMemory/1024/1024)
+	}
+	return count
+}
+
 // getMetaModelData returns the appropriate meta model data based on the settings.
 func (bn *BirdNET) getMetaModelData() ([]byte, error) {
 	// Check if external model path is specified
@@ -623,9 +680,11 @@ func (bn *BirdNET) getCachedSpeciesScores(targetDate time.Time) (map[string]floa
 
 // Delete releases resources used by the TensorFlow Lite interpreters.
 func (bn *BirdNET) Delete() {
-	if bn.AnalysisInterpreter != nil {
-		bn.AnalysisInterpreter.Delete()
+	if bn.pool != nil {
+		bn.pool.delete()
 	}
+	deleteAdditionalModels(bn.models)
+	bn.models = nil
 	if bn.RangeInterpreter != nil {
 		bn.RangeInterpreter.Delete()
 	}
@@ -859,14 +918,12 @@ func (bn *BirdNET) validateModelAndLabels() error {
 			Build()
 	}
 
-	// Pre-allocate results buffer with the model's output size
-	if bn.resultsBuffer == nil || len(bn.resultsBuffer) != modelOutputSize {
-		bn.resultsBuffer = make([]datastore.Results, modelOutputSize)
-	}
+	// Pre-allocate results and confidence buffers of each interpreter with the model's output size
+	bn.pool.allocateBuffers(modelOutputSize)
 
-	// Pre-allocate confidence buffer with the model's output size
-	if bn.confidenceBuffer == nil || len(bn.confidenceBuffer) != modelOutputSize {
-		bn.confidenceBuffer = make([]float32, modelOutputSize)
+	bn.embeddingOutput = findEmbeddingOutput(bn.AnalysisInterpreter)
+	if bn.Settings.BirdNET.Embeddings && bn.embeddingOutput == 0 {
+		log.Printf("⚠️ Embeddings are enabled but model %s has no embeddings output, similarity search is not available", bn.ModelInfo.ID)
 	}
 
 	bn.Debug("\033[32m✅ Model validation successful: %d labels match model output size\033[0m", modelOutputSize)
@@ -881,8 +938,10 @@ func (bn *BirdNET) ReloadModel() error {
 	bn.Debug("\033[32m✅ Acquired mutex for model reload\033[0m")
 
 	// Store old interpreters to clean up after successful reload
+	oldPool := bn.pool
 	oldAnalysisInterpreter := bn.AnalysisInterpreter
 	oldRangeInterpreter := bn.RangeInterpreter
+	oldEmbeddingOutput := bn.embeddingOutput
 
 	// Re-determine model info if using a custom model path
 	if bn.Settings.BirdNET.ModelPath != "" {
@@ -909,11 +968,10 @@ func (bn *BirdNET) ReloadModel() error {
 
 	// Initialize new meta model
 	if err := bn.initializeMetaModel(); err != nil {
-		// Clean up the newly created analysis interpreter if meta model fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		// Clean up the newly created analysis interpreters if meta model fails
+		bn.pool.delete()
 		// Restore the old interpreters
+		bn.pool = oldPool
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ failed to reload meta model: %w\033[0m", err)
@@ -923,13 +981,12 @@ func (bn *BirdNET) ReloadModel() error {
 	// Reload labels
 	if err := bn.loadLabels(); err != nil {
 		// Clean up the newly created interpreters if label loading fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		bn.pool.delete()
 		if bn.RangeInterpreter != nil {
 			bn.RangeInterpreter.Delete()
 		}
 		// Restore the old interpreters
+		bn.pool = oldPool
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ failed to reload labels: %w\033[0m", err)
@@ -939,21 +996,36 @@ func (bn *BirdNET) ReloadModel() error {
 	// Validate that the model and labels match
 	if err := bn.validateModelAndLabels(); err != nil {
 		// Clean up the newly created interpreters if validation fails
-		if bn.AnalysisInterpreter != nil {
-			bn.AnalysisInterpreter.Delete()
-		}
+		bn.pool.delete()
 		if bn.RangeInterpreter != nil {
 			bn.RangeInterpreter.Delete()
 		}
 		// Restore the old interpreters
+		bn.pool = oldPool
 		bn.AnalysisInterpreter = oldAnalysisInterpreter
 		bn.RangeInterpreter = oldRangeInterpreter
 		return fmt.Errorf("\033[31m❌ model validation failed: %w\033[0m", err)
 	}
 
+	// Reload additional models on the new interpreter settings
+	oldModels := bn.models
+	if err := bn.initializeAdditionalModels(); err != nil {
+		bn.pool.delete()
+		if bn.RangeInterpreter != nil {
+			bn.RangeInterpreter.Delete()
+		}
+		// Restore the old interpreters
+		bn.pool = oldPool
+		bn.AnalysisInterpreter = oldAnalysisInterpreter
+		bn.RangeInterpreter = oldRangeInterpreter
+		bn.embeddingOutput = oldEmbeddingOutput
+		return fmt.Errorf("\033[31m❌ failed to reload additional models: %w\033[0m", err)
+	}
+	deleteAdditionalModels(oldModels)
+
 	// Clean up old interpreters after successful reload
-	if oldAnalysisInterpreter != nil {
-		oldAnalysisInterpreter.Delete()
+	if oldPool != nil {
+		oldPool.delete()
 	}
 	if oldRangeInterpreter != nil {
 		oldRangeInterpreter.Delete()
@@ -0,0 +1,47 @@
+// embeddings.go extraction of the embeddings of analyzed chunks for similarity search
+package birdnet
+
+import (
+	tflite "github.com/tphakala/go-tflite"
+)
+
+// findEmbeddingOutput returns the index of the output tensor holding the embeddings of the
+// analyzed chunk, the first float32 output after the class logits, or 0 when the model has
+// no embeddings output. Interpreters cannot read intermediate layers, so the model must be
+// exported with the embeddings layer as an additional output.
+func findEmbeddingOutput(interpreter *tflite.Interpreter) int {
+	if interpreter == nil {
+		return 0
+	}
+	for i := 1; i < interpreter.GetOutputTensorCount(); i++ {
+		tensor := interpreter.GetOutputTensor(i)
+		if tensor != nil && tensor.Type() == tflite.Float32 && tensor.NumDims() > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// extractEmbedding copies the embeddings out of the output tensor of an interpreter
+func extractEmbedding(interpreter *tflite.Interpreter, index int) []float32 {
+	tensor := interpreter.GetOutputTensor(index)
+	if tensor == nil {
+		return nil
+	}
+	values := tensor.Float32s()
+	embedding := make([]float32, len(values))
+	copy(embedding, values)
+	return embedding
+}
+
+// HasEmbeddings reports whether the analysis model exposes embeddings of the analyzed chunks
+func (bn *BirdNET) HasEmbeddings() bool {
+	bn.mu.RLock()
+	defer bn.mu.RUnlock()
+	return bn.embeddingOutput > 0
+}
+
+// extractsEmbeddings reports whether predictions extract embeddings. Must be called with bn.mu held.
+func (bn *BirdNET) extractsEmbeddings() bool {
+	return bn.Settings.BirdNET.Embeddings && bn.embeddingOutput > 0
+}
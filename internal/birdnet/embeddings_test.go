@@ -0,0 +1,27 @@
+package birdnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestExtractsEmbeddings(t *testing.T) {
+	t.Parallel()
+	bn := &BirdNET{Settings: &conf.Settings{}}
+	assert.Equal(t, 0, findEmbeddingOutput(nil))
+	assert.False(t, bn.extractsEmbeddings())
+
+	// Enabled embeddings need a model with an embeddings output
+	bn.Settings.BirdNET.Embeddings = true
+	assert.False(t, bn.extractsEmbeddings())
+	assert.False(t, bn.HasEmbeddings())
+
+	bn.embeddingOutput = 1
+	assert.True(t, bn.extractsEmbeddings())
+	assert.True(t, bn.HasEmbeddings())
+
+	bn.Settings.BirdNET.Embeddings = false
+	assert.False(t, bn.extractsEmbeddings())
+}
@@ -0,0 +1,291 @@
+// model_set.go additional classification models analyzing the same audio as the BirdNET model
+package birdnet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio/equalizer"
+	"github.com/tphakala/birdnet-go/internal/myaudio/resample"
+	tflite "github.com/tphakala/go-tflite"
+)
+
+// chunkSeconds is the length of the audio chunks analyzed by every model
+const chunkSeconds = 3
+
+// additionalModel is a classification model with its own labels and interpreters that
+// analyzes the same audio chunks as the BirdNET model
+type additionalModel struct {
+	settings  conf.ModelSettings
+	labels    []string
+	inputSize int // samples per chunk expected by the model, chunks are resampled to it
+	pool      *interpreterPool
+}
+
+// initializeAdditionalModels loads the enabled additional models with the same number of
+// interpreters and threads per interpreter as the BirdNET model
+func (bn *BirdNET) initializeAdditionalModels() error {
+	if bn.pool == nil {
+		return fmt.Errorf("BirdNET model must be initialized before additional models")
+	}
+	count := bn.pool.size()
+	threads := max(1, bn.determineThreadCount(bn.Settings.BirdNET.Threads)/count)
+
+	models := make([]*additionalModel, 0, len(bn.Settings.BirdNET.Models))
+	for i := range bn.Settings.BirdNET.Models {
+		settings := bn.Settings.BirdNET.Models[i]
+		if !settings.Enabled {
+			continue
+		}
+		model, err := bn.loadAdditionalModel(&settings, count, threads)
+		if err != nil {
+			deleteAdditionalModels(models)
+			return err
+		}
+		models = append(models, model)
+		fmt.Printf("%s model initialized with %d labels\n", settings.ID, len(model.labels))
+	}
+	bn.models = models
+	return nil
+}
+
+// loadAdditionalModel loads an additional model and its labels, and checks them against the
+// model input and output sizes
+func (bn *BirdNET) loadAdditionalModel(settings *conf.ModelSettings, count, threads int) (*additionalModel, error) {
+	start := time.Now()
+	buildError := func(err error, category errors.ErrorCategory, operation string) error {
+		return errors.New(err).
+			Category(category).
+			ModelContext(settings.ModelPath, settings.ID).
+			Context("label_path", settings.LabelPath).
+			Context("operation", operation).
+			Timing("additional-model-load", time.Since(start)).
+			Build()
+	}
+
+	labels, err := readLabelFile(settings.LabelPath)
+	if err != nil {
+		return nil, buildError(err, errors.CategoryLabelLoad, "load_labels")
+	}
+
+	modelData, err := os.ReadFile(settings.ModelPath)
+	if err != nil {
+		return nil, buildError(err, errors.CategoryModelLoad, "read_model")
+	}
+	tfModel := tflite.NewModel(modelData)
+	if tfModel == nil {
+		return nil, buildError(fmt.Errorf("cannot load TensorFlow Lite model"), errors.CategoryModelInit, "load_model")
+	}
+
+	interpreters := make([]*tflite.Interpreter, 0, count)
+	for range count {
+		interpreter, err := bn.newAnalysisInterpreter(tfModel, threads)
+		if err != nil {
+			for _, created := range interpreters {
+				created.Delete()
+			}
+			return nil, buildError(err, errors.CategoryModelInit, "create_interpreter")
+		}
+		interpreters = append(interpreters, interpreter)
+	}
+	model := &additionalModel{
+		settings: *settings,
+		labels:   labels,
+		pool:     newInterpreterPool(interpreters, false),
+	}
+
+	if err := model.validate(); err != nil {
+		model.pool.delete()
+		return nil, buildError(err, errors.CategoryValidation, "validate_model")
+	}
+	return model, nil
+}
+
+// validate checks that the label count matches the model output and the configured sample
+// rate matches the model input and is not above the capture sample rate, and allocates the
+// output buffers
+func (m *additionalModel) validate() error {
+	interpreter := m.pool.first()
+	inputTensor := interpreter.GetInputTensor(0)
+	outputTensor := interpreter.GetOutputTensor(0)
+	if inputTensor == nil || outputTensor == nil {
+		return fmt.Errorf("cannot get input and output tensors from model")
+	}
+
+	m.inputSize = inputTensor.Dim(inputTensor.NumDims() - 1)
+	if m.settings.SampleRate > 0 && m.inputSize != m.settings.SampleRate*chunkSeconds {
+		return fmt.Errorf("model input of %d samples does not match %d seconds at %d Hz",
+			m.inputSize, chunkSeconds, m.settings.SampleRate)
+	}
+	if m.inputSize > conf.SampleRate*chunkSeconds {
+		return fmt.Errorf("model input of %d samples is above %d seconds at the %d Hz capture sample rate",
+			m.inputSize, chunkSeconds, conf.SampleRate)
+	}
+
+	outputSize := outputTensor.Dim(outputTensor.NumDims() - 1)
+	if outputSize != len(m.labels) {
+		return fmt.Errorf("label count mismatch: model expects %d classes but label file has %d labels",
+			outputSize, len(m.labels))
+	}
+	m.pool.allocateBuffers(outputSize)
+	return nil
+}
+
+// predict runs the model on a chunk and returns its top results tagged with the model ID
+func (m *additionalModel) predict(ctx context.Context, chunk []float32, sensitivity float64) ([]datastore.Results, error) {
+	pi, err := m.pool.acquire(ctx, sourceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer m.pool.release(pi)
+
+	inputTensor := pi.interpreter.GetInputTensor(0)
+	if inputTensor == nil {
+		return nil, fmt.Errorf("cannot get input tensor")
+	}
+	resampled, err := resampleChunk(chunk, m.inputSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resample audio for model %s: %w", m.settings.ID, err)
+	}
+	copy(inputTensor.Float32s(), resampled)
+
+	if status := pi.interpreter.Invoke(); status != tflite.OK {
+		return nil, fmt.Errorf("tensor invoke failed: %v", status)
+	}
+
+	predictions := extractPredictions(pi.interpreter.GetOutputTensor(0))
+	confidence := applySigmoidToPredictionsReuse(predictions, sensitivity, pi.confidenceBuffer)
+	results, err := pairLabelsAndConfidenceReuse(m.labels, confidence, pi.resultsBuffer)
+	if err != nil {
+		return nil, err
+	}
+	return tagResults(getTopKResults(results, 10), m.settings.ID), nil
+}
+
+// PredictModelSetWithContext runs the BirdNET model and the additional models on the same
+// sample and returns the top results of each model, tagged with the model ID, and the
+// embedding of the sample when embeddings are enabled. A failing additional model is logged
+// and skipped so it does not stop BirdNET detections.
+func (bn *BirdNET) PredictModelSetWithContext(ctx context.Context, sample [][]float32) ([]datastore.Results, []float32, error) {
+	results, embedding, err := bn.predict(ctx, sample, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bn.mu.RLock()
+	defer bn.mu.RUnlock()
+	for _, model := range bn.models {
+		modelResults, err := model.predict(ctx, sample[0], bn.Settings.BirdNET.Sensitivity)
+		if err != nil {
+			log.Printf("⚠️ Prediction of model %s failed: %v", model.settings.ID, err)
+			continue
+		}
+		results = append(results, modelResults...)
+	}
+	return results, embedding, nil
+}
+
+// ModelIDs returns the IDs of the BirdNET model and the loaded additional models
+func (bn *BirdNET) ModelIDs() []string {
+	bn.mu.RLock()
+	defer bn.mu.RUnlock()
+	ids := make([]string, 0, len(bn.models)+1)
+	ids = append(ids, bn.ModelInfo.ID)
+	for _, model := range bn.models {
+		ids = append(ids, model.settings.ID)
+	}
+	return ids
+}
+
+// tagResults copies results out of the reused prediction buffer and tags them with the model ID
+func tagResults(results []datastore.Results, modelID string) []datastore.Results {
+	tagged := make([]datastore.Results, len(results))
+	for i := range results {
+		tagged[i] = results[i]
+		tagged[i].Model = modelID
+	}
+	return tagged
+}
+
+// Anti-aliasing filter applied before a chunk is downsampled: the cutoff is a fraction of the
+// Nyquist frequency of the model, each pass adds 12 dB/octave of attenuation above it
+const (
+	antiAliasCutoff = 0.8
+	antiAliasQ      = 0.707
+	antiAliasPasses = 4
+)
+
+// resampleChunk resamples a chunk to the given number of samples. A 3 second chunk resampled to
+// the model input size is the chunk at the model sample rate, so the sample counts are used as
+// the sample rates. Chunks are low-pass filtered below the Nyquist frequency of the model before
+// downsampling, so frequencies the model cannot represent do not alias into its input.
+func resampleChunk(chunk []float32, size int) ([]float32, error) {
+	if len(chunk) == size || len(chunk) == 0 {
+		return chunk, nil
+	}
+
+	input := chunk
+	if size < len(chunk) {
+		filter, err := equalizer.NewLowPass(float64(len(chunk)), antiAliasCutoff*float64(size)/2, antiAliasQ, antiAliasPasses)
+		if err != nil {
+			return nil, err
+		}
+		samples := make([]float64, len(chunk))
+		for i, v := range chunk {
+			samples[i] = float64(v)
+		}
+		filter.ApplyBatch(samples)
+		input = make([]float32, len(samples))
+		for i, v := range samples {
+			input[i] = float32(v)
+		}
+	}
+
+	resampled, err := resample.Cubic(input, len(chunk), size)
+	if err != nil {
+		return nil, err
+	}
+	// Rounding may leave the resampled chunk a sample short of the model input size
+	if len(resampled) < size {
+		resampled = append(resampled, make([]float32, size-len(resampled))...)
+	}
+	return resampled[:size], nil
+}
+
+// readLabelFile reads a label file with one label per line
+func readLabelFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if label := strings.TrimSpace(scanner.Text()); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("label file %s is empty", path)
+	}
+	return labels, nil
+}
+
+// deleteAdditionalModels releases the interpreters of the additional models
+func deleteAdditionalModels(models []*additionalModel) {
+	for _, model := range models {
+		model.pool.delete()
+	}
+}
@@ -0,0 +1,96 @@
+package birdnet
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	tflite "github.com/tphakala/go-tflite"
+)
+
+func TestResampleChunk(t *testing.T) {
+	t.Parallel()
+	chunk := []float32{0, 1, 2, 3, 4}
+	same, err := resampleChunk(chunk, 5)
+	require.NoError(t, err)
+	assert.Equal(t, chunk, same)
+
+	// 3 seconds at 48 kHz resampled for a 32 kHz model
+	tone := func(frequency float64) []float32 {
+		samples := make([]float32, 3*conf.SampleRate)
+		for i := range samples {
+			samples[i] = float32(math.Sin(2 * math.Pi * frequency * float64(i) / conf.SampleRate))
+		}
+		return samples
+	}
+	rms := func(samples []float32) float64 {
+		var sum float64
+		for _, v := range samples[len(samples)/10:] {
+			sum += float64(v) * float64(v)
+		}
+		return math.Sqrt(sum / float64(len(samples)-len(samples)/10))
+	}
+
+	passed, err := resampleChunk(tone(3000), 96000)
+	require.NoError(t, err)
+	require.Len(t, passed, 96000)
+	assert.InDelta(t, math.Sqrt2/2, rms(passed), 0.05, "frequencies well below the model Nyquist frequency are kept")
+
+	// A 20 kHz tone would alias to 12 kHz at 32 kHz without the low-pass filter
+	aliased, err := resampleChunk(tone(20000), 96000)
+	require.NoError(t, err)
+	require.Len(t, aliased, 96000)
+	assert.Less(t, rms(aliased), 0.01, "frequencies above the model Nyquist frequency are filtered")
+}
+
+func TestTagResultsCopiesBuffer(t *testing.T) {
+	t.Parallel()
+	buffer := []datastore.Results{{Species: "Myotis daubentonii_Daubenton's Bat", Confidence: 0.9}}
+	tagged := tagResults(buffer, "bats")
+	buffer[0].Species = "overwritten by the next prediction"
+
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "Myotis daubentonii_Daubenton's Bat", tagged[0].Species)
+	assert.Equal(t, "bats", tagged[0].Model)
+	assert.Empty(t, buffer[0].Model)
+}
+
+func TestReadLabelFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "labels.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Myotis daubentonii_Daubenton's Bat\n\n Pipistrellus pipistrellus_Common Pipistrelle \n"), 0o600))
+
+	labels, err := readLabelFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Myotis daubentonii_Daubenton's Bat", "Pipistrellus pipistrellus_Common Pipistrelle"}, labels)
+
+	empty := filepath.Join(dir, "empty.txt")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	_, err = readLabelFile(empty)
+	require.Error(t, err)
+}
+
+func TestInitializeAdditionalModels(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.BirdNET.Models = []conf.ModelSettings{
+		{ID: "insects", Enabled: false, ModelPath: "insects.tflite", LabelPath: "insects.txt"},
+	}
+	bn := &BirdNET{Settings: settings, pool: newInterpreterPool([]*tflite.Interpreter{nil}, true)}
+
+	// Disabled models are not loaded
+	require.NoError(t, bn.initializeAdditionalModels())
+	assert.Empty(t, bn.models)
+
+	// Enabled models must load
+	settings.BirdNET.Models[0].Enabled = true
+	settings.BirdNET.Models[0].LabelPath = filepath.Join(t.TempDir(), "missing.txt")
+	require.Error(t, bn.initializeAdditionalModels())
+	assert.Empty(t, bn.models)
+}
@@ -0,0 +1,235 @@
+// pool.go interpreter pool for parallel inference of several audio sources
+package birdnet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+	tflite "github.com/tphakala/go-tflite"
+)
+
+// interpreterMemoryFactor estimates the memory of one analysis interpreter as a multiple of
+// the model size, covering the weights packed by XNNPACK and the tensor arena
+const interpreterMemoryFactor = 3
+
+// defaultSource groups predictions that are not tagged with an audio source
+const defaultSource = "default"
+
+type sourceContextKey struct{}
+
+// WithSource tags the context of a prediction with its audio source. Sources waiting for
+// a free interpreter are served in turn, so one busy source cannot starve the others.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey{}, source)
+}
+
+// sourceFromContext returns the audio source of a prediction
+func sourceFromContext(ctx context.Context) string {
+	if source, ok := ctx.Value(sourceContextKey{}).(string); ok && source != "" {
+		return source
+	}
+	return defaultSource
+}
+
+// pooledInterpreter is an analysis interpreter with its own output buffers
+type pooledInterpreter struct {
+	interpreter      *tflite.Interpreter
+	resultsBuffer    []datastore.Results // Pre-allocated buffer for results to reduce allocations
+	confidenceBuffer []float32           // Pre-allocated buffer for confidence values to reduce allocations
+}
+
+// interpreterPool hands out analysis interpreters to concurrent predictions. When all
+// interpreters are busy, predictions wait in a queue per source and freed interpreters go
+// to the sources in round-robin order.
+type interpreterPool struct {
+	mu      sync.Mutex
+	all     []*pooledInterpreter
+	idle    []*pooledInterpreter
+	waiters map[string][]chan *pooledInterpreter // waiting predictions per source in arrival order
+	order   []string                             // sources with waiting predictions in serving order
+	report  bool                                 // whether the pool reports the interpreter metrics
+}
+
+// newInterpreterPool creates a pool of the given interpreters. The interpreter metrics are not
+// labeled by model, so only the pool of the BirdNET model reports them.
+func newInterpreterPool(interpreters []*tflite.Interpreter, reportMetrics bool) *interpreterPool {
+	p := &interpreterPool{waiters: make(map[string][]chan *pooledInterpreter), report: reportMetrics}
+	for _, interpreter := range interpreters {
+		pi := &pooledInterpreter{interpreter: interpreter}
+		p.all = append(p.all, pi)
+		p.idle = append(p.idle, pi)
+	}
+	if m := p.metrics(); m != nil {
+		m.SetInterpreterPoolSize(len(p.all))
+		m.SetInterpretersBusy(0)
+	}
+	return p
+}
+
+// size returns the number of interpreters in the pool
+func (p *interpreterPool) size() int {
+	return len(p.all)
+}
+
+// first returns the first interpreter, used for model validation
+func (p *interpreterPool) first() *tflite.Interpreter {
+	if len(p.all) == 0 {
+		return nil
+	}
+	return p.all[0].interpreter
+}
+
+// allocateBuffers allocates the output buffers of every interpreter for the model output size
+func (p *interpreterPool) allocateBuffers(outputSize int) {
+	for _, pi := range p.all {
+		if len(pi.resultsBuffer) != outputSize {
+			pi.resultsBuffer = make([]datastore.Results, outputSize)
+		}
+		if len(pi.confidenceBuffer) != outputSize {
+			pi.confidenceBuffer = make([]float32, outputSize)
+		}
+	}
+}
+
+// acquire returns a free interpreter, waiting for one when all are busy. It returns the
+// context error if the context is done before an interpreter is free.
+func (p *interpreterPool) acquire(ctx context.Context, source string) (*pooledInterpreter, error) {
+	p.mu.Lock()
+	if len(p.idle) > 0 && len(p.order) == 0 {
+		pi := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.reportBusy()
+		p.mu.Unlock()
+		return pi, nil
+	}
+
+	ch := make(chan *pooledInterpreter, 1)
+	if len(p.waiters[source]) == 0 {
+		p.order = append(p.order, source)
+	}
+	p.waiters[source] = append(p.waiters[source], ch)
+	p.reportQueueDepth(source)
+	p.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case pi := <-ch:
+		if m := p.metrics(); m != nil {
+			m.RecordInterpreterWait(source, time.Since(start).Seconds())
+		}
+		return pi, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		removed := p.removeWaiter(source, ch)
+		p.mu.Unlock()
+		if !removed {
+			// An interpreter was handed over while the context was canceled
+			p.release(<-ch)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release returns an interpreter to the pool or hands it to the next waiting source
+func (p *interpreterPool) release(pi *pooledInterpreter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.order) == 0 {
+		p.idle = append(p.idle, pi)
+		p.reportBusy()
+		return
+	}
+
+	// Serve the source at the head of the order and move it to the back if it has more waiting
+	source := p.order[0]
+	p.order = p.order[1:]
+	queue := p.waiters[source]
+	ch := queue[0]
+	if len(queue) > 1 {
+		p.waiters[source] = queue[1:]
+		p.order = append(p.order, source)
+	} else {
+		delete(p.waiters, source)
+	}
+	p.reportQueueDepth(source)
+	ch <- pi
+}
+
+// removeWaiter removes a canceled waiter, reporting false if it was already served.
+// Must be called with p.mu held.
+func (p *interpreterPool) removeWaiter(source string, ch chan *pooledInterpreter) bool {
+	queue := p.waiters[source]
+	for i, waiting := range queue {
+		if waiting != ch {
+			continue
+		}
+		queue = append(queue[:i], queue[i+1:]...)
+		if len(queue) > 0 {
+			p.waiters[source] = queue
+		} else {
+			delete(p.waiters, source)
+			for j, s := range p.order {
+				if s == source {
+					p.order = append(p.order[:j], p.order[j+1:]...)
+					break
+				}
+			}
+		}
+		p.reportQueueDepth(source)
+		return true
+	}
+	return false
+}
+
+// metrics returns the metrics the pool reports to, or nil if it does not report metrics
+func (p *interpreterPool) metrics() *metrics.BirdNETMetrics {
+	if !p.report {
+		return nil
+	}
+	return getMetrics()
+}
+
+// reportBusy updates the busy interpreters metric. Must be called with p.mu held.
+func (p *interpreterPool) reportBusy() {
+	if m := p.metrics(); m != nil {
+		m.SetInterpretersBusy(len(p.all) - len(p.idle))
+	}
+}
+
+// reportQueueDepth updates the queue depth metric of a source. Must be called with p.mu held.
+func (p *interpreterPool) reportQueueDepth(source string) {
+	if m := p.metrics(); m != nil {
+		m.SetInterpreterQueueDepth(source, len(p.waiters[source]))
+	}
+}
+
+// delete releases the interpreters of the pool. The pool must not be in use.
+func (p *interpreterPool) delete() {
+	for _, pi := range p.all {
+		if pi.interpreter != nil {
+			pi.interpreter.Delete()
+		}
+	}
+	p.all = nil
+	p.idle = nil
+}
+
+// interpreterCount returns the size of the interpreter pool. A configured count is used
+// as is, otherwise the pool gets one interpreter per audio source. Either is limited to
+// one interpreter per thread and to the interpreters fitting in half of the available
+// memory, when it is known.
+func interpreterCount(configured, sources, threads int, availableMemory, interpreterMemory uint64) int {
+	count := configured
+	if count <= 0 {
+		count = sources
+	}
+	count = min(count, threads)
+	if availableMemory > 0 && interpreterMemory > 0 {
+		count = min(count, int(availableMemory/2/interpreterMemory)) //nolint:gosec // G115: bounded by thread count above
+	}
+	return max(count, 1)
+}
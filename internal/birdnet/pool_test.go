@@ -0,0 +1,148 @@
+package birdnet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/observability/metrics"
+	tflite "github.com/tphakala/go-tflite"
+)
+
+// waitForWaiters waits until the pool has the given number of waiting predictions
+func waitForWaiters(t *testing.T, p *interpreterPool, count int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		waiting := 0
+		for _, queue := range p.waiters {
+			waiting += len(queue)
+		}
+		return waiting == count
+	}, time.Second, time.Millisecond)
+}
+
+func TestInterpreterPoolServesSourcesInTurn(t *testing.T) {
+	t.Parallel()
+	pool := newInterpreterPool([]*tflite.Interpreter{nil}, true)
+	held, err := pool.acquire(context.Background(), "cam1")
+	require.NoError(t, err)
+
+	// cam1 floods the queue before cam2 asks for the interpreter
+	var (
+		mu     sync.Mutex
+		served []string
+		wg     sync.WaitGroup
+	)
+	waiters := []string{"cam1", "cam1", "cam1", "cam2"}
+	for i, source := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pi, err := pool.acquire(context.Background(), source)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			served = append(served, source)
+			mu.Unlock()
+			pool.release(pi)
+		}()
+		waitForWaiters(t, pool, i+1)
+	}
+
+	pool.release(held)
+	wg.Wait()
+	assert.Equal(t, []string{"cam1", "cam2", "cam1", "cam1"}, served)
+	assert.Len(t, pool.idle, 1)
+}
+
+func TestInterpreterPoolParallel(t *testing.T) {
+	t.Parallel()
+	pool := newInterpreterPool([]*tflite.Interpreter{nil, nil}, true)
+
+	first, err := pool.acquire(context.Background(), "cam1")
+	require.NoError(t, err)
+	second, err := pool.acquire(context.Background(), "cam2")
+	require.NoError(t, err, "second interpreter is free")
+	assert.NotSame(t, first, second)
+
+	pool.release(first)
+	pool.release(second)
+	assert.Len(t, pool.idle, 2)
+}
+
+func TestInterpreterPoolAcquireCanceled(t *testing.T) {
+	t.Parallel()
+	pool := newInterpreterPool([]*tflite.Interpreter{nil}, true)
+	held, err := pool.acquire(context.Background(), "cam1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.acquire(ctx, "cam2")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, pool.waiters)
+	assert.Empty(t, pool.order)
+
+	pool.release(held)
+	assert.Len(t, pool.idle, 1, "interpreter returns to the pool without waiters")
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, defaultSource, sourceFromContext(context.Background()))
+	assert.Equal(t, defaultSource, sourceFromContext(WithSource(context.Background(), "")))
+	assert.Equal(t, "rtsp_1", sourceFromContext(WithSource(context.Background(), "rtsp_1")))
+}
+
+func TestInterpreterCount(t *testing.T) {
+	t.Parallel()
+	const gb = 1 << 30
+	tests := []struct {
+		name                 string
+		configured, sources  int
+		threads              int
+		available, perInterp uint64
+		want                 int
+	}{
+		{"one source", 0, 1, 8, 8 * gb, 150 << 20, 1},
+		{"one per source", 0, 4, 8, 8 * gb, 150 << 20, 4},
+		{"file analysis has no sources", 0, 0, 8, 8 * gb, 150 << 20, 1},
+		{"limited by threads", 0, 6, 4, 8 * gb, 150 << 20, 4},
+		{"limited by memory", 0, 4, 8, 1 * gb, 150 << 20, 3},
+		{"configured", 2, 4, 8, 8 * gb, 150 << 20, 2},
+		{"configured limited by memory", 8, 1, 8, 512 << 20, 150 << 20, 1},
+		{"unknown memory", 4, 1, 8, 0, 150 << 20, 4},
+	}
+	for _, tt := range tests {
+		got := interpreterCount(tt.configured, tt.sources, tt.threads, tt.available, tt.perInterp)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+// TestInterpreterPoolMetricsOnlyForBirdNET tests that the pools of additional models do not
+// overwrite the unlabeled interpreter metrics of the BirdNET pool
+func TestInterpreterPoolMetricsOnlyForBirdNET(t *testing.T) {
+	m, err := metrics.NewBirdNETMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	SetMetrics(m)
+	if getMetrics() != m {
+		t.Skip("metrics were already set by another test")
+	}
+
+	newInterpreterPool([]*tflite.Interpreter{nil, nil}, true)
+	model := newInterpreterPool([]*tflite.Interpreter{nil, nil, nil}, false)
+	held, err := model.acquire(context.Background(), "cam1")
+	require.NoError(t, err)
+	defer model.release(held)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.InterpreterPoolSize), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.InterpretersBusy), 0)
+}
@@ -11,6 +11,7 @@ type Results struct {
 	StartTime   time.Time                // Time when the analysis started
 	PCMdata     []byte                   // Raw PCM audio data
 	Results     []datastore.Results      // Slice of analysis results
+	Embedding   []float32                // Embedding of the analyzed chunk, nil when embeddings are disabled
 	ElapsedTime time.Duration            // Time taken for analysis
 	ClipName    string                   // Name of the audio clip
 	Source      datastore.AudioSource    // Audio source with ID, SafeString, and DisplayName
@@ -0,0 +1,77 @@
+// Package classifier trains species verification heads on the embeddings of reviewed
+// detections and applies them to new detections. A head is a logistic regression that
+// estimates how likely a detection of its species is correct at this station, so species
+// prone to local false positives can be re-scored or suppressed.
+package classifier
+
+import (
+	"math"
+	"time"
+)
+
+// FormatVersion is the version of the classifier file format
+const FormatVersion = 1
+
+// Head is the verification head of one species
+type Head struct {
+	Weights        []float64 `json:"weights"`        // weights of the normalized embedding values
+	Bias           float64   `json:"bias"`           // bias of the logistic regression
+	Correct        int       `json:"correct"`        // detections reviewed as correct used for training
+	FalsePositives int       `json:"falsePositives"` // detections reviewed as false positives used for training
+	Accuracy       float64   `json:"accuracy"`       // training accuracy, share of the training detections classified as reviewed
+}
+
+// Classifier is a versioned set of species verification heads trained on the embeddings
+// of one model
+type Classifier struct {
+	Format     int              `json:"format"`     // classifier file format version
+	Version    int              `json:"version"`    // classifier version, incremented by each training
+	Model      string           `json:"model"`      // ID of the model that produced the embeddings
+	Dimensions int              `json:"dimensions"` // number of embedding values
+	CreatedAt  time.Time        `json:"createdAt"`  // when the classifier was trained
+	Species    map[string]*Head `json:"species"`    // heads by scientific name
+}
+
+// Score returns the probability that a detection of the species with the embedding is
+// correct. It reports false when the classifier has no head for the species or the
+// embedding size does not match.
+func (c *Classifier) Score(scientificName string, embedding []float32) (float64, bool) {
+	head, ok := c.Species[scientificName]
+	if !ok || len(embedding) != c.Dimensions || len(head.Weights) != c.Dimensions {
+		return 0, false
+	}
+	return head.probability(normalize(embedding)), true
+}
+
+// probability returns the probability that the normalized embedding is a correct detection
+func (h *Head) probability(x []float64) float64 {
+	z := h.Bias
+	for i, w := range h.Weights {
+		z += w * x[i]
+	}
+	return sigmoid(z)
+}
+
+// sigmoid is the logistic function
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// normalize returns the embedding scaled to unit length, as embeddings are compared by
+// direction in similarity search
+func normalize(embedding []float32) []float64 {
+	x := make([]float64, len(embedding))
+	var norm float64
+	for i, v := range embedding {
+		x[i] = float64(v)
+		norm += x[i] * x[i]
+	}
+	if norm == 0 {
+		return x
+	}
+	norm = math.Sqrt(norm)
+	for i := range x {
+		x[i] /= norm
+	}
+	return x
+}
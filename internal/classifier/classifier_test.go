@@ -0,0 +1,118 @@
+package classifier
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticSamples returns reviewed detections of a species whose false positives point
+// in a different direction of the embedding space than its correct detections
+func syntheticSamples(species string, correct, falsePositives int, rng *rand.Rand) []Sample {
+	samples := make([]Sample, 0, correct+falsePositives)
+	for i := range correct + falsePositives {
+		embedding := make([]float32, 8)
+		for j := range embedding {
+			embedding[j] = float32(rng.NormFloat64() * 0.1)
+		}
+		isCorrect := i < correct
+		if isCorrect {
+			embedding[0] += 1
+		} else {
+			embedding[1] += 1
+		}
+		samples = append(samples, Sample{Species: species, Embedding: embedding, Correct: isCorrect})
+	}
+	return samples
+}
+
+func TestTrainSeparatesFalsePositives(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // deterministic test data
+	samples := syntheticSamples("Corvus corax", 40, 8, rng)
+	samples = append(samples, syntheticSamples("Strix aluco", 12, 0, rng)...)
+	samples = append(samples, syntheticSamples("Bubo bubo", 2, 2, rng)...)
+
+	c, skipped, err := Train("BirdNET_GLOBAL_6K_V2.4", samples, DefaultTrainOptions())
+	require.NoError(t, err)
+	assert.Equal(t, 8, c.Dimensions)
+	require.Contains(t, c.Species, "Corvus corax")
+	head := c.Species["Corvus corax"]
+	assert.Equal(t, 40, head.Correct)
+	assert.Equal(t, 8, head.FalsePositives)
+	assert.InDelta(t, 1, head.Accuracy, 1e-9)
+
+	// Species without false positives or with too few reviews get no head
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "Bubo bubo", skipped[0].Species)
+	assert.Equal(t, "Strix aluco", skipped[1].Species)
+	assert.Equal(t, "no false positives", skipped[1].Reason)
+
+	correct, ok := c.Score("Corvus corax", []float32{1, 0, 0, 0, 0, 0, 0, 0})
+	require.True(t, ok)
+	assert.Greater(t, correct, 0.5)
+	falsePositive, ok := c.Score("Corvus corax", []float32{0, 2, 0, 0, 0, 0, 0, 0})
+	require.True(t, ok)
+	assert.Less(t, falsePositive, 0.5)
+
+	_, ok = c.Score("Strix aluco", []float32{1, 0, 0, 0, 0, 0, 0, 0})
+	assert.False(t, ok, "species without a head are not scored")
+	_, ok = c.Score("Corvus corax", []float32{1, 0})
+	assert.False(t, ok, "embeddings of another size are not scored")
+}
+
+func TestTrainErrors(t *testing.T) {
+	t.Parallel()
+	_, _, err := Train("model", nil, DefaultTrainOptions())
+	require.Error(t, err)
+
+	mixed := []Sample{{Species: "a", Embedding: []float32{1, 0}}, {Species: "a", Embedding: []float32{1}}}
+	_, _, err = Train("model", mixed, DefaultTrainOptions())
+	require.Error(t, err)
+
+	onlyCorrect := []Sample{{Species: "a", Embedding: []float32{1}, Correct: true}}
+	opts := DefaultTrainOptions()
+	opts.MinReviews = 1
+	_, skipped, err := Train("model", onlyCorrect, opts)
+	require.Error(t, err)
+	assert.Len(t, skipped, 1)
+}
+
+func TestSaveAndLoadVersions(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "classifiers")
+	head := &Head{Weights: []float64{0.5, -0.5}, Bias: 0.1}
+
+	first := &Classifier{Format: FormatVersion, Model: "m", Dimensions: 2, Species: map[string]*Head{"a": head}}
+	path, err := Save(dir, first)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "classifier-v1.json"), path)
+
+	second := &Classifier{Format: FormatVersion, Model: "m", Dimensions: 2, Species: map[string]*Head{"b": head}}
+	_, err = Save(dir, second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Version)
+
+	// A directory loads the latest version, a file loads that version
+	latest, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, latest.Version)
+	assert.Contains(t, latest.Species, "b")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded.Version)
+	assert.Equal(t, head.Weights, loaded.Species["a"].Weights)
+
+	_, err = Load(t.TempDir())
+	require.Error(t, err, "empty directory has no classifier")
+
+	invalid := filepath.Join(dir, "classifier-v9.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"format":1,"dimensions":3,"species":{"a":{"weights":[1]}}}`), 0o600))
+	_, err = Load(invalid)
+	require.Error(t, err)
+}
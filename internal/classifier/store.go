@@ -0,0 +1,109 @@
+// store.go: versioned classifier files
+package classifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// File names of classifier versions are classifier-v<version>.json
+const (
+	filePrefix = "classifier-v"
+	fileSuffix = ".json"
+)
+
+// FileName returns the file name of a classifier version
+func FileName(version int) string {
+	return filePrefix + strconv.Itoa(version) + fileSuffix
+}
+
+// Save writes the classifier to the directory as the version after the latest one in the
+// directory, sets the version of the classifier and returns the path of the file
+func Save(dir string, c *Classifier) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create classifier directory: %w", err)
+	}
+	latest, _, err := latestVersion(dir)
+	if err != nil {
+		return "", err
+	}
+	c.Version = latest + 1
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode classifier: %w", err)
+	}
+
+	// Write a temporary file and rename it so a running processor never loads a partial file
+	path := filepath.Join(dir, FileName(c.Version))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil { //nolint:gosec // G306: classifier files are not secret
+		return "", fmt.Errorf("failed to write classifier: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to write classifier: %w", err)
+	}
+	return path, nil
+}
+
+// Load reads a classifier file, or the latest version in a directory
+func Load(path string) (*Classifier, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifier: %w", err)
+	}
+	if info.IsDir() {
+		version, latest, err := latestVersion(path)
+		if err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			return nil, fmt.Errorf("no classifier in %s, train one with the train command", path)
+		}
+		path = latest
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifier: %w", err)
+	}
+	var c Classifier
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode classifier %s: %w", path, err)
+	}
+	if c.Format != FormatVersion {
+		return nil, fmt.Errorf("classifier %s has unsupported format %d", path, c.Format)
+	}
+	for name, head := range c.Species {
+		if head == nil || len(head.Weights) != c.Dimensions {
+			return nil, fmt.Errorf("classifier %s has an invalid head for %s", path, name)
+		}
+	}
+	return &c, nil
+}
+
+// latestVersion returns the latest classifier version in the directory and its path,
+// version 0 when there is none
+func latestVersion(dir string) (version int, path string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to list classifiers: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil || v <= version {
+			continue
+		}
+		version, path = v, filepath.Join(dir, name)
+	}
+	return version, path, nil
+}
@@ -0,0 +1,165 @@
+// train.go: training of species verification heads
+package classifier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Default training options
+const (
+	DefaultMinReviews   = 10
+	DefaultEpochs       = 500
+	DefaultLearningRate = 0.5
+	DefaultL2           = 0.001
+)
+
+// Sample is the embedding of a reviewed detection
+type Sample struct {
+	Species   string    // scientific name of the detected species
+	Embedding []float32 // embedding of the detection audio
+	Correct   bool      // true when reviewed as correct, false when reviewed as a false positive
+}
+
+// TrainOptions configures the training of the verification heads
+type TrainOptions struct {
+	MinReviews   int     // reviewed detections a species needs for a head
+	Epochs       int     // gradient descent passes over the detections of a species
+	LearningRate float64 // gradient descent step size
+	L2           float64 // L2 regularization of the weights
+}
+
+// DefaultTrainOptions returns the default training options
+func DefaultTrainOptions() TrainOptions {
+	return TrainOptions{
+		MinReviews:   DefaultMinReviews,
+		Epochs:       DefaultEpochs,
+		LearningRate: DefaultLearningRate,
+		L2:           DefaultL2,
+	}
+}
+
+// SkippedSpecies is a species without a head and the reason
+type SkippedSpecies struct {
+	Species string
+	Reviews int
+	Reason  string
+}
+
+// Train trains a verification head for each species with enough reviewed detections and
+// at least one false positive, species without false positives need no filtering. Classes
+// are weighted by their frequency so a few false positives are not outweighed by many
+// correct detections. The version of the returned classifier is set when it is saved.
+func Train(model string, samples []Sample, opts TrainOptions) (*Classifier, []SkippedSpecies, error) {
+	if len(samples) == 0 {
+		return nil, nil, fmt.Errorf("no reviewed detections with embeddings to train on")
+	}
+	if opts.MinReviews < 1 || opts.Epochs < 1 || opts.LearningRate <= 0 || opts.L2 < 0 {
+		return nil, nil, fmt.Errorf("invalid training options: %+v", opts)
+	}
+
+	dimensions := len(samples[0].Embedding)
+	bySpecies := make(map[string][]Sample)
+	for i := range samples {
+		if len(samples[i].Embedding) != dimensions {
+			return nil, nil, fmt.Errorf("embedding of %s has %d values, expected %d",
+				samples[i].Species, len(samples[i].Embedding), dimensions)
+		}
+		bySpecies[samples[i].Species] = append(bySpecies[samples[i].Species], samples[i])
+	}
+
+	species := make([]string, 0, len(bySpecies))
+	for name := range bySpecies {
+		species = append(species, name)
+	}
+	sort.Strings(species)
+
+	c := &Classifier{
+		Format:     FormatVersion,
+		Model:      model,
+		Dimensions: dimensions,
+		CreatedAt:  time.Now(),
+		Species:    make(map[string]*Head),
+	}
+	var skipped []SkippedSpecies
+	for _, name := range species {
+		speciesSamples := bySpecies[name]
+		correct := 0
+		for i := range speciesSamples {
+			if speciesSamples[i].Correct {
+				correct++
+			}
+		}
+		switch {
+		case len(speciesSamples) < opts.MinReviews:
+			skipped = append(skipped, SkippedSpecies{Species: name, Reviews: len(speciesSamples),
+				Reason: fmt.Sprintf("fewer than %d reviews", opts.MinReviews)})
+		case correct == len(speciesSamples):
+			skipped = append(skipped, SkippedSpecies{Species: name, Reviews: len(speciesSamples), Reason: "no false positives"})
+		default:
+			c.Species[name] = trainHead(speciesSamples, dimensions, &opts)
+		}
+	}
+	if len(c.Species) == 0 {
+		return nil, skipped, fmt.Errorf("no species has enough reviewed detections with false positives")
+	}
+	return c, skipped, nil
+}
+
+// trainHead fits a class weighted logistic regression with full batch gradient descent
+func trainHead(samples []Sample, dimensions int, opts *TrainOptions) *Head {
+	xs := make([][]float64, len(samples))
+	ys := make([]float64, len(samples))
+	head := &Head{Weights: make([]float64, dimensions)}
+	for i := range samples {
+		xs[i] = normalize(samples[i].Embedding)
+		if samples[i].Correct {
+			ys[i] = 1
+			head.Correct++
+		} else {
+			head.FalsePositives++
+		}
+	}
+
+	// Each class contributes half of the loss regardless of its size, a species reviewed
+	// only as false positives weights all its detections equally
+	weightCorrect := 1 / float64(len(samples))
+	weightFalse := weightCorrect
+	if head.Correct > 0 && head.FalsePositives > 0 {
+		weightCorrect = 0.5 / float64(head.Correct)
+		weightFalse = 0.5 / float64(head.FalsePositives)
+	}
+
+	gradient := make([]float64, dimensions)
+	for range opts.Epochs {
+		for j := range gradient {
+			gradient[j] = opts.L2 * head.Weights[j]
+		}
+		biasGradient := 0.0
+		for i, x := range xs {
+			weight := weightFalse
+			if ys[i] == 1 {
+				weight = weightCorrect
+			}
+			residual := weight * (head.probability(x) - ys[i])
+			for j, v := range x {
+				gradient[j] += residual * v
+			}
+			biasGradient += residual
+		}
+		for j := range head.Weights {
+			head.Weights[j] -= opts.LearningRate * gradient[j]
+		}
+		head.Bias -= opts.LearningRate * biasGradient
+	}
+
+	matches := 0
+	for i, x := range xs {
+		if (head.probability(x) >= 0.5) == (ys[i] == 1) {
+			matches++
+		}
+	}
+	head.Accuracy = float64(matches) / float64(len(xs))
+	return head
+}
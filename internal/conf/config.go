@@ -56,6 +56,18 @@ type RetentionSettings struct {
 	KeepSpectrograms bool   `json:"keepSpectrograms"` // true to keep spectrograms
 }
 
+// DatabaseRetentionSettings contains settings for pruning old detection records
+// from the database. Locked and reviewed detections are never removed.
+type DatabaseRetentionSettings struct {
+	Enabled       bool   `json:"enabled"`       // true to enable detection record retention
+	Debug         bool   `json:"debug"`         // true to log every deleted batch
+	MaxAge        string `json:"maxAge"`        // maximum age of detections to keep, empty to disable
+	MaxRows       int    `json:"maxRows"`       // maximum number of detections to keep, 0 to disable
+	MinDetections int    `json:"minDetections"` // minimum number of detections per species to keep
+	Interval      int    `json:"interval"`      // hours between retention runs
+	BatchSize     int    `json:"batchSize"`     // number of detections deleted per transaction
+}
+
 // AudioSettings contains settings for audio processing and export.
 // SoundLevelSettings contains settings for sound level monitoring
 type SoundLevelSettings struct {
@@ -63,6 +75,8 @@ type SoundLevelSettings struct {
 	Interval             int  `yaml:"interval" mapstructure:"interval" json:"interval"`                                         // measurement interval in seconds (default: 10)
 	Debug                bool `yaml:"debug" mapstructure:"debug" json:"debug"`                                                  // true to enable debug logging for sound level monitoring
 	DebugRealtimeLogging bool `yaml:"debug_realtime_logging" mapstructure:"debug_realtime_logging" json:"debugRealtimeLogging"` // true to log debug messages for every realtime update, false to log only at configured interval
+	Store                bool `yaml:"store" mapstructure:"store" json:"store"`                                                  // true to store sound level measurements in the database
+	StoreInterval        int  `yaml:"store_interval" mapstructure:"store_interval" json:"storeInterval"`                        // seconds of measurements aggregated into one stored record (default: 60)
 }
 
 type AudioSettings struct {
@@ -171,6 +185,44 @@ type DogBarkFilterSettings struct {
 	Species    []string `json:"species"`    // species list for filtering
 }
 
+// ClassifierSettings contains settings for the classifier trained from reviewed detections,
+// which re-scores or suppresses detections of species prone to local false positives.
+type ClassifierSettings struct {
+	Enabled   bool    `json:"enabled"`   // true to filter detections with the trained classifier
+	Path      string  `json:"path"`      // classifier file, or directory of which the latest version is used
+	Threshold float64 `json:"threshold"` // detections less likely to be correct than this are suppressed
+	Rescore   bool    `json:"rescore"`   // true to also scale the confidence of detections by their likelihood of being correct
+}
+
+// Actions of adaptive rules
+const (
+	AdaptiveRuleRaiseThreshold = "raise_threshold" // detections at or below the rule confidence are suppressed
+	AdaptiveRuleSuppress       = "suppress"        // all detections are suppressed
+	AdaptiveRuleAllow          = "allow"           // overrides only, learned rules are not applied
+)
+
+// AdaptiveRulesSettings contains settings for false positive rules learned from reviewed
+// detections, which raise thresholds or suppress species by audio source and hour of day.
+type AdaptiveRulesSettings struct {
+	Enabled         bool                   `json:"enabled"`         // true to filter detections with rules learned from reviews
+	WindowDays      int                    `json:"windowDays"`      // reviews of detections from this many past days are used
+	MinReviews      int                    `json:"minReviews"`      // reviewed detections a species, source or hour needs for a rule
+	RaiseRate       float64                `json:"raiseRate"`       // false positive rate at which the threshold is raised
+	SuppressRate    float64                `json:"suppressRate"`    // false positive rate at which detections are suppressed
+	RefreshInterval int                    `json:"refreshInterval"` // minutes between learning the rules again
+	Overrides       []AdaptiveRuleOverride `json:"overrides"`       // rules set by hand, applied before learned rules
+}
+
+// AdaptiveRuleOverride is an adaptive rule set by hand for a species, optionally limited
+// to an audio source and an hour of day.
+type AdaptiveRuleOverride struct {
+	Species       string  `json:"species"`                 // scientific name
+	Source        string  `json:"source,omitempty"`        // audio source ID, all sources when empty
+	Hour          *int    `json:"hour,omitempty"`          // hour of day 0-23, all hours when nil
+	Action        string  `json:"action"`                  // raise_threshold, suppress or allow
+	MinConfidence float64 `json:"minConfidence,omitempty"` // detections at or below this are suppressed by raise_threshold
+}
+
 // RTSPHealthSettings contains settings for RTSP stream health monitoring.
 type RTSPHealthSettings struct {
 	HealthyDataThreshold int `json:"healthyDataThreshold"` // seconds before stream considered unhealthy (default: 60)
@@ -187,15 +239,33 @@ type RTSPSettings struct {
 
 // MQTTSettings contains settings for MQTT integration.
 type MQTTSettings struct {
-	Enabled       bool            `json:"enabled"`       // true to enable MQTT
-	Debug         bool            `json:"debug"`         // true to enable MQTT debug
-	Broker        string          `json:"broker"`        // MQTT broker URL
-	Topic         string          `json:"topic"`         // MQTT topic
-	Username      string          `json:"username"`      // MQTT username
-	Password      string          `json:"password"`      // MQTT password
-	Retain        bool            `json:"retain"`        // true to retain messages
-	RetrySettings RetrySettings   `json:"retrySettings"` // settings for retry mechanism
-	TLS           MQTTTLSSettings `json:"tls"`           // TLS/SSL configuration
+	Enabled       bool                  `json:"enabled"`       // true to enable MQTT
+	Debug         bool                  `json:"debug"`         // true to enable MQTT debug
+	Broker        string                `json:"broker"`        // MQTT broker URL
+	Topic         string                `json:"topic"`         // MQTT topic
+	Username      string                `json:"username"`      // MQTT username
+	Password      string                `json:"password"`      // MQTT password
+	Retain        bool                  `json:"retain"`        // true to retain messages
+	RetrySettings RetrySettings         `json:"retrySettings"` // settings for retry mechanism
+	TLS           MQTTTLSSettings       `json:"tls"`           // TLS/SSL configuration
+	HomeAssistant HomeAssistantSettings `json:"homeAssistant"` // Home Assistant MQTT discovery
+	Commands      MQTTCommandSettings   `json:"commands"`      // remote control through a command topic
+}
+
+// MQTTCommandSettings contains settings for remote control commands received over MQTT
+type MQTTCommandSettings struct {
+	Enabled         bool     `json:"enabled"`         // true to subscribe to the command topic
+	Topic           string   `json:"topic"`           // command topic, defaults to <topic>/command
+	StatusTopic     string   `json:"statusTopic"`     // topic for command acknowledgements, defaults to <topic>/command/status
+	Token           string   `json:"token"`           // shared secret that every command must include
+	AllowedCommands []string `json:"allowedCommands"` // control signals that may be triggered remotely
+}
+
+// HomeAssistantSettings contains settings for Home Assistant MQTT discovery
+type HomeAssistantSettings struct {
+	Enabled         bool   `json:"enabled"`         // true to publish Home Assistant discovery configs and state topics
+	DiscoveryPrefix string `json:"discoveryPrefix"` // discovery topic prefix configured in Home Assistant
+	DeviceName      string `json:"deviceName"`      // device name shown in Home Assistant, defaults to the node name
 }
 
 // MQTTTLSSettings contains TLS/SSL configuration for secure MQTT connections
@@ -207,6 +277,27 @@ type MQTTTLSSettings struct {
 	ClientKey          string `yaml:"clientkey,omitempty" json:"clientKey,omitempty"`   // path to client key file (managed internally)
 }
 
+// WebhookSettings contains settings for posting detections to HTTP endpoints
+type WebhookSettings struct {
+	Enabled       bool              `json:"enabled"`       // true to post detections to the configured endpoints
+	Endpoints     []WebhookEndpoint `json:"endpoints"`     // endpoints receiving detections
+	RetrySettings RetrySettings     `json:"retrySettings"` // settings for retry mechanism
+}
+
+// WebhookEndpoint contains the settings of a single webhook endpoint
+type WebhookEndpoint struct {
+	Name           string            `json:"name"`           // name used in logs
+	URL            string            `json:"url"`            // http or https URL receiving the POST requests
+	Template       string            `json:"template"`       // Go template for the request body, detection JSON when empty
+	ContentType    string            `json:"contentType"`    // Content-Type header, defaults to application/json
+	Headers        map[string]string `json:"headers"`        // additional request headers
+	Secret         string            `json:"secret"`         // HMAC-SHA256 signing key, requests are unsigned when empty
+	Species        []string          `json:"species"`        // only post these species, all species when empty
+	ExcludeSpecies []string          `json:"excludeSpecies"` // never post these species
+	MinConfidence  float64           `json:"minConfidence"`  // minimum confidence (0-1) of posted detections
+	Timeout        int               `json:"timeout"`        // request timeout in seconds
+}
+
 // TelemetrySettings contains settings for telemetry.
 type TelemetrySettings struct {
 	Enabled bool   `json:"enabled"` // true to enable Prometheus compatible telemetry endpoint
@@ -245,6 +336,48 @@ type SentrySettings struct {
 	Debug   bool `json:"debug"`   // true to enable transparent telemetry logging
 }
 
+// NotificationSettings contains settings for delivering notifications outside the web UI
+type NotificationSettings struct {
+	Push PushSettings `json:"push"` // push delivery channels
+}
+
+// PushSettings contains the channels notifications are delivered to
+type PushSettings struct {
+	Enabled  bool                  `json:"enabled"`  // true to deliver notifications to the configured channels
+	Channels []PushChannelSettings `json:"channels"` // delivery channels
+}
+
+// PushChannelSettings contains the settings of a single notification delivery channel
+type PushChannelSettings struct {
+	Name            string            `json:"name"`            // name used in logs
+	Type            string            `json:"type"`            // smtp, ntfy, gotify, webhook or telegram
+	Enabled         bool              `json:"enabled"`         // true to deliver to this channel
+	Types           []string          `json:"types"`           // notification types delivered, all when empty
+	Priorities      []string          `json:"priorities"`      // notification priorities delivered, all when empty
+	Components      []string          `json:"components"`      // notification components delivered, all when empty
+	RateLimit       int               `json:"rateLimit"`       // maximum messages per rate limit window, 0 for no limit
+	RateLimitWindow int               `json:"rateLimitWindow"` // rate limit window in minutes, defaults to 60
+	TitleTemplate   string            `json:"titleTemplate"`   // Go template for the title, notification title when empty
+	MessageTemplate string            `json:"messageTemplate"` // Go template for the message, notification message when empty
+	Timeout         int               `json:"timeout"`         // delivery timeout in seconds, defaults to 30
+	URL             string            `json:"url"`             // ntfy topic URL, Gotify server, webhook URL or bot API base URL
+	Token           string            `json:"token"`           // ntfy access token, Gotify application token or bot token
+	ChatID          string            `json:"chatId"`          // chat ID for bot API channels
+	Headers         map[string]string `json:"headers"`         // additional headers for webhook channels
+	SMTP            SMTPSettings      `json:"smtp"`            // settings for smtp channels
+}
+
+// SMTPSettings contains the mail server settings of an email channel
+type SMTPSettings struct {
+	Host     string   `json:"host"`     // mail server host
+	Port     int      `json:"port"`     // mail server port, defaults to 587
+	Username string   `json:"username"` // username, authentication is skipped when empty
+	Password string   `json:"password"` // password
+	From     string   `json:"from"`     // sender address
+	To       []string `json:"to"`       // recipient addresses
+	TLS      bool     `json:"tls"`      // true for implicit TLS (usually port 465), STARTTLS is used when offered otherwise
+}
+
 // RealtimeSettings contains all settings related to realtime processing.
 type RealtimeSettings struct {
 	Interval         int                      `json:"interval"`         // minimum interval between log messages in seconds
@@ -262,8 +395,11 @@ type RealtimeSettings struct {
 	OpenWeather      OpenWeatherSettings      `yaml:"-" json:"-"`       // OpenWeather integration settings
 	PrivacyFilter    PrivacyFilterSettings    `json:"privacyFilter"`    // Privacy filter settings
 	DogBarkFilter    DogBarkFilterSettings    `json:"dogBarkFilter"`    // Dog bark filter settings
+	Classifier       ClassifierSettings       `json:"classifier"`       // Classifier trained from reviewed detections
+	AdaptiveRules    AdaptiveRulesSettings    `json:"adaptiveRules"`    // False positive rules learned from reviewed detections
 	RTSP             RTSPSettings             `json:"rtsp"`             // RTSP settings
 	MQTT             MQTTSettings             `json:"mqtt"`             // MQTT settings
+	Webhooks         WebhookSettings          `json:"webhooks"`         // Detection webhook settings
 	Telemetry        TelemetrySettings        `json:"telemetry"`        // Telemetry settings
 	Monitoring       MonitoringSettings       `json:"monitoring"`       // System resource monitoring settings
 	Species          SpeciesSettings          `json:"species"`          // Custom thresholds and actions for species
@@ -527,22 +663,43 @@ type InputConfig struct {
 	Path      string `yaml:"-" json:"-"` // path to input file or directory
 	Recursive bool   `yaml:"-" json:"-"` // true for recursive directory analysis
 	Watch     bool   `yaml:"-" json:"-"` // true to watch directory for new files
+	Database  bool   `yaml:"-" json:"-"` // true to save detections of analyzed files to the configured database
+	Timezone  string `yaml:"-" json:"-"` // time zone of file name timestamps, empty for UTC on AudioMoth and local time otherwise
+
+	Clips           bool          `yaml:"-" json:"-"` // true to export audio clips of detections in analyzed files
+	ClipPrePadding  time.Duration `yaml:"-" json:"-"` // audio before a detection included in its clip
+	ClipPostPadding time.Duration `yaml:"-" json:"-"` // audio after a detection included in its clip
 }
 
 type BirdNETConfig struct {
-	Debug       bool                `json:"debug"`       // true to enable debug mode
-	Sensitivity float64             `json:"sensitivity"` // birdnet analysis sigmoid sensitivity
-	Threshold   float64             `json:"threshold"`   // threshold for prediction confidence to report
-	Overlap     float64             `json:"overlap"`     // birdnet analysis overlap between chunks
-	Longitude   float64             `json:"longitude"`   // longitude of recording location for prediction filtering
-	Latitude    float64             `json:"latitude"`    // latitude of recording location for prediction filtering
-	Threads     int                 `json:"threads"`     // number of CPU threads to use for analysis
-	Locale      string              `json:"locale"`      // language to use for labels
-	RangeFilter RangeFilterSettings `json:"rangeFilter"` // range filter settings
-	ModelPath   string              `json:"modelPath"`   // path to external model file (empty for embedded)
-	LabelPath   string              `json:"labelPath"`   // path to external label file (empty for embedded)
-	Labels      []string            `yaml:"-" json:"-"`  // list of available species labels, runtime value
-	UseXNNPACK  bool                `json:"useXnnpack"`  // true to use XNNPACK delegate for inference acceleration
+	Debug        bool                `json:"debug"`        // true to enable debug mode
+	Sensitivity  float64             `json:"sensitivity"`  // birdnet analysis sigmoid sensitivity
+	Threshold    float64             `json:"threshold"`    // threshold for prediction confidence to report
+	Overlap      float64             `json:"overlap"`      // birdnet analysis overlap between chunks
+	Longitude    float64             `json:"longitude"`    // longitude of recording location for prediction filtering
+	Latitude     float64             `json:"latitude"`     // latitude of recording location for prediction filtering
+	Threads      int                 `json:"threads"`      // number of CPU threads to use for analysis
+	Interpreters int                 `json:"interpreters"` // number of analysis interpreters for parallel inference, 0 for automatic
+	Locale       string              `json:"locale"`       // language to use for labels
+	RangeFilter  RangeFilterSettings `json:"rangeFilter"`  // range filter settings
+	ModelPath    string              `json:"modelPath"`    // path to external model file (empty for embedded)
+	LabelPath    string              `json:"labelPath"`    // path to external label file (empty for embedded)
+	Labels       []string            `yaml:"-" json:"-"`   // list of available species labels, runtime value
+	UseXNNPACK   bool                `json:"useXnnpack"`   // true to use XNNPACK delegate for inference acceleration
+	Models       []ModelSettings     `json:"models"`       // additional classification models analyzing the same audio in realtime
+	MergeModels  bool                `json:"mergeModels"`  // true to merge detections of the same species from all models, false to keep the detections of each model separate
+	Embeddings   bool                `json:"embeddings"`   // true to store embeddings of detections for similarity search, requires a model with an embeddings output
+}
+
+// ModelSettings contains settings for an additional classification model, such as a regional
+// or custom bat or insect model, analyzing the same audio as the BirdNET model
+type ModelSettings struct {
+	ID         string  `json:"id"`         // model identifier stored with its results, e.g. "bats-eu"
+	Enabled    bool    `json:"enabled"`    // true to run the model
+	ModelPath  string  `json:"modelPath"`  // path to the TensorFlow Lite model file
+	LabelPath  string  `json:"labelPath"`  // path to the label file, one "Scientific name_Common name" per line
+	SampleRate int     `json:"sampleRate"` // sample rate the model expects, 0 to derive it from the model input
+	Threshold  float64 `json:"threshold"`  // confidence threshold for results of the model, 0 to use the BirdNET threshold
 }
 
 // RangeFilterSettings contains settings for the range filter
@@ -609,6 +766,16 @@ type WebServerSettings struct {
 	Port       string             `json:"port"`       // port for web server
 	Log        LogConfig          `json:"log"`        // logging configuration for web server
 	LiveStream LiveStreamSettings `json:"liveStream"` // live stream configuration
+	Analyze    AnalyzeSettings    `json:"analyze"`    // recording upload and analysis configuration
+}
+
+// AnalyzeSettings contains settings for recordings uploaded for analysis through the API
+type AnalyzeSettings struct {
+	Enabled   bool `json:"enabled"`   // true to accept recordings for analysis
+	MaxSizeMB int  `json:"maxSizeMB"` // maximum size of an uploaded recording in megabytes
+	Workers   int  `json:"workers"`   // number of recordings analyzed at the same time
+	QueueSize int  `json:"queueSize"` // number of recordings waiting for analysis
+	KeepHours int  `json:"keepHours"` // hours the results of finished analyses are kept
 }
 
 type LiveStreamSettings struct {
@@ -689,28 +856,6 @@ func (s *SFTPBackupSettings) Validate() error {
 	return nil
 }
 
-// S3BackupSettings defines settings for S3-compatible backup target
-type S3BackupSettings struct {
-	Endpoint        string `yaml:"endpoint"`        // S3 endpoint URL
-	Region          string `yaml:"region"`          // AWS region
-	Bucket          string `yaml:"bucket"`          // S3 bucket name
-	AccessKeyID     string `yaml:"accesskeyid"`     // AWS access key ID
-	SecretAccessKey string `yaml:"secretaccesskey"` // AWS secret access key
-	Prefix          string `yaml:"prefix"`          // Object key prefix
-	UseSSL          bool   `yaml:"usessl"`          // Use SSL/TLS (default: true)
-}
-
-// Validate validates S3 backup settings
-func (s *S3BackupSettings) Validate() error {
-	if s.Bucket == "" {
-		return fmt.Errorf("S3 bucket name cannot be empty")
-	}
-	if s.Region == "" {
-		return fmt.Errorf("S3 region cannot be empty")
-	}
-	return nil
-}
-
 // RsyncBackupSettings defines settings for rsync backup target
 type RsyncBackupSettings struct {
 	Host       string   `yaml:"host"`       // Remote host (optional for local rsync)
@@ -807,6 +952,8 @@ type Settings struct {
 	Security  Security          `json:"security"`  // security configuration
 	Sentry    SentrySettings    `json:"sentry"`    // Sentry error tracking configuration
 
+	Notification NotificationSettings `json:"notification"` // notification delivery configuration
+
 	Output struct {
 		File struct {
 			Enabled bool   `yaml:"-" json:"-"` // true to enable file output
@@ -827,6 +974,18 @@ type Settings struct {
 			Host     string `json:"host"`     // host for mysql database
 			Port     string `json:"port"`     // port for mysql database
 		} `json:"mysql"`
+
+		PostgreSQL struct {
+			Enabled  bool   `json:"enabled"`  // true to enable postgresql output
+			Username string `json:"username"` // username for postgresql database
+			Password string `json:"password"` // password for postgresql database
+			Database string `json:"database"` // database name for postgresql database
+			Host     string `json:"host"`     // host for postgresql database
+			Port     string `json:"port"`     // port for postgresql database
+			SSLMode  string `json:"sslMode"`  // disable, require, verify-ca or verify-full
+		} `json:"postgresql"`
+
+		Retention DatabaseRetentionSettings `json:"retention"` // detection record retention
 	} `json:"output"`
 
 	Backup BackupConfig `json:"backup"` // Backup configuration
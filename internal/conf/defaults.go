@@ -26,12 +26,16 @@ func setDefaultConfig() {
 	viper.SetDefault("birdnet.threshold", 0.8)
 	viper.SetDefault("birdnet.overlap", 0.0)
 	viper.SetDefault("birdnet.threads", 0)
+	viper.SetDefault("birdnet.interpreters", 0)
 	viper.SetDefault("birdnet.locale", DefaultFallbackLocale)
 	viper.SetDefault("birdnet.latitude", 0.000)
 	viper.SetDefault("birdnet.longitude", 0.000)
 	viper.SetDefault("birdnet.modelpath", "")
 	viper.SetDefault("birdnet.labelpath", "")
 	viper.SetDefault("birdnet.usexnnpack", true)
+	viper.SetDefault("birdnet.models", []ModelSettings{})
+	viper.SetDefault("birdnet.mergemodels", true)
+	viper.SetDefault("birdnet.embeddings", false)
 
 	// Range filter configuration
 	viper.SetDefault("birdnet.rangefilter.debug", false)
@@ -50,6 +54,8 @@ func setDefaultConfig() {
 	// Sound level monitoring configuration
 	viper.SetDefault("realtime.audio.soundlevel.enabled", false)
 	viper.SetDefault("realtime.audio.soundlevel.interval", 10)
+	viper.SetDefault("realtime.audio.soundlevel.store", false)
+	viper.SetDefault("realtime.audio.soundlevel.store_interval", 60)
 
 	// Audio export configuration
 	viper.SetDefault("realtime.audio.export.debug", false)
@@ -171,6 +177,23 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.mqtt.retrysettings.initialdelay", 30)
 	viper.SetDefault("realtime.mqtt.retrysettings.maxdelay", 3600)
 	viper.SetDefault("realtime.mqtt.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("realtime.mqtt.homeassistant.enabled", false)
+	viper.SetDefault("realtime.mqtt.homeassistant.discoveryprefix", "homeassistant")
+	viper.SetDefault("realtime.mqtt.homeassistant.devicename", "")
+	viper.SetDefault("realtime.mqtt.commands.enabled", false)
+	viper.SetDefault("realtime.mqtt.commands.topic", "")
+	viper.SetDefault("realtime.mqtt.commands.statustopic", "")
+	viper.SetDefault("realtime.mqtt.commands.token", "")
+	viper.SetDefault("realtime.mqtt.commands.allowedcommands", []string{"reload_birdnet", "rebuild_range_filter"})
+
+	// Webhook configuration
+	viper.SetDefault("realtime.webhooks.enabled", false)
+	viper.SetDefault("realtime.webhooks.endpoints", []WebhookEndpoint{})
+	viper.SetDefault("realtime.webhooks.retrysettings.enabled", true)
+	viper.SetDefault("realtime.webhooks.retrysettings.maxretries", 3)
+	viper.SetDefault("realtime.webhooks.retrysettings.initialdelay", 10)
+	viper.SetDefault("realtime.webhooks.retrysettings.maxdelay", 300)
+	viper.SetDefault("realtime.webhooks.retrysettings.backoffmultiplier", 2.0)
 
 	// Privacy filter configuration
 	viper.SetDefault("realtime.privacyfilter.enabled", true)
@@ -184,6 +207,21 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dogbarkfilter.confidence", 0.1)
 	viper.SetDefault("realtime.dogbarkfilter.species", []string{})
 
+	// Classifier trained from reviewed detections
+	viper.SetDefault("realtime.classifier.enabled", false)
+	viper.SetDefault("realtime.classifier.path", "classifiers")
+	viper.SetDefault("realtime.classifier.threshold", 0.5)
+	viper.SetDefault("realtime.classifier.rescore", false)
+
+	// Adaptive false positive rules configuration
+	viper.SetDefault("realtime.adaptiverules.enabled", false)
+	viper.SetDefault("realtime.adaptiverules.windowdays", 90)
+	viper.SetDefault("realtime.adaptiverules.minreviews", 10)
+	viper.SetDefault("realtime.adaptiverules.raiserate", 0.5)
+	viper.SetDefault("realtime.adaptiverules.suppressrate", 0.9)
+	viper.SetDefault("realtime.adaptiverules.refreshinterval", 60)
+	viper.SetDefault("realtime.adaptiverules.overrides", []AdaptiveRuleOverride{})
+
 	// Telemetry configuration
 	viper.SetDefault("realtime.telemetry.enabled", false)
 	viper.SetDefault("realtime.telemetry.listen", "0.0.0.0:8090")
@@ -252,6 +290,13 @@ func setDefaultConfig() {
 	viper.SetDefault("webserver.livestream.segmentLength", 2)
 	viper.SetDefault("webserver.livestream.ffmpegLogLevel", "warning")
 
+	// Recording upload and analysis configuration
+	viper.SetDefault("webserver.analyze.enabled", true)
+	viper.SetDefault("webserver.analyze.maxsizemb", 200)
+	viper.SetDefault("webserver.analyze.workers", 1)
+	viper.SetDefault("webserver.analyze.queuesize", 10)
+	viper.SetDefault("webserver.analyze.keephours", 24)
+
 	// File output configuration
 	viper.SetDefault("output.file.enabled", true)
 	viper.SetDefault("output.file.path", "output/")
@@ -269,6 +314,24 @@ func setDefaultConfig() {
 	viper.SetDefault("output.mysql.host", "localhost")
 	viper.SetDefault("output.mysql.port", 3306)
 
+	// PostgreSQL output configuration
+	viper.SetDefault("output.postgresql.enabled", false)
+	viper.SetDefault("output.postgresql.username", "birdnet")
+	viper.SetDefault("output.postgresql.password", "secret")
+	viper.SetDefault("output.postgresql.database", "birdnet")
+	viper.SetDefault("output.postgresql.host", "localhost")
+	viper.SetDefault("output.postgresql.port", 5432)
+	viper.SetDefault("output.postgresql.sslmode", "disable")
+
+	// Detection record retention configuration
+	viper.SetDefault("output.retention.enabled", false)
+	viper.SetDefault("output.retention.debug", false)
+	viper.SetDefault("output.retention.maxage", "2y")
+	viper.SetDefault("output.retention.maxrows", 0)
+	viper.SetDefault("output.retention.mindetections", 100)
+	viper.SetDefault("output.retention.interval", 24)
+	viper.SetDefault("output.retention.batchsize", 1000)
+
 	// Security configuration
 	viper.SetDefault("security.debug", false)
 	viper.SetDefault("security.host", "")
@@ -305,4 +368,8 @@ func setDefaultConfig() {
 	viper.SetDefault("sentry.dsn", "")
 	viper.SetDefault("sentry.samplerate", 1.0)
 	viper.SetDefault("sentry.debug", false)
+
+	// Notification delivery configuration
+	viper.SetDefault("notification.push.enabled", false)
+	viper.SetDefault("notification.push.channels", []PushChannelSettings{})
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/url"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -17,6 +18,9 @@ import (
 // MinSoundLevelInterval is the minimum sound level interval in seconds to prevent excessive CPU usage
 const MinSoundLevelInterval = 5
 
+// MinMQTTCommandTokenLength is the minimum length of the shared secret required for MQTT remote commands
+const MinMQTTCommandTokenLength = 16
+
 // ValidationError represents a collection of validation errors
 type ValidationError struct {
 	Errors []string
@@ -86,6 +90,16 @@ func ValidateSettings(settings *Settings) error {
 		ve.Errors = append(ve.Errors, err.Error())
 	}
 
+	// Validate detection record retention settings
+	if err := validateDatabaseRetentionSettings(&settings.Output.Retention); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate notification delivery settings
+	if err := validatePushSettings(&settings.Notification.Push); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
 	// If there are any errors, return the ValidationError
 	if len(ve.Errors) > 0 {
 		return ve
@@ -127,6 +141,11 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 		errs = append(errs, "BirdNET threads must be at least 0")
 	}
 
+	// Check if interpreters is non-negative
+	if birdnetSettings.Interpreters < 0 {
+		errs = append(errs, "BirdNET interpreters must be at least 0")
+	}
+
 	// Validate RangeFilter settings
 	if birdnetSettings.RangeFilter.Model == "" {
 		errs = append(errs, "RangeFilter model must not be empty")
@@ -137,6 +156,28 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 		errs = append(errs, "RangeFilter threshold must be between 0 and 1")
 	}
 
+	// Validate additional models
+	modelIDs := make(map[string]bool, len(birdnetSettings.Models))
+	for i := range birdnetSettings.Models {
+		model := &birdnetSettings.Models[i]
+		switch {
+		case model.ID == "":
+			errs = append(errs, fmt.Sprintf("BirdNET model %d must have an id", i+1))
+		case modelIDs[model.ID]:
+			errs = append(errs, fmt.Sprintf("BirdNET model id '%s' is used more than once", model.ID))
+		}
+		modelIDs[model.ID] = true
+		if model.Enabled && (model.ModelPath == "" || model.LabelPath == "") {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' must have a model path and a label path", model.ID))
+		}
+		if model.SampleRate < 0 || model.SampleRate > SampleRate {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' sample rate must be between 0 and the %d Hz capture sample rate", model.ID, SampleRate))
+		}
+		if model.Threshold < 0 || model.Threshold > 1 {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' threshold must be between 0 and 1", model.ID))
+		}
+	}
+
 	// Validate locale setting
 	if birdnetSettings.Locale != "" {
 		normalizedLocale, err := NormalizeLocale(birdnetSettings.Locale)
@@ -277,6 +318,11 @@ func validateRealtimeSettings(settings *RealtimeSettings) error {
 		return err
 	}
 
+	// Validate webhook settings
+	if err := validateWebhookSettings(&settings.Webhooks); err != nil {
+		return err
+	}
+
 	// Validate sound level settings
 	if err := validateSoundLevelSettings(&settings.Audio.SoundLevel); err != nil {
 		return err
@@ -287,10 +333,105 @@ func validateRealtimeSettings(settings *RealtimeSettings) error {
 		return err
 	}
 
+	// Validate classifier settings
+	if err := validateClassifierSettings(&settings.Classifier); err != nil {
+		return err
+	}
+
+	// Validate adaptive rule settings
+	if err := validateAdaptiveRulesSettings(&settings.AdaptiveRules); err != nil {
+		return err
+	}
+
 	// Add more realtime settings validation as needed
 	return nil
 }
 
+// validateClassifierSettings validates the settings of the classifier trained from reviewed detections
+func validateClassifierSettings(settings *ClassifierSettings) error {
+	if settings.Threshold < 0 || settings.Threshold > 1 {
+		return errors.New(fmt.Errorf("classifier threshold must be between 0 and 1")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "classifier-threshold").
+			Context("threshold", settings.Threshold).
+			Build()
+	}
+	if settings.Enabled && settings.Path == "" {
+		return errors.New(fmt.Errorf("classifier path is required when the classifier is enabled")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "classifier-path-required").
+			Build()
+	}
+	return nil
+}
+
+// validateAdaptiveRulesSettings validates the settings of the false positive rules learned from reviews
+func validateAdaptiveRulesSettings(settings *AdaptiveRulesSettings) error {
+	if settings.Enabled {
+		if settings.WindowDays < 1 || settings.MinReviews < 1 || settings.RefreshInterval < 1 {
+			return errors.New(fmt.Errorf("adaptive rule window days, minimum reviews and refresh interval must be at least 1")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "adaptive-rules-limits").
+				Context("window_days", settings.WindowDays).
+				Context("min_reviews", settings.MinReviews).
+				Context("refresh_interval", settings.RefreshInterval).
+				Build()
+		}
+		if settings.RaiseRate <= 0 || settings.RaiseRate > settings.SuppressRate || settings.SuppressRate > 1 {
+			return errors.New(fmt.Errorf("adaptive rule rates must satisfy 0 < raise rate <= suppress rate <= 1")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "adaptive-rules-rates").
+				Context("raise_rate", settings.RaiseRate).
+				Context("suppress_rate", settings.SuppressRate).
+				Build()
+		}
+	}
+	for i := range settings.Overrides {
+		if err := ValidateAdaptiveRuleOverride(&settings.Overrides[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateAdaptiveRuleOverride validates an adaptive rule set by hand
+func ValidateAdaptiveRuleOverride(override *AdaptiveRuleOverride) error {
+	if override.Species == "" {
+		return errors.New(fmt.Errorf("adaptive rule override requires a species")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "adaptive-rule-species").
+			Build()
+	}
+	if override.Hour != nil && (*override.Hour < 0 || *override.Hour > 23) {
+		return errors.New(fmt.Errorf("adaptive rule override hour must be between 0 and 23")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "adaptive-rule-hour").
+			Context("species", override.Species).
+			Context("hour", *override.Hour).
+			Build()
+	}
+	switch override.Action {
+	case AdaptiveRuleSuppress, AdaptiveRuleAllow:
+	case AdaptiveRuleRaiseThreshold:
+		if override.MinConfidence <= 0 || override.MinConfidence > 1 {
+			return errors.New(fmt.Errorf("adaptive rule override minimum confidence must be between 0 and 1")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "adaptive-rule-confidence").
+				Context("species", override.Species).
+				Context("min_confidence", override.MinConfidence).
+				Build()
+		}
+	default:
+		return errors.New(fmt.Errorf("adaptive rule override action must be raise_threshold, suppress or allow")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "adaptive-rule-action").
+			Context("species", override.Species).
+			Context("action", override.Action).
+			Build()
+	}
+	return nil
+}
+
 // validateMQTTSettings validates the MQTT-specific settings
 func validateMQTTSettings(settings *MQTTSettings) error {
 	if settings.Enabled {
@@ -340,7 +481,85 @@ func validateMQTTSettings(settings *MQTTSettings) error {
 					Build()
 			}
 		}
+
+		// Discovery topics are built from the prefix and must not contain wildcards
+		if settings.HomeAssistant.Enabled {
+			prefix := settings.HomeAssistant.DiscoveryPrefix
+			if prefix == "" || strings.ContainsAny(prefix, "#+") {
+				return errors.New(fmt.Errorf("Home Assistant discovery prefix must be a non-empty topic without wildcards, got %q", prefix)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-homeassistant-discovery-prefix").
+					Build()
+			}
+		}
+
+		// Remote commands trigger control actions, never accept them without a token
+		if settings.Commands.Enabled {
+			if len(settings.Commands.Token) < MinMQTTCommandTokenLength {
+				return errors.New(fmt.Errorf("MQTT command token must be at least %d characters when remote commands are enabled", MinMQTTCommandTokenLength)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "mqtt-command-token").
+					Build()
+			}
+			for _, topic := range []string{settings.Commands.Topic, settings.Commands.StatusTopic} {
+				if strings.ContainsAny(topic, "#+") {
+					return errors.New(fmt.Errorf("MQTT command topics must not contain wildcards, got %q", topic)).
+						Category(errors.CategoryValidation).
+						Context("validation_type", "mqtt-command-topic").
+						Build()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateWebhookSettings validates the detection webhook endpoints
+func validateWebhookSettings(settings *WebhookSettings) error {
+	// Webhooks are optional, only validate if enabled
+	if !settings.Enabled {
+		return nil
+	}
+
+	for i := range settings.Endpoints {
+		endpoint := &settings.Endpoints[i]
+
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New(fmt.Errorf("webhook endpoint %d must have an http or https URL, got %q", i+1, endpoint.URL)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-url").
+				Context("endpoint", endpoint.Name).
+				Build()
+		}
+
+		if endpoint.MinConfidence < 0 || endpoint.MinConfidence > 1 {
+			return errors.New(fmt.Errorf("webhook endpoint %d minimum confidence must be between 0 and 1, got %v", i+1, endpoint.MinConfidence)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-min-confidence").
+				Context("endpoint", endpoint.Name).
+				Build()
+		}
+
+		if endpoint.Timeout < 0 {
+			return errors.New(fmt.Errorf("webhook endpoint %d timeout must not be negative, got %d", i+1, endpoint.Timeout)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-timeout").
+				Context("endpoint", endpoint.Name).
+				Build()
+		}
+
+		if endpoint.Template != "" {
+			if _, err := ParseWebhookTemplate(endpoint.Template); err != nil {
+				return errors.New(fmt.Errorf("webhook endpoint %d template is invalid: %w", i+1, err)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "webhook-template").
+					Context("endpoint", endpoint.Name).
+					Build()
+			}
+		}
 	}
+
 	return nil
 }
 
@@ -357,7 +576,141 @@ func validateSoundLevelSettings(settings *SoundLevelSettings) error {
 				Context("minimum_interval", MinSoundLevelInterval).
 				Build()
 		}
+
+		// Stored records aggregate one or more measurements
+		if settings.Store && settings.StoreInterval < settings.Interval {
+			return errors.New(fmt.Errorf("sound level store interval must be at least the measurement interval of %d seconds, got %d", settings.Interval, settings.StoreInterval)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "sound-level-store-interval").
+				Context("interval", settings.Interval).
+				Context("store_interval", settings.StoreInterval).
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateDatabaseRetentionSettings validates the detection record retention settings
+func validateDatabaseRetentionSettings(settings *DatabaseRetentionSettings) error {
+	// Retention settings are optional, only validate if enabled
+	if !settings.Enabled {
+		return nil
+	}
+
+	if settings.MaxAge != "" {
+		if _, err := ParseRetentionPeriod(settings.MaxAge); err != nil {
+			return errors.New(err).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "database-retention-max-age").
+				Context("max_age", settings.MaxAge).
+				Build()
+		}
 	}
+
+	if settings.MaxRows < 0 || settings.MinDetections < 0 {
+		return errors.New(fmt.Errorf("database retention maxrows and mindetections must not be negative")).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "database-retention-limits").
+			Context("max_rows", settings.MaxRows).
+			Context("min_detections", settings.MinDetections).
+			Build()
+	}
+
+	if settings.Interval < 1 {
+		return errors.New(fmt.Errorf("database retention interval must be at least 1 hour, got %d", settings.Interval)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "database-retention-interval").
+			Context("interval", settings.Interval).
+			Build()
+	}
+
+	if settings.BatchSize < 1 {
+		return errors.New(fmt.Errorf("database retention batch size must be at least 1, got %d", settings.BatchSize)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "database-retention-batch-size").
+			Context("batch_size", settings.BatchSize).
+			Build()
+	}
+
+	return nil
+}
+
+// validatePushSettings validates the notification delivery channels
+func validatePushSettings(settings *PushSettings) error {
+	// Push delivery is optional, only validate if enabled
+	if !settings.Enabled {
+		return nil
+	}
+
+	for i := range settings.Channels {
+		channel := &settings.Channels[i]
+		if !channel.Enabled {
+			continue
+		}
+
+		var missing string
+		switch strings.ToLower(channel.Type) {
+		case "ntfy", "webhook":
+			if channel.URL == "" {
+				missing = "url"
+			}
+		case "gotify":
+			if channel.URL == "" {
+				missing = "url"
+			} else if channel.Token == "" {
+				missing = "token"
+			}
+		case "telegram":
+			if channel.Token == "" {
+				missing = "token"
+			} else if channel.ChatID == "" {
+				missing = "chatid"
+			}
+		case "smtp":
+			switch {
+			case channel.SMTP.Host == "":
+				missing = "smtp.host"
+			case channel.SMTP.From == "":
+				missing = "smtp.from"
+			case len(channel.SMTP.To) == 0:
+				missing = "smtp.to"
+			}
+		default:
+			return errors.New(fmt.Errorf("notification channel %d has unknown type %q, expected smtp, ntfy, gotify, webhook or telegram", i+1, channel.Type)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "notification-channel-type").
+				Context("channel", channel.Name).
+				Build()
+		}
+		if missing != "" {
+			return errors.New(fmt.Errorf("notification channel %d (%s) requires %s", i+1, channel.Type, missing)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "notification-channel-settings").
+				Context("channel", channel.Name).
+				Build()
+		}
+
+		for _, priority := range channel.Priorities {
+			switch strings.ToLower(priority) {
+			case "critical", "high", "medium", "low":
+			default:
+				return errors.New(fmt.Errorf("notification channel %d has unknown priority %q", i+1, priority)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "notification-channel-priority").
+					Context("channel", channel.Name).
+					Build()
+			}
+		}
+
+		if channel.RateLimit < 0 || channel.RateLimitWindow < 0 || channel.Timeout < 0 {
+			return errors.New(fmt.Errorf("notification channel %d rate limit, window and timeout must not be negative", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "notification-channel-limits").
+				Context("channel", channel.Name).
+				Build()
+		}
+	}
+
 	return nil
 }
 
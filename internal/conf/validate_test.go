@@ -90,6 +90,26 @@ func TestValidateSoundLevelSettings(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "store interval shorter than measurement interval - should fail",
+			settings: SoundLevelSettings{
+				Enabled:       true,
+				Interval:      10,
+				Store:         true,
+				StoreInterval: 5,
+			},
+			wantErr: true,
+			errType: "sound-level-store-interval",
+		},
+		{
+			name: "store interval ignored when storing is disabled - should pass",
+			settings: SoundLevelSettings{
+				Enabled:       true,
+				Interval:      10,
+				StoreInterval: 5,
+			},
+			wantErr: false,
+		},
 	}
 
 	// Run test cases
@@ -220,4 +240,341 @@ func BenchmarkValidateSoundLevelSettingsWithError(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = validateSoundLevelSettings(settings)
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateDatabaseRetentionSettings(t *testing.T) {
+	valid := DatabaseRetentionSettings{
+		Enabled:       true,
+		MaxAge:        "2y",
+		MinDetections: 100,
+		Interval:      24,
+		BatchSize:     1000,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *DatabaseRetentionSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *DatabaseRetentionSettings) {}, false},
+		{"disabled with invalid values", func(s *DatabaseRetentionSettings) {
+			s.Enabled = false
+			s.MaxAge = "invalid"
+			s.Interval = 0
+		}, false},
+		{"empty max age disables age limit", func(s *DatabaseRetentionSettings) { s.MaxAge = "" }, false},
+		{"invalid max age", func(s *DatabaseRetentionSettings) { s.MaxAge = "2x" }, true},
+		{"negative max rows", func(s *DatabaseRetentionSettings) { s.MaxRows = -1 }, true},
+		{"negative min detections", func(s *DatabaseRetentionSettings) { s.MinDetections = -1 }, true},
+		{"zero interval", func(s *DatabaseRetentionSettings) { s.Interval = 0 }, true},
+		{"zero batch size", func(s *DatabaseRetentionSettings) { s.BatchSize = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			err := validateDatabaseRetentionSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDatabaseRetentionSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMQTTHomeAssistantSettings(t *testing.T) {
+	valid := MQTTSettings{
+		Enabled: true,
+		Broker:  "tcp://localhost:1883",
+		Topic:   "birdnet",
+		HomeAssistant: HomeAssistantSettings{
+			Enabled:         true,
+			DiscoveryPrefix: "homeassistant",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *MQTTSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *MQTTSettings) {}, false},
+		{"custom prefix", func(s *MQTTSettings) { s.HomeAssistant.DiscoveryPrefix = "ha/discovery" }, false},
+		{"empty prefix", func(s *MQTTSettings) { s.HomeAssistant.DiscoveryPrefix = "" }, true},
+		{"wildcard prefix", func(s *MQTTSettings) { s.HomeAssistant.DiscoveryPrefix = "homeassistant/#" }, true},
+		{"discovery disabled", func(s *MQTTSettings) {
+			s.HomeAssistant.Enabled = false
+			s.HomeAssistant.DiscoveryPrefix = ""
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			err := validateMQTTSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMQTTSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMQTTCommandSettings(t *testing.T) {
+	valid := MQTTSettings{
+		Enabled: true,
+		Broker:  "tcp://localhost:1883",
+		Topic:   "birdnet",
+		Commands: MQTTCommandSettings{
+			Enabled:         true,
+			Token:           "0123456789abcdef",
+			AllowedCommands: []string{"reload_birdnet"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *MQTTSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *MQTTSettings) {}, false},
+		{"custom topics", func(s *MQTTSettings) {
+			s.Commands.Topic = "control/in"
+			s.Commands.StatusTopic = "control/out"
+		}, false},
+		{"empty token", func(s *MQTTSettings) { s.Commands.Token = "" }, true},
+		{"short token", func(s *MQTTSettings) { s.Commands.Token = "secret" }, true},
+		{"wildcard command topic", func(s *MQTTSettings) { s.Commands.Topic = "control/#" }, true},
+		{"wildcard status topic", func(s *MQTTSettings) { s.Commands.StatusTopic = "control/+/status" }, true},
+		{"commands disabled", func(s *MQTTSettings) {
+			s.Commands.Enabled = false
+			s.Commands.Token = ""
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			err := validateMQTTSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMQTTSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookSettings(t *testing.T) {
+	valid := WebhookSettings{
+		Enabled: true,
+		Endpoints: []WebhookEndpoint{
+			{Name: "nodered", URL: "http://localhost:1880/birdnet", MinConfidence: 0.8, Timeout: 10},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *WebhookSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *WebhookSettings) {}, false},
+		{"https URL", func(s *WebhookSettings) { s.Endpoints[0].URL = "https://example.com/hook" }, false},
+		{"empty URL", func(s *WebhookSettings) { s.Endpoints[0].URL = "" }, true},
+		{"unsupported scheme", func(s *WebhookSettings) { s.Endpoints[0].URL = "ftp://example.com" }, true},
+		{"confidence above 1", func(s *WebhookSettings) { s.Endpoints[0].MinConfidence = 80 }, true},
+		{"negative timeout", func(s *WebhookSettings) { s.Endpoints[0].Timeout = -1 }, true},
+		{"valid template", func(s *WebhookSettings) { s.Endpoints[0].Template = `{"species": {{json .CommonName}}}` }, false},
+		{"unparsable template", func(s *WebhookSettings) { s.Endpoints[0].Template = `{{.CommonName` }, true},
+		{"unknown template function", func(s *WebhookSettings) { s.Endpoints[0].Template = `{{title .CommonName}}` }, true},
+		{"disabled with invalid endpoint", func(s *WebhookSettings) {
+			s.Enabled = false
+			s.Endpoints[0].URL = ""
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			settings.Endpoints = append([]WebhookEndpoint(nil), valid.Endpoints...)
+			tt.modify(&settings)
+			err := validateWebhookSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWebhookSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePushSettings(t *testing.T) {
+	valid := PushSettings{
+		Enabled: true,
+		Channels: []PushChannelSettings{
+			{Name: "phone", Type: "ntfy", Enabled: true, URL: "https://ntfy.sh/birdnet", Priorities: []string{"critical", "high"}},
+			{Name: "mail", Type: "smtp", Enabled: true, SMTP: SMTPSettings{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *PushSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *PushSettings) {}, false},
+		{"unknown type", func(s *PushSettings) { s.Channels[0].Type = "pager" }, true},
+		{"ntfy without url", func(s *PushSettings) { s.Channels[0].URL = "" }, true},
+		{"gotify without token", func(s *PushSettings) { s.Channels[0].Type = "gotify" }, true},
+		{"telegram without chat", func(s *PushSettings) {
+			s.Channels[0].Type = "telegram"
+			s.Channels[0].Token = "123:abc"
+		}, true},
+		{"smtp without recipients", func(s *PushSettings) { s.Channels[1].SMTP.To = nil }, true},
+		{"unknown priority", func(s *PushSettings) { s.Channels[0].Priorities = []string{"urgent"} }, true},
+		{"negative rate limit", func(s *PushSettings) { s.Channels[0].RateLimit = -1 }, true},
+		{"disabled channel not validated", func(s *PushSettings) {
+			s.Channels[0].Enabled = false
+			s.Channels[0].Type = "pager"
+		}, false},
+		{"delivery disabled", func(s *PushSettings) {
+			s.Enabled = false
+			s.Channels[0].Type = "pager"
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			settings.Channels = append([]PushChannelSettings(nil), valid.Channels...)
+			tt.modify(&settings)
+			err := validatePushSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePushSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBirdNETModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *BirdNETConfig)
+		wantErr bool
+	}{
+		{"valid settings", func(s *BirdNETConfig) {}, false},
+		{"missing id", func(s *BirdNETConfig) { s.Models[0].ID = "" }, true},
+		{"duplicate id", func(s *BirdNETConfig) { s.Models[1].ID = s.Models[0].ID }, true},
+		{"enabled without label path", func(s *BirdNETConfig) { s.Models[0].LabelPath = "" }, true},
+		{"disabled without paths", func(s *BirdNETConfig) {
+			s.Models[1].ModelPath = ""
+			s.Models[1].LabelPath = ""
+		}, false},
+		{"negative sample rate", func(s *BirdNETConfig) { s.Models[0].SampleRate = -1 }, true},
+		{"sample rate above capture rate", func(s *BirdNETConfig) { s.Models[0].SampleRate = 96000 }, true},
+		{"threshold above 1", func(s *BirdNETConfig) { s.Models[0].Threshold = 1.5 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &Settings{}
+			settings.BirdNET = BirdNETConfig{
+				Sensitivity: 1,
+				Threshold:   0.8,
+				RangeFilter: RangeFilterSettings{Model: "latest", Threshold: 0.01},
+				Models: []ModelSettings{
+					{ID: "bats", Enabled: true, ModelPath: "bats.tflite", LabelPath: "bats.txt", SampleRate: 48000, Threshold: 0.5},
+					{ID: "insects"},
+				},
+			}
+			tt.modify(&settings.BirdNET)
+			err := validateBirdNETSettings(&settings.BirdNET, settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBirdNETSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateClassifierSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *ClassifierSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *ClassifierSettings) {}, false},
+		{"threshold above 1", func(s *ClassifierSettings) { s.Threshold = 1.1 }, true},
+		{"negative threshold", func(s *ClassifierSettings) { s.Threshold = -0.1 }, true},
+		{"enabled without path", func(s *ClassifierSettings) { s.Path = "" }, true},
+		{"disabled without path", func(s *ClassifierSettings) {
+			s.Enabled = false
+			s.Path = ""
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := ClassifierSettings{Enabled: true, Path: "classifiers", Threshold: 0.5}
+			tt.modify(&settings)
+			err := validateClassifierSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateClassifierSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAdaptiveRulesSettings(t *testing.T) {
+	hour := 5
+	invalidHour := 24
+	tests := []struct {
+		name    string
+		modify  func(s *AdaptiveRulesSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *AdaptiveRulesSettings) {}, false},
+		{"zero window", func(s *AdaptiveRulesSettings) { s.WindowDays = 0 }, true},
+		{"zero minimum reviews", func(s *AdaptiveRulesSettings) { s.MinReviews = 0 }, true},
+		{"zero refresh interval", func(s *AdaptiveRulesSettings) { s.RefreshInterval = 0 }, true},
+		{"raise rate above suppress rate", func(s *AdaptiveRulesSettings) { s.RaiseRate = 0.95 }, true},
+		{"suppress rate above 1", func(s *AdaptiveRulesSettings) { s.SuppressRate = 1.5 }, true},
+		{"disabled with invalid limits", func(s *AdaptiveRulesSettings) {
+			s.Enabled = false
+			s.WindowDays = 0
+		}, false},
+		{"valid overrides", func(s *AdaptiveRulesSettings) {
+			s.Overrides = []AdaptiveRuleOverride{
+				{Species: "Corvus corax", Source: "rtsp_1", Hour: &hour, Action: AdaptiveRuleSuppress},
+				{Species: "Strix aluco", Action: AdaptiveRuleRaiseThreshold, MinConfidence: 0.8},
+				{Species: "Bubo bubo", Action: AdaptiveRuleAllow},
+			}
+		}, false},
+		{"override without species", func(s *AdaptiveRulesSettings) {
+			s.Overrides = []AdaptiveRuleOverride{{Action: AdaptiveRuleSuppress}}
+		}, true},
+		{"override with invalid hour", func(s *AdaptiveRulesSettings) {
+			s.Overrides = []AdaptiveRuleOverride{{Species: "Corvus corax", Hour: &invalidHour, Action: AdaptiveRuleSuppress}}
+		}, true},
+		{"override with unknown action", func(s *AdaptiveRulesSettings) {
+			s.Overrides = []AdaptiveRuleOverride{{Species: "Corvus corax", Action: "ignore"}}
+		}, true},
+		{"raised threshold without confidence", func(s *AdaptiveRulesSettings) {
+			s.Overrides = []AdaptiveRuleOverride{{Species: "Corvus corax", Action: AdaptiveRuleRaiseThreshold}}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := AdaptiveRulesSettings{
+				Enabled:         true,
+				WindowDays:      90,
+				MinReviews:      10,
+				RaiseRate:       0.5,
+				SuppressRate:    0.9,
+				RefreshInterval: 60,
+			}
+			tt.modify(&settings)
+			err := validateAdaptiveRulesSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAdaptiveRulesSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
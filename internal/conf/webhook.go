@@ -0,0 +1,30 @@
+// conf/webhook.go body templates of webhook endpoints
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// webhookTemplateFuncs are the functions available in webhook body templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes a value, use it to embed strings safely in JSON bodies
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// percent formats a 0-1 confidence as a whole percentage
+	"percent": func(confidence float64) string {
+		return fmt.Sprintf("%.0f", confidence*100)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ParseWebhookTemplate parses the body template of a webhook endpoint. Fields missing from
+// the detection fail the template instead of rendering as "<no value>".
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(text)
+}
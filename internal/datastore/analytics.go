@@ -72,14 +72,15 @@ func (ds *DataStore) GetSpeciesSummaryData(startDate, endDate string) ([]Species
 	}
 
 	// Start building query
+	dateTimeExpr := ds.GetDateTimeFormat()
 	queryStr := `
 		SELECT 
 			scientific_name,
 			MAX(common_name) as common_name,
 			MAX(species_code) as species_code,
 			COUNT(*) as count,
-			MIN(datetime(date || ' ' || time)) as first_seen,
-			MAX(datetime(date || ' ' || time)) as last_seen,
+			MIN(` + dateTimeExpr + `) as first_seen,
+			MAX(` + dateTimeExpr + `) as last_seen,
 			AVG(confidence) as avg_confidence,
 			MAX(confidence) as max_confidence
 		FROM notes
@@ -326,6 +327,27 @@ func (ds *DataStore) GetDetectionTrends(period string, limit int) ([]DailyAnalyt
 				Context("limit", fmt.Sprintf("%d", limit)).
 				Build()
 		}
+	case "postgres":
+		// date is stored as YYYY-MM-DD text, compare against a formatted date
+		startDate = fmt.Sprintf("TO_CHAR(CURRENT_DATE - INTERVAL '%s', 'YYYY-MM-DD')", interval)
+		query := fmt.Sprintf(`
+			SELECT date, COUNT(*) as count
+			FROM notes
+			WHERE date >= %s
+			GROUP BY date
+			ORDER BY date DESC
+			LIMIT ?
+		`, startDate)
+
+		if err := ds.DB.Raw(query, limit).Scan(&trends).Error; err != nil {
+			return nil, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryDatabase).
+				Context("operation", "get_detection_trends_postgres").
+				Context("period", period).
+				Context("limit", fmt.Sprintf("%d", limit)).
+				Build()
+		}
 	default:
 		return nil, errors.Newf("unsupported database dialect").
 			Component("datastore").
@@ -540,7 +562,8 @@ func (ds *DataStore) GetNewSpeciesDetections(startDate, endDate string, limit, o
 	        MIN(CASE WHEN date != '' AND date IS NOT NULL THEN date ELSE NULL END) as first_detection_date
 	    FROM notes
 	    GROUP BY scientific_name
-	    HAVING first_detection_date IS NOT NULL AND first_detection_date != '' 
+	    -- Repeat the aggregate, PostgreSQL does not allow output aliases in HAVING
+	    HAVING MIN(CASE WHEN date != '' AND date IS NOT NULL THEN date ELSE NULL END) IS NOT NULL
 	), 
 	SpeciesInPeriod AS (
 	    SELECT 
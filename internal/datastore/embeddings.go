@@ -0,0 +1,278 @@
+// embeddings.go: storage and similarity search of detection embeddings
+package datastore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// embeddingBatchSize is the number of embeddings read at a time when the index is loaded
+const embeddingBatchSize = 1000
+
+// SimilarNote is a note found by similarity search with the cosine similarity of its
+// embedding to the embedding of the searched note, from -1 to 1
+type SimilarNote struct {
+	Note       Note
+	Similarity float64
+}
+
+// ReviewedEmbedding is the embedding of a note reviewed as correct or false positive
+type ReviewedEmbedding struct {
+	NoteID         uint
+	ScientificName string
+	CommonName     string
+	Verified       string // Values: "correct", "false_positive"
+	Model          string
+	Vector         []byte
+}
+
+// NewEmbedding creates the embedding of a note from the embedding values
+func NewEmbedding(noteID uint, model string, values []float32) *Embedding {
+	vector := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(vector[4*i:], math.Float32bits(v))
+	}
+	return &Embedding{NoteID: noteID, Model: model, Vector: vector}
+}
+
+// Values returns the embedding values
+func (e *Embedding) Values() ([]float32, error) {
+	if len(e.Vector)%4 != 0 {
+		return nil, fmt.Errorf("embedding vector of %d bytes is not a float32 vector", len(e.Vector))
+	}
+	values := make([]float32, len(e.Vector)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(e.Vector[4*i:]))
+	}
+	return values, nil
+}
+
+// indexedEmbedding is an embedding in the similarity index, normalized to unit length so
+// the dot product of two embeddings is their cosine similarity
+type indexedEmbedding struct {
+	model  string
+	vector []float32
+}
+
+// embeddingIndex holds the embeddings of all notes in memory for exhaustive similarity
+// search. It is loaded from the database on the first search and kept up to date by
+// SaveEmbedding and Delete. Embeddings of notes deleted otherwise, such as by retention, are
+// dropped from the index and the database when a search finds them.
+type embeddingIndex struct {
+	mu      sync.RWMutex
+	loaded  bool
+	vectors map[uint]indexedEmbedding // by note ID
+}
+
+// add adds or replaces the embedding of a note. Must be called with mu held.
+func (idx *embeddingIndex) add(noteID uint, model string, values []float32) {
+	if idx.vectors == nil {
+		idx.vectors = make(map[uint]indexedEmbedding)
+	}
+	idx.vectors[noteID] = indexedEmbedding{model: model, vector: normalizeVector(values)}
+}
+
+// search returns the IDs of the notes with the embeddings most similar to the given
+// embedding, most similar first. Only embeddings of the same model and size are compared.
+func (idx *embeddingIndex) search(excludeID uint, model string, values []float32) []SimilarNote {
+	query := normalizeVector(values)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	matches := make([]SimilarNote, 0, len(idx.vectors))
+	for noteID, candidate := range idx.vectors {
+		if noteID == excludeID || candidate.model != model || len(candidate.vector) != len(query) {
+			continue
+		}
+		var similarity float64
+		for i, v := range candidate.vector {
+			similarity += float64(v) * float64(query[i])
+		}
+		matches = append(matches, SimilarNote{Note: Note{ID: noteID}, Similarity: similarity})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Note.ID < matches[j].Note.ID
+	})
+	return matches
+}
+
+// remove drops the embeddings of deleted notes from the index
+func (idx *embeddingIndex) remove(noteIDs []uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, noteID := range noteIDs {
+		delete(idx.vectors, noteID)
+	}
+}
+
+// normalizeVector returns a copy of the vector scaled to unit length
+func normalizeVector(values []float32) []float32 {
+	var norm float64
+	for _, v := range values {
+		norm += float64(v) * float64(v)
+	}
+	normalized := make([]float32, len(values))
+	if norm == 0 {
+		return normalized
+	}
+	scale := 1 / math.Sqrt(norm)
+	for i, v := range values {
+		normalized[i] = float32(float64(v) * scale)
+	}
+	return normalized
+}
+
+// SaveEmbedding saves the embedding of a note, replacing an earlier embedding of the note
+func (ds *DataStore) SaveEmbedding(embedding *Embedding) error {
+	if embedding.NoteID == 0 {
+		return validationError("note ID cannot be zero", "note_id", embedding.NoteID)
+	}
+	values, err := embedding.Values()
+	if err != nil || len(values) == 0 {
+		return validationError("embedding must be a non-empty float32 vector", "vector", len(embedding.Vector))
+	}
+
+	if err := ds.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "note_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model", "vector"}),
+	}).Create(embedding).Error; err != nil {
+		return dbError(err, "save_embedding", errors.PriorityLow,
+			"table", "embeddings",
+			"note_id", embedding.NoteID)
+	}
+
+	ds.embeddings.mu.Lock()
+	defer ds.embeddings.mu.Unlock()
+	if ds.embeddings.loaded {
+		ds.embeddings.add(embedding.NoteID, embedding.Model, values)
+	}
+	return nil
+}
+
+// FindSimilarNotes returns up to limit notes with the embeddings most similar to the
+// embedding of the note, most similar first. It returns ErrEmbeddingNotFound when no
+// embedding was saved for the note.
+func (ds *DataStore) FindSimilarNotes(noteID string, limit int) ([]SimilarNote, error) {
+	id, err := strconv.ParseUint(noteID, 10, 32)
+	if err != nil {
+		return nil, validationError("invalid note ID format", "id", noteID)
+	}
+	if limit <= 0 {
+		return nil, validationError("limit must be positive", "limit", limit)
+	}
+
+	var embedding Embedding
+	if err := ds.DB.Where("note_id = ?", id).First(&embedding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmbeddingNotFound
+		}
+		return nil, dbError(err, "get_embedding", errors.PriorityMedium, "table", "embeddings", "note_id", noteID)
+	}
+	values, err := embedding.Values()
+	if err != nil {
+		return nil, dbError(err, "decode_embedding", errors.PriorityMedium, "table", "embeddings", "note_id", noteID)
+	}
+
+	if err := ds.loadEmbeddingIndex(); err != nil {
+		return nil, err
+	}
+	matches := ds.embeddings.search(uint(id), embedding.Model, values)
+
+	// Load the notes of the best matches, dropping embeddings whose notes were deleted
+	similar := make([]SimilarNote, 0, limit)
+	for start := 0; start < len(matches) && len(similar) < limit; start += limit {
+		page := matches[start:min(start+limit, len(matches))]
+		ids := make([]uint, len(page))
+		for i := range page {
+			ids[i] = page[i].Note.ID
+		}
+
+		var notes []Note
+		if err := ds.DB.Preload("Review").Preload("Lock").Where("id IN ?", ids).Find(&notes).Error; err != nil {
+			return nil, dbError(err, "get_similar_notes", errors.PriorityMedium, "table", "notes")
+		}
+		byID := make(map[uint]Note, len(notes))
+		for i := range notes {
+			if notes[i].Review != nil {
+				notes[i].Verified = notes[i].Review.Verified
+			}
+			notes[i].Locked = notes[i].Lock != nil
+			byID[notes[i].ID] = notes[i]
+		}
+
+		var deleted []uint
+		for _, match := range page {
+			note, ok := byID[match.Note.ID]
+			if !ok {
+				deleted = append(deleted, match.Note.ID)
+				continue
+			}
+			if len(similar) < limit {
+				similar = append(similar, SimilarNote{Note: note, Similarity: match.Similarity})
+			}
+		}
+		if len(deleted) > 0 {
+			if err := ds.DB.Where("note_id IN ?", deleted).Delete(&Embedding{}).Error; err != nil {
+				return nil, dbError(err, "delete_orphan_embeddings", errors.PriorityLow, "table", "embeddings")
+			}
+			ds.embeddings.remove(deleted)
+		}
+	}
+	return similar, nil
+}
+
+// loadEmbeddingIndex loads the embeddings of all notes into the index unless already loaded
+func (ds *DataStore) loadEmbeddingIndex() error {
+	ds.embeddings.mu.Lock()
+	defer ds.embeddings.mu.Unlock()
+	if ds.embeddings.loaded {
+		return nil
+	}
+
+	var batch []Embedding
+	err := ds.DB.Model(&Embedding{}).FindInBatches(&batch, embeddingBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			values, err := batch[i].Values()
+			if err != nil {
+				getLogger().Warn("Skipping invalid embedding", "note_id", batch[i].NoteID, "error", err)
+				continue
+			}
+			ds.embeddings.add(batch[i].NoteID, batch[i].Model, values)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return dbError(err, "load_embeddings", errors.PriorityMedium, "table", "embeddings")
+	}
+	ds.embeddings.loaded = true
+	return nil
+}
+
+// GetReviewedEmbeddings returns the embeddings of the notes reviewed as correct or false
+// positive, the training data of species verification classifiers
+func (ds *DataStore) GetReviewedEmbeddings() ([]ReviewedEmbedding, error) {
+	var reviewed []ReviewedEmbedding
+	err := ds.DB.Table("embeddings").
+		Select("embeddings.note_id, notes.scientific_name, notes.common_name, note_reviews.verified, embeddings.model, embeddings.vector").
+		Joins("JOIN notes ON notes.id = embeddings.note_id").
+		Joins("JOIN note_reviews ON note_reviews.note_id = embeddings.note_id").
+		Where("note_reviews.verified IN ?", []string{"correct", "false_positive"}).
+		Order("embeddings.note_id").
+		Scan(&reviewed).Error
+	if err != nil {
+		return nil, dbError(err, "get_reviewed_embeddings", errors.PriorityMedium, "table", "embeddings")
+	}
+	return reviewed, nil
+}
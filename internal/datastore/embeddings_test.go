@@ -0,0 +1,115 @@
+package datastore
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gorm.io/gorm"
+)
+
+func TestEmbeddingValuesRoundTrip(t *testing.T) {
+	t.Parallel()
+	values := []float32{0.25, -1.5, 3e-7, 0}
+	embedding := NewEmbedding(1, "model", values)
+	assert.Len(t, embedding.Vector, 16)
+
+	decoded, err := embedding.Values()
+	require.NoError(t, err)
+	assert.Equal(t, values, decoded)
+
+	_, err = (&Embedding{Vector: []byte{1, 2, 3}}).Values()
+	require.Error(t, err)
+}
+
+func TestFindSimilarNotes(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	saveNote := func(commonName string, model string, values []float32) string {
+		t.Helper()
+		note := &Note{CommonName: commonName, ScientificName: commonName, Date: "2025-05-01", Time: "12:00:00"}
+		require.NoError(t, store.Save(note, nil))
+		if values != nil {
+			require.NoError(t, store.SaveEmbedding(NewEmbedding(note.ID, model, values)))
+		}
+		return strconv.FormatUint(uint64(note.ID), 10)
+	}
+
+	query := saveNote("Query", "birdnet", []float32{1, 0, 0})
+	closeMatch := saveNote("Close", "birdnet", []float32{0.9, 0.1, 0})
+	far := saveNote("Far", "birdnet", []float32{0, 1, 0})
+	saveNote("Other model", "perch", []float32{1, 0, 0})
+	saveNote("Other size", "birdnet", []float32{1, 0})
+	noEmbedding := saveNote("No embedding", "", nil)
+
+	similar, err := store.FindSimilarNotes(query, 10)
+	require.NoError(t, err)
+	require.Len(t, similar, 2, "only embeddings of the same model and size are compared")
+	assert.Equal(t, "Close", similar[0].Note.CommonName)
+	assert.Greater(t, similar[0].Similarity, 0.99)
+	assert.Equal(t, "Far", similar[1].Note.CommonName)
+	assert.InDelta(t, 0, similar[1].Similarity, 1e-6)
+
+	// Embeddings saved after the index is loaded are searchable
+	later := saveNote("Later", "birdnet", []float32{2, 0, 0})
+	similar, err = store.FindSimilarNotes(query, 1)
+	require.NoError(t, err)
+	require.Len(t, similar, 1)
+	assert.Equal(t, "Later", similar[0].Note.CommonName)
+	assert.InDelta(t, 1, similar[0].Similarity, 1e-6)
+
+	// Deleted notes are not returned
+	require.NoError(t, store.Delete(later))
+	require.NoError(t, store.Delete(closeMatch))
+	similar, err = store.FindSimilarNotes(query, 10)
+	require.NoError(t, err)
+	require.Len(t, similar, 1)
+	assert.Equal(t, far, strconv.FormatUint(uint64(similar[0].Note.ID), 10))
+
+	var embeddings int64
+	require.NoError(t, store.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&Embedding{}).Where("note_id IN ?", []string{later, closeMatch}).Count(&embeddings).Error
+	}))
+	assert.Zero(t, embeddings, "deleting a note deletes its embedding")
+
+	_, err = store.FindSimilarNotes(noEmbedding, 10)
+	require.ErrorIs(t, err, ErrEmbeddingNotFound)
+	_, err = store.FindSimilarNotes("abc", 10)
+	require.Error(t, err)
+	require.Error(t, store.SaveEmbedding(&Embedding{NoteID: 1}))
+}
+
+func TestGetReviewedEmbeddings(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	saveReviewed := func(scientificName, verified string, withEmbedding bool) {
+		t.Helper()
+		note := &Note{CommonName: scientificName, ScientificName: scientificName, Date: "2025-05-01", Time: "12:00:00"}
+		require.NoError(t, store.Save(note, nil))
+		if verified != "" {
+			require.NoError(t, store.SaveNoteReview(&NoteReview{NoteID: note.ID, Verified: verified}))
+		}
+		if withEmbedding {
+			require.NoError(t, store.SaveEmbedding(NewEmbedding(note.ID, "birdnet", []float32{1, 2})))
+		}
+	}
+
+	saveReviewed("Corvus corax", "correct", true)
+	saveReviewed("Corvus corax", "false_positive", true)
+	saveReviewed("Strix aluco", "", true)
+	saveReviewed("Bubo bubo", "correct", false)
+
+	reviewed, err := store.GetReviewedEmbeddings()
+	require.NoError(t, err)
+	require.Len(t, reviewed, 2, "only reviewed notes with embeddings are returned")
+	assert.Equal(t, "correct", reviewed[0].Verified)
+	assert.Equal(t, "false_positive", reviewed[1].Verified)
+	assert.Equal(t, "Corvus corax", reviewed[1].ScientificName)
+	assert.Equal(t, "birdnet", reviewed[1].Model)
+
+	values, err := (&Embedding{Vector: reviewed[1].Vector}).Values()
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 2}, values)
+}
@@ -30,6 +30,8 @@ var (
 	ErrNoteReviewNotFound = errors.Newf("note review not found").Component("datastore").Category(errors.CategoryNotFound).Build()
 	ErrNoteLockNotFound   = errors.Newf("note lock not found").Component("datastore").Category(errors.CategoryNotFound).Build()
 	ErrImageCacheNotFound = errors.Newf("image cache not found").Component("datastore").Category(errors.CategoryNotFound).Build()
+	ErrUserNotFound       = errors.Newf("user not found").Component("datastore").Category(errors.CategoryNotFound).Build()
+	ErrEmbeddingNotFound  = errors.Newf("embedding not found").Component("datastore").Category(errors.CategoryNotFound).Build()
 )
 
 // StoreInterface abstracts the underlying database implementation and defines the interface for database operations.
@@ -92,6 +94,21 @@ type Interface interface {
 	GetSpeciesFirstDetectionInPeriod(startDate, endDate string, limit, offset int) ([]NewSpeciesData, error)
 	// Search functionality
 	SearchDetections(filters *SearchFilters) ([]DetectionRecord, int, error)
+	// Sound level methods
+	SaveSoundLevels(levels []SoundLevel) error
+	GetSoundLevels(query *SoundLevelQuery) ([]SoundLevel, error)
+	GetSoundLevelSources() ([]SoundLevelSource, error)
+	// User account methods
+	GetUser(username string) (*User, error)
+	GetUsers() ([]User, error)
+	SaveUser(user *User) error
+	DeleteUser(username string) error
+	// Embedding methods
+	SaveEmbedding(embedding *Embedding) error
+	FindSimilarNotes(noteID string, limit int) ([]SimilarNote, error)
+	GetReviewedEmbeddings() ([]ReviewedEmbedding, error)
+	// Review outcome methods
+	GetReviewOutcomes(startDate string) ([]ReviewOutcome, error)
 }
 
 // DataStore implements StoreInterface using a GORM database.
@@ -101,6 +118,7 @@ type DataStore struct {
 	sunTimesCache sync.Map         // Thread-safe map for caching sun times by date
 	metrics       *Metrics // Metrics instance for tracking operations
 	metricsMu     sync.RWMutex     // Mutex to protect metrics field access
+	embeddings    embeddingIndex   // Embeddings of notes for similarity search, loaded on first search
 	
 	// Monitoring lifecycle management
 	monitoringCtx    context.Context    // Context for monitoring goroutines
@@ -128,6 +146,13 @@ func New(settings *conf.Settings) Interface {
 				SunCalc: sunCalc,
 			},
 		}
+	case settings.Output.PostgreSQL.Enabled:
+		return &PostgreSQLStore{
+			Settings: settings,
+			DataStore: DataStore{
+				SunCalc: sunCalc,
+			},
+		}
 	default:
 		// Consider handling the case where neither database is enabled
 		return nil
@@ -242,7 +267,7 @@ func (ds *DataStore) Get(id string) (Note, error) {
 	return note, nil
 }
 
-// Delete removes a note and its associated results from the database.
+// Delete removes a note and its associated results and embedding from the database.
 func (ds *DataStore) Delete(id string) error {
 	// Convert the id from string to unsigned integer
 	noteID, err := strconv.ParseUint(id, 10, 32)
@@ -265,7 +290,7 @@ func (ds *DataStore) Delete(id string) error {
 	}
 
 	// Perform the deletion within a transaction
-	return ds.DB.Transaction(func(tx *gorm.DB) error {
+	err = ds.DB.Transaction(func(tx *gorm.DB) error {
 		// Delete the full results entry associated with the note
 		if err := tx.Where("note_id = ?", noteID).Delete(&Results{}).Error; err != nil {
 			return dbError(err, "delete_results", errors.PriorityMedium,
@@ -273,6 +298,13 @@ func (ds *DataStore) Delete(id string) error {
 				"table", "results",
 				"action", "delete_detection_results")
 		}
+		// Delete the embedding of the note
+		if err := tx.Where("note_id = ?", noteID).Delete(&Embedding{}).Error; err != nil {
+			return dbError(err, "delete_embedding", errors.PriorityMedium,
+				"note_id", fmt.Sprintf("%d", noteID),
+				"table", "embeddings",
+				"action", "delete_detection_embedding")
+		}
 		// Delete the note itself
 		if err := tx.Delete(&Note{}, noteID).Error; err != nil {
 			return dbError(err, "delete_note", errors.PriorityMedium,
@@ -282,6 +314,11 @@ func (ds *DataStore) Delete(id string) error {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	ds.embeddings.remove([]uint{uint(noteID)})
+	return nil
 }
 
 // GetNoteClipPath retrieves the path to the audio clip associated with a note.
@@ -423,18 +460,42 @@ func (ds *DataStore) Dialector() gorm.Dialector {
 
 // GetHourFormat returns the database-specific SQL fragment for formatting a time column as hour.
 func (ds *DataStore) GetHourFormat() string {
-	// Handling for supported databases: SQLite and MySQL
+	// Handling for supported databases: SQLite, MySQL and PostgreSQL
 	switch strings.ToLower(ds.Dialector().Name()) {
 	case "sqlite":
 		return "strftime('%H', time)"
 	case "mysql":
 		return "TIME_FORMAT(time, '%H')"
+	case "postgres":
+		// time is stored as HH:MM:SS text
+		return "SUBSTR(time, 1, 2)"
 	default:
 		// Log or handle unsupported database types
 		return ""
 	}
 }
 
+// GetDateTimeFormat returns the database-specific SQL fragment combining the date and time
+// columns into a "YYYY-MM-DD HH:MM:SS" value.
+func (ds *DataStore) GetDateTimeFormat() string {
+	switch strings.ToLower(ds.Dialector().Name()) {
+	case "postgres":
+		// Both columns are zero padded text, so the concatenation sorts chronologically
+		return "(date || ' ' || time)"
+	default:
+		return "datetime(date || ' ' || time)"
+	}
+}
+
+// likeOperator returns the case-insensitive pattern matching operator of the database.
+// LIKE ignores case in SQLite and MySQL, PostgreSQL needs ILIKE for the same behavior.
+func likeOperator(db *gorm.DB) string {
+	if db != nil && db.Dialector != nil && strings.EqualFold(db.Dialector.Name(), "postgres") {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
 // GetHourlyOccurrences retrieves hourly occurrences of a specified bird species.
 func (ds *DataStore) GetHourlyOccurrences(date, commonName string, minConfidenceNormalized float64) ([24]int, error) {
 	var hourlyCounts [24]int
@@ -559,10 +620,11 @@ func (ds *DataStore) GetAllDetectedSpecies() ([]Note, error) {
 func (ds *DataStore) SearchNotes(query string, sortAscending bool, limit, offset int) ([]Note, error) {
 	var notes []Note
 	sortOrder := sortAscendingString(sortAscending)
+	like := likeOperator(ds.DB)
 
 	err := ds.DB.Preload("Review").Preload("Lock").Preload("Comments", func(db *gorm.DB) *gorm.DB {
 		return db.Order("created_at DESC") // Order comments by creation time, newest first
-	}).Where("common_name LIKE ? OR scientific_name LIKE ?", "%"+query+"%", "%"+query+"%").
+	}).Where("common_name "+like+" ? OR scientific_name "+like+" ?", "%"+query+"%", "%"+query+"%").
 		Order("id " + sortOrder).
 		Limit(limit).
 		Offset(offset).
@@ -767,8 +829,9 @@ func (ds *DataStore) CountSpeciesDetections(species, date, hour string, duration
 // CountSearchResults counts the number of search results for a given query.
 func (ds *DataStore) CountSearchResults(query string) (int64, error) {
 	var count int64
+	like := likeOperator(ds.DB)
 	err := ds.DB.Model(&Note{}).
-		Where("common_name LIKE ? OR scientific_name LIKE ?", "%"+query+"%", "%"+query+"%").
+		Where("common_name "+like+" ? OR scientific_name "+like+" ?", "%"+query+"%", "%"+query+"%").
 		Count(&count).Error
 
 	if err != nil {
@@ -1483,7 +1546,8 @@ func (f *SearchFilters) sanitise() error {
 func applySpeciesFilter(query *gorm.DB, species string) *gorm.DB {
 	if species != "" {
 		likeParam := "%" + species + "%"
-		return query.Where("notes.scientific_name LIKE ? OR notes.common_name LIKE ?", likeParam, likeParam)
+		like := likeOperator(query)
+		return query.Where("notes.scientific_name "+like+" ? OR notes.common_name "+like+" ?", likeParam, likeParam)
 	}
 	return query
 }
@@ -1541,7 +1605,7 @@ func applyCommonFilters(query *gorm.DB, filters *SearchFilters, ds *DataStore) *
 	} // --- End Dynamic TimeOfDay Filter ---
 
 	if filters.Device != "" {
-		query = query.Where("notes.source_node LIKE ?", "%"+filters.Device+"%")
+		query = query.Where("notes.source_node "+likeOperator(query)+" ?", "%"+filters.Device+"%")
 	}
 
 	return query
@@ -1,15 +1,14 @@
 package datastore
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
-	"net/url"
 	"slices"
 	"strings"
 	"time"
 
-	"github.com/tphakala/birdnet-go/internal/errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -250,200 +249,119 @@ func hasCorrectImageCacheIndexMySQL(db *gorm.DB, dbName string, debug bool) (boo
 	return foundCorrectIndex && !foundIncorrectIndex, nil
 }
 
-// performAutoMigration automates database migrations with error handling.
-// It checks the schema of the image_caches table and drops/recreates it if incorrect.
-func performAutoMigration(db *gorm.DB, debug bool, dbType, connectionInfo string) error {
-	migrationStart := time.Now()
-	migrationLogger := getLogger().With("db_type", dbType)
-	
-	migrationLogger.Info("Starting database migration")
-	
-	// Validate and fix schema if needed
-	if err := validateAndFixSchema(db, dbType, connectionInfo, debug, migrationLogger); err != nil {
-		return err
-	}
-
-	// Perform table migrations
-	successCount, err := migrateTables(db, dbType, migrationLogger)
-	if err != nil {
-		return err
-	}
-	
-	// Create optimized indexes for new species tracking performance
-	if err := createOptimizedIndexes(db, dbType, migrationLogger); err != nil {
-		return err
+// hasCorrectImageCacheIndexPostgreSQL checks if the PostgreSQL database has the correct
+// composite unique index on the image_caches table in the current schema.
+func hasCorrectImageCacheIndexPostgreSQL(db *gorm.DB, debug bool) (bool, error) {
+	type IndexInfo struct {
+		IndexName  string `gorm:"column:index_name"`
+		ColumnName string `gorm:"column:column_name"`
+		SeqInIndex int    `gorm:"column:seq_in_index"`
+		IsUnique   bool   `gorm:"column:is_unique"`
 	}
-	
-	// Log successful migration completion
-	migrationLogger.Info("Database migration completed successfully",
-		"db_type", dbType,
-		"total_duration", time.Since(migrationStart),
-		"tables_migrated", successCount)
 
-	return nil
-}
-
-// extractDBNameFromMySQLInfo parses the database name from a MySQL DSN string.
-// Example DSNs:
-//
-//	user:pass@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4
-//	user:pass@unix(/path/to/socket)/dbname
-//	user:pass@/dbname?charset=utf8mb4 (no host/protocol)
-//	/dbname (only path)
-func extractDBNameFromMySQLInfo(connectionInfo string) string {
-	// The go-sql-driver/mysql doesn't strictly require a scheme,
-	// but net/url.Parse needs one for correct parsing. Add a dummy scheme if missing.
-	// We need to handle cases where the DSN might *only* be the path, e.g., "/dbname".
-	// Also handle cases like "user:pass@/dbname"
-	parseInput := connectionInfo
-	if !strings.Contains(parseInput, "://") && !strings.HasPrefix(parseInput, "/") {
-		// If no scheme and not starting with '/', add dummy scheme for parsing.
-		parseInput = "dummy://" + parseInput
-	} else if strings.HasPrefix(parseInput, "/") {
-		// Case like "/dbname?params=value"
-		parseInput = "dummy://dummyhost" + parseInput // Add dummy scheme and host
-	}
+	var stats []IndexInfo
+	targetIndexName := "idx_imagecache_provider_species"
+	targetTableName := "image_caches"
 
-	u, err := url.Parse(parseInput)
-	if err != nil {
-		sanitizedConnectionInfo := redactSensitiveInfo(connectionInfo)
-		log.Printf("WARN: Failed to parse MySQL connection info '%s' as URL: %v. Cannot extract DB name.", sanitizedConnectionInfo, err)
-		return "" // Return empty on parse error
+	// Check if the table exists first
+	if !db.Migrator().HasTable(&ImageCache{}) {
+		return false, nil // Table doesn't exist, index can't be correct (will be created by AutoMigrate)
 	}
 
-	// The database name is the path component, stripping the leading '/' if present.
-	dbName := u.Path
-	dbName = strings.TrimPrefix(dbName, "/") // Use TrimPrefix directly
-
-	// The go-sql-driver/mysql can handle DSNs without a path/dbname
-	// (e.g., connecting to the default database). Return empty string in that case.
-	if dbName == "" {
-		return ""
-	}
+	// Query the system catalogs for index details, one row per indexed column.
+	// indkey is zero based, array_position on the int2 array gives the one based column position.
+	query := `SELECT i.relname AS index_name, a.attname AS column_name,
+	                 array_position(ix.indkey::int2[], a.attnum) AS seq_in_index, ix.indisunique AS is_unique
+	          FROM pg_class t
+	          JOIN pg_namespace n ON n.oid = t.relnamespace
+	          JOIN pg_index ix ON ix.indrelid = t.oid
+	          JOIN pg_class i ON i.oid = ix.indexrelid
+	          JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+	          WHERE n.nspname = current_schema() AND t.relname = ?`
 
-	// The Path might still contain parameters if the original DSN was just `/dbname?param=val`
-	// and we added a dummy host. Check for '?' again.
-	if qMark := strings.Index(dbName, "?"); qMark != -1 {
-		dbName = dbName[:qMark]
+	if err := db.Raw(query, targetTableName).Scan(&stats).Error; err != nil {
+		return false, fmt.Errorf("failed to query index info from pg_catalog for %s: %w", targetTableName, err)
 	}
 
-	return dbName
-}
-
-// redactSensitiveInfo redacts sensitive information (e.g., password) from a MySQL DSN string.
-func redactSensitiveInfo(dsn string) string {
-	// Parse the DSN to extract components. Add a dummy scheme if needed for parsing,
-	// similar to how extractDBNameFromMySQLInfo does, but focus just on enabling parsing.
-	parseInput := dsn
-	needsDummyScheme := false
-	if !strings.Contains(parseInput, "://") {
-		// Add dummy scheme if it's likely a DSN needing one (contains '@' or starts without '/')
-		if strings.Contains(parseInput, "@") || (!strings.HasPrefix(parseInput, "/") && strings.Contains(parseInput, "(")) {
-			parseInput = "dummy://" + parseInput
-			needsDummyScheme = true
-		} else if strings.HasPrefix(parseInput, "/") {
-			// Handle path-only or path-with-params DSNs like "/dbname?..."
-			parseInput = "dummy://dummyhost" + parseInput
-			needsDummyScheme = true
+	// Map index names to their columns and positions
+	indexDetails := make(map[string]struct {
+		IsUnique  bool
+		SeqInCols map[string]int
+	})
+	for _, stat := range stats {
+		if debug {
+			log.Printf("DEBUG: PostgreSQL Processing index stat: Index=%s, Column=%s, Seq=%d, Unique=%v",
+				stat.IndexName, stat.ColumnName, stat.SeqInIndex, stat.IsUnique)
 		}
-		// Note: Plain "dbname" without scheme/user/host/params might fail parsing, which is acceptable.
-	}
-
-	u, err := url.Parse(parseInput)
-	if err != nil {
-		// If parsing fails even with added scheme, return a generic redacted string
-		// as we cannot reliably locate the password. Avoid logging the raw DSN.
-		log.Printf("DEBUG: Failed to parse DSN for redaction: %v. Returning generic redaction.", err)
-		return "[REDACTED DSN]"
+		detail, exists := indexDetails[stat.IndexName]
+		if !exists {
+			detail.IsUnique = stat.IsUnique
+			detail.SeqInCols = make(map[string]int)
+		}
+		detail.SeqInCols[stat.ColumnName] = stat.SeqInIndex
+		indexDetails[stat.IndexName] = detail
 	}
 
-	// Redact the password if present in the UserInfo
-	if u.User != nil {
-		_, hasPassword := u.User.Password()
-		if hasPassword {
-			u.User = url.UserPassword(u.User.Username(), "[REDACTED]")
+	foundCorrectIndex := false
+	foundIncorrectIndex := false
+	for indexName, detail := range indexDetails {
+		if !detail.IsUnique {
+			continue
+		}
+		if indexName == targetIndexName {
+			// Expected: provider_name first, scientific_name second
+			if len(detail.SeqInCols) == 2 && detail.SeqInCols["provider_name"] == 1 && detail.SeqInCols["scientific_name"] == 2 {
+				foundCorrectIndex = true
+			}
+		} else if _, ok := detail.SeqInCols["scientific_name"]; ok && len(detail.SeqInCols) == 1 {
+			// The incorrect single-column unique index on scientific_name
+			foundIncorrectIndex = true
+			if debug {
+				log.Printf("DEBUG: PostgreSQL Found incorrect single-column unique index on scientific_name: %s", indexName)
+			}
 		}
 	}
 
-	// Reconstruct the string. If we added a dummy scheme/host, remove it.
-	sanitized := u.String()
-	if needsDummyScheme {
-		if strings.HasPrefix(sanitized, "dummy://dummyhost") {
-			sanitized = strings.TrimPrefix(sanitized, "dummy://dummyhost")
-		} else if strings.HasPrefix(sanitized, "dummy://") {
-			sanitized = strings.TrimPrefix(sanitized, "dummy://")
-		}
+	if debug {
+		log.Printf("DEBUG: PostgreSQL Schema Check Result: foundCorrectIndex=%v, foundIncorrectIndex=%v", foundCorrectIndex, foundIncorrectIndex)
 	}
 
-	return sanitized
+	// Schema is correct if the target composite index exists and the incorrect single-column one doesn't
+	return foundCorrectIndex && !foundIncorrectIndex, nil
 }
 
-// validateAndFixSchema checks and fixes the database schema if needed
-func validateAndFixSchema(db *gorm.DB, dbType, connectionInfo string, debug bool, lgr *slog.Logger) error {
-	migrator := db.Migrator()
-	var schemaCorrect bool
-	var err error
-
-	// Note: Database type comparison is case-insensitive. Different database drivers
-	// may return varying case formats (e.g., "sqlite" vs "SQLite", "mysql" vs "MySQL").
-	// Using strings.ToLower ensures consistent matching regardless of driver implementation.
-	switch strings.ToLower(dbType) {
-	case "sqlite":
-		schemaCorrect, err = hasCorrectImageCacheIndexSQLite(db, debug)
-		if err != nil {
-			enhancedErr := criticalError(err, "schema_validation", "schema_integrity_check_failed",
-				"db_type", dbType,
-				"table", "image_caches",
-				"action", "database_schema_validation")
-			
-			lgr.Error("Schema validation failed", "error", enhancedErr)
-			return enhancedErr
-		}
-	case "mysql":
-		// Need to extract dbName from connectionInfo for MySQL check
-		dbName := extractDBNameFromMySQLInfo(connectionInfo)
-		if dbName == "" {
-			lgr.Warn("Could not determine database name from connection info for MySQL schema check. Assuming schema is correct.")
-			schemaCorrect = true // Avoid dropping if we can't check
-		} else {
-			schemaCorrect, err = hasCorrectImageCacheIndexMySQL(db, dbName, debug)
-			if err != nil {
-				enhancedErr := criticalError(err, "schema_validation", "schema_integrity_check_failed",
-					"db_type", dbType,
-					"table", "image_caches",
-					"database", dbName,
-					"action", "database_schema_validation")
-				
-				lgr.Error("Schema validation failed", "error", enhancedErr)
-				return enhancedErr
-			}
-		}
-	default:
-		lgr.Warn("Unsupported database type for image_caches schema check. Assuming schema is correct.",
-			"db_type", dbType)
-		schemaCorrect = true // Avoid dropping for unsupported types
-	}
+// performAutoMigration creates and updates the tables with AutoMigrate and then applies
+// the pending versioned schema migrations. An existing database is backed up with backup
+// before migrations change it, a nil backup skips the backup.
+func performAutoMigration(db *gorm.DB, dbType string, backup schemaBackupFunc) error {
+	migrationStart := time.Now()
+	migrationLogger := getLogger().With("db_type", dbType)
 	
-	lgr.Info("Schema validation completed",
-		"schema_correct", schemaCorrect)
+	migrationLogger.Info("Starting database migration")
 
-	if !schemaCorrect {
-		if migrator.HasTable(&ImageCache{}) {
-			if debug {
-				lgr.Debug("Incorrect schema detected for 'image_caches'. Dropping table")
-			}
-			if err := migrator.DropTable(&ImageCache{}); err != nil {
-				return fmt.Errorf("failed to drop existing 'image_caches' table with incorrect schema: %w", err)
-			}
-		} else if debug {
-			lgr.Debug("'image_caches' table does not exist. AutoMigrate will create it")
-		}
-	} else {
-		if debug {
-			lgr.Debug("Schema for 'image_caches' appears correct. Skipping drop")
-		}
+	// A new database has nothing worth backing up
+	existingDatabase := db.Migrator().HasTable(&Note{})
+
+	// Perform table migrations
+	successCount, err := migrateTables(db, dbType, migrationLogger)
+	if err != nil {
+		return err
+	}
+	
+	// Apply versioned schema migrations such as index rewrites and data backfills
+	applied, err := newSchemaMigrator(db, backup).Up(context.Background(), SchemaMigrationOptions{SkipBackup: !existingDatabase})
+	if err != nil {
+		migrationLogger.Error("Schema migration failed", "error", err)
+		return err
 	}
 	
+	// Log successful migration completion
+	migrationLogger.Info("Database migration completed successfully",
+		"db_type", dbType,
+		"total_duration", time.Since(migrationStart),
+		"tables_migrated", successCount,
+		"schema_migrations_applied", len(applied))
+
 	return nil
 }
 
@@ -461,6 +379,9 @@ func migrateTables(db *gorm.DB, dbType string, lgr *slog.Logger) (int, error) {
 		{&HourlyWeather{}, "hourly_weather"},
 		{&NoteLock{}, "note_locks"},
 		{&ImageCache{}, "image_caches"},
+		{&SoundLevel{}, "sound_levels"},
+		{&User{}, "users"},
+		{&Embedding{}, "embeddings"},
 	}
 	
 	lgr.Info("Starting table migrations",
@@ -565,62 +486,6 @@ func findNewColumns(db *gorm.DB, model interface{}, columnsBefore []string) []st
 	return addedColumns
 }
 
-// createOptimizedIndexes creates optimized database indexes for performance
-func createOptimizedIndexes(db *gorm.DB, dbType string, lgr *slog.Logger) error {
-	indexStart := time.Now()
-	lgr.Info("Creating optimized indexes",
-		"db_type", dbType)
-	
-	// Define the optimized index for new species tracking
-	// This index benefits the new species tracking queries that filter by scientific_name and date
-	indexName := "idx_notes_sciname_date_optimized"
-	tableName := "notes"
-	
-	// Check if index already exists using GORM's migrator
-	if db.Migrator().HasIndex(&Note{}, indexName) {
-		lgr.Debug("Optimized index already exists, skipping creation",
-			"index", indexName,
-			"table", tableName)
-		return nil
-	}
-	
-	// Create the optimized composite index using GORM's built-in index management
-	// Column order (scientific_name, date) is critical for query performance
-	if err := db.Migrator().CreateIndex(&Note{}, indexName); err != nil {
-		// Handle duplicate index errors gracefully
-		errMsg := strings.ToLower(err.Error())
-		isDuplicateIndex := strings.Contains(errMsg, "duplicate key name") || 
-							strings.Contains(errMsg, "already exists") || 
-							strings.Contains(errMsg, "duplicate") ||
-							strings.Contains(errMsg, "index") && strings.Contains(errMsg, "exist")
-		
-		if isDuplicateIndex {
-			lgr.Debug("Index already exists, continuing",
-				"index", indexName,
-				"table", tableName,
-				"db_type", dbType)
-			return nil
-		}
-		
-		return errors.New(err).
-			Component("datastore").
-			Category(errors.CategoryDatabase).
-			Context("operation", "create_optimized_index").
-			Context("db_type", dbType).
-			Context("index_name", indexName).
-			Context("table_name", tableName).
-			Build()
-	}
-	
-	lgr.Info("Optimized index created successfully",
-		"index", indexName,
-		"table", tableName,
-		"duration", time.Since(indexStart),
-		"db_type", dbType)
-	
-	return nil
-}
-
 // logTableMigration logs the result of a table migration
 func logTableMigration(lgr *slog.Logger, tableName, action string, addedColumns []string, duration time.Duration) {
 	logFields := []any{
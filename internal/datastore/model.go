@@ -15,15 +15,16 @@ type AudioSource struct {
 type Note struct {
 	ID         uint `gorm:"primaryKey"`
 	SourceNode string
-	Date       string `gorm:"index:idx_notes_date;index:idx_notes_date_commonname_confidence;index:idx_notes_sciname_date;index:idx_notes_sciname_date_optimized,priority:2"`
+	SourceID   string `gorm:"index"` // Persisted source ID, e.g. "rtsp_87b89761" or "file_20240501_063000.WAV" for analyzed recordings
+	Date       string `gorm:"index:idx_notes_date;index:idx_notes_date_commonname_confidence;index:idx_notes_sciname_date"`
 	Time       string `gorm:"index:idx_notes_time"`
 	//InputFile      string
 	Source      AudioSource `gorm:"-"` // Runtime only, not stored in database
 	BeginTime   time.Time
 	EndTime     time.Time
 	SpeciesCode string
-	// ScientificName includes optimized index (scientific_name, date) for new species tracking performance
-	ScientificName string  `gorm:"index:idx_notes_sciname;index:idx_notes_sciname_date;index:idx_notes_sciname_date_optimized,priority:1"`
+	// The (scientific_name, date) index for new species tracking is created by schema migration 2
+	ScientificName string  `gorm:"index:idx_notes_sciname;index:idx_notes_sciname_date"`
 	CommonName     string  `gorm:"index:idx_notes_comname;index:idx_notes_date_commonname_confidence"`
 	Confidence     float64 `gorm:"index:idx_notes_date_commonname_confidence"`
 	Latitude       float64
@@ -49,6 +50,7 @@ type Results struct {
 	NoteID     uint `gorm:"index;not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;foreignKey:NoteID;references:ID"` // Foreign key to associate with Note
 	Species    string
 	Confidence float32
+	Model      string `gorm:"type:varchar(100)"` // ID of the model that produced the result, empty for results saved before models were tagged
 }
 
 // Copy creates a deep copy of the Results struct
@@ -58,6 +60,7 @@ func (r Results) Copy() Results {
 		NoteID:     r.NoteID,
 		Species:    r.Species,
 		Confidence: r.Confidence,
+		Model:      r.Model,
 	}
 }
 
@@ -67,6 +70,7 @@ type NoteReview struct {
 	ID        uint      `gorm:"primaryKey"`
 	NoteID    uint      `gorm:"uniqueIndex;not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;foreignKey:NoteID;references:ID"` // Foreign key to associate with Note
 	Verified  string    `gorm:"type:varchar(20)"`                                                                                  // Values: "correct", "false_positive"
+	Author    string    `gorm:"type:varchar(100)"`                                                                                 // Username of the reviewer, empty when authentication is not used
 	CreatedAt time.Time `gorm:"index"`                                                                                             // When the review was created
 	UpdatedAt time.Time // When the review was last updated
 }
@@ -77,6 +81,7 @@ type NoteComment struct {
 	ID        uint      `gorm:"primaryKey"`
 	NoteID    uint      `gorm:"index;not null;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;foreignKey:NoteID;references:ID"` // Foreign key to associate with Note
 	Entry     string    `gorm:"type:text"`                                                                                   // The actual comment text
+	Author    string    `gorm:"type:varchar(100)"`                                                                           // Username of the commenter, empty when authentication is not used
 	CreatedAt time.Time `gorm:"index"`                                                                                       // When the comment was created
 	UpdatedAt time.Time // When the comment was last updated
 }
@@ -89,6 +94,27 @@ type NoteLock struct {
 	LockedAt time.Time `gorm:"index;not null"`                                                                                    // When the note was locked
 }
 
+// Embedding represents the embedding of the audio of a Note, used to find acoustically similar detections
+// GORM will automatically create table name as 'embeddings'
+type Embedding struct {
+	ID        uint      `gorm:"primaryKey"`
+	NoteID    uint      `gorm:"uniqueIndex;not null"`    // ID of the Note, deleted together with the note
+	Model     string    `gorm:"type:varchar(100);index"` // ID of the model that produced the embedding, only embeddings of the same model are compared
+	Vector    []byte    `gorm:"not null"`                // Embedding values as little-endian float32
+	CreatedAt time.Time // When the embedding was saved
+}
+
+// User represents a named account for the web UI and API
+// GORM will automatically create table name as 'users'
+type User struct {
+	ID           uint      `gorm:"primaryKey"`
+	Username     string    `gorm:"type:varchar(100);uniqueIndex;not null"` // Login name
+	PasswordHash string    `gorm:"type:varchar(100)"`                      // bcrypt hash of the account password
+	Role         string    `gorm:"type:varchar(20);not null"`              // Values: "viewer", "reviewer", "admin"
+	CreatedAt    time.Time // When the user was created
+	UpdatedAt    time.Time // When the user was last updated
+}
+
 // DailyEvents represents the daily weather data that doesn't change throughout the day
 type DailyEvents struct {
 	ID       uint   `gorm:"primaryKey"`
@@ -120,6 +146,28 @@ type HourlyWeather struct {
 	WeatherIcon   string
 }
 
+// SoundLevel represents octave band sound levels of an audio source aggregated over an interval
+// GORM will automatically create table name as 'sound_levels'
+type SoundLevel struct {
+	ID        uint            `gorm:"primaryKey"`
+	Source    string          `gorm:"type:varchar(255);not null;index:idx_soundlevels_source_timestamp,priority:1"` // Audio source ID
+	Name      string          // Display name of the audio source
+	Timestamp time.Time       `gorm:"not null;index:idx_soundlevels_source_timestamp,priority:2;index:idx_soundlevels_timestamp"` // Start of the interval (UTC)
+	Interval  int             // Length of the interval in seconds
+	Bands     SoundLevelBands `gorm:"serializer:json;type:text"` // Levels per octave band
+}
+
+// SoundLevelBand holds the levels of a single octave band over an interval
+type SoundLevelBand struct {
+	CenterFreq float64 `json:"center_frequency_hz"`
+	Leq        float64 `json:"leq_db"` // Equivalent continuous level
+	Min        float64 `json:"min_db"`
+	Max        float64 `json:"max_db"`
+}
+
+// SoundLevelBands maps octave band keys (e.g. "1.0_kHz") to their levels
+type SoundLevelBands map[string]SoundLevelBand
+
 // ImageCache represents cached image metadata for species
 type ImageCache struct {
 	ID             uint      `gorm:"primaryKey"`
@@ -148,6 +148,15 @@ func (ds *DataStore) getDatabaseSize() (int64, error) {
 		return size, nil
 	}
 	
+	// PostgreSQL-specific query
+	if strings.ToLower(ds.DB.Name()) == "postgres" {
+		err := ds.DB.Raw("SELECT pg_database_size(current_database())").Scan(&size).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to get PostgreSQL database size: %w", err)
+		}
+		return size, nil
+	}
+	
 	return 0, fmt.Errorf("unsupported database type: %s", ds.DB.Name())
 }
 
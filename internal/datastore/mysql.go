@@ -40,16 +40,21 @@ func validateMySQLConfig() error {
 	return nil
 }
 
+// mySQLDSN builds the connection string of the configured MySQL database
+func mySQLDSN(settings *conf.Settings) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		settings.Output.MySQL.Username, settings.Output.MySQL.Password,
+		settings.Output.MySQL.Host, settings.Output.MySQL.Port,
+		settings.Output.MySQL.Database)
+}
+
 // InitializeDatabase sets up the MySQL database connection
 func (store *MySQLStore) Open() error {
 	if err := validateMySQLConfig(); err != nil {
 		return err // validateMySQLConfig returns a properly formatted error
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		store.Settings.Output.MySQL.Username, store.Settings.Output.MySQL.Password,
-		store.Settings.Output.MySQL.Host, store.Settings.Output.MySQL.Port,
-		store.Settings.Output.MySQL.Database)
+	dsn := mySQLDSN(store.Settings)
 	
 	// Log database opening (with sanitized DSN)
 	sanitizedDSN := fmt.Sprintf("%s:***@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -85,7 +90,7 @@ func (store *MySQLStore) Open() error {
 		"port", store.Settings.Output.MySQL.Port,
 		"database", store.Settings.Output.MySQL.Database)
 	
-	if err := performAutoMigration(db, store.Settings.Debug, "MySQL", dsn); err != nil {
+	if err := performAutoMigration(db, "MySQL", nil); err != nil {
 		return err
 	}
 	
@@ -0,0 +1,231 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgreSQLStore implements DataStore for PostgreSQL
+type PostgreSQLStore struct {
+	DataStore
+	Settings *conf.Settings
+}
+
+// validatePostgreSQLConfig checks the settings needed to build a connection string
+func validatePostgreSQLConfig(settings *conf.Settings) error {
+	if settings.Output.PostgreSQL.Host == "" || settings.Output.PostgreSQL.Database == "" {
+		return errors.Newf("postgresql host and database must be configured").
+			Component("datastore").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "validate_postgresql_config").
+			Build()
+	}
+	return nil
+}
+
+// postgreSQLDSN builds the connection URL, user info is escaped so passwords may contain any character
+func postgreSQLDSN(settings *conf.Settings) *url.URL {
+	cfg := settings.Output.PostgreSQL
+
+	host := cfg.Host
+	if cfg.Port != "" {
+		host = net.JoinHostPort(cfg.Host, cfg.Port)
+	}
+
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	return &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     host,
+		Path:     "/" + cfg.Database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+}
+
+// Open sets up the PostgreSQL database connection
+func (store *PostgreSQLStore) Open() error {
+	if err := validatePostgreSQLConfig(store.Settings); err != nil {
+		return err
+	}
+
+	dsn := postgreSQLDSN(store.Settings)
+
+	// Log database opening (with sanitized DSN)
+	getLogger().Info("Opening PostgreSQL database connection",
+		"dsn", dsn.Redacted())
+
+	// Configure GORM logger with metrics if available
+	var gormLogger logger.Interface
+	if store.Settings.Debug {
+		// Use debug log level with lower slow threshold
+		gormLogger = NewGormLogger(100*time.Millisecond, logger.Info, store.metrics)
+		datastoreLevelVar.Set(slog.LevelDebug)
+	} else {
+		// Use default settings with metrics
+		gormLogger = NewGormLogger(200*time.Millisecond, logger.Warn, store.metrics)
+	}
+
+	// Open the PostgreSQL database
+	db, err := gorm.Open(postgres.Open(dsn.String()), &gorm.Config{Logger: gormLogger})
+	if err != nil {
+		return errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "open_postgresql").
+			Context("host", store.Settings.Output.PostgreSQL.Host).
+			Context("database", store.Settings.Output.PostgreSQL.Database).
+			Build()
+	}
+
+	store.DB = db
+
+	// Log successful connection
+	getLogger().Info("PostgreSQL database opened successfully",
+		"host", store.Settings.Output.PostgreSQL.Host,
+		"port", store.Settings.Output.PostgreSQL.Port,
+		"database", store.Settings.Output.PostgreSQL.Database)
+
+	if err := performAutoMigration(db, "PostgreSQL", nil); err != nil {
+		return err
+	}
+
+	// Start monitoring if metrics are available, same intervals as MySQL
+	if store.metrics != nil {
+		store.StartMonitoring(30*time.Second, 5*time.Minute)
+	}
+
+	return nil
+}
+
+// Close PostgreSQL database connections
+func (store *PostgreSQLStore) Close() error {
+	// Ensure that the store's DB field is not nil to avoid a panic
+	if store.DB == nil {
+		return errors.Newf("database connection is not initialized").
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "close").
+			Build()
+	}
+
+	// Stop monitoring before closing database
+	store.StopMonitoring()
+
+	getLogger().Info("Closing PostgreSQL database connection",
+		"host", store.Settings.Output.PostgreSQL.Host,
+		"database", store.Settings.Output.PostgreSQL.Database)
+
+	// Retrieve the generic database object from the GORM DB object
+	sqlDB, err := store.DB.DB()
+	if err != nil {
+		getLogger().Error("Failed to retrieve generic DB object",
+			"error", err)
+		return err
+	}
+
+	// Close the generic database object, which closes the underlying SQL database connection
+	if err := sqlDB.Close(); err != nil {
+		getLogger().Error("Failed to close PostgreSQL database",
+			"host", store.Settings.Output.PostgreSQL.Host,
+			"database", store.Settings.Output.PostgreSQL.Database,
+			"error", err)
+		return err
+	}
+
+	getLogger().Info("PostgreSQL database closed successfully",
+		"host", store.Settings.Output.PostgreSQL.Host,
+		"database", store.Settings.Output.PostgreSQL.Database)
+
+	return nil
+}
+
+// Optimize runs VACUUM and ANALYZE on the tables of the current schema. Plain VACUUM
+// reclaims space for reuse without locking the tables, unlike VACUUM FULL.
+func (store *PostgreSQLStore) Optimize(ctx context.Context) error {
+	if store.DB == nil {
+		return errors.Newf("database connection is not initialized").
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "optimize").
+			Build()
+	}
+
+	optimizeStart := time.Now()
+	optimizeLogger := getLogger().With("operation", "optimize", "db_type", "PostgreSQL")
+
+	optimizeLogger.Info("Starting database optimization")
+
+	db := store.DB.WithContext(ctx)
+
+	// Get list of tables in the current schema
+	var tables []string
+	if err := db.Raw("SELECT tablename FROM pg_tables WHERE schemaname = current_schema()").Pluck("tablename", &tables).Error; err != nil {
+		enhancedErr := errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "list_tables").
+			Build()
+		optimizeLogger.Error("Failed to get table list", "error", enhancedErr)
+		return enhancedErr
+	}
+
+	optimizeLogger.Info("Found tables to optimize", "table_count", len(tables))
+
+	optimizedCount := 0
+	for _, table := range tables {
+		// Validate table name to prevent SQL injection
+		if !isValidTableName(table) {
+			optimizeLogger.Error("Invalid table name detected, skipping",
+				"table", table,
+				"reason", "contains unsafe characters or exceeds length limit")
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tableStart := time.Now()
+		optimizeLogger.Debug("Optimizing table", "table", table)
+
+		// VACUUM cannot run inside a transaction, Exec runs it as a single statement
+		if err := db.Exec(fmt.Sprintf(`VACUUM (ANALYZE) %q`, table)).Error; err != nil {
+			optimizeLogger.Warn("Failed to vacuum table",
+				"table", table,
+				"error", err)
+			continue
+		}
+
+		optimizedCount++
+		optimizeLogger.Info("Table optimization completed",
+			"table", table,
+			"duration", time.Since(tableStart))
+	}
+
+	optimizeLogger.Info("Database optimization completed",
+		"total_duration", time.Since(optimizeStart),
+		"tables_processed", len(tables),
+		"tables_optimized", optimizedCount)
+
+	return nil
+}
+
+// UpdateNote updates specific fields of a note in PostgreSQL
+func (store *PostgreSQLStore) UpdateNote(id string, updates map[string]interface{}) error {
+	return store.DB.Model(&Note{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,386 @@
+// retention.go: detection record retention for the datastore
+package datastore
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRetentionBatchSize  = 1000
+	defaultRetentionBatchPause = 100 * time.Millisecond
+)
+
+// RetentionPolicy defines which detection records are pruned from the database.
+// Locked and reviewed detections are always kept.
+type RetentionPolicy struct {
+	MaxAge        time.Duration // Detections older than this are deleted, 0 disables
+	MaxRows       int64         // Maximum number of detections to keep, 0 disables
+	MinDetections int           // Newest detections kept per species regardless of age and row limit
+	BatchSize     int           // Number of detections deleted per transaction
+	BatchPause    time.Duration // Pause between batches so writers are not starved
+	Debug         bool          // Log every deleted batch
+}
+
+// RetentionResult describes the outcome of a retention run
+type RetentionResult struct {
+	DeletedByAge       int64         // Detections deleted for exceeding the maximum age
+	DeletedByCount     int64         // Detections deleted for exceeding the maximum row count
+	DeletedSoundLevels int64         // Sound level records deleted for exceeding the maximum age
+	Retained           int           // Detections exempt from deletion (locked, reviewed or per-species minimum)
+	Optimized          bool          // Whether the database was optimized after deleting
+	Duration           time.Duration // Duration of the run
+}
+
+// Deleted returns the total number of deleted detections
+func (r *RetentionResult) Deleted() int64 {
+	return r.DeletedByAge + r.DeletedByCount
+}
+
+// NewRetentionPolicy creates a retention policy from the retention settings
+func NewRetentionPolicy(settings *conf.DatabaseRetentionSettings) (*RetentionPolicy, error) {
+	policy := &RetentionPolicy{
+		MaxRows:       int64(settings.MaxRows),
+		MinDetections: settings.MinDetections,
+		BatchSize:     settings.BatchSize,
+		BatchPause:    defaultRetentionBatchPause,
+		Debug:         settings.Debug,
+	}
+
+	if settings.MaxAge != "" {
+		hours, err := conf.ParseRetentionPeriod(settings.MaxAge)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "parse_retention_policy").
+				Context("max_age", settings.MaxAge).
+				Build()
+		}
+		policy.MaxAge = time.Duration(hours) * time.Hour
+	}
+
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = defaultRetentionBatchSize
+	}
+
+	return policy, nil
+}
+
+// ApplyRetentionPolicy deletes detections exceeding the policy limits. Deletes run in
+// batches, each in its own transaction, so concurrent writes are only blocked briefly.
+// The database is optimized afterwards if anything was deleted.
+func ApplyRetentionPolicy(ctx context.Context, store Interface, policy *RetentionPolicy, now time.Time) (*RetentionResult, error) {
+	start := time.Now()
+	result := &RetentionResult{}
+	retentionLogger := getLogger().With("operation", "apply_retention")
+
+	if policy.MaxAge <= 0 && policy.MaxRows <= 0 {
+		return result, nil
+	}
+
+	batchSize := policy.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+
+	retained, err := loadRetainedNoteIDs(ctx, store, policy.MinDetections)
+	if err != nil {
+		return result, err
+	}
+	result.Retained = len(retained)
+	if policy.Debug {
+		log.Printf("Retention: %d detections are locked, reviewed or kept as the species minimum", result.Retained)
+	}
+
+	// Prune by age first, the row limit then applies to what is left
+	if policy.MaxAge > 0 {
+		cutoff := now.Add(-policy.MaxAge).Format("2006-01-02")
+		result.DeletedByAge, err = deleteNotesInBatches(ctx, store, policy, batchSize, retained, -1,
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("date < ?", cutoff)
+			})
+		if err != nil {
+			return result, err
+		}
+		retentionLogger.Info("Deleted detections exceeding maximum age",
+			"cutoff_date", cutoff,
+			"deleted", result.DeletedByAge)
+
+		// Sound level history follows the detections it is compared against
+		result.DeletedSoundLevels, err = deleteSoundLevelsBefore(ctx, store, policy, batchSize, now.Add(-policy.MaxAge))
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if policy.MaxRows > 0 {
+		var total int64
+		if err := store.Transaction(func(tx *gorm.DB) error {
+			return tx.WithContext(ctx).Model(&Note{}).Count(&total).Error
+		}); err != nil {
+			return result, dbError(err, "count_notes", errors.PriorityMedium,
+				"action", "apply_retention_row_limit")
+		}
+
+		if excess := total - policy.MaxRows; excess > 0 {
+			result.DeletedByCount, err = deleteNotesInBatches(ctx, store, policy, batchSize, retained, excess, nil)
+			if err != nil {
+				return result, err
+			}
+			retentionLogger.Info("Deleted detections exceeding maximum row count",
+				"total", total,
+				"max_rows", policy.MaxRows,
+				"deleted", result.DeletedByCount)
+		}
+	}
+
+	if result.Deleted() > 0 || result.DeletedSoundLevels > 0 {
+		if err := store.Optimize(ctx); err != nil {
+			result.Duration = time.Since(start)
+			return result, err
+		}
+		result.Optimized = true
+	}
+
+	result.Duration = time.Since(start)
+	retentionLogger.Info("Retention policy applied",
+		"deleted_by_age", result.DeletedByAge,
+		"deleted_by_count", result.DeletedByCount,
+		"deleted_sound_levels", result.DeletedSoundLevels,
+		"retained", result.Retained,
+		"optimized", result.Optimized,
+		"duration_ms", result.Duration.Milliseconds())
+
+	return result, nil
+}
+
+// loadRetainedNoteIDs returns the IDs of notes that must never be deleted: locked
+// and reviewed notes, and the newest minPerSpecies notes of every species.
+func loadRetainedNoteIDs(ctx context.Context, store Interface, minPerSpecies int) (map[uint]struct{}, error) {
+	retained := make(map[uint]struct{})
+
+	err := store.Transaction(func(tx *gorm.DB) error {
+		tx = tx.WithContext(ctx)
+
+		var ids []uint
+		if err := tx.Model(&NoteLock{}).Pluck("note_id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			retained[id] = struct{}{}
+		}
+
+		ids = nil
+		if err := tx.Model(&NoteReview{}).Pluck("note_id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			retained[id] = struct{}{}
+		}
+
+		if minPerSpecies <= 0 {
+			return nil
+		}
+
+		var species []string
+		if err := tx.Model(&Note{}).Distinct().Pluck("scientific_name", &species).Error; err != nil {
+			return err
+		}
+		for _, name := range species {
+			ids = nil
+			if err := tx.Model(&Note{}).
+				Where("scientific_name = ?", name).
+				Order("date DESC, time DESC, id DESC").
+				Limit(minPerSpecies).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				retained[id] = struct{}{}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, dbError(err, "load_retained_notes", errors.PriorityMedium,
+			"action", "apply_retention")
+	}
+
+	return retained, nil
+}
+
+// deleteNotesInBatches walks the notes matching filter in ID order and deletes those not
+// in retained. At most limit notes are deleted, a negative limit deletes all matches.
+func deleteNotesInBatches(ctx context.Context, store Interface, policy *RetentionPolicy, batchSize int,
+	retained map[uint]struct{}, limit int64, filter func(*gorm.DB) *gorm.DB) (int64, error) {
+	var deleted int64
+	var lastID uint
+
+	for limit < 0 || deleted < limit {
+		if err := ctx.Err(); err != nil {
+			return deleted, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryCancellation).
+				Context("operation", "apply_retention").
+				Context("deleted", deleted).
+				Build()
+		}
+
+		var ids []uint
+		err := store.Transaction(func(tx *gorm.DB) error {
+			query := tx.WithContext(ctx).Model(&Note{}).Where("id > ?", lastID)
+			if filter != nil {
+				query = filter(query)
+			}
+			return query.Order("id ASC").Limit(batchSize).Pluck("id", &ids).Error
+		})
+		if err != nil {
+			return deleted, dbError(err, "select_expired_notes", errors.PriorityMedium,
+				"action", "apply_retention")
+		}
+		if len(ids) == 0 {
+			break
+		}
+		lastID = ids[len(ids)-1]
+
+		candidates := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if _, ok := retained[id]; ok {
+				continue
+			}
+			if limit >= 0 && deleted+int64(len(candidates)) >= limit {
+				break
+			}
+			candidates = append(candidates, id)
+		}
+
+		if len(candidates) > 0 {
+			n, err := deleteNoteBatch(ctx, store, candidates)
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+			if policy.Debug {
+				log.Printf("Retention: deleted %d of %d selected detections up to ID %d", n, len(ids), lastID)
+			}
+		}
+
+		if len(ids) < batchSize {
+			break
+		}
+
+		if policy.BatchPause > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(policy.BatchPause):
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteNoteBatch deletes the given notes with their results, comments and embeddings in a single
+// transaction. Notes locked or reviewed since the retained set was loaded are skipped.
+func deleteNoteBatch(ctx context.Context, store Interface, ids []uint) (int64, error) {
+	var deleted int64
+
+	err := store.Transaction(func(tx *gorm.DB) error {
+		tx = tx.WithContext(ctx)
+
+		var deletable []uint
+		if err := tx.Model(&Note{}).
+			Where("id IN ?", ids).
+			Where("id NOT IN (?)", tx.Model(&NoteLock{}).Select("note_id")).
+			Where("id NOT IN (?)", tx.Model(&NoteReview{}).Select("note_id")).
+			Pluck("id", &deletable).Error; err != nil {
+			return err
+		}
+		if len(deletable) == 0 {
+			return nil
+		}
+
+		if err := tx.Where("note_id IN ?", deletable).Delete(&Results{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("note_id IN ?", deletable).Delete(&NoteComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("note_id IN ?", deletable).Delete(&Embedding{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id IN ?", deletable).Delete(&Note{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, dbError(err, "delete_expired_notes", errors.PriorityMedium,
+			"batch_size", len(ids),
+			"action", "apply_retention")
+	}
+
+	return deleted, nil
+}
+
+// deleteSoundLevelsBefore deletes sound level records older than cutoff in batches
+func deleteSoundLevelsBefore(ctx context.Context, store Interface, policy *RetentionPolicy, batchSize int, cutoff time.Time) (int64, error) {
+	var deleted int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryCancellation).
+				Context("operation", "apply_retention").
+				Context("deleted_sound_levels", deleted).
+				Build()
+		}
+
+		var n int64
+		err := store.Transaction(func(tx *gorm.DB) error {
+			tx = tx.WithContext(ctx)
+			var ids []uint
+			if err := tx.Model(&SoundLevel{}).
+				Where("timestamp < ?", cutoff.UTC()).
+				Order("id ASC").
+				Limit(batchSize).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			res := tx.Where("id IN ?", ids).Delete(&SoundLevel{})
+			n = res.RowsAffected
+			return res.Error
+		})
+		if err != nil {
+			return deleted, dbError(err, "delete_expired_sound_levels", errors.PriorityLow,
+				"table", "sound_levels",
+				"action", "apply_retention")
+		}
+		deleted += n
+		if policy.Debug && n > 0 {
+			log.Printf("Retention: deleted %d sound level records older than %s", n, cutoff.Format(time.RFC3339))
+		}
+		if n < int64(batchSize) {
+			return deleted, nil
+		}
+
+		if policy.BatchPause > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(policy.BatchPause):
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gorm.io/gorm"
+)
+
+var retentionTestNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// saveRetentionTestNote stores a note for the species detected the given number of days before retentionTestNow
+func saveRetentionTestNote(t *testing.T, store Interface, species string, daysAgo int) uint {
+	t.Helper()
+	ts := retentionTestNow.AddDate(0, 0, -daysAgo)
+	note := &Note{
+		Date:           ts.Format("2006-01-02"),
+		Time:           ts.Format("15:04:05"),
+		ScientificName: species,
+		CommonName:     species,
+		Confidence:     0.9,
+	}
+	require.NoError(t, store.Save(note, []Results{{Species: species, Confidence: 0.9}}))
+	require.NotZero(t, note.ID)
+	require.NoError(t, store.SaveEmbedding(NewEmbedding(note.ID, "birdnet", []float32{1, 0})))
+	return note.ID
+}
+
+func countRetentionTestRows(t *testing.T, store Interface, model any) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, store.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(model).Count(&count).Error
+	}))
+	return count
+}
+
+func TestApplyRetentionPolicy_MaxAge(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	// Species A has four old detections and one recent one
+	var oldA []uint
+	for i := 0; i < 4; i++ {
+		oldA = append(oldA, saveRetentionTestNote(t, store, "Species a", 400+i))
+	}
+	recentA := saveRetentionTestNote(t, store, "Species a", 1)
+	// Species B was only detected long ago
+	oldB := saveRetentionTestNote(t, store, "Species b", 500)
+
+	// Locked and reviewed notes are kept regardless of age
+	require.NoError(t, store.LockNote(strconv.FormatUint(uint64(oldA[2]), 10)))
+	require.NoError(t, store.SaveNoteReview(&NoteReview{NoteID: oldA[3], Verified: "correct"}))
+
+	policy := &RetentionPolicy{MaxAge: 365 * 24 * time.Hour, MinDetections: 1, BatchSize: 2, Debug: true}
+	result, err := ApplyRetentionPolicy(context.Background(), store, policy, retentionTestNow)
+	require.NoError(t, err)
+
+	// oldA[0] and oldA[1] are deleted; the per-species minimum keeps the only detection of species B
+	assert.Equal(t, int64(2), result.DeletedByAge)
+	assert.Zero(t, result.DeletedByCount)
+	assert.True(t, result.Optimized)
+
+	for _, id := range []uint{oldA[0], oldA[1]} {
+		_, err := store.Get(strconv.FormatUint(uint64(id), 10))
+		assert.Error(t, err, "note %d should be deleted", id)
+	}
+	for _, id := range []uint{oldA[2], oldA[3], recentA, oldB} {
+		_, err := store.Get(strconv.FormatUint(uint64(id), 10))
+		assert.NoError(t, err, "note %d should be kept", id)
+	}
+	assert.Equal(t, int64(4), countRetentionTestRows(t, store, &Results{}), "results of deleted notes must be removed")
+	assert.Equal(t, int64(4), countRetentionTestRows(t, store, &Embedding{}), "embeddings of deleted notes must be removed")
+}
+
+func TestApplyRetentionPolicy_MaxRows(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	var ids []uint
+	for i := 10; i > 0; i-- {
+		ids = append(ids, saveRetentionTestNote(t, store, "Species a", i))
+	}
+	require.NoError(t, store.LockNote(strconv.FormatUint(uint64(ids[0]), 10)))
+
+	policy := &RetentionPolicy{MaxRows: 6, MinDetections: 2, BatchSize: 3}
+	result, err := ApplyRetentionPolicy(context.Background(), store, policy, retentionTestNow)
+	require.NoError(t, err)
+
+	// The oldest unprotected notes are deleted until the row limit is met
+	assert.Equal(t, int64(4), result.DeletedByCount)
+	assert.Equal(t, int64(6), countRetentionTestRows(t, store, &Note{}))
+
+	_, err = store.Get(strconv.FormatUint(uint64(ids[0]), 10))
+	require.NoError(t, err, "locked note should be kept")
+	for _, id := range ids[1:5] {
+		_, err := store.Get(strconv.FormatUint(uint64(id), 10))
+		assert.Error(t, err, "note %d should be deleted", id)
+	}
+}
+
+func TestApplyRetentionPolicy_Disabled(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+	saveRetentionTestNote(t, store, "Species a", 1000)
+
+	result, err := ApplyRetentionPolicy(context.Background(), store, &RetentionPolicy{}, retentionTestNow)
+	require.NoError(t, err)
+	assert.Zero(t, result.Deleted())
+	assert.False(t, result.Optimized)
+	assert.Equal(t, int64(1), countRetentionTestRows(t, store, &Note{}))
+}
+
+func TestNewRetentionPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewRetentionPolicy(&conf.DatabaseRetentionSettings{MaxAge: "2w", MaxRows: 100, MinDetections: 5, Debug: true})
+	require.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, policy.MaxAge)
+	assert.Equal(t, int64(100), policy.MaxRows)
+	assert.Equal(t, 5, policy.MinDetections)
+	assert.Equal(t, defaultRetentionBatchSize, policy.BatchSize)
+	assert.True(t, policy.Debug)
+
+	_, err = NewRetentionPolicy(&conf.DatabaseRetentionSettings{MaxAge: "forever"})
+	assert.Error(t, err)
+}
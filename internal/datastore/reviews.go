@@ -0,0 +1,36 @@
+// reviews.go: review outcomes of detections for learning false positive rules
+package datastore
+
+import (
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// ReviewOutcome is a detection reviewed as correct or false positive
+type ReviewOutcome struct {
+	ScientificName string
+	CommonName     string
+	SourceID       string // empty for realtime detections saved before sources were stored
+	Date           string
+	Time           string
+	Confidence     float64
+	Verified       string // Values: "correct", "false_positive"
+}
+
+// GetReviewOutcomes returns the detections on or after the start date, in YYYY-MM-DD
+// format, that were reviewed as correct or false positive
+func (ds *DataStore) GetReviewOutcomes(startDate string) ([]ReviewOutcome, error) {
+	var outcomes []ReviewOutcome
+	err := ds.DB.Table("notes").
+		Select("notes.scientific_name, notes.common_name, notes.source_id, notes.date, notes.time, notes.confidence, note_reviews.verified").
+		Joins("JOIN note_reviews ON note_reviews.note_id = notes.id").
+		Where("note_reviews.verified IN ?", []string{"correct", "false_positive"}).
+		Where("notes.date >= ?", startDate).
+		Order("notes.id").
+		Scan(&outcomes).Error
+	if err != nil {
+		return nil, dbError(err, "get_review_outcomes", errors.PriorityMedium,
+			"table", "note_reviews",
+			"start_date", startDate)
+	}
+	return outcomes, nil
+}
@@ -0,0 +1,49 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestGetReviewOutcomes(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	saveReviewed := func(date, sourceID, verified string) {
+		t.Helper()
+		note := &Note{
+			ScientificName: "Corvus corax",
+			CommonName:     "Common Raven",
+			SourceID:       sourceID,
+			Date:           date,
+			Time:           "05:30:00",
+			Confidence:     0.75,
+		}
+		require.NoError(t, store.Save(note, nil))
+		if verified != "" {
+			require.NoError(t, store.SaveNoteReview(&NoteReview{NoteID: note.ID, Verified: verified}))
+		}
+	}
+
+	saveReviewed("2025-04-01", "rtsp_1", "false_positive")
+	saveReviewed("2025-05-01", "rtsp_1", "false_positive")
+	saveReviewed("2025-05-02", "", "correct")
+	saveReviewed("2025-05-03", "rtsp_1", "")
+
+	outcomes, err := store.GetReviewOutcomes("2025-05-01")
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2, "only reviewed detections since the start date are returned")
+	assert.Equal(t, ReviewOutcome{
+		ScientificName: "Corvus corax",
+		CommonName:     "Common Raven",
+		SourceID:       "rtsp_1",
+		Date:           "2025-05-01",
+		Time:           "05:30:00",
+		Confidence:     0.75,
+		Verified:       "false_positive",
+	}, outcomes[0])
+	assert.Equal(t, "correct", outcomes[1].Verified)
+	assert.Empty(t, outcomes[1].SourceID)
+}
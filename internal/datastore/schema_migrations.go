@@ -0,0 +1,419 @@
+// schema_migrations.go: versioned schema migrations applied on top of the AutoMigrate baseline
+package datastore
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SchemaVersion records a schema migration applied to the database
+type SchemaVersion struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:128;not null"`
+	AppliedAt time.Time
+}
+
+// TableName sets the table name of the applied schema migrations
+func (SchemaVersion) TableName() string { return "schema_version" }
+
+// SchemaMigration is a versioned change of the database schema or data. Up and Down run
+// in a transaction together with the schema_version update. MySQL commits DDL statements
+// implicitly, so migrations must be safe to run again after a partial failure.
+type SchemaMigration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // nil when the migration cannot be reverted
+}
+
+// SchemaMigrationStatus describes a known migration and whether it has been applied
+type SchemaMigrationStatus struct {
+	Version    int
+	Name       string
+	Applied    bool
+	AppliedAt  time.Time
+	Reversible bool
+}
+
+// SchemaMigrationOptions controls how migrations are applied
+type SchemaMigrationOptions struct {
+	DryRun     bool // Only return the migrations that would run
+	SkipBackup bool // Do not back up the database before changing it
+}
+
+// schemaBackupFunc backs up the database before migrations change it and returns the
+// location of the backup. version is the schema version at the time of the backup.
+type schemaBackupFunc func(ctx context.Context, version int) (string, error)
+
+// schemaMigrations lists the migrations in version order. Versions are never reused or
+// reordered once released.
+var schemaMigrations = []SchemaMigration{
+	{
+		Version: 1,
+		Name:    "image_caches_provider_species_index",
+		Up:      migrateImageCacheIndex,
+	},
+	{
+		Version: 2,
+		Name:    "notes_sciname_date_index",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex("notes", notesSciNameDateIndex) {
+				return nil
+			}
+			// Column order (scientific_name, date) serves the new species tracking queries
+			return tx.Exec("CREATE INDEX " + notesSciNameDateIndex + " ON notes (scientific_name, date)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasIndex("notes", notesSciNameDateIndex) {
+				return nil
+			}
+			return tx.Migrator().DropIndex("notes", notesSciNameDateIndex)
+		},
+	},
+}
+
+// notesSciNameDateIndex is the index created by schema migration 2
+const notesSciNameDateIndex = "idx_notes_sciname_date_optimized"
+
+// migrateImageCacheIndex recreates the image_caches table when it lacks the composite
+// unique index on provider and species, or still has the old unique index on species only.
+// The table only holds cached image metadata which is fetched again on demand.
+func migrateImageCacheIndex(tx *gorm.DB) error {
+	var correct bool
+	var err error
+	switch dialectName(tx) {
+	case "sqlite":
+		correct, err = hasCorrectImageCacheIndexSQLite(tx, false)
+	case "mysql":
+		var dbName string
+		if err := tx.Raw("SELECT DATABASE()").Scan(&dbName).Error; err != nil {
+			return err
+		}
+		correct, err = hasCorrectImageCacheIndexMySQL(tx, dbName, false)
+	case "postgres":
+		correct, err = hasCorrectImageCacheIndexPostgreSQL(tx, false)
+	default:
+		correct = true
+	}
+	if err != nil || correct {
+		return err
+	}
+
+	getLogger().Info("Recreating image_caches table with the provider and species index")
+	if err := tx.Migrator().DropTable(&ImageCache{}); err != nil {
+		return err
+	}
+	return tx.AutoMigrate(&ImageCache{})
+}
+
+// dialectName returns the lower case name of the database dialect
+func dialectName(db *gorm.DB) string {
+	return strings.ToLower(db.Dialector.Name())
+}
+
+// SchemaMigrator applies and reverts versioned schema migrations
+type SchemaMigrator struct {
+	db         *gorm.DB
+	migrations []SchemaMigration
+	backup     schemaBackupFunc // nil when the engine has no automatic backup
+	closeDB    bool             // The migrator opened the connection and closes it
+}
+
+// newSchemaMigrator creates a migrator for the known migrations on an open connection
+func newSchemaMigrator(db *gorm.DB, backup schemaBackupFunc) *SchemaMigrator {
+	return &SchemaMigrator{db: db, migrations: schemaMigrations, backup: backup}
+}
+
+// OpenSchemaMigrator connects to the configured database without running any migrations,
+// for inspecting and changing the schema version from the command line. SQLite databases
+// are backed up with the backup SQLite source before they are changed.
+func OpenSchemaMigrator(settings *conf.Settings) (*SchemaMigrator, error) {
+	var dialector gorm.Dialector
+	var backup schemaBackupFunc
+
+	switch {
+	case settings.Output.SQLite.Enabled:
+		dbPath := settings.Output.SQLite.Path
+		if _, err := os.Stat(dbPath); err != nil {
+			return nil, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryDatabase).
+				Context("operation", "open_schema_migrator").
+				Context("db_path", dbPath).
+				Build()
+		}
+		dialector = sqlite.Open(dbPath)
+		backup = sqlitePreMigrationBackup(settings)
+	case settings.Output.MySQL.Enabled:
+		dialector = mysql.Open(mySQLDSN(settings))
+	case settings.Output.PostgreSQL.Enabled:
+		if err := validatePostgreSQLConfig(settings); err != nil {
+			return nil, err
+		}
+		dialector = postgres.Open(postgreSQLDSN(settings).String())
+	default:
+		return nil, errors.Newf("no database is enabled in the output settings").
+			Component("datastore").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "open_schema_migrator").
+			Build()
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: createGormLogger()})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "open_schema_migrator").
+			Build()
+	}
+	if dialectName(db) == "sqlite" {
+		if err := db.Exec("PRAGMA foreign_keys=ON").Error; err != nil {
+			getLogger().Warn("Failed to enable foreign keys", "error", err)
+		}
+	}
+
+	m := newSchemaMigrator(db, backup)
+	m.closeDB = true
+	return m, nil
+}
+
+// Close closes a connection opened by OpenSchemaMigrator
+func (m *SchemaMigrator) Close() error {
+	if !m.closeDB {
+		return nil
+	}
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// LatestVersion returns the version of the newest known migration
+func (m *SchemaMigrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion returns the highest applied migration version, zero when none is applied
+func (m *SchemaMigrator) CurrentVersion(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Status returns every known migration with its applied state
+func (m *SchemaMigrator) Status(ctx context.Context) ([]SchemaMigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]SchemaMigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := SchemaMigrationStatus{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			Reversible: migration.Down != nil,
+		}
+		if v, ok := applied[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = v.AppliedAt
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// Up applies all pending migrations in version order and returns them. A failed migration
+// stops the run, migrations applied before it stay applied.
+func (m *SchemaMigrator) Up(ctx context.Context, opts SchemaMigrationOptions) ([]SchemaMigration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []SchemaMigration
+	current := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			current = max(current, migration.Version)
+			continue
+		}
+		pending = append(pending, migration)
+	}
+	for v := range applied {
+		if !slices.ContainsFunc(m.migrations, func(mg SchemaMigration) bool { return mg.Version == v }) {
+			getLogger().Warn("Database has a schema migration unknown to this version",
+				"version", v,
+				"name", applied[v].Name)
+		}
+	}
+	if len(pending) == 0 || opts.DryRun {
+		return pending, nil
+	}
+
+	if err := m.backupBeforeChange(ctx, current, opts); err != nil {
+		return nil, err
+	}
+
+	for i := range pending {
+		migration := &pending[i]
+		start := time.Now()
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return pending[:i], schemaMigrationError(err, "schema_migration_up", migration)
+		}
+		getLogger().Info("Applied schema migration",
+			"version", migration.Version,
+			"name", migration.Name,
+			"duration", time.Since(start))
+	}
+	return pending, nil
+}
+
+// Down reverts the applied migrations above the target version, newest first, and returns
+// them. Nothing is reverted when any of them cannot be reverted.
+func (m *SchemaMigrator) Down(ctx context.Context, target int, opts SchemaMigrationOptions) ([]SchemaMigration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var revert []SchemaMigration
+	current := 0
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		current = max(current, migration.Version)
+		if migration.Version <= target {
+			continue
+		}
+		if migration.Down == nil {
+			return nil, errors.Newf("schema migration %d %s cannot be reverted", migration.Version, migration.Name).
+				Component("datastore").
+				Category(errors.CategoryValidation).
+				Context("operation", "schema_migration_down").
+				Context("version", migration.Version).
+				Build()
+		}
+		revert = append(revert, migration)
+	}
+	if len(revert) == 0 || opts.DryRun {
+		return revert, nil
+	}
+
+	if err := m.backupBeforeChange(ctx, current, opts); err != nil {
+		return nil, err
+	}
+
+	for i := range revert {
+		migration := &revert[i]
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, migration.Version).Error
+		})
+		if err != nil {
+			return revert[:i], schemaMigrationError(err, "schema_migration_down", migration)
+		}
+		getLogger().Info("Reverted schema migration",
+			"version", migration.Version,
+			"name", migration.Name)
+	}
+	return revert, nil
+}
+
+// applied returns the applied migrations by version and creates the schema_version table
+// when it does not exist yet
+func (m *SchemaMigrator) applied(ctx context.Context) (map[int]SchemaVersion, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&SchemaVersion{}) {
+		if err := db.Migrator().CreateTable(&SchemaVersion{}); err != nil {
+			return nil, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryDatabase).
+				Context("operation", "create_schema_version_table").
+				Build()
+		}
+	}
+
+	var versions []SchemaVersion
+	if err := db.Order("version").Find(&versions).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "read_schema_version").
+			Build()
+	}
+
+	applied := make(map[int]SchemaVersion, len(versions))
+	for _, v := range versions {
+		applied[v.Version] = v
+	}
+	return applied, nil
+}
+
+// backupBeforeChange backs up the database unless disabled or unsupported by the engine
+func (m *SchemaMigrator) backupBeforeChange(ctx context.Context, version int, opts SchemaMigrationOptions) error {
+	if opts.SkipBackup {
+		return nil
+	}
+	if m.backup == nil {
+		getLogger().Warn("No automatic backup for this database engine, back up the database before schema migrations",
+			"db_type", dialectName(m.db))
+		return nil
+	}
+
+	path, err := m.backup(ctx, version)
+	if err != nil {
+		return errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "pre_migration_backup").
+			Context("schema_version", version).
+			Build()
+	}
+	getLogger().Info("Created pre-migration database backup",
+		"path", path,
+		"schema_version", version)
+	return nil
+}
+
+// schemaMigrationError wraps the error of a failed migration step
+func schemaMigrationError(err error, operation string, migration *SchemaMigration) error {
+	return errors.New(err).
+		Component("datastore").
+		Category(errors.CategoryDatabase).
+		Context("operation", operation).
+		Context("version", migration.Version).
+		Context("migration", migration.Name).
+		Build()
+}
@@ -0,0 +1,220 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// widget is a table created by the test migrations
+type widget struct {
+	ID    uint
+	Name  string
+	Color string
+}
+
+// testSchemaMigrations returns migrations that record the order they run in
+func testSchemaMigrations(calls *[]string) []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Version: 1, Name: "create_widgets",
+			Up: func(tx *gorm.DB) error {
+				*calls = append(*calls, "up 1")
+				return tx.Migrator().CreateTable(&widget{})
+			},
+			Down: func(tx *gorm.DB) error {
+				*calls = append(*calls, "down 1")
+				return tx.Migrator().DropTable(&widget{})
+			},
+		},
+		{
+			Version: 2, Name: "backfill_widget_color",
+			Up: func(tx *gorm.DB) error {
+				*calls = append(*calls, "up 2")
+				return tx.Model(&widget{}).Where("color = '' OR color IS NULL").Update("color", "green").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				*calls = append(*calls, "down 2")
+				return tx.Model(&widget{}).Where("color = ?", "green").Update("color", "").Error
+			},
+		},
+		{
+			Version: 3, Name: "widget_name_index",
+			Up: func(tx *gorm.DB) error {
+				*calls = append(*calls, "up 3")
+				return tx.Exec("CREATE INDEX idx_widgets_name ON widgets (name)").Error
+			},
+		},
+	}
+}
+
+// newTestMigrator opens an empty SQLite database for the given migrations
+func newTestMigrator(t *testing.T, migrations []SchemaMigration, backup schemaBackupFunc) *SchemaMigrator {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "schema.db")), &gorm.Config{Logger: createGormLogger()})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m := newSchemaMigrator(db, backup)
+	m.migrations = migrations
+	return m
+}
+
+func TestSchemaMigratorUpDown(t *testing.T) {
+	var calls []string
+	backups := 0
+	m := newTestMigrator(t, testSchemaMigrations(&calls), func(ctx context.Context, version int) (string, error) {
+		backups++
+		return "backup", nil
+	})
+	ctx := context.Background()
+
+	// Dry run plans without touching the database
+	planned, err := m.Up(ctx, SchemaMigrationOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, planned, 3)
+	assert.Empty(t, calls)
+	assert.Zero(t, backups)
+
+	applied, err := m.Up(ctx, SchemaMigrationOptions{})
+	require.NoError(t, err)
+	assert.Len(t, applied, 3)
+	assert.Equal(t, []string{"up 1", "up 2", "up 3"}, calls)
+	assert.Equal(t, 1, backups, "one backup per run")
+
+	version, err := m.CurrentVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, version)
+
+	// Nothing is pending on the second run
+	applied, err = m.Up(ctx, SchemaMigrationOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, 1, backups)
+
+	// Migration 3 has no down migration, nothing is reverted
+	calls = nil
+	_, err = m.Down(ctx, 1, SchemaMigrationOptions{})
+	require.Error(t, err)
+	assert.Empty(t, calls)
+
+	// Drop the irreversible migration to test reverting the others
+	m.migrations = m.migrations[:2]
+	require.NoError(t, m.db.Exec("DROP INDEX idx_widgets_name").Error)
+	require.NoError(t, m.db.Delete(&SchemaVersion{}, 3).Error)
+
+	reverted, err := m.Down(ctx, 0, SchemaMigrationOptions{})
+	require.NoError(t, err)
+	assert.Len(t, reverted, 2)
+	assert.Equal(t, []string{"down 2", "down 1"}, calls)
+	assert.False(t, m.db.Migrator().HasTable(&widget{}))
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		assert.False(t, s.Applied, "migration %d should be reverted", s.Version)
+		assert.True(t, s.Reversible)
+	}
+}
+
+func TestSchemaMigratorFailedMigration(t *testing.T) {
+	var calls []string
+	migrations := testSchemaMigrations(&calls)[:2]
+	migrations[1].Up = func(tx *gorm.DB) error {
+		if err := tx.Create(&widget{Name: "half done"}).Error; err != nil {
+			return err
+		}
+		return errors.New("backfill failed")
+	}
+	m := newTestMigrator(t, migrations, nil)
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx, SchemaMigrationOptions{})
+	require.Error(t, err)
+	assert.Len(t, applied, 1, "migrations before the failed one stay applied")
+
+	version, err := m.CurrentVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	var count int64
+	require.NoError(t, m.db.Model(&widget{}).Count(&count).Error)
+	assert.Zero(t, count, "changes of the failed migration are rolled back")
+}
+
+func TestSchemaMigrationsOnOpen(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	m := newSchemaMigrator(store.DB, nil)
+	ctx := context.Background()
+
+	version, err := m.CurrentVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, m.LatestVersion(), version)
+	assert.True(t, store.DB.Migrator().HasIndex("notes", notesSciNameDateIndex))
+
+	correct, err := hasCorrectImageCacheIndexSQLite(store.DB, false)
+	require.NoError(t, err)
+	assert.True(t, correct)
+}
+
+func TestImageCacheIndexMigration(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	db := store.DB
+	ctx := context.Background()
+
+	// Recreate the table with the old unique index on the species only
+	require.NoError(t, db.Migrator().DropTable(&ImageCache{}))
+	require.NoError(t, db.AutoMigrate(&ImageCache{}))
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_image_caches_scientific_name ON image_caches (scientific_name)").Error)
+	require.NoError(t, db.Delete(&SchemaVersion{}, 1).Error)
+
+	correct, err := hasCorrectImageCacheIndexSQLite(db, false)
+	require.NoError(t, err)
+	require.False(t, correct)
+
+	applied, err := newSchemaMigrator(db, nil).Up(ctx, SchemaMigrationOptions{})
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, 1, applied[0].Version)
+
+	correct, err = hasCorrectImageCacheIndexSQLite(db, false)
+	require.NoError(t, err)
+	assert.True(t, correct)
+}
+
+func TestSchemaMigrationBackup(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	m := newSchemaMigrator(store.DB, sqlitePreMigrationBackup(store.Settings))
+	ctx := context.Background()
+
+	// Dry run neither backs up nor reverts
+	reverted, err := m.Down(ctx, 1, SchemaMigrationOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	backups, err := filepath.Glob(store.Settings.Output.SQLite.Path + ".pre-migration_*")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	_, err = m.Down(ctx, 1, SchemaMigrationOptions{})
+	require.NoError(t, err)
+	assert.False(t, store.DB.Migrator().HasIndex("notes", notesSciNameDateIndex))
+
+	backups, err = filepath.Glob(store.Settings.Output.SQLite.Path + ".pre-migration_v2_*")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1, "the database should be backed up before reverting")
+
+	// Up restores the index
+	_, err = m.Up(ctx, SchemaMigrationOptions{SkipBackup: true})
+	require.NoError(t, err)
+	assert.True(t, store.DB.Migrator().HasIndex("notes", notesSciNameDateIndex))
+}
@@ -24,6 +24,8 @@ type AdvancedSearchFilters struct {
 	SortAscending  bool
 	Limit          int
 	Offset         int
+	AfterID        uint // Keyset pagination, only notes after this ID in the sort order
+	SkipCount      bool // Skip counting the total results, the returned total is 0
 }
 
 // ConfidenceFilter represents a confidence level filter
@@ -67,7 +69,8 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 
 	// Apply text search if provided
 	if filters.TextQuery != "" {
-		query = query.Where("common_name LIKE ? OR scientific_name LIKE ?",
+		like := likeOperator(ds.DB)
+		query = query.Where("common_name "+like+" ? OR scientific_name "+like+" ?",
 			"%"+filters.TextQuery+"%", "%"+filters.TextQuery+"%")
 	}
 
@@ -101,14 +104,16 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 
 	// Count total results before pagination
 	var totalCount int64
-	countQuery := query.Session(&gorm.Session{})
-	if err := countQuery.Count(&totalCount).Error; err != nil {
-		return nil, 0, errors.Newf("failed to count advanced search results: %w", err).
-			Context("operation", "count_advanced_search_results").
-			Context("filters", fmt.Sprintf("%+v", filters)).
-			Component("datastore").
-			Category(errors.CategoryDatabase).
-			Build()
+	if !filters.SkipCount {
+		countQuery := query.Session(&gorm.Session{})
+		if err := countQuery.Count(&totalCount).Error; err != nil {
+			return nil, 0, errors.Newf("failed to count advanced search results: %w", err).
+				Context("operation", "count_advanced_search_results").
+				Context("filters", fmt.Sprintf("%+v", filters)).
+				Component("datastore").
+				Category(errors.CategoryDatabase).
+				Build()
+		}
 	}
 
 	// Apply sorting
@@ -118,6 +123,16 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 	}
 	query = query.Order("id " + order)
 
+	// Continue after the last note of the previous page, unlike an offset this neither
+	// rescans earlier pages nor skips notes when notes are deleted meanwhile
+	if filters.AfterID > 0 {
+		if filters.SortAscending {
+			query = query.Where("id > ?", filters.AfterID)
+		} else {
+			query = query.Where("id < ?", filters.AfterID)
+		}
+	}
+
 	// Apply pagination
 	if filters.Limit > 0 {
 		query = query.Limit(filters.Limit)
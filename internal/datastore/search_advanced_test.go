@@ -0,0 +1,44 @@
+package datastore
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestSearchNotesAdvancedKeyset(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	var ids []uint
+	for range 5 {
+		note := &Note{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Date: "2025-05-01", Time: "06:00:00"}
+		require.NoError(t, store.Save(note, nil))
+		ids = append(ids, note.ID)
+	}
+
+	filters := &AdvancedSearchFilters{SortAscending: true, Limit: 2}
+	notes, total, err := store.SearchNotesAdvanced(filters)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, notes, 2)
+
+	// A note of the next page deleted meanwhile does not shift the pages
+	require.NoError(t, store.Delete(strconv.FormatUint(uint64(ids[2]), 10)))
+	filters.AfterID = notes[1].ID
+	filters.SkipCount = true
+	notes, total, err = store.SearchNotesAdvanced(filters)
+	require.NoError(t, err)
+	assert.Zero(t, total, "the total is not counted")
+	require.Len(t, notes, 2)
+	assert.Equal(t, []uint{ids[3], ids[4]}, []uint{notes[0].ID, notes[1].ID})
+
+	filters.SortAscending = false
+	filters.AfterID = ids[4]
+	notes, _, err = store.SearchNotesAdvanced(filters)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, ids[3], notes[0].ID, "descending pages continue with smaller IDs")
+}
@@ -0,0 +1,254 @@
+// soundlevels.go: storage and queries for sound level measurements
+package datastore
+
+import (
+	"math"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// maxSoundLevelRows limits the number of measurements returned by a single query
+const maxSoundLevelRows = 200000
+
+// ErrTooManySoundLevels is returned when a query would return more than maxSoundLevelRows
+// measurements, the range must be shortened or the resolution made coarser
+var ErrTooManySoundLevels = errors.NewStd("too many sound level measurements in range")
+
+// SoundLevelQuery defines the measurements returned by GetSoundLevels
+type SoundLevelQuery struct {
+	Source     string        // Audio source ID, empty for all sources
+	Start      time.Time     // Inclusive start of the time range
+	End        time.Time     // Exclusive end of the time range
+	Resolution time.Duration // Downsampling bucket size, 0 returns measurements as stored
+}
+
+// SoundLevelSource summarizes the stored measurements of an audio source
+type SoundLevelSource struct {
+	Source string    `json:"source"`
+	Name   string    `json:"name"`
+	First  time.Time `json:"first"`
+	Last   time.Time `json:"last"`
+	Count  int64     `json:"count"`
+}
+
+// SaveSoundLevels stores sound level measurements in a single transaction
+func (ds *DataStore) SaveSoundLevels(levels []SoundLevel) error {
+	if len(levels) == 0 {
+		return nil
+	}
+
+	for i := range levels {
+		if levels[i].Source == "" {
+			return validationError("sound level source cannot be empty", "index", i)
+		}
+		levels[i].Timestamp = levels[i].Timestamp.UTC()
+	}
+
+	if err := ds.DB.Create(&levels).Error; err != nil {
+		return dbError(err, "save_sound_levels", errors.PriorityLow,
+			"count", len(levels),
+			"table", "sound_levels",
+			"action", "store_sound_level_history")
+	}
+	return nil
+}
+
+// GetSoundLevels returns the measurements within the query range ordered by source and time.
+// With a resolution set, measurements are merged into buckets of that size.
+func (ds *DataStore) GetSoundLevels(query *SoundLevelQuery) ([]SoundLevel, error) {
+	if query == nil || query.Start.IsZero() || query.End.IsZero() {
+		return nil, validationError("start and end time are required", "time range", query)
+	}
+	if !query.End.After(query.Start) {
+		return nil, validationError("must be after the start time", "end", query.End)
+	}
+
+	db := ds.DB.Model(&SoundLevel{}).
+		Where("timestamp >= ? AND timestamp < ?", query.Start.UTC(), query.End.UTC())
+	if query.Source != "" {
+		db = db.Where("source = ?", query.Source)
+	}
+
+	rows, err := db.Order("source ASC, timestamp ASC").Rows()
+	if err != nil {
+		return nil, dbError(err, "get_sound_levels", errors.PriorityLow,
+			"source", query.Source,
+			"table", "sound_levels",
+			"action", "query_sound_level_history")
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			getLogger().Error("Failed to close rows",
+				"error", err,
+				"operation", "get_sound_levels")
+		}
+	}()
+
+	// Measurements are downsampled while reading, long ranges never load all rows at once
+	downsampler := soundLevelDownsampler{resolution: query.Resolution, limit: maxSoundLevelRows}
+	for rows.Next() {
+		var level SoundLevel
+		if err := ds.DB.ScanRows(rows, &level); err != nil {
+			return nil, dbError(err, "get_sound_levels", errors.PriorityLow,
+				"source", query.Source,
+				"table", "sound_levels",
+				"action", "query_sound_level_history")
+		}
+		if err := downsampler.add(&level); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "get_sound_levels", errors.PriorityLow,
+			"source", query.Source,
+			"table", "sound_levels",
+			"action", "query_sound_level_history")
+	}
+
+	return downsampler.finish(), nil
+}
+
+// GetSoundLevelSources returns the audio sources with stored measurements
+func (ds *DataStore) GetSoundLevelSources() ([]SoundLevelSource, error) {
+	var sources []SoundLevelSource
+	err := ds.DB.Model(&SoundLevel{}).
+		Select("source, MAX(name) AS name, COUNT(*) AS count").
+		Group("source").
+		Order("source ASC").
+		Scan(&sources).Error
+	if err != nil {
+		return nil, dbError(err, "get_sound_level_sources", errors.PriorityLow,
+			"table", "sound_levels",
+			"action", "list_sound_level_sources")
+	}
+
+	// Aggregated timestamps lose their column type in SQLite, so look them up through the index
+	for i := range sources {
+		for _, bound := range []struct {
+			order string
+			dest  *time.Time
+		}{
+			{"timestamp ASC", &sources[i].First},
+			{"timestamp DESC", &sources[i].Last},
+		} {
+			var level SoundLevel
+			if err := ds.DB.Select("timestamp").
+				Where("source = ?", sources[i].Source).
+				Order(bound.order).
+				Limit(1).
+				Find(&level).Error; err != nil {
+				return nil, dbError(err, "get_sound_level_sources", errors.PriorityLow,
+					"source", sources[i].Source,
+					"table", "sound_levels",
+					"action", "list_sound_level_sources")
+			}
+			*bound.dest = level.Timestamp
+		}
+	}
+
+	return sources, nil
+}
+
+// soundLevelDownsampler merges measurements of each source into buckets of the resolution.
+// Measurements must be added ordered by source and timestamp. Levels are averaged on an energy
+// basis, minimum and maximum are taken over the bucket. Without a resolution measurements are
+// kept as stored.
+type soundLevelDownsampler struct {
+	resolution time.Duration
+	limit      int // Maximum number of results, 0 for no limit
+	bucket     []SoundLevel
+	result     []SoundLevel
+}
+
+// add adds a measurement, completing the current bucket when the measurement falls outside it.
+// Returns ErrTooManySoundLevels when the results exceed the limit.
+func (d *soundLevelDownsampler) add(level *SoundLevel) error {
+	if d.resolution <= 0 {
+		d.result = append(d.result, *level)
+	} else {
+		if len(d.bucket) > 0 {
+			prev := &d.bucket[0]
+			if prev.Source != level.Source || !prev.Timestamp.Truncate(d.resolution).Equal(level.Timestamp.Truncate(d.resolution)) {
+				d.flush()
+			}
+		}
+		d.bucket = append(d.bucket, *level)
+	}
+	if d.limit > 0 && len(d.result) > d.limit {
+		return ErrTooManySoundLevels
+	}
+	return nil
+}
+
+// flush merges the measurements of the current bucket into one
+func (d *soundLevelDownsampler) flush() {
+	if len(d.bucket) == 0 {
+		return
+	}
+	merged := MergeSoundLevels(d.bucket)
+	merged.Timestamp = merged.Timestamp.Truncate(d.resolution)
+	merged.Interval = int(d.resolution / time.Second)
+	d.result = append(d.result, merged)
+	d.bucket = d.bucket[:0]
+}
+
+// finish completes the last bucket and returns the downsampled measurements
+func (d *soundLevelDownsampler) finish() []SoundLevel {
+	d.flush()
+	return d.result
+}
+
+// MergeSoundLevels combines consecutive measurements of a source into one. Band levels are
+// averaged on an energy basis weighted by interval length, minimum and maximum are taken over
+// all measurements. The result starts at the first measurement and spans all intervals.
+func MergeSoundLevels(levels []SoundLevel) SoundLevel {
+	if len(levels) == 0 {
+		return SoundLevel{}
+	}
+
+	merged := SoundLevel{
+		Source:    levels[0].Source,
+		Name:      levels[0].Name,
+		Timestamp: levels[0].Timestamp,
+		Bands:     make(SoundLevelBands),
+	}
+
+	type accumulator struct {
+		band   SoundLevelBand
+		energy float64
+		weight float64
+	}
+	acc := make(map[string]*accumulator)
+
+	for i := range levels {
+		weight := float64(levels[i].Interval)
+		if weight <= 0 {
+			weight = 1
+		}
+		merged.Interval += levels[i].Interval
+		if levels[i].Timestamp.Before(merged.Timestamp) {
+			merged.Timestamp = levels[i].Timestamp
+		}
+
+		for key, band := range levels[i].Bands {
+			a, ok := acc[key]
+			if !ok {
+				a = &accumulator{band: SoundLevelBand{CenterFreq: band.CenterFreq, Min: band.Min, Max: band.Max}}
+				acc[key] = a
+			}
+			a.energy += weight * math.Pow(10, band.Leq/10)
+			a.weight += weight
+			a.band.Min = math.Min(a.band.Min, band.Min)
+			a.band.Max = math.Max(a.band.Max, band.Max)
+		}
+	}
+
+	for key, a := range acc {
+		band := a.band
+		band.Leq = math.Round(10*math.Log10(a.energy/a.weight)*100) / 100
+		merged.Bands[key] = band
+	}
+
+	return merged
+}
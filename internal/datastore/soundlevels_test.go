@@ -0,0 +1,109 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func soundLevelTestRecord(source string, ts time.Time, leq float64) SoundLevel {
+	return SoundLevel{
+		Source:    source,
+		Name:      source + " name",
+		Timestamp: ts,
+		Interval:  60,
+		Bands: SoundLevelBands{
+			"1.0_kHz": {CenterFreq: 1000, Leq: leq, Min: leq - 5, Max: leq + 5},
+		},
+	}
+}
+
+func TestSoundLevelsRoundTrip(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var levels []SoundLevel
+	for i := 0; i < 4; i++ {
+		levels = append(levels, soundLevelTestRecord("mic", start.Add(time.Duration(i)*time.Minute), -40))
+	}
+	levels = append(levels, soundLevelTestRecord("rtsp_1", start, -60))
+	require.NoError(t, store.SaveSoundLevels(levels))
+
+	// Stored measurements
+	got, err := store.GetSoundLevels(&SoundLevelQuery{Source: "mic", Start: start, End: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, got, 4)
+	assert.True(t, got[0].Timestamp.Equal(start))
+	assert.Equal(t, "mic name", got[0].Name)
+	assert.InDelta(t, -40, got[0].Bands["1.0_kHz"].Leq, 0.001)
+
+	// The end of the range is exclusive
+	got, err = store.GetSoundLevels(&SoundLevelQuery{Source: "mic", Start: start, End: start.Add(2 * time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	// Downsampled to one bucket per source
+	got, err = store.GetSoundLevels(&SoundLevelQuery{Start: start, End: start.Add(time.Hour), Resolution: 5 * time.Minute})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "mic", got[0].Source)
+	assert.Equal(t, 300, got[0].Interval)
+	assert.Equal(t, "rtsp_1", got[1].Source)
+
+	sources, err := store.GetSoundLevelSources()
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	assert.Equal(t, "mic", sources[0].Source)
+	assert.Equal(t, int64(4), sources[0].Count)
+	assert.True(t, sources[0].First.Equal(start))
+	assert.True(t, sources[0].Last.Equal(start.Add(3*time.Minute)))
+
+	_, err = store.GetSoundLevels(&SoundLevelQuery{Start: start, End: start})
+	assert.Error(t, err)
+}
+
+func TestSoundLevelDownsamplerLimit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	add := func(d *soundLevelDownsampler, count int) error {
+		for i := range count {
+			level := soundLevelTestRecord("mic", start.Add(time.Duration(i)*time.Minute), -40)
+			if err := d.add(&level); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	raw := soundLevelDownsampler{limit: 2}
+	assert.ErrorIs(t, add(&raw, 3), ErrTooManySoundLevels, "results beyond the limit are rejected, not truncated")
+
+	// The limit applies to the downsampled results, not the measurements read
+	downsampled := soundLevelDownsampler{resolution: time.Hour, limit: 2}
+	require.NoError(t, add(&downsampled, 120))
+	assert.Len(t, downsampled.finish(), 2)
+}
+
+func TestMergeSoundLevels(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	quiet := soundLevelTestRecord("mic", start, -60)
+	loud := soundLevelTestRecord("mic", start.Add(time.Minute), -40)
+
+	merged := MergeSoundLevels([]SoundLevel{loud, quiet})
+	assert.True(t, merged.Timestamp.Equal(start))
+	assert.Equal(t, 120, merged.Interval)
+
+	band := merged.Bands["1.0_kHz"]
+	// Energy average of -60 and -40 dB is dominated by the louder interval
+	assert.InDelta(t, -42.97, band.Leq, 0.001)
+	assert.InDelta(t, -65, band.Min, 0.001)
+	assert.InDelta(t, -35, band.Max, 0.001)
+
+	assert.Equal(t, SoundLevel{}, MergeSoundLevels(nil))
+}
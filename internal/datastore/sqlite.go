@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/diskmanager"
 	"github.com/tphakala/birdnet-go/internal/errors"
@@ -74,13 +75,8 @@ func checkWritePermission(path string) error {
 	return nil
 }
 
-// createBackup creates a timestamped backup of the SQLite database file
-func (s *SQLiteStore) createBackup(dbPath string) error {
-	// Check if source database exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return nil // No need to backup if database doesn't exist yet
-	}
-
+// checkBackupSpace checks that a copy of the database fits next to it and the directory is writable
+func checkBackupSpace(dbPath string) error {
 	// Get database file size
 	dbInfo, err := os.Stat(dbPath)
 	if err != nil {
@@ -115,6 +111,20 @@ func (s *SQLiteStore) createBackup(dbPath string) error {
 		return err
 	}
 
+	return nil
+}
+
+// createBackup creates a timestamped backup of the SQLite database file
+func (s *SQLiteStore) createBackup(dbPath string) error {
+	// Check if source database exists
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil // No need to backup if database doesn't exist yet
+	}
+
+	if err := checkBackupSpace(dbPath); err != nil {
+		return err
+	}
+
 	// Create timestamp for backup file
 	timestamp := time.Now().Format("20060102_150405")
 	backupPath := fmt.Sprintf("%s.backup_%s", dbPath, timestamp)
@@ -166,6 +176,53 @@ func (s *SQLiteStore) createBackup(dbPath string) error {
 	return nil
 }
 
+// sqlitePreMigrationBackup returns a backup function that copies the SQLite database next to
+// the database file with the backup SQLite source before schema migrations change it
+func sqlitePreMigrationBackup(settings *conf.Settings) schemaBackupFunc {
+	return func(ctx context.Context, version int) (string, error) {
+		dbPath := settings.Output.SQLite.Path
+		if err := checkBackupSpace(dbPath); err != nil {
+			return "", err
+		}
+
+		backupPath := fmt.Sprintf("%s.pre-migration_v%d_%s", dbPath, version, time.Now().Format("20060102_150405"))
+		reader, err := sources.NewSQLiteSource(settings, getLogger()).Backup(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			if err := reader.Close(); err != nil {
+				log.Printf("Failed to close backup stream: %v", err)
+			}
+		}()
+
+		destination, err := os.OpenFile(backupPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return "", errors.New(err).
+				Component("datastore").
+				Category(errors.CategorySystem).
+				Context("operation", "create_backup_file").
+				Context("backup_path", backupPath).
+				Build()
+		}
+		_, err = io.Copy(destination, reader)
+		if closeErr := destination.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(backupPath)
+			return "", errors.New(err).
+				Component("datastore").
+				Category(errors.CategorySystem).
+				Context("operation", "write_backup_file").
+				Context("backup_path", backupPath).
+				Build()
+		}
+
+		return backupPath, nil
+	}
+}
+
 // Open initializes the SQLite database connection
 func (s *SQLiteStore) Open() error {
 	// Get database path from settings
@@ -271,7 +328,7 @@ func (s *SQLiteStore) Open() error {
 	}
 	
 	// Perform auto-migration
-	if err := performAutoMigration(db, s.Settings.Debug, "SQLite", dbPath); err != nil {
+	if err := performAutoMigration(db, "SQLite", sqlitePreMigrationBackup(s.Settings)); err != nil {
 		// Send migration error to telemetry with enhanced context
 		if s.telemetry != nil {
 			s.telemetry.CaptureEnhancedError(err, "auto_migration", s)
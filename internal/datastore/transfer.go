@@ -0,0 +1,526 @@
+// transfer.go: copies the data of one database engine into another
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Database engines accepted by NewForEngine
+const (
+	EngineSQLite     = "sqlite"
+	EngineMySQL      = "mysql"
+	EnginePostgreSQL = "postgresql"
+)
+
+// DefaultTransferBatchSize is the number of rows copied per transaction
+const DefaultTransferBatchSize = 500
+
+// NewForEngine creates a datastore for the given engine from that engine's output settings,
+// regardless of which engine is enabled in the configuration. The store is not opened.
+func NewForEngine(settings *conf.Settings, engine string) (Interface, error) {
+	engineSettings := *settings
+	engineSettings.Output.SQLite.Enabled = false
+	engineSettings.Output.MySQL.Enabled = false
+	engineSettings.Output.PostgreSQL.Enabled = false
+
+	switch strings.ToLower(engine) {
+	case EngineSQLite:
+		engineSettings.Output.SQLite.Enabled = true
+	case EngineMySQL:
+		engineSettings.Output.MySQL.Enabled = true
+	case EnginePostgreSQL:
+		engineSettings.Output.PostgreSQL.Enabled = true
+	default:
+		return nil, errors.Newf("unknown database engine %q, expected sqlite, mysql or postgresql", engine).
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "new_for_engine").
+			Build()
+	}
+
+	return New(&engineSettings), nil
+}
+
+// TransferProgress records how far a table has been copied. It is stored in the target
+// database in the same transaction as the copied rows, which makes transfers resumable.
+type TransferProgress struct {
+	Table        string `gorm:"primaryKey;size:64"`
+	LastSourceID uint   // Highest source ID copied or skipped
+	Copied       int64
+	Skipped      int64 // Rows whose parent row does not exist in the source
+	UpdatedAt    time.Time
+}
+
+// TableName sets the table name of the transfer progress records
+func (TransferProgress) TableName() string { return "transfer_progress" }
+
+// TransferIDMap maps the ID of a copied row in the source database to its ID in the target
+type TransferIDMap struct {
+	Table    string `gorm:"primaryKey;size:64"`
+	SourceID uint   `gorm:"primaryKey;autoIncrement:false"`
+	TargetID uint   `gorm:"not null"`
+}
+
+// TableName sets the table name of the transfer ID mappings
+func (TransferIDMap) TableName() string { return "transfer_id_maps" }
+
+// TransferOptions configures a database transfer
+type TransferOptions struct {
+	BatchSize int // Rows per transaction, DefaultTransferBatchSize when zero
+	// Progress is called after each committed batch with the running totals of the table
+	Progress func(table string, copied, skipped, total int64)
+}
+
+// TransferTableResult summarizes the transfer or verification of a single table
+type TransferTableResult struct {
+	Table          string
+	Copied         int64
+	Skipped        int64
+	SourceRows     int64  // Rows with an existing parent, counted during verification
+	TargetRows     int64  // Rows in the target table, counted during verification
+	SourceChecksum uint64 // Order independent checksum of the source rows
+	TargetChecksum uint64 // Order independent checksum of the target rows
+}
+
+// Verified reports whether the row counts and checksums of the table match
+func (r *TransferTableResult) Verified() bool {
+	return r.SourceRows == r.TargetRows && r.SourceChecksum == r.TargetChecksum
+}
+
+// DatabaseTransfer copies all detection data from one datastore into another. Rows get new
+// IDs in the target and foreign keys are remapped. The target must be empty when a transfer
+// starts, an interrupted transfer continues where it stopped when run again.
+type DatabaseTransfer struct {
+	src    *gorm.DB
+	dst    *gorm.DB
+	opts   TransferOptions
+	idMaps map[string]map[uint]uint // Source to target IDs of the parent tables
+}
+
+// transferTable copies and verifies one table
+type transferTable interface {
+	name() string
+	copyBatch(ctx context.Context, t *DatabaseTransfer, progress *TransferProgress) (int, error)
+	checksum(ctx context.Context, db *gorm.DB, batchSize int, idMaps map[string]map[uint]uint) (count int64, sum uint64, err error)
+}
+
+// transferTables lists the tables in copy order, parents before their children
+var transferTables = []transferTable{
+	&transferSpec[Note]{
+		table: "notes", mapIDs: true,
+		id: func(n *Note) *uint { return &n.ID },
+		fingerprint: func(n *Note) string {
+			return joinFingerprint(n.SourceNode, n.Date, n.Time, n.ScientificName, n.CommonName, n.SpeciesCode,
+				formatFloat(n.Confidence), formatFloat(n.Latitude), formatFloat(n.Longitude), n.ClipName, formatTime(n.BeginTime))
+		},
+	},
+	&transferSpec[Results]{
+		table: "results", parent: "notes",
+		id:  func(r *Results) *uint { return &r.ID },
+		ref: func(r *Results) *uint { return &r.NoteID },
+		fingerprint: func(r *Results) string {
+			return joinFingerprint(strconv.FormatUint(uint64(r.NoteID), 10), r.Species, strconv.FormatFloat(float64(r.Confidence), 'g', -1, 32), r.Model)
+		},
+	},
+	&transferSpec[NoteReview]{
+		table: "note_reviews", parent: "notes",
+		id:  func(r *NoteReview) *uint { return &r.ID },
+		ref: func(r *NoteReview) *uint { return &r.NoteID },
+		fingerprint: func(r *NoteReview) string {
+			return joinFingerprint(strconv.FormatUint(uint64(r.NoteID), 10), r.Verified, formatTime(r.CreatedAt))
+		},
+	},
+	&transferSpec[NoteComment]{
+		table: "note_comments", parent: "notes",
+		id:  func(c *NoteComment) *uint { return &c.ID },
+		ref: func(c *NoteComment) *uint { return &c.NoteID },
+		fingerprint: func(c *NoteComment) string {
+			return joinFingerprint(strconv.FormatUint(uint64(c.NoteID), 10), c.Entry, formatTime(c.CreatedAt))
+		},
+	},
+	&transferSpec[NoteLock]{
+		table: "note_locks", parent: "notes",
+		id:  func(l *NoteLock) *uint { return &l.ID },
+		ref: func(l *NoteLock) *uint { return &l.NoteID },
+		fingerprint: func(l *NoteLock) string {
+			return joinFingerprint(strconv.FormatUint(uint64(l.NoteID), 10), formatTime(l.LockedAt))
+		},
+	},
+	&transferSpec[Embedding]{
+		table: "embeddings", parent: "notes",
+		id:  func(e *Embedding) *uint { return &e.ID },
+		ref: func(e *Embedding) *uint { return &e.NoteID },
+		fingerprint: func(e *Embedding) string {
+			return joinFingerprint(strconv.FormatUint(uint64(e.NoteID), 10), e.Model, string(e.Vector))
+		},
+	},
+	&transferSpec[DailyEvents]{
+		table: "daily_events", mapIDs: true,
+		id: func(d *DailyEvents) *uint { return &d.ID },
+		fingerprint: func(d *DailyEvents) string {
+			return joinFingerprint(d.Date, strconv.FormatInt(d.Sunrise, 10), strconv.FormatInt(d.Sunset, 10), d.Country, d.CityName)
+		},
+	},
+	&transferSpec[HourlyWeather]{
+		table: "hourly_weathers", parent: "daily_events",
+		id:  func(h *HourlyWeather) *uint { return &h.ID },
+		ref: func(h *HourlyWeather) *uint { return &h.DailyEventsID },
+		fingerprint: func(h *HourlyWeather) string {
+			return joinFingerprint(strconv.FormatUint(uint64(h.DailyEventsID), 10), formatTime(h.Time),
+				formatFloat(h.Temperature), strconv.Itoa(h.Humidity), h.WeatherMain, h.WeatherIcon)
+		},
+	},
+	&transferSpec[ImageCache]{
+		table: "image_caches",
+		id:    func(c *ImageCache) *uint { return &c.ID },
+		fingerprint: func(c *ImageCache) string {
+			return joinFingerprint(c.ProviderName, c.ScientificName, c.SourceProvider, c.URL, c.LicenseName, c.AuthorName)
+		},
+	},
+	&transferSpec[SoundLevel]{
+		table: "sound_levels",
+		id:    func(s *SoundLevel) *uint { return &s.ID },
+		fingerprint: func(s *SoundLevel) string {
+			return joinFingerprint(s.Source, formatTime(s.Timestamp), strconv.Itoa(s.Interval), strconv.Itoa(len(s.Bands)))
+		},
+	},
+	&transferSpec[User]{
+		table: "users",
+		id:    func(u *User) *uint { return &u.ID },
+		fingerprint: func(u *User) string {
+			return u.Username
+		},
+	},
+}
+
+// NewDatabaseTransfer creates a transfer between two opened datastores
+func NewDatabaseTransfer(src, dst Interface, opts TransferOptions) (*DatabaseTransfer, error) {
+	srcDB, err := gormDB(src)
+	if err != nil {
+		return nil, err
+	}
+	dstDB, err := gormDB(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultTransferBatchSize
+	}
+
+	return &DatabaseTransfer{
+		src:    srcDB,
+		dst:    dstDB,
+		opts:   opts,
+		idMaps: make(map[string]map[uint]uint),
+	}, nil
+}
+
+// gormDB returns the database handle of an opened datastore
+func gormDB(store Interface) (*gorm.DB, error) {
+	var db *gorm.DB
+	switch s := store.(type) {
+	case *SQLiteStore:
+		db = s.DB
+	case *MySQLStore:
+		db = s.DB
+	case *PostgreSQLStore:
+		db = s.DB
+	}
+	if db == nil {
+		return nil, errors.Newf("datastore is not opened or does not support transfers").
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "database_transfer").
+			Context("store_type", fmt.Sprintf("%T", store)).
+			Build()
+	}
+	return db, nil
+}
+
+// Resumable reports whether the target holds the state of an interrupted transfer
+func (t *DatabaseTransfer) Resumable() bool {
+	return t.dst.Migrator().HasTable(&TransferProgress{})
+}
+
+// Run copies all tables. It returns the per table totals, including rows copied by
+// earlier interrupted runs.
+func (t *DatabaseTransfer) Run(ctx context.Context) ([]TransferTableResult, error) {
+	progress, err := t.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TransferTableResult, 0, len(transferTables))
+	for _, table := range transferTables {
+		p := progress[table.name()]
+
+		var total int64
+		if t.opts.Progress != nil {
+			if err := t.src.WithContext(ctx).Table(table.name()).Count(&total).Error; err != nil {
+				return nil, transferError(err, "count_source_rows", table.name())
+			}
+			t.opts.Progress(table.name(), p.Copied, p.Skipped, total)
+		}
+
+		for {
+			n, err := table.copyBatch(ctx, t, p)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				break
+			}
+			if t.opts.Progress != nil {
+				t.opts.Progress(table.name(), p.Copied, p.Skipped, total)
+			}
+		}
+
+		results = append(results, TransferTableResult{Table: table.name(), Copied: p.Copied, Skipped: p.Skipped})
+	}
+
+	return results, nil
+}
+
+// prepare creates the transfer state tables and loads the state of an interrupted transfer.
+// A new transfer is only started into a target without detection data.
+func (t *DatabaseTransfer) prepare(ctx context.Context) (map[string]*TransferProgress, error) {
+	db := t.dst.WithContext(ctx)
+
+	if !t.Resumable() {
+		for _, table := range transferTables {
+			var count int64
+			if err := db.Table(table.name()).Count(&count).Error; err != nil {
+				return nil, transferError(err, "check_target_empty", table.name())
+			}
+			if count > 0 {
+				return nil, errors.Newf("target table %s already contains %d rows, transfers require an empty target database", table.name(), count).
+					Component("datastore").
+					Category(errors.CategoryValidation).
+					Context("operation", "check_target_empty").
+					Context("table", table.name()).
+					Build()
+			}
+		}
+	}
+
+	if err := db.AutoMigrate(&TransferProgress{}, &TransferIDMap{}); err != nil {
+		return nil, transferError(err, "create_transfer_state", "transfer_progress")
+	}
+
+	progress := make(map[string]*TransferProgress, len(transferTables))
+	for _, table := range transferTables {
+		p := &TransferProgress{Table: table.name()}
+		if err := db.Where(TransferProgress{Table: table.name()}).FirstOrCreate(p).Error; err != nil {
+			return nil, transferError(err, "load_transfer_progress", table.name())
+		}
+		progress[table.name()] = p
+	}
+
+	if err := t.loadIDMaps(ctx); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// loadIDMaps reads the ID mappings of earlier runs into memory
+func (t *DatabaseTransfer) loadIDMaps(ctx context.Context) error {
+	var batch []TransferIDMap
+	err := t.dst.WithContext(ctx).Model(&TransferIDMap{}).FindInBatches(&batch, 10000, func(tx *gorm.DB, _ int) error {
+		for _, m := range batch {
+			t.mapID(m.Table, m.SourceID, m.TargetID)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return transferError(err, "load_transfer_id_maps", "transfer_id_maps")
+	}
+	return nil
+}
+
+// mapID records the target ID of a copied parent row
+func (t *DatabaseTransfer) mapID(table string, sourceID, targetID uint) {
+	m := t.idMaps[table]
+	if m == nil {
+		m = make(map[uint]uint)
+		t.idMaps[table] = m
+	}
+	m[sourceID] = targetID
+}
+
+// Verify compares the row counts and checksums of every table. Foreign keys of source
+// rows are mapped to target IDs before hashing, rows skipped as orphans are left out.
+func (t *DatabaseTransfer) Verify(ctx context.Context) ([]TransferTableResult, error) {
+	if len(t.idMaps) == 0 {
+		if err := t.loadIDMaps(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]TransferTableResult, 0, len(transferTables))
+	for _, table := range transferTables {
+		result := TransferTableResult{Table: table.name()}
+		var err error
+
+		result.SourceRows, result.SourceChecksum, err = table.checksum(ctx, t.src, t.opts.BatchSize, t.idMaps)
+		if err != nil {
+			return nil, transferError(err, "checksum_source", table.name())
+		}
+		result.TargetRows, result.TargetChecksum, err = table.checksum(ctx, t.dst, t.opts.BatchSize, nil)
+		if err != nil {
+			return nil, transferError(err, "checksum_target", table.name())
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Cleanup drops the transfer state tables after a verified transfer
+func (t *DatabaseTransfer) Cleanup() error {
+	if err := t.dst.Migrator().DropTable(&TransferIDMap{}, &TransferProgress{}); err != nil {
+		return transferError(err, "drop_transfer_state", "transfer_progress")
+	}
+	return nil
+}
+
+// transferSpec describes how the rows of one table are copied and compared
+type transferSpec[T any] struct {
+	table       string
+	parent      string // Table referenced by ref, empty when the rows have no foreign key
+	mapIDs      bool   // Record the ID mappings of the table, children reference it
+	id          func(*T) *uint
+	ref         func(*T) *uint // Foreign key remapped through the parent table
+	fingerprint func(*T) string
+}
+
+func (s *transferSpec[T]) name() string { return s.table }
+
+// copyBatch copies the next batch of rows after the last copied source ID. It returns
+// the number of source rows handled, zero when the table is done.
+func (s *transferSpec[T]) copyBatch(ctx context.Context, t *DatabaseTransfer, progress *TransferProgress) (int, error) {
+	var rows []T
+	if err := t.src.WithContext(ctx).Where("id > ?", progress.LastSourceID).Order("id").Limit(t.opts.BatchSize).Find(&rows).Error; err != nil {
+		return 0, transferError(err, "read_source_batch", s.table)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	lastID := *s.id(&rows[len(rows)-1])
+	sourceIDs := make([]uint, 0, len(rows))
+	batch := rows[:0]
+	var skipped int64
+	for i := range rows {
+		row := rows[i]
+		if s.ref != nil {
+			if ref := s.ref(&row); *ref != 0 {
+				targetID, ok := t.idMaps[s.parent][*ref]
+				if !ok {
+					// Orphaned row, the parent was deleted without cascading
+					skipped++
+					continue
+				}
+				*ref = targetID
+			}
+		}
+		sourceIDs = append(sourceIDs, *s.id(&row))
+		*s.id(&row) = 0
+		batch = append(batch, row)
+	}
+
+	updated := *progress
+	updated.LastSourceID = lastID
+	updated.Copied += int64(len(batch))
+	updated.Skipped += skipped
+
+	var idMaps []TransferIDMap
+	err := t.dst.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(batch) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&batch).Error; err != nil {
+				return err
+			}
+			if s.mapIDs {
+				idMaps = make([]TransferIDMap, len(batch))
+				for i := range batch {
+					idMaps[i] = TransferIDMap{Table: s.table, SourceID: sourceIDs[i], TargetID: *s.id(&batch[i])}
+				}
+				if err := tx.Create(&idMaps).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Save(&updated).Error
+	})
+	if err != nil {
+		return 0, transferError(err, "write_target_batch", s.table)
+	}
+
+	*progress = updated
+	for _, m := range idMaps {
+		t.mapID(m.Table, m.SourceID, m.TargetID)
+	}
+	return len(rows), nil
+}
+
+// checksum counts the rows of the table and sums the hashes of their fingerprints, which
+// makes the result independent of row order and IDs. With idMaps, foreign keys are mapped
+// to target IDs and rows that cannot be mapped are left out. Without idMaps foreign keys
+// are hashed as stored.
+func (s *transferSpec[T]) checksum(ctx context.Context, db *gorm.DB, batchSize int, idMaps map[string]map[uint]uint) (count int64, sum uint64, err error) {
+	var rows []T
+	err = db.WithContext(ctx).Model(new(T)).FindInBatches(&rows, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range rows {
+			row := &rows[i]
+			if s.ref != nil && idMaps != nil {
+				if ref := s.ref(row); *ref != 0 {
+					targetID, ok := idMaps[s.parent][*ref]
+					if !ok {
+						continue
+					}
+					*ref = targetID
+				}
+			}
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(s.fingerprint(row)))
+			sum += h.Sum64()
+			count++
+		}
+		return nil
+	}).Error
+	return count, sum, err
+}
+
+// joinFingerprint joins the compared values of a row
+func joinFingerprint(values ...string) string {
+	return strings.Join(values, "\x1f")
+}
+
+// formatFloat formats a float so that it round trips exactly
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// formatTime formats a time at millisecond precision, the precision MySQL keeps
+func formatTime(t time.Time) string {
+	return strconv.FormatInt(t.Round(time.Millisecond).UnixMilli(), 10)
+}
+
+// transferError wraps a database error of a transfer step
+func transferError(err error, operation, table string) error {
+	return errors.New(err).
+		Component("datastore").
+		Category(errors.CategoryDatabase).
+		Context("operation", operation).
+		Context("table", table).
+		Build()
+}
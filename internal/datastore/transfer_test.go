@@ -0,0 +1,139 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// seedTransferSource fills a store with related rows whose IDs differ from a fresh database
+func seedTransferSource(t *testing.T, db *SQLiteStore) {
+	t.Helper()
+	now := time.Date(2024, 5, 1, 6, 30, 0, 123456789, time.UTC)
+
+	for i := 1; i <= 5; i++ {
+		note := Note{
+			ID:             uint(i * 10),
+			Date:           "2024-05-01",
+			Time:           "06:30:00",
+			ScientificName: "Troglodytes troglodytes",
+			CommonName:     "Eurasian Wren",
+			Confidence:     0.5 + float64(i)/100,
+			BeginTime:      now,
+		}
+		require.NoError(t, db.DB.Create(&note).Error)
+		require.NoError(t, db.DB.Create(&Results{NoteID: note.ID, Species: "Troglodytes troglodytes_Eurasian Wren", Confidence: 0.61}).Error)
+	}
+	require.NoError(t, db.DB.Create(&NoteReview{NoteID: 20, Verified: "correct", CreatedAt: now}).Error)
+	require.NoError(t, db.DB.Create(&NoteComment{NoteID: 30, Entry: "Singing from the hedge", CreatedAt: now}).Error)
+	require.NoError(t, db.DB.Create(&NoteLock{NoteID: 40, LockedAt: now}).Error)
+
+	daily := DailyEvents{ID: 7, Date: "2024-05-01", Sunrise: 1714531200, Sunset: 1714588800, CityName: "Helsinki"}
+	require.NoError(t, db.DB.Create(&daily).Error)
+	require.NoError(t, db.DB.Create(&HourlyWeather{DailyEventsID: daily.ID, Time: now, Temperature: 12.5, WeatherMain: "Clouds"}).Error)
+	require.NoError(t, db.DB.Create(&ImageCache{ProviderName: "wikimedia", ScientificName: "Troglodytes troglodytes", URL: "https://example.com/wren.jpg", CachedAt: now}).Error)
+	require.NoError(t, db.DB.Create(&SoundLevel{Source: "rtsp_1", Timestamp: now, Interval: 10, Bands: SoundLevelBands{"1.0_kHz": {CenterFreq: 1000, Leq: 40}}}).Error)
+
+	// Orphaned result left behind by a delete without foreign key enforcement
+	require.NoError(t, db.DB.Exec("PRAGMA foreign_keys=OFF").Error)
+	require.NoError(t, db.DB.Create(&Results{NoteID: 999, Species: "Orphan", Confidence: 0.5}).Error)
+	require.NoError(t, db.DB.Exec("PRAGMA foreign_keys=ON").Error)
+}
+
+func TestDatabaseTransfer(t *testing.T) {
+	src := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	dst := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	seedTransferSource(t, src)
+
+	// Interrupt the transfer after the first batch
+	ctx, cancel := context.WithCancel(context.Background())
+	transfer, err := NewDatabaseTransfer(src, dst, TransferOptions{
+		BatchSize: 2,
+		Progress: func(table string, copied, skipped, total int64) {
+			if copied > 0 {
+				cancel()
+			}
+		},
+	})
+	require.NoError(t, err)
+	_, err = transfer.Run(ctx)
+	require.Error(t, err, "canceled transfer should fail")
+
+	var copiedNotes int64
+	require.NoError(t, dst.DB.Model(&Note{}).Count(&copiedNotes).Error)
+	assert.Equal(t, int64(2), copiedNotes, "the first batch should be committed")
+
+	// Resume with a new transfer as a new process would
+	transfer, err = NewDatabaseTransfer(src, dst, TransferOptions{BatchSize: 2})
+	require.NoError(t, err)
+	require.True(t, transfer.Resumable())
+	results, err := transfer.Run(context.Background())
+	require.NoError(t, err)
+
+	byTable := make(map[string]TransferTableResult)
+	for _, r := range results {
+		byTable[r.Table] = r
+	}
+	assert.Equal(t, int64(5), byTable["notes"].Copied)
+	assert.Equal(t, int64(5), byTable["results"].Copied)
+	assert.Equal(t, int64(1), byTable["results"].Skipped, "orphaned result should be skipped")
+	assert.Equal(t, int64(1), byTable["hourly_weathers"].Copied)
+
+	// Foreign keys point to the remapped parents
+	var review NoteReview
+	require.NoError(t, dst.DB.First(&review).Error)
+	var reviewed Note
+	require.NoError(t, dst.DB.First(&reviewed, review.NoteID).Error)
+	assert.InDelta(t, 0.52, reviewed.Confidence, 1e-9)
+	assert.NotEqual(t, uint(20), review.NoteID, "note IDs should be remapped")
+
+	var weather HourlyWeather
+	require.NoError(t, dst.DB.First(&weather).Error)
+	var daily DailyEvents
+	require.NoError(t, dst.DB.First(&daily, weather.DailyEventsID).Error)
+	assert.Equal(t, "Helsinki", daily.CityName)
+
+	verified, err := transfer.Verify(context.Background())
+	require.NoError(t, err)
+	for i := range verified {
+		assert.True(t, verified[i].Verified(), "table %s should verify: %+v", verified[i].Table, verified[i])
+	}
+
+	// A changed row is detected
+	require.NoError(t, dst.DB.Model(&NoteComment{}).Where("1 = 1").Update("entry", "edited").Error)
+	verified, err = transfer.Verify(context.Background())
+	require.NoError(t, err)
+	for i := range verified {
+		assert.Equal(t, verified[i].Table != "note_comments", verified[i].Verified(), verified[i].Table)
+	}
+
+	require.NoError(t, transfer.Cleanup())
+	assert.False(t, transfer.Resumable())
+
+	// A new transfer refuses a target that already holds data
+	transfer, err = NewDatabaseTransfer(src, dst, TransferOptions{})
+	require.NoError(t, err)
+	_, err = transfer.Run(context.Background())
+	require.Error(t, err)
+}
+
+func TestNewForEngine(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+
+	store, err := NewForEngine(settings, "postgresql")
+	require.NoError(t, err)
+	assert.IsType(t, &PostgreSQLStore{}, store)
+	assert.True(t, settings.Output.SQLite.Enabled, "settings of the caller must not change")
+
+	store, err = NewForEngine(settings, "MySQL")
+	require.NoError(t, err)
+	assert.IsType(t, &MySQLStore{}, store)
+
+	_, err = NewForEngine(settings, "oracle")
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+// users.go: storage of user accounts for the web UI and API
+package datastore
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// GetUser returns the user with the username, ErrUserNotFound if there is none.
+// Usernames are matched case-insensitively.
+func (ds *DataStore) GetUser(username string) (*User, error) {
+	var user User
+	err := ds.DB.Where("LOWER(username) = ?", strings.ToLower(strings.TrimSpace(username))).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, dbError(err, "get_user", errors.PriorityMedium, "table", "users")
+	}
+	return &user, nil
+}
+
+// GetUsers returns all users ordered by username
+func (ds *DataStore) GetUsers() ([]User, error) {
+	var users []User
+	if err := ds.DB.Order("username").Find(&users).Error; err != nil {
+		return nil, dbError(err, "get_users", errors.PriorityMedium, "table", "users")
+	}
+	return users, nil
+}
+
+// SaveUser creates the user or updates the password and role of the existing user with the username
+func (ds *DataStore) SaveUser(user *User) error {
+	user.Username = strings.TrimSpace(user.Username)
+	if user.Username == "" {
+		return validationError("username cannot be empty", "username", user.Username)
+	}
+	if user.Role == "" {
+		return validationError("role cannot be empty", "role", user.Role)
+	}
+
+	existing, err := ds.GetUser(user.Username)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		if err := ds.DB.Create(user).Error; err != nil {
+			return dbError(err, "create_user", errors.PriorityMedium, "table", "users")
+		}
+		return nil
+	case err != nil:
+		return err
+	}
+
+	user.ID = existing.ID
+	user.Username = existing.Username
+	user.CreatedAt = existing.CreatedAt
+	err = ds.DB.Model(existing).Select("password_hash", "role", "updated_at").Updates(user).Error
+	if err != nil {
+		return dbError(err, "update_user", errors.PriorityMedium, "table", "users")
+	}
+	return nil
+}
+
+// DeleteUser deletes the user with the username, ErrUserNotFound if there is none
+func (ds *DataStore) DeleteUser(username string) error {
+	result := ds.DB.Where("LOWER(username) = ?", strings.ToLower(strings.TrimSpace(username))).Delete(&User{})
+	if result.Error != nil {
+		return dbError(result.Error, "delete_user", errors.PriorityMedium, "table", "users")
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestUsersRoundTrip(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	require.NoError(t, store.SaveUser(&User{Username: " Alice ", PasswordHash: "hash", Role: "reviewer"}))
+	require.NoError(t, store.SaveUser(&User{Username: "bob@example.com", Role: "viewer"}))
+
+	user, err := store.GetUser("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Username, "usernames are trimmed and matched case-insensitively")
+	assert.Equal(t, "hash", user.PasswordHash)
+	assert.Equal(t, "reviewer", user.Role)
+
+	// Saving an existing username updates the account
+	require.NoError(t, store.SaveUser(&User{Username: "ALICE", PasswordHash: "new", Role: "admin"}))
+	users, err := store.GetUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "Alice", users[0].Username)
+	assert.Equal(t, "new", users[0].PasswordHash)
+	assert.Equal(t, "admin", users[0].Role)
+	assert.Equal(t, user.ID, users[0].ID)
+
+	require.NoError(t, store.DeleteUser("Bob@Example.com"))
+	_, err = store.GetUser("bob@example.com")
+	require.ErrorIs(t, err, ErrUserNotFound)
+	require.ErrorIs(t, store.DeleteUser("bob@example.com"), ErrUserNotFound)
+
+	require.Error(t, store.SaveUser(&User{Username: " ", Role: "viewer"}))
+	require.Error(t, store.SaveUser(&User{Username: "carol"}))
+}
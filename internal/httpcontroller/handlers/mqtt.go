@@ -103,8 +103,7 @@ func (h *Handlers) TestMQTT(c echo.Context) error {
 		return h.NewHandlerError(err, "Failed to create MQTT client", http.StatusInternalServerError)
 	}
 
-	// Set the control channel for the MQTT client
-	mqttClient.SetControlChannel(h.controlChan)
+	// The test client is not given the control channel, it must not act on remote commands
 
 	// Create context with timeout for the test
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
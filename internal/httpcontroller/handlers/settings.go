@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -757,6 +758,11 @@ func birdnetSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		return true
 	}
 
+	// Check for changes in BirdNET interpreter pool size
+	if oldSettings.BirdNET.Interpreters != currentSettings.BirdNET.Interpreters {
+		return true
+	}
+
 	// Check for changes in BirdNET model path
 	if oldSettings.BirdNET.ModelPath != currentSettings.BirdNET.ModelPath {
 		return true
@@ -823,7 +829,12 @@ func mqttSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		oldSettings.Realtime.MQTT.TLS.InsecureSkipVerify != currentSettings.Realtime.MQTT.TLS.InsecureSkipVerify ||
 		oldSettings.Realtime.MQTT.TLS.CACert != currentSettings.Realtime.MQTT.TLS.CACert ||
 		oldSettings.Realtime.MQTT.TLS.ClientCert != currentSettings.Realtime.MQTT.TLS.ClientCert ||
-		oldSettings.Realtime.MQTT.TLS.ClientKey != currentSettings.Realtime.MQTT.TLS.ClientKey
+		oldSettings.Realtime.MQTT.TLS.ClientKey != currentSettings.Realtime.MQTT.TLS.ClientKey ||
+		oldSettings.Realtime.MQTT.Commands.Enabled != currentSettings.Realtime.MQTT.Commands.Enabled ||
+		oldSettings.Realtime.MQTT.Commands.Topic != currentSettings.Realtime.MQTT.Commands.Topic ||
+		oldSettings.Realtime.MQTT.Commands.StatusTopic != currentSettings.Realtime.MQTT.Commands.StatusTopic ||
+		oldSettings.Realtime.MQTT.Commands.Token != currentSettings.Realtime.MQTT.Commands.Token ||
+		!slices.Equal(oldSettings.Realtime.MQTT.Commands.AllowedCommands, currentSettings.Realtime.MQTT.Commands.AllowedCommands)
 }
 
 // birdWeatherSettingsChanged checks if BirdWeather integration settings have changed
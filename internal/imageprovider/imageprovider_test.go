@@ -233,6 +233,25 @@ func (m *mockStore) GetSpeciesSummaryData(startDate, endDate string) ([]datastor
 func (m *mockStore) SearchDetections(filters *datastore.SearchFilters) ([]datastore.DetectionRecord, int, error) {
 	return nil, 0, nil
 }
+func (m *mockStore) SaveSoundLevels(levels []datastore.SoundLevel) error { return nil }
+func (m *mockStore) GetSoundLevels(query *datastore.SoundLevelQuery) ([]datastore.SoundLevel, error) {
+	return nil, nil
+}
+func (m *mockStore) GetSoundLevelSources() ([]datastore.SoundLevelSource, error) { return nil, nil }
+func (m *mockStore) GetUser(username string) (*datastore.User, error) {
+	return nil, datastore.ErrUserNotFound
+}
+func (m *mockStore) GetUsers() ([]datastore.User, error) { return nil, nil }
+func (m *mockStore) SaveUser(user *datastore.User) error { return nil }
+func (m *mockStore) DeleteUser(username string) error    { return nil }
+func (m *mockStore) SaveEmbedding(embedding *datastore.Embedding) error { return nil }
+func (m *mockStore) FindSimilarNotes(noteID string, limit int) ([]datastore.SimilarNote, error) {
+	return nil, datastore.ErrEmbeddingNotFound
+}
+func (m *mockStore) GetReviewedEmbeddings() ([]datastore.ReviewedEmbedding, error) { return nil, nil }
+func (m *mockStore) GetReviewOutcomes(startDate string) ([]datastore.ReviewOutcome, error) {
+	return nil, nil
+}
 
 // GetHourlyDistribution implements the datastore.Interface GetHourlyDistribution method
 func (m *mockStore) GetHourlyDistribution(startDate, endDate, species string) ([]datastore.HourlyDistributionData, error) {
@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// AnalyzerSource reads BirdNET-Analyzer CSV results and Raven selection tables.
+// Detection times are offsets into the recording, the recording start is parsed from
+// the audio or result file name and falls back to RecordingStart.
+type AnalyzerSource struct {
+	Paths          []string // Result files or directories searched recursively
+	RecordingStart time.Time
+}
+
+// analyzerColumns holds the column indexes of a result file, -1 when missing
+type analyzerColumns struct {
+	begin, end, offset       int
+	scientific, common, code int
+	confidence, file         int
+}
+
+// Name returns the result paths
+func (s *AnalyzerSource) Name() string {
+	return strings.Join(s.Paths, ", ")
+}
+
+// Records reads the detections of every result file
+func (s *AnalyzerSource) Records(ctx context.Context, emit func(*Record) error) error {
+	for _, root := range s.Paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if ext != ".csv" && ext != ".txt" {
+				return nil
+			}
+			return s.readFile(path, emit)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile reads one CSV or Raven result file, files in other formats are reported as skipped
+func (s *AnalyzerSource) readFile(path string, emit func(*Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	br := bufio.NewReader(f)
+	firstLine, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	r := csv.NewReader(br)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if strings.Contains(strings.SplitN(string(firstLine), "\n", 2)[0], "\t") {
+		r.Comma = '\t'
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return emit(&Record{Origin: path, SkipReason: "file has no header"})
+	}
+	cols := mapAnalyzerColumns(header)
+	if cols.begin < 0 || cols.confidence < 0 || (cols.scientific < 0 && cols.code < 0) {
+		return emit(&Record{Origin: path, SkipReason: "not a BirdNET-Analyzer CSV or Raven result file"})
+	}
+
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return emit(&Record{Origin: fmt.Sprintf("%s:%d", path, line), SkipReason: err.Error()})
+		}
+		if err := emit(s.parseRow(path, line, row, &cols)); err != nil {
+			return err
+		}
+	}
+}
+
+// mapAnalyzerColumns finds the columns of the CSV and Raven formats by header name
+func mapAnalyzerColumns(header []string) analyzerColumns {
+	cols := analyzerColumns{-1, -1, -1, -1, -1, -1, -1, -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "start (s)", "begin time (s)":
+			cols.begin = i
+		case "end (s)", "end time (s)":
+			cols.end = i
+		case "file offset (s)":
+			cols.offset = i
+		case "scientific name":
+			cols.scientific = i
+		case "common name":
+			cols.common = i
+		case "species code":
+			cols.code = i
+		case "confidence":
+			cols.confidence = i
+		case "file", "begin path", "begin file":
+			cols.file = i
+		}
+	}
+	return cols
+}
+
+// parseRow converts a result row into a record
+func (s *AnalyzerSource) parseRow(path string, line int, row []string, cols *analyzerColumns) *Record {
+	rec := &Record{Origin: fmt.Sprintf("%s:%d", path, line)}
+	field := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	scientific, common, code := field(cols.scientific), field(cols.common), field(cols.code)
+	switch {
+	case strings.EqualFold(common, "nocall") || strings.EqualFold(code, "nocall"):
+		rec.SkipReason = "no detection"
+		return rec
+	case scientific != "":
+		rec.Species = scientific + "_" + common
+	case code != "":
+		rec.SpeciesCode = code
+	default:
+		rec.SkipReason = "species is empty"
+		return rec
+	}
+
+	confidence, err := strconv.ParseFloat(field(cols.confidence), 64)
+	if err != nil {
+		rec.SkipReason = fmt.Sprintf("invalid confidence %q", field(cols.confidence))
+		return rec
+	}
+	rec.Confidence = confidence
+
+	// Combined Raven tables count begin times across files, the file offset is per recording
+	beginCol := cols.begin
+	if cols.offset >= 0 {
+		beginCol = cols.offset
+	}
+	begin, err := strconv.ParseFloat(field(beginCol), 64)
+	if err != nil {
+		rec.SkipReason = fmt.Sprintf("invalid begin time %q", field(beginCol))
+		return rec
+	}
+	duration := 3.0
+	if start, err1 := strconv.ParseFloat(field(cols.begin), 64); err1 == nil {
+		if end, err2 := strconv.ParseFloat(field(cols.end), 64); err2 == nil && end > start {
+			duration = end - start
+		}
+	}
+
+	recordingStart, ok := s.recordingStart(field(cols.file), path)
+	if !ok {
+		rec.SkipReason = "recording start time is unknown, name files YYYYMMDD_HHMMSS or set --recording-start"
+		return rec
+	}
+	rec.Begin = recordingStart.Add(time.Duration(begin * float64(time.Second)))
+	rec.End = rec.Begin.Add(time.Duration(duration * float64(time.Second)))
+	return rec
+}
+
+// recordingStart returns the start time of the analyzed recording from the audio file name,
+// the result file name or the configured start time
+func (s *AnalyzerSource) recordingStart(audioFile, resultFile string) (time.Time, bool) {
+	for _, name := range []string{audioFile, resultFile} {
+		if name == "" {
+			continue
+		}
+		// Audio paths may come from another operating system
+		base := name[strings.LastIndexAny(name, `/\`)+1:]
+		if t, ok := myaudio.ParseRecordingTimeFromName(base, nil); ok {
+			return t, true
+		}
+	}
+	return s.RecordingStart, !s.RecordingStart.IsZero()
+}
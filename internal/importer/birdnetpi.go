@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver for reading birds.db
+)
+
+// BirdNETPiSource reads the detections table of a BirdNET-Pi birds.db database
+type BirdNETPiSource struct {
+	DBPath string
+	// ClipsDir is the BirdNET-Pi extracted clips directory, usually ~/BirdSongs/Extracted.
+	// Clips are not attached when empty.
+	ClipsDir string
+}
+
+// Name returns the database path
+func (s *BirdNETPiSource) Name() string {
+	return s.DBPath
+}
+
+// Records reads the detections oldest first
+func (s *BirdNETPiSource) Records(ctx context.Context, emit func(*Record) error) error {
+	// Open read-only, BirdNET-Pi may still be running
+	db, err := sql.Open("sqlite3", "file:"+s.DBPath+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	// The driver converts DATE columns to timestamps, read them as stored
+	rows, err := db.QueryContext(ctx, `SELECT rowid, CAST(Date AS TEXT), CAST(Time AS TEXT), Sci_Name, Com_Name, Confidence,
+		Lat, Lon, Cutoff, Sens, File_Name FROM detections ORDER BY Date, Time`)
+	if err != nil {
+		return fmt.Errorf("failed to read BirdNET-Pi detections from %s: %w", s.DBPath, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			rowID                  int64
+			date, clock            sql.NullString
+			sciName, comName       sql.NullString
+			confidence             sql.NullFloat64
+			lat, lon, cutoff, sens sql.NullFloat64
+			fileName               sql.NullString
+		)
+		if err := rows.Scan(&rowID, &date, &clock, &sciName, &comName, &confidence,
+			&lat, &lon, &cutoff, &sens, &fileName); err != nil {
+			return err
+		}
+
+		rec := &Record{
+			Origin:      fmt.Sprintf("%s:%d", filepath.Base(s.DBPath), rowID),
+			Confidence:  confidence.Float64,
+			Latitude:    lat.Float64,
+			Longitude:   lon.Float64,
+			Threshold:   cutoff.Float64,
+			Sensitivity: sens.Float64,
+		}
+		if sciName.String != "" {
+			rec.Species = strings.TrimSpace(sciName.String) + "_" + strings.TrimSpace(comName.String)
+		} else {
+			rec.SkipReason = "scientific name is empty"
+		}
+		if !confidence.Valid {
+			rec.SkipReason = "confidence is empty"
+		}
+
+		begin, err := time.ParseInLocation(time.DateTime, date.String+" "+clock.String, time.Local)
+		if err != nil {
+			rec.SkipReason = fmt.Sprintf("invalid date and time %q %q", date.String, clock.String)
+		} else {
+			// BirdNET-Pi analyzes 3 second segments
+			rec.Begin = begin
+			rec.End = begin.Add(3 * time.Second)
+		}
+
+		if s.ClipsDir != "" && fileName.String != "" {
+			rec.ClipPath = s.clipPath(date.String, comName.String, fileName.String)
+		}
+
+		if err := emit(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// clipPath returns the location of a clip, BirdNET-Pi stores clips by date and common name
+// with apostrophes removed and spaces replaced by underscores
+func (s *BirdNETPiSource) clipPath(date, commonName, fileName string) string {
+	species := strings.ReplaceAll(strings.ReplaceAll(commonName, "'", ""), " ", "_")
+	clip := filepath.Join(s.ClipsDir, "By_Date", date, species, filepath.Base(fileName))
+	if abs, err := filepath.Abs(clip); err == nil {
+		return abs
+	}
+	return clip
+}
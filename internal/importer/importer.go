@@ -0,0 +1,397 @@
+// Package importer imports detections recorded by BirdNET-Pi and BirdNET-Analyzer into the datastore
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/observation"
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is the number of detections written per transaction
+const DefaultBatchSize = 500
+
+// Record is a detection read from an import source
+type Record struct {
+	Origin string // Source file and row, used in the report
+
+	Begin time.Time
+	End   time.Time
+
+	// Species is a "ScientificName_CommonName" label. Sources that only know the eBird
+	// code set SpeciesCode instead and the label is looked up from the taxonomy.
+	Species     string
+	SpeciesCode string
+	Confidence  float64
+
+	// Location and analysis settings, zero values fall back to the configuration
+	Latitude    float64
+	Longitude   float64
+	Threshold   float64
+	Sensitivity float64
+
+	ClipPath string // Absolute path of an existing audio clip, optional
+
+	// SkipReason is set by sources for rows they could read but not interpret
+	SkipReason string
+}
+
+// Source reads detections from an external format
+type Source interface {
+	// Name describes the source in messages
+	Name() string
+	// Records calls emit for every detection of the source in order
+	Records(ctx context.Context, emit func(*Record) error) error
+}
+
+// Options controls an import
+type Options struct {
+	DryRun    bool // Read and check the records without writing anything
+	CopyClips bool // Copy clips into the clip directory, otherwise only clips already inside it are attached
+	BatchSize int
+	Progress  func(report *Report)
+}
+
+// SkippedRecord is a record that was not imported
+type SkippedRecord struct {
+	Origin string
+	Reason string
+}
+
+// Report summarizes an import
+type Report struct {
+	Read          int
+	Imported      int
+	Duplicates    int
+	ClipsAttached int
+	ClipsMissing  int
+	Skipped       []SkippedRecord
+}
+
+// WriteSkipped writes the skipped records as CSV
+func (r *Report) WriteSkipped(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"origin", "reason"}); err != nil {
+		return err
+	}
+	for _, s := range r.Skipped {
+		if err := cw.Write([]string{s.Origin, s.Reason}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Importer maps records onto notes and writes them to the datastore
+type Importer struct {
+	settings *conf.Settings
+	store    datastore.Interface
+	bn       *birdnet.BirdNET
+	opts     Options
+
+	// existing holds the date, time and scientific name of stored and imported detections
+	existing    map[string]struct{}
+	loadedDates map[string]bool
+	pending     []datastore.Note
+	report      *Report
+}
+
+// New creates an importer writing to an opened datastore
+func New(settings *conf.Settings, store datastore.Interface, opts Options) (*Importer, error) {
+	if store == nil {
+		return nil, errors.Newf("datastore is required for import").
+			Component("importer").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	// Only the taxonomy is needed to resolve species codes, the model is not loaded
+	taxonomyMap, scientificIndex, err := birdnet.LoadTaxonomyData("")
+	if err != nil {
+		return nil, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryFileIO).
+			Context("operation", "load_taxonomy").
+			Build()
+	}
+
+	return &Importer{
+		settings: settings,
+		store:    store,
+		bn: &birdnet.BirdNET{
+			Settings:        settings,
+			TaxonomyMap:     taxonomyMap,
+			ScientificIndex: scientificIndex,
+		},
+		opts:        opts,
+		existing:    make(map[string]struct{}),
+		loadedDates: make(map[string]bool),
+	}, nil
+}
+
+// Import reads all records of a source and stores the new ones
+func (imp *Importer) Import(ctx context.Context, src Source) (*Report, error) {
+	imp.report = &Report{}
+
+	err := src.Records(ctx, func(rec *Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		imp.report.Read++
+		if err := imp.add(rec); err != nil {
+			return err
+		}
+		if len(imp.pending) >= imp.opts.BatchSize {
+			return imp.flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = imp.flush()
+	}
+	if err != nil {
+		return imp.report, errors.New(err).
+			Component("importer").
+			Category(errors.CategoryDatabase).
+			Context("source", src.Name()).
+			Context("imported", imp.report.Imported).
+			Build()
+	}
+	return imp.report, nil
+}
+
+// add converts a record into a pending note unless it is invalid or already stored
+func (imp *Importer) add(rec *Record) error {
+	if rec.SkipReason != "" {
+		imp.skip(rec, rec.SkipReason)
+		return nil
+	}
+	if rec.Begin.IsZero() {
+		imp.skip(rec, "detection time is unknown")
+		return nil
+	}
+	if rec.Confidence < 0 || rec.Confidence > 1 {
+		imp.skip(rec, fmt.Sprintf("confidence %g is out of range", rec.Confidence))
+		return nil
+	}
+
+	label := rec.Species
+	if label == "" && rec.SpeciesCode != "" {
+		name, ok := birdnet.GetSpeciesNameFromCode(imp.bn.TaxonomyMap, rec.SpeciesCode)
+		if !ok {
+			imp.skip(rec, fmt.Sprintf("species code %q is not in the taxonomy", rec.SpeciesCode))
+			return nil
+		}
+		label = name
+	}
+	scientificName, commonName, speciesCode := observation.ParseSpeciesString(label)
+	if scientificName == "" || scientificName == commonName {
+		imp.skip(rec, fmt.Sprintf("species %q has no scientific name", label))
+		return nil
+	}
+	if speciesCode == "" {
+		_, _, speciesCode = imp.bn.EnrichResultWithTaxonomy(label)
+	}
+
+	// Detections are dated in local time like realtime detections, AudioMoth names are UTC
+	local := rec.Begin.Local()
+	date := local.Format(time.DateOnly)
+	clock := local.Format(time.TimeOnly)
+	duplicate, err := imp.isDuplicate(date, clock, scientificName)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		imp.report.Duplicates++
+		return nil
+	}
+
+	end := rec.End
+	if end.Before(rec.Begin) || end.IsZero() {
+		end = rec.Begin.Add(3 * time.Second)
+	}
+
+	note := datastore.Note{
+		SourceNode:     imp.settings.Main.Name,
+		Date:           date,
+		Time:           clock,
+		BeginTime:      rec.Begin,
+		EndTime:        end,
+		SpeciesCode:    speciesCode,
+		ScientificName: scientificName,
+		CommonName:     commonName,
+		Confidence:     math.Round(rec.Confidence*100) / 100,
+		Latitude:       rec.Latitude,
+		Longitude:      rec.Longitude,
+		Threshold:      rec.Threshold,
+		Sensitivity:    rec.Sensitivity,
+		Results: []datastore.Results{
+			{Species: scientificName + "_" + commonName, Confidence: float32(rec.Confidence)},
+		},
+	}
+	if note.Latitude == 0 && note.Longitude == 0 {
+		note.Latitude = imp.settings.BirdNET.Latitude
+		note.Longitude = imp.settings.BirdNET.Longitude
+	}
+	if note.Threshold == 0 {
+		note.Threshold = imp.settings.BirdNET.Threshold
+	}
+	if note.Sensitivity == 0 {
+		note.Sensitivity = imp.settings.BirdNET.Sensitivity
+	}
+
+	if rec.ClipPath != "" {
+		clipName, err := imp.attachClip(rec.ClipPath, &note)
+		if err != nil {
+			return err
+		}
+		note.ClipName = clipName
+	}
+
+	imp.existing[duplicateKey(date, clock, scientificName)] = struct{}{}
+	imp.pending = append(imp.pending, note)
+	return nil
+}
+
+// skip records why a record was not imported
+func (imp *Importer) skip(rec *Record, reason string) {
+	imp.report.Skipped = append(imp.report.Skipped, SkippedRecord{Origin: rec.Origin, Reason: reason})
+}
+
+// duplicateKey identifies a detection, realtime detections of a species never share a second
+func duplicateKey(date, clock, scientificName string) string {
+	return date + " " + clock + " " + strings.ToLower(scientificName)
+}
+
+// isDuplicate reports whether the datastore or this import already holds the detection.
+// Stored detections are loaded one date at a time as the records reach it.
+func (imp *Importer) isDuplicate(date, clock, scientificName string) (bool, error) {
+	if !imp.loadedDates[date] {
+		var rows []struct {
+			Time           string
+			ScientificName string
+		}
+		err := imp.store.Transaction(func(tx *gorm.DB) error {
+			return tx.Model(&datastore.Note{}).
+				Select("time, scientific_name").
+				Where("date = ?", date).
+				Find(&rows).Error
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, row := range rows {
+			imp.existing[duplicateKey(date, row.Time, row.ScientificName)] = struct{}{}
+		}
+		imp.loadedDates[date] = true
+	}
+
+	_, exists := imp.existing[duplicateKey(date, clock, scientificName)]
+	return exists, nil
+}
+
+// attachClip returns the clip name of an existing clip relative to the clip directory,
+// copying the clip there when enabled. Missing clips are counted and not attached.
+func (imp *Importer) attachClip(clipPath string, note *datastore.Note) (string, error) {
+	info, err := os.Stat(clipPath)
+	if err != nil || !info.Mode().IsRegular() {
+		imp.report.ClipsMissing++
+		return "", nil
+	}
+
+	exportDir, err := filepath.Abs(imp.settings.Realtime.Audio.Export.Path)
+	if err != nil {
+		return "", err
+	}
+
+	// Clips already inside the clip directory are referenced where they are
+	if rel, err := filepath.Rel(exportDir, clipPath); err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel) {
+		imp.report.ClipsAttached++
+		return filepath.ToSlash(rel), nil
+	}
+	if !imp.opts.CopyClips {
+		imp.report.ClipsMissing++
+		return "", nil
+	}
+
+	clipName := importedClipName(note, filepath.Ext(clipPath))
+	if !imp.opts.DryRun {
+		if err := copyClip(clipPath, filepath.Join(exportDir, filepath.FromSlash(clipName)), info.Size()); err != nil {
+			return "", err
+		}
+	}
+	imp.report.ClipsAttached++
+	return clipName, nil
+}
+
+// importedClipName names a copied clip like the clips saved by realtime analysis,
+// in year and month directories
+func importedClipName(note *datastore.Note, ext string) string {
+	formattedName := strings.ToLower(strings.ReplaceAll(note.ScientificName, " ", "_"))
+	name := fmt.Sprintf("%s_%.0fp_%s%s", formattedName, note.Confidence*100, note.BeginTime.Format("20060102T150405Z"), strings.ToLower(ext))
+	return filepath.ToSlash(filepath.Join(note.BeginTime.Format("2006"), note.BeginTime.Format("01"), name))
+}
+
+// copyClip copies a clip unless a file of the same size already exists at the destination,
+// so that an interrupted import can be run again
+func copyClip(src, dst string, size int64) error {
+	if info, err := os.Stat(dst); err == nil && info.Size() == size {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// flush writes the pending notes and their results in one transaction
+func (imp *Importer) flush() error {
+	if len(imp.pending) == 0 {
+		return nil
+	}
+	if !imp.opts.DryRun {
+		if err := imp.store.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&imp.pending).Error
+		}); err != nil {
+			return err
+		}
+	}
+	imp.report.Imported += len(imp.pending)
+	imp.pending = imp.pending[:0]
+	if imp.opts.Progress != nil {
+		imp.opts.Progress(imp.report)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"gorm.io/gorm"
+)
+
+// setupImport opens an empty datastore with a clip export directory
+func setupImport(t *testing.T) (*conf.Settings, datastore.Interface) {
+	t.Helper()
+	settings := &conf.Settings{}
+	settings.Main.Name = "garden"
+	settings.BirdNET.Latitude = 60.17
+	settings.BirdNET.Longitude = 24.94
+	settings.BirdNET.Threshold = 0.8
+	settings.BirdNET.Sensitivity = 1.0
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	settings.Realtime.Audio.Export.Path = filepath.Join(t.TempDir(), "clips")
+
+	store := datastore.New(settings)
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+	return settings, store
+}
+
+// storedNotes returns the notes of the datastore with their results, oldest first
+func storedNotes(t *testing.T, store datastore.Interface) []datastore.Note {
+	t.Helper()
+	var notes []datastore.Note
+	require.NoError(t, store.Transaction(func(tx *gorm.DB) error {
+		return tx.Preload("Results").Order("date, time").Find(&notes).Error
+	}))
+	return notes
+}
+
+// createBirdNETPiDB writes a birds.db with the BirdNET-Pi detections schema
+func createBirdNETPiDB(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = db.Exec(`CREATE TABLE detections (Date DATE, Time TIME, Sci_Name VARCHAR(100) NOT NULL,
+		Com_Name VARCHAR(100) NOT NULL, Confidence FLOAT, Lat FLOAT, Lon FLOAT, Cutoff FLOAT,
+		Week INT, Sens FLOAT, Overlap FLOAT, File_Name VARCHAR(100) NOT NULL)`)
+	require.NoError(t, err)
+
+	rows := [][]any{
+		{"2024-05-01", "06:30:00", "Troglodytes troglodytes", "Eurasian Wren", 0.912, 61.5, 23.8, 0.7, 18, 1.25, 0.0, "Eurasian_Wren-91-2024-05-01-birdnet-06:30:00.mp3"},
+		{"2024-05-01", "06:31:12", "Turdus merula", "Eurasian Blackbird", 0.75, 61.5, 23.8, 0.7, 18, 1.25, 0.0, "Eurasian_Blackbird-75-2024-05-01-birdnet-06:31:12.mp3"},
+		{"2024-05-02", "05:10:00", "", "Unknown", 0.8, 61.5, 23.8, 0.7, 18, 1.25, 0.0, "unknown.mp3"},
+	}
+	for _, row := range rows {
+		_, err := db.Exec(`INSERT INTO detections VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, row...)
+		require.NoError(t, err)
+	}
+}
+
+func TestImportBirdNETPi(t *testing.T) {
+	settings, store := setupImport(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "birds.db")
+	createBirdNETPiDB(t, dbPath)
+
+	// Only the wren clip exists, BirdNET-Pi removes apostrophes and spaces from directory names
+	clipsDir := filepath.Join(dir, "Extracted")
+	wrenDir := filepath.Join(clipsDir, "By_Date", "2024-05-01", "Eurasian_Wren")
+	require.NoError(t, os.MkdirAll(wrenDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(wrenDir, "Eurasian_Wren-91-2024-05-01-birdnet-06:30:00.mp3"), []byte("ID3 audio"), 0o600))
+
+	imp, err := New(settings, store, Options{CopyClips: true})
+	require.NoError(t, err)
+	report, err := imp.Import(context.Background(), &BirdNETPiSource{DBPath: dbPath, ClipsDir: clipsDir})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Read)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.ClipsAttached)
+	assert.Equal(t, 1, report.ClipsMissing)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, "birds.db:3", report.Skipped[0].Origin)
+
+	notes := storedNotes(t, store)
+	require.Len(t, notes, 2)
+	wren := notes[0]
+	assert.Equal(t, "garden", wren.SourceNode)
+	assert.Equal(t, "2024-05-01", wren.Date)
+	assert.Equal(t, "06:30:00", wren.Time)
+	assert.Equal(t, "Troglodytes troglodytes", wren.ScientificName)
+	assert.Equal(t, "Eurasian Wren", wren.CommonName)
+	assert.Equal(t, "winwre4", wren.SpeciesCode)
+	assert.InDelta(t, 0.91, wren.Confidence, 0.0001)
+	assert.InDelta(t, 61.5, wren.Latitude, 0.0001, "location comes from the BirdNET-Pi row")
+	assert.InDelta(t, 0.7, wren.Threshold, 0.0001)
+	assert.Equal(t, 3*time.Second, wren.EndTime.Sub(wren.BeginTime))
+	require.Len(t, wren.Results, 1)
+	assert.Equal(t, "Troglodytes troglodytes_Eurasian Wren", wren.Results[0].Species)
+
+	assert.Equal(t, "2024/05/troglodytes_troglodytes_91p_20240501T063000Z.mp3", wren.ClipName)
+	clip, err := os.ReadFile(filepath.Join(settings.Realtime.Audio.Export.Path, filepath.FromSlash(wren.ClipName)))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ID3 audio"), clip)
+
+	assert.Equal(t, "eurbla", notes[1].SpeciesCode)
+	assert.Empty(t, notes[1].ClipName)
+
+	// Running the import again finds every detection in the database
+	imp, err = New(settings, store, Options{CopyClips: true})
+	require.NoError(t, err)
+	report, err = imp.Import(context.Background(), &BirdNETPiSource{DBPath: dbPath, ClipsDir: clipsDir})
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, 2, report.Duplicates)
+	assert.Len(t, storedNotes(t, store), 2)
+}
+
+func TestImportAnalyzerResults(t *testing.T) {
+	settings, store := setupImport(t)
+	dir := t.TempDir()
+
+	// CSV result named after an AudioMoth recording
+	csvResult := "Start (s),End (s),Scientific name,Common name,Confidence\n" +
+		"0.0,3.0,Turdus merula,Eurasian Blackbird,0.8512\n" +
+		"9.0,12.0,Turdus merula,Eurasian Blackbird,0.6\n" +
+		"9.0,12.0,Turdus merula,Eurasian Blackbird,0.7\n" +
+		"12.0,15.0,Turdus merula,Eurasian Blackbird,high\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20240501_063000.BirdNET.results.csv"), []byte(csvResult), 0o600))
+
+	// Combined Raven table, the recording start comes from the begin path
+	raven := "Selection\tView\tChannel\tBegin Time (s)\tEnd Time (s)\tLow Freq (Hz)\tHigh Freq (Hz)\tCommon Name\tSpecies Code\tConfidence\tBegin Path\tFile Offset (s)\n" +
+		"1\tSpectrogram 1\t1\t3603.0\t3606.0\t0\t15000\tEurasian Wren\twinwre4\t0.9\tC:\\rec\\SMU001_20240502_050000.wav\t3.0\n" +
+		"2\tSpectrogram 1\t1\t0\t3.0\t0\t15000\tUnknown Bird\tnotacode\t0.9\tC:\\rec\\SMU001_20240502_050000.wav\t0\n" +
+		"3\tSpectrogram 1\t1\t0\t3.0\t0\t15000\tnocall\tnocall\t1.0\tC:\\rec\\SMU001_20240502_050000.wav\t0\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "raven"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "raven", "combined.selection.table.txt"), []byte(raven), 0o600))
+
+	// A result without a timestamp and an unrelated text file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "garden.BirdNET.results.csv"), []byte(csvResult), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("field notes\n"), 0o600))
+
+	imp, err := New(settings, store, Options{})
+	require.NoError(t, err)
+	report, err := imp.Import(context.Background(), &AnalyzerSource{Paths: []string{dir}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Imported)
+	assert.Equal(t, 1, report.Duplicates, "the same detection twice in the results is imported once")
+	reasons := make(map[string]int)
+	for _, s := range report.Skipped {
+		reasons[s.Reason]++
+	}
+	assert.Equal(t, 3, reasons["recording start time is unknown, name files YYYYMMDD_HHMMSS or set --recording-start"])
+	assert.Equal(t, 1, reasons[`species code "notacode" is not in the taxonomy`])
+	assert.Equal(t, 1, reasons["no detection"])
+	assert.Equal(t, 2, reasons[`invalid confidence "high"`])
+	assert.Equal(t, 1, reasons["not a BirdNET-Analyzer CSV or Raven result file"])
+
+	notes := storedNotes(t, store)
+	require.Len(t, notes, 3)
+	assert.Equal(t, "06:30:00", notes[0].Time)
+	assert.Equal(t, "eurbla", notes[0].SpeciesCode)
+	assert.InDelta(t, 0.85, notes[0].Confidence, 0.0001)
+	assert.InDelta(t, 60.17, notes[0].Latitude, 0.0001, "location comes from the configuration")
+	assert.InDelta(t, 0.8, notes[0].Threshold, 0.0001)
+	assert.Equal(t, "06:30:09", notes[1].Time)
+	assert.InDelta(t, 0.6, notes[1].Confidence, 0.0001)
+
+	wren := notes[2]
+	assert.Equal(t, "2024-05-02", wren.Date)
+	assert.Equal(t, "05:00:03", wren.Time, "the file offset is used for combined tables")
+	assert.Equal(t, "Troglodytes troglodytes", wren.ScientificName)
+	assert.Equal(t, "Eurasian Wren", wren.CommonName)
+	assert.Equal(t, "winwre4", wren.SpeciesCode)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteSkipped(&buf))
+	assert.Contains(t, buf.String(), "origin,reason\n")
+}
+
+func TestImportRecordingStartAndDryRun(t *testing.T) {
+	settings, store := setupImport(t)
+	path := filepath.Join(t.TempDir(), "garden.BirdNET.results.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Start (s),End (s),Scientific name,Common name,Confidence,File\n"+
+		"6.0,9.0,Turdus merula,Eurasian Blackbird,0.9,/data/garden.wav\n"), 0o600))
+
+	start := time.Date(2024, 6, 1, 4, 0, 0, 0, time.Local)
+	imp, err := New(settings, store, Options{DryRun: true})
+	require.NoError(t, err)
+	report, err := imp.Import(context.Background(), &AnalyzerSource{Paths: []string{path}, RecordingStart: start})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Imported)
+	assert.Empty(t, report.Skipped)
+	assert.Empty(t, storedNotes(t, store), "a dry run writes nothing")
+
+	imp, err = New(settings, store, Options{})
+	require.NoError(t, err)
+	_, err = imp.Import(context.Background(), &AnalyzerSource{Paths: []string{path}, RecordingStart: start})
+	require.NoError(t, err)
+	notes := storedNotes(t, store)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "2024-06-01", notes[0].Date)
+	assert.Equal(t, "04:00:06", notes[0].Time)
+}
+
+func TestImportAnalyzerResultsLocalTime(t *testing.T) {
+	settings, store := setupImport(t)
+	path := filepath.Join(t.TempDir(), "results.BirdNET.results.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Start (s),End (s),Scientific name,Common name,Confidence,File\n"+
+		"3.0,6.0,Turdus merula,Eurasian Blackbird,0.9,/sd/20240501_223000.WAV\n"), 0o600))
+
+	imp, err := New(settings, store, Options{})
+	require.NoError(t, err)
+	_, err = imp.Import(context.Background(), &AnalyzerSource{Paths: []string{path}})
+	require.NoError(t, err)
+
+	// AudioMoth names the file by its UTC start time
+	local := time.Date(2024, 5, 1, 22, 30, 3, 0, time.UTC).Local()
+	notes := storedNotes(t, store)
+	require.Len(t, notes, 1)
+	assert.Equal(t, local.Format(time.DateOnly), notes[0].Date)
+	assert.Equal(t, local.Format(time.TimeOnly), notes[0].Time)
+}
@@ -35,7 +35,8 @@ type client struct {
 	reconnectTimer  *time.Timer
 	reconnectStop   chan struct{}
 	metrics         *metrics.MQTTMetrics
-	controlChan     chan string // Channel for control signals
+	controlChan     chan string               // Channel for control signals
+	subscriptions   map[string]MessageHandler // Handlers by topic, renewed on every connect
 }
 
 // NewClient creates a new MQTT client with the provided configuration.
@@ -49,6 +50,9 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 	config.Topic = settings.Realtime.MQTT.Topic
 	config.Retain = settings.Realtime.MQTT.Retain
 	config.Debug = settings.Realtime.MQTT.Debug
+	if settings.Realtime.MQTT.HomeAssistant.Enabled {
+		config.AvailabilityTopic = NewHomeAssistantDiscovery(settings).AvailabilityTopic()
+	}
 
 	// Configure TLS settings
 	config.TLS.Enabled = settings.Realtime.MQTT.TLS.Enabled
@@ -57,6 +61,11 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 	config.TLS.ClientCert = settings.Realtime.MQTT.TLS.ClientCert
 	config.TLS.ClientKey = settings.Realtime.MQTT.TLS.ClientKey
 
+	// Configure remote commands
+	if settings.Realtime.MQTT.Commands.Enabled {
+		config.Commands = newCommandConfig(settings)
+	}
+
 	// Auto-detect TLS from broker URL scheme
 	if strings.HasPrefix(config.Broker, "ssl://") || strings.HasPrefix(config.Broker, "tls://") || strings.HasPrefix(config.Broker, "mqtts://") {
 		config.TLS.Enabled = true
@@ -78,6 +87,7 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 		"username", config.Username, // Log username, usually not sensitive
 		"topic", config.Topic,
 		"retain", config.Retain,
+		"availability_topic", config.AvailabilityTopic,
 		"debug", config.Debug,
 		"tls_enabled", config.TLS.Enabled,
 		"tls_skip_verify", config.TLS.InsecureSkipVerify,
@@ -88,15 +98,46 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 		reconnectStop: make(chan struct{}),
 		metrics:       observabilityMetrics.MQTT,
 		controlChan:   nil, // Will be set externally when needed
+		subscriptions: make(map[string]MessageHandler),
 	}, nil
 }
 
-// SetControlChannel sets the control channel for the client
+// SetControlChannel sets the control channel for the client. When the client is already
+// connected the command topic is subscribed right away, otherwise onConnect subscribes it.
 func (c *client) SetControlChannel(ch chan string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	mqttLogger.Debug("Setting control channel for MQTT client")
 	c.controlChan = ch
+
+	// Only clients wired to a control channel listen for remote commands, so
+	// temporary clients such as connection tests never answer them
+	topic := c.config.Commands.Topic
+	if ch == nil || topic == "" {
+		c.mu.Unlock()
+		return
+	}
+	if _, exists := c.subscriptions[topic]; exists {
+		c.mu.Unlock()
+		return
+	}
+	c.subscriptions[topic] = c.handleCommandMessage
+	clientToSubscribe := c.internalClient
+	c.mu.Unlock()
+
+	mqttLogger.Info("MQTT remote commands enabled",
+		"command_topic", topic,
+		"status_topic", c.config.Commands.StatusTopic,
+		"allowed_commands", c.config.Commands.AllowedCommands)
+
+	// The client may have connected before the channel was set, onConnect then ran
+	// without the command topic. Subscribing twice is harmless.
+	if clientToSubscribe != nil && clientToSubscribe.IsConnected() {
+		go func() {
+			if err := c.subscribe(context.Background(), clientToSubscribe, topic, c.handleCommandMessage); err != nil {
+				mqttLogger.Error("Failed to subscribe to MQTT topic", "topic", topic, "error", err)
+			}
+		}()
+	}
 }
 
 // IsDebug returns the current debug setting in a thread-safe manner.
@@ -244,6 +285,16 @@ func (c *client) Connect(ctx context.Context) error {
 
 // Publish sends a message to the specified topic on the MQTT broker.
 func (c *client) Publish(ctx context.Context, topic, payload string) error {
+	return c.publish(ctx, topic, payload, false)
+}
+
+// PublishRetained sends a message to the specified topic and asks the broker to retain it.
+func (c *client) PublishRetained(ctx context.Context, topic, payload string) error {
+	return c.publish(ctx, topic, payload, true)
+}
+
+// publish sends a message, retaining it if forceRetain is set or retain is configured.
+func (c *client) publish(ctx context.Context, topic, payload string, forceRetain bool) error {
 	// Check context before acquiring lock
 	if err := ctx.Err(); err != nil {
 		mqttLogger.Warn("Publish context already cancelled", "topic", topic, "error", err)
@@ -267,9 +318,9 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 		return enhancedErr
 	}
 	mqttLogger.Debug("Client is connected, continuing")
-	clientToPublish := c.internalClient // Get client instance under lock
-	currentRetain := c.config.Retain    // Get config value under lock
-	c.mu.Unlock()                       // Unlock before blocking publish call
+	clientToPublish := c.internalClient             // Get client instance under lock
+	currentRetain := c.config.Retain || forceRetain // Get config value under lock
+	c.mu.Unlock()                                   // Unlock before blocking publish call
 
 	logger := mqttLogger.With("topic", topic, "qos", defaultQoS, "retain", currentRetain)
 	timer := c.metrics.StartPublishTimer()
@@ -328,6 +379,58 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 	return nil
 }
 
+// Subscribe registers a handler for messages on the specified topic.
+func (c *client) Subscribe(ctx context.Context, topic string, handler MessageHandler) error {
+	c.mu.Lock()
+	if c.subscriptions == nil {
+		c.subscriptions = make(map[string]MessageHandler)
+	}
+	c.subscriptions[topic] = handler
+	clientToSubscribe := c.internalClient
+	c.mu.Unlock()
+
+	// Without a connection the subscription is made by onConnect
+	if clientToSubscribe == nil || !clientToSubscribe.IsConnected() {
+		mqttLogger.Debug("Subscription registered, will subscribe on connect", "topic", topic)
+		return nil
+	}
+
+	return c.subscribe(ctx, clientToSubscribe, topic, handler)
+}
+
+// subscribe subscribes the Paho client to a topic and waits for the broker to acknowledge it
+func (c *client) subscribe(ctx context.Context, clientToSubscribe mqtt.Client, topic string, handler MessageHandler) error {
+	token := clientToSubscribe.Subscribe(topic, defaultQoS, func(_ mqtt.Client, msg mqtt.Message) {
+		handler(msg.Topic(), msg.Payload())
+	})
+
+	timeout := c.config.PublishTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	if !token.WaitTimeout(timeout) {
+		return errors.Newf("subscribe timeout after %v", timeout).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("topic", topic).
+			Context("operation", "subscribe_timeout").
+			Build()
+	}
+	if err := token.Error(); err != nil {
+		return errors.New(err).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("topic", topic).
+			Context("operation", "subscribe").
+			Build()
+	}
+
+	mqttLogger.Info("Subscribed to MQTT topic", "topic", topic)
+	return nil
+}
+
 // IsConnected returns true if the client is currently connected to the MQTT broker.
 func (c *client) IsConnected() bool {
 	// RLock is sufficient for read-only check
@@ -397,6 +500,11 @@ func (c *client) configureClientOptions(logger *slog.Logger) (*mqtt.ClientOption
 	opts.SetWriteTimeout(10 * time.Second)
 	opts.SetConnectTimeout(c.config.ConnectTimeout) // Use config timeout for initial connection attempt
 
+	// Let the broker mark the node offline if the connection drops without a disconnect
+	if c.config.AvailabilityTopic != "" {
+		opts.SetWill(c.config.AvailabilityTopic, AvailabilityOffline, defaultQoS, true)
+	}
+
 	// Configure TLS if enabled
 	if c.config.TLS.Enabled {
 		tlsConfig, err := c.createTLSConfig()
@@ -649,6 +757,13 @@ func (c *client) disconnectWithTimeout(timeout time.Duration) {
 		// Check connection status *outside* lock to avoid potential deadlock
 		// if IsConnected internally needs a lock (though it uses RLock)
 		if clientToDisconnect.IsConnected() {
+			// A clean disconnect does not trigger the last will, report offline explicitly
+			if c.config.AvailabilityTopic != "" {
+				token := clientToDisconnect.Publish(c.config.AvailabilityTopic, defaultQoS, true, AvailabilityOffline)
+				if !token.WaitTimeout(timeout) || token.Error() != nil {
+					logger.Warn("Failed to publish offline availability", "topic", c.config.AvailabilityTopic, "error", token.Error())
+				}
+			}
 			disconnectTimeoutMs := uint(timeout.Milliseconds()) // #nosec G115 -- timeout value conversion safe
 			logger.Debug("Sending disconnect signal to Paho client", "timeout_ms", disconnectTimeoutMs)
 			clientToDisconnect.Disconnect(disconnectTimeoutMs) // Perform disconnect outside lock
@@ -669,6 +784,35 @@ func (c *client) onConnect(client mqtt.Client) {
 	// Log using the package-level logger
 	mqttLogger.Info("Connected to MQTT broker", "broker", c.config.Broker, "client_id", c.config.ClientID)
 	c.metrics.UpdateConnectionStatus(true)
+
+	// Replace the retained last will with the online state. Publish from a goroutine,
+	// waiting on a token inside the connect handler would block the Paho client.
+	if topic := c.config.AvailabilityTopic; topic != "" {
+		go func() {
+			token := client.Publish(topic, defaultQoS, true, AvailabilityOnline)
+			if !token.WaitTimeout(c.config.PublishTimeout) || token.Error() != nil {
+				mqttLogger.Warn("Failed to publish online availability", "topic", topic, "error", token.Error())
+			}
+		}()
+	}
+
+	// Clean sessions drop subscriptions on disconnect, renew them on every connect
+	c.mu.RLock()
+	subscriptions := make(map[string]MessageHandler, len(c.subscriptions))
+	for topic, handler := range c.subscriptions {
+		subscriptions[topic] = handler
+	}
+	c.mu.RUnlock()
+	if len(subscriptions) > 0 {
+		go func() {
+			for topic, handler := range subscriptions {
+				if err := c.subscribe(context.Background(), client, topic, handler); err != nil {
+					mqttLogger.Error("Failed to subscribe to MQTT topic", "topic", topic, "error", err)
+				}
+			}
+		}()
+	}
+
 	// Reset reconnect attempts on successful connection - might be handled by Connect logic resetting lastConnAttempt implicitly
 }
 
@@ -0,0 +1,160 @@
+// commands.go: remote control commands received over MQTT
+package mqtt
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command acknowledgement statuses
+const (
+	CommandStatusAccepted = "accepted"
+	CommandStatusRejected = "rejected"
+)
+
+// commandSendTimeout is how long a command waits for room in the control channel
+const commandSendTimeout = 5 * time.Second
+
+// SupportedCommands are the control signals that can be triggered over MQTT. Signals
+// that replace the MQTT client itself are left out, the acknowledgement would be lost.
+var SupportedCommands = []string{
+	"reload_birdnet",
+	"rebuild_range_filter",
+	"reconfigure_rtsp_sources",
+	"reconfigure_birdweather",
+	"reconfigure_sound_level",
+	"update_detection_intervals",
+	"reconfigure_telemetry",
+}
+
+// CommandConfig holds the configuration for remote commands
+type CommandConfig struct {
+	Topic           string   // Topic receiving commands, empty disables remote commands
+	StatusTopic     string   // Topic receiving acknowledgements
+	Token           string   // Shared secret every command must include
+	AllowedCommands []string // Commands accepted from the command topic
+}
+
+// CommandRequest is the payload published to the command topic
+type CommandRequest struct {
+	ID      string `json:"id,omitempty"` // Optional request ID echoed in the acknowledgement
+	Command string `json:"command"`
+	Token   string `json:"token"`
+}
+
+// CommandResponse is the acknowledgement published to the status topic
+type CommandResponse struct {
+	ID        string    `json:"id,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// newCommandConfig creates the command configuration from the MQTT settings
+func newCommandConfig(settings *conf.Settings) CommandConfig {
+	commands := settings.Realtime.MQTT.Commands
+	base := strings.TrimSuffix(settings.Realtime.MQTT.Topic, "/")
+
+	config := CommandConfig{
+		Topic:           commands.Topic,
+		StatusTopic:     commands.StatusTopic,
+		Token:           commands.Token,
+		AllowedCommands: commands.AllowedCommands,
+	}
+	if config.Topic == "" {
+		config.Topic = base + "/command"
+	}
+	if config.StatusTopic == "" {
+		config.StatusTopic = config.Topic + "/status"
+	}
+	return config
+}
+
+// handleCommandMessage processes a message from the command topic. Forwarding the command
+// and publishing the acknowledgement can wait, so both run outside the delivery goroutine.
+func (c *client) handleCommandMessage(_ string, payload []byte) {
+	go c.executeCommand(payload)
+}
+
+// executeCommand runs a command and publishes the acknowledgement to the status topic
+func (c *client) executeCommand(payload []byte) {
+	c.mu.RLock()
+	controlChan := c.controlChan
+	c.mu.RUnlock()
+
+	response := processCommand(&c.config.Commands, controlChan, payload)
+
+	mqttLogger.Info("Processed MQTT command",
+		"command", response.Command,
+		"id", response.ID,
+		"status", response.Status,
+		"error", response.Error)
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		mqttLogger.Error("Failed to marshal MQTT command response", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.PublishTimeout)
+	defer cancel()
+	if err := c.Publish(ctx, c.config.Commands.StatusTopic, string(data)); err != nil {
+		mqttLogger.Warn("Failed to publish MQTT command response", "topic", c.config.Commands.StatusTopic, "error", err)
+	}
+}
+
+// processCommand authenticates a command and forwards it to the control channel
+func processCommand(config *CommandConfig, controlChan chan string, payload []byte) (response CommandResponse) {
+	response = CommandResponse{
+		Status:    CommandStatusRejected,
+		Timestamp: time.Now(),
+	}
+
+	var request CommandRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		response.Error = "invalid command payload"
+		return response
+	}
+	response.ID = request.ID
+	response.Command = request.Command
+
+	// An empty token never authenticates, even if the configured token is empty
+	if config.Token == "" || subtle.ConstantTimeCompare([]byte(request.Token), []byte(config.Token)) != 1 {
+		response.Error = "invalid token"
+		return response
+	}
+
+	if !slices.Contains(config.AllowedCommands, request.Command) || !slices.Contains(SupportedCommands, request.Command) {
+		response.Error = "command not allowed"
+		return response
+	}
+
+	if controlChan == nil {
+		response.Error = "control channel not available"
+		return response
+	}
+
+	// The channel is closed during shutdown, a late command must not crash the process
+	defer func() {
+		if recover() != nil {
+			response.Status = CommandStatusRejected
+			response.Error = "control channel closed"
+		}
+	}()
+
+	select {
+	case controlChan <- request.Command:
+		response.Status = CommandStatusAccepted
+	case <-time.After(commandSendTimeout):
+		response.Error = "control channel busy"
+	}
+
+	return response
+}
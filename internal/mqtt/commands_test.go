@@ -0,0 +1,186 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const testCommandToken = "0123456789abcdef"
+
+func newTestCommandConfig() *CommandConfig {
+	return &CommandConfig{
+		Topic:           "birdnet/command",
+		StatusTopic:     "birdnet/command/status",
+		Token:           testCommandToken,
+		AllowedCommands: []string{"reload_birdnet", "reconfigure_mqtt"},
+	}
+}
+
+func commandPayload(t *testing.T, request CommandRequest) []byte {
+	t.Helper()
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestNewCommandConfig(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.MQTT.Topic = "birdnet/"
+	settings.Realtime.MQTT.Commands.Token = testCommandToken
+
+	config := newCommandConfig(settings)
+	assert.Equal(t, "birdnet/command", config.Topic)
+	assert.Equal(t, "birdnet/command/status", config.StatusTopic)
+	assert.Equal(t, testCommandToken, config.Token)
+
+	settings.Realtime.MQTT.Commands.Topic = "control/in"
+	settings.Realtime.MQTT.Commands.StatusTopic = "control/out"
+	config = newCommandConfig(settings)
+	assert.Equal(t, "control/in", config.Topic)
+	assert.Equal(t, "control/out", config.StatusTopic)
+}
+
+func TestProcessCommandAccepted(t *testing.T) {
+	t.Parallel()
+
+	controlChan := make(chan string, 1)
+	response := processCommand(newTestCommandConfig(), controlChan, commandPayload(t, CommandRequest{
+		ID:      "42",
+		Command: "reload_birdnet",
+		Token:   testCommandToken,
+	}))
+
+	assert.Equal(t, CommandStatusAccepted, response.Status)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "42", response.ID)
+	assert.Equal(t, "reload_birdnet", response.Command)
+	assert.False(t, response.Timestamp.IsZero())
+
+	select {
+	case signal := <-controlChan:
+		assert.Equal(t, "reload_birdnet", signal)
+	default:
+		t.Fatal("expected command to be forwarded to the control channel")
+	}
+}
+
+func TestProcessCommandRejected(t *testing.T) {
+	t.Parallel()
+
+	closedChan := make(chan string, 1)
+	close(closedChan)
+
+	tests := []struct {
+		name        string
+		config      func(c *CommandConfig)
+		payload     []byte
+		controlChan chan string
+		wantError   string
+	}{
+		{
+			name:        "invalid payload",
+			payload:     []byte("reload_birdnet"),
+			controlChan: make(chan string, 1),
+			wantError:   "invalid command payload",
+		},
+		{
+			name:        "wrong token",
+			payload:     commandPayload(t, CommandRequest{Command: "reload_birdnet", Token: "wrong"}),
+			controlChan: make(chan string, 1),
+			wantError:   "invalid token",
+		},
+		{
+			name:        "empty token configured",
+			config:      func(c *CommandConfig) { c.Token = "" },
+			payload:     commandPayload(t, CommandRequest{Command: "reload_birdnet"}),
+			controlChan: make(chan string, 1),
+			wantError:   "invalid token",
+		},
+		{
+			name:        "command not in allow list",
+			payload:     commandPayload(t, CommandRequest{Command: "rebuild_range_filter", Token: testCommandToken}),
+			controlChan: make(chan string, 1),
+			wantError:   "command not allowed",
+		},
+		{
+			name:        "allowed but unsupported command",
+			payload:     commandPayload(t, CommandRequest{Command: "reconfigure_mqtt", Token: testCommandToken}),
+			controlChan: make(chan string, 1),
+			wantError:   "command not allowed",
+		},
+		{
+			name:      "no control channel",
+			payload:   commandPayload(t, CommandRequest{Command: "reload_birdnet", Token: testCommandToken}),
+			wantError: "control channel not available",
+		},
+		{
+			name:        "closed control channel",
+			payload:     commandPayload(t, CommandRequest{Command: "reload_birdnet", Token: testCommandToken}),
+			controlChan: closedChan,
+			wantError:   "control channel closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := newTestCommandConfig()
+			if tt.config != nil {
+				tt.config(config)
+			}
+
+			response := processCommand(config, tt.controlChan, tt.payload)
+			assert.Equal(t, CommandStatusRejected, response.Status)
+			assert.Equal(t, tt.wantError, response.Error)
+
+			if tt.controlChan != nil && tt.controlChan != closedChan {
+				assert.Empty(t, tt.controlChan, "rejected command must not reach the control channel")
+			}
+		})
+	}
+}
+
+// connectedPahoClient is a connected Paho client recording the topics it subscribes to
+type connectedPahoClient struct {
+	mqtt.Client
+	subscribed chan string
+}
+
+func (f *connectedPahoClient) IsConnected() bool { return true }
+
+func (f *connectedPahoClient) Subscribe(topic string, _ byte, _ mqtt.MessageHandler) mqtt.Token {
+	f.subscribed <- topic
+	return &mqtt.DummyToken{}
+}
+
+// TestSetControlChannelAfterConnect subscribes the command topic when the client connected
+// before the control channel was set, onConnect then ran without the command topic
+func TestSetControlChannelAfterConnect(t *testing.T) {
+	t.Parallel()
+
+	paho := &connectedPahoClient{subscribed: make(chan string, 1)}
+	c := &client{
+		config:         Config{Commands: *newTestCommandConfig(), PublishTimeout: time.Second},
+		internalClient: paho,
+		subscriptions:  make(map[string]MessageHandler),
+	}
+
+	c.SetControlChannel(make(chan string, 1))
+
+	select {
+	case topic := <-paho.subscribed:
+		assert.Equal(t, "birdnet/command", topic)
+	case <-time.After(time.Second):
+		t.Fatal("expected the command topic to be subscribed on a connected client")
+	}
+	assert.Contains(t, c.subscriptions, "birdnet/command", "the topic is renewed on reconnect")
+}
@@ -0,0 +1,327 @@
+// homeassistant.go: Home Assistant MQTT discovery configs and state topics
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Availability payloads published to the availability topic
+const (
+	AvailabilityOnline  = "online"
+	AvailabilityOffline = "offline"
+)
+
+// Sub-topics below the configured base topic
+const (
+	availabilitySubtopic = "status"
+	statsSubtopic        = "stats"
+	systemSubtopic       = "system"
+	speciesSubtopic      = "species"
+	sourcesSubtopic      = "sources"
+	detectionSubtopic    = "detection"
+	soundLevelSubtopic   = "soundlevel"
+)
+
+// HomeAssistantDevice describes the BirdNET-Go node as a Home Assistant device
+type HomeAssistantDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+// HomeAssistantEntity is the discovery config payload of a single entity
+type HomeAssistantEntity struct {
+	Name                   string              `json:"name"`
+	UniqueID               string              `json:"unique_id"`
+	ObjectID               string              `json:"object_id"`
+	StateTopic             string              `json:"state_topic"`
+	ValueTemplate          string              `json:"value_template,omitempty"`
+	JSONAttributesTopic    string              `json:"json_attributes_topic,omitempty"`
+	JSONAttributesTemplate string              `json:"json_attributes_template,omitempty"`
+	UnitOfMeasurement      string              `json:"unit_of_measurement,omitempty"`
+	DeviceClass            string              `json:"device_class,omitempty"`
+	StateClass             string              `json:"state_class,omitempty"`
+	EntityCategory         string              `json:"entity_category,omitempty"`
+	Icon                   string              `json:"icon,omitempty"`
+	PayloadOn              string              `json:"payload_on,omitempty"`
+	PayloadOff             string              `json:"payload_off,omitempty"`
+	AvailabilityTopic      string              `json:"availability_topic,omitempty"`
+	PayloadAvailable       string              `json:"payload_available,omitempty"`
+	PayloadNotAvailable    string              `json:"payload_not_available,omitempty"`
+	Device                 HomeAssistantDevice `json:"device"`
+
+	component string // sensor or binary_sensor, part of the discovery topic
+}
+
+// DiscoveryMessage is a discovery config ready to be published as a retained message
+type DiscoveryMessage struct {
+	Topic   string
+	Payload string
+}
+
+// HomeAssistantDiscovery builds discovery configs and state topics for a BirdNET-Go node
+type HomeAssistantDiscovery struct {
+	prefix    string
+	baseTopic string
+	nodeID    string
+	device    HomeAssistantDevice
+}
+
+// NewHomeAssistantDiscovery creates a discovery builder from the MQTT settings
+func NewHomeAssistantDiscovery(settings *conf.Settings) *HomeAssistantDiscovery {
+	ha := settings.Realtime.MQTT.HomeAssistant
+
+	prefix := strings.Trim(ha.DiscoveryPrefix, "/")
+	if prefix == "" {
+		prefix = "homeassistant"
+	}
+
+	nodeID := TopicSlug(settings.Main.Name)
+	if nodeID == "" {
+		nodeID = "birdnet_go"
+	}
+
+	deviceName := ha.DeviceName
+	if deviceName == "" {
+		deviceName = settings.Main.Name
+	}
+	if deviceName == "" {
+		deviceName = "BirdNET-Go"
+	}
+
+	return &HomeAssistantDiscovery{
+		prefix:    prefix,
+		baseTopic: strings.TrimSuffix(settings.Realtime.MQTT.Topic, "/"),
+		nodeID:    nodeID,
+		device: HomeAssistantDevice{
+			Identifiers:  []string{nodeID},
+			Name:         deviceName,
+			Manufacturer: "BirdNET-Go",
+			Model:        "BirdNET-Go",
+			SWVersion:    settings.Version,
+		},
+	}
+}
+
+// DetectionTopic returns the topic carrying every detection
+func (d *HomeAssistantDiscovery) DetectionTopic() string {
+	return d.baseTopic
+}
+
+// AvailabilityTopic returns the topic carrying the online/offline state of the node
+func (d *HomeAssistantDiscovery) AvailabilityTopic() string {
+	return d.baseTopic + "/" + availabilitySubtopic
+}
+
+// StatsTopic returns the topic carrying the daily detection statistics
+func (d *HomeAssistantDiscovery) StatsTopic() string {
+	return d.baseTopic + "/" + statsSubtopic
+}
+
+// SystemTopic returns the topic carrying system health readings
+func (d *HomeAssistantDiscovery) SystemTopic() string {
+	return d.baseTopic + "/" + systemSubtopic
+}
+
+// SpeciesTopic returns the topic carrying the latest detection of a species
+func (d *HomeAssistantDiscovery) SpeciesTopic(scientificName string) string {
+	return d.baseTopic + "/" + speciesSubtopic + "/" + TopicSlug(scientificName)
+}
+
+// SourceDetectionTopic returns the topic carrying the latest detection of an audio source
+func (d *HomeAssistantDiscovery) SourceDetectionTopic(sourceID string) string {
+	return d.baseTopic + "/" + sourcesSubtopic + "/" + TopicSlug(sourceID) + "/" + detectionSubtopic
+}
+
+// SourceSoundLevelTopic returns the topic carrying the sound level of an audio source
+func (d *HomeAssistantDiscovery) SourceSoundLevelTopic(sourceID string) string {
+	return d.baseTopic + "/" + sourcesSubtopic + "/" + TopicSlug(sourceID) + "/" + soundLevelSubtopic
+}
+
+// DeviceEntities returns the discovery configs of the node wide entities
+func (d *HomeAssistantDiscovery) DeviceEntities() ([]DiscoveryMessage, error) {
+	entities := []HomeAssistantEntity{
+		{
+			component:           "sensor",
+			Name:                "Last detection",
+			ObjectID:            "last_detection",
+			StateTopic:          d.DetectionTopic(),
+			ValueTemplate:       "{{ value_json.CommonName }}",
+			JSONAttributesTopic: d.DetectionTopic(),
+			JSONAttributesTemplate: "{{ {'scientific_name': value_json.ScientificName, " +
+				"'confidence': value_json.Confidence, 'source': value_json.Source.displayName, " +
+				"'date': value_json.Date, 'time': value_json.Time} | tojson }}",
+			Icon: "mdi:bird",
+		},
+		{
+			component:         "sensor",
+			Name:              "Species today",
+			ObjectID:          "species_today",
+			StateTopic:        d.StatsTopic(),
+			ValueTemplate:     "{{ value_json.species_today }}",
+			UnitOfMeasurement: "species",
+			StateClass:        "measurement",
+			Icon:              "mdi:feather",
+		},
+		{
+			component:         "sensor",
+			Name:              "Detections today",
+			ObjectID:          "detections_today",
+			StateTopic:        d.StatsTopic(),
+			ValueTemplate:     "{{ value_json.detections_today }}",
+			UnitOfMeasurement: "detections",
+			StateClass:        "total_increasing",
+			Icon:              "mdi:counter",
+		},
+		{
+			component:      "binary_sensor",
+			Name:           "Status",
+			ObjectID:       "status",
+			StateTopic:     d.AvailabilityTopic(),
+			PayloadOn:      AvailabilityOnline,
+			PayloadOff:     AvailabilityOffline,
+			DeviceClass:    "connectivity",
+			EntityCategory: "diagnostic",
+		},
+		{
+			component:         "sensor",
+			Name:              "CPU usage",
+			ObjectID:          "cpu_usage",
+			StateTopic:        d.SystemTopic(),
+			ValueTemplate:     "{{ value_json.cpu_percent }}",
+			UnitOfMeasurement: "%",
+			StateClass:        "measurement",
+			EntityCategory:    "diagnostic",
+			Icon:              "mdi:cpu-64-bit",
+		},
+		{
+			component:         "sensor",
+			Name:              "Memory usage",
+			ObjectID:          "memory_usage",
+			StateTopic:        d.SystemTopic(),
+			ValueTemplate:     "{{ value_json.memory_percent }}",
+			UnitOfMeasurement: "%",
+			StateClass:        "measurement",
+			EntityCategory:    "diagnostic",
+			Icon:              "mdi:memory",
+		},
+		{
+			component:         "sensor",
+			Name:              "Disk usage",
+			ObjectID:          "disk_usage",
+			StateTopic:        d.SystemTopic(),
+			ValueTemplate:     "{{ value_json.disk_percent }}",
+			UnitOfMeasurement: "%",
+			StateClass:        "measurement",
+			EntityCategory:    "diagnostic",
+			Icon:              "mdi:harddisk",
+		},
+		{
+			component:         "sensor",
+			Name:              "Uptime",
+			ObjectID:          "uptime",
+			StateTopic:        d.SystemTopic(),
+			ValueTemplate:     "{{ value_json.uptime_seconds }}",
+			UnitOfMeasurement: "s",
+			DeviceClass:       "duration",
+			EntityCategory:    "diagnostic",
+		},
+	}
+
+	return d.buildMessages(entities)
+}
+
+// SourceEntities returns the discovery configs of the entities of a single audio source
+func (d *HomeAssistantDiscovery) SourceEntities(sourceID, displayName string) ([]DiscoveryMessage, error) {
+	if displayName == "" {
+		displayName = sourceID
+	}
+	slug := TopicSlug(sourceID)
+
+	entities := []HomeAssistantEntity{
+		{
+			component:           "sensor",
+			Name:                "Last detection (" + displayName + ")",
+			ObjectID:            "source_" + slug + "_last_detection",
+			StateTopic:          d.SourceDetectionTopic(sourceID),
+			ValueTemplate:       "{{ value_json.CommonName }}",
+			JSONAttributesTopic: d.SourceDetectionTopic(sourceID),
+			JSONAttributesTemplate: "{{ {'scientific_name': value_json.ScientificName, " +
+				"'confidence': value_json.Confidence, 'date': value_json.Date, 'time': value_json.Time} | tojson }}",
+			Icon: "mdi:bird",
+		},
+		{
+			component:         "sensor",
+			Name:              "Sound level (" + displayName + ")",
+			ObjectID:          "source_" + slug + "_sound_level",
+			StateTopic:        d.SourceSoundLevelTopic(sourceID),
+			ValueTemplate:     "{{ value_json.level_db }}",
+			UnitOfMeasurement: "dB",
+			DeviceClass:       "sound_pressure",
+			StateClass:        "measurement",
+		},
+	}
+
+	return d.buildMessages(entities)
+}
+
+// buildMessages fills in the shared entity fields and marshals the discovery payloads
+func (d *HomeAssistantDiscovery) buildMessages(entities []HomeAssistantEntity) ([]DiscoveryMessage, error) {
+	messages := make([]DiscoveryMessage, 0, len(entities))
+	for i := range entities {
+		entity := &entities[i]
+		entity.UniqueID = d.nodeID + "_" + entity.ObjectID
+		entity.Device = d.device
+
+		// The status sensor reports availability itself and must stay available
+		if entity.StateTopic != d.AvailabilityTopic() {
+			entity.AvailabilityTopic = d.AvailabilityTopic()
+			entity.PayloadAvailable = AvailabilityOnline
+			entity.PayloadNotAvailable = AvailabilityOffline
+		}
+
+		payload, err := json.Marshal(entity)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("mqtt").
+				Category(errors.CategoryValidation).
+				Context("operation", "marshal_discovery_config").
+				Context("object_id", entity.ObjectID).
+				Build()
+		}
+
+		messages = append(messages, DiscoveryMessage{
+			Topic:   d.prefix + "/" + entity.component + "/" + d.nodeID + "/" + entity.ObjectID + "/config",
+			Payload: string(payload),
+		})
+	}
+	return messages, nil
+}
+
+// TopicSlug converts a name to a lowercase topic level containing only letters,
+// digits and underscores, so it is safe in MQTT topics and Home Assistant IDs.
+func TopicSlug(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "_")
+}
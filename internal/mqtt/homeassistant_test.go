@@ -0,0 +1,115 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func newHomeAssistantTestSettings() *conf.Settings {
+	settings := &conf.Settings{}
+	settings.Main.Name = "Backyard Node"
+	settings.Version = "1.2.3"
+	settings.Realtime.MQTT.Topic = "birdnet/"
+	settings.Realtime.MQTT.HomeAssistant.Enabled = true
+	settings.Realtime.MQTT.HomeAssistant.DiscoveryPrefix = "homeassistant/"
+	return settings
+}
+
+func TestTopicSlug(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Turdus merula":         "turdus_merula",
+		"rtsp_87b89761":         "rtsp_87b89761",
+		"  BirdNET-Go / Node 1": "birdnet_go_node_1",
+		"Mésange bleue":         "m_sange_bleue",
+		"#+/":                   "",
+		"":                      "",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, TopicSlug(input), "input %q", input)
+	}
+}
+
+func TestHomeAssistantTopics(t *testing.T) {
+	t.Parallel()
+
+	d := NewHomeAssistantDiscovery(newHomeAssistantTestSettings())
+
+	assert.Equal(t, "birdnet", d.DetectionTopic())
+	assert.Equal(t, "birdnet/status", d.AvailabilityTopic())
+	assert.Equal(t, "birdnet/stats", d.StatsTopic())
+	assert.Equal(t, "birdnet/system", d.SystemTopic())
+	assert.Equal(t, "birdnet/species/turdus_merula", d.SpeciesTopic("Turdus merula"))
+	assert.Equal(t, "birdnet/sources/rtsp_87b89761/detection", d.SourceDetectionTopic("rtsp_87b89761"))
+	assert.Equal(t, "birdnet/sources/rtsp_87b89761/soundlevel", d.SourceSoundLevelTopic("rtsp_87b89761"))
+}
+
+func TestHomeAssistantDeviceEntities(t *testing.T) {
+	t.Parallel()
+
+	d := NewHomeAssistantDiscovery(newHomeAssistantTestSettings())
+	messages, err := d.DeviceEntities()
+	require.NoError(t, err)
+	require.NotEmpty(t, messages)
+
+	entities := make(map[string]map[string]any)
+	for _, message := range messages {
+		assert.True(t, strings.HasPrefix(message.Topic, "homeassistant/"), message.Topic)
+		assert.True(t, strings.HasSuffix(message.Topic, "/config"), message.Topic)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal([]byte(message.Payload), &payload), message.Topic)
+		entities[message.Topic] = payload
+	}
+
+	last := entities["homeassistant/sensor/backyard_node/last_detection/config"]
+	require.NotNil(t, last, "last detection sensor must be discovered")
+	assert.Equal(t, "backyard_node_last_detection", last["unique_id"])
+	assert.Equal(t, "birdnet", last["state_topic"])
+	assert.Equal(t, "birdnet/status", last["availability_topic"])
+
+	device, ok := last["device"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "Backyard Node", device["name"])
+	assert.Equal(t, "1.2.3", device["sw_version"])
+	assert.Equal(t, []any{"backyard_node"}, device["identifiers"])
+
+	assert.Contains(t, entities, "homeassistant/sensor/backyard_node/species_today/config")
+	assert.Contains(t, entities, "homeassistant/sensor/backyard_node/cpu_usage/config")
+
+	// The status sensor reports availability and must not depend on it
+	status := entities["homeassistant/binary_sensor/backyard_node/status/config"]
+	require.NotNil(t, status)
+	assert.Equal(t, "birdnet/status", status["state_topic"])
+	assert.NotContains(t, status, "availability_topic")
+}
+
+func TestHomeAssistantSourceEntities(t *testing.T) {
+	t.Parallel()
+
+	settings := newHomeAssistantTestSettings()
+	settings.Realtime.MQTT.HomeAssistant.DeviceName = "Garden"
+	d := NewHomeAssistantDiscovery(settings)
+
+	messages, err := d.SourceEntities("rtsp_87b89761", "Pond camera")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	var level map[string]any
+	for _, message := range messages {
+		if strings.Contains(message.Topic, "sound_level") {
+			require.NoError(t, json.Unmarshal([]byte(message.Payload), &level))
+		}
+	}
+	require.NotNil(t, level)
+	assert.Equal(t, "Sound level (Pond camera)", level["name"])
+	assert.Equal(t, "birdnet/sources/rtsp_87b89761/soundlevel", level["state_topic"])
+	assert.Equal(t, "sound_pressure", level["device_class"])
+	assert.Equal(t, "Garden", level["device"].(map[string]any)["name"])
+}
@@ -35,6 +35,14 @@ type Client interface {
 	// It returns an error if the publish operation fails.
 	Publish(ctx context.Context, topic string, payload string) error
 
+	// PublishRetained sends a message that the broker retains regardless of the
+	// configured retain setting, for state that new subscribers must receive.
+	PublishRetained(ctx context.Context, topic string, payload string) error
+
+	// Subscribe registers a handler for messages on the specified topic. The subscription
+	// is made immediately when connected and renewed on every reconnect.
+	Subscribe(ctx context.Context, topic string, handler MessageHandler) error
+
 	// IsConnected returns true if the client is currently connected to the MQTT broker.
 	IsConnected() bool
 
@@ -50,6 +58,10 @@ type Client interface {
 	SetControlChannel(ch chan string)
 }
 
+// MessageHandler is called for every message received on a subscribed topic.
+// Handlers run on the MQTT client's delivery goroutine and must not block.
+type MessageHandler func(topic string, payload []byte)
+
 // Config holds the configuration for the MQTT client.
 type Config struct {
 	Broker            string
@@ -59,6 +71,7 @@ type Config struct {
 	Password          string
 	Topic             string // Default topic for publishing messages
 	Retain            bool   // true to retain messages at the broker
+	AvailabilityTopic string // Topic for the online/offline state, also used as last will. Empty disables
 	ReconnectCooldown time.Duration
 	ReconnectDelay    time.Duration
 	// Connection timeouts
@@ -69,6 +82,8 @@ type Config struct {
 	ShutdownDisconnectTimeout time.Duration // Timeout for disconnect during shutdown (shorter than normal)
 	// TLS configuration
 	TLS TLSConfig
+	// Remote command configuration
+	Commands CommandConfig
 }
 
 // TLSConfig holds TLS/SSL configuration for secure MQTT connections
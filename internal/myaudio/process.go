@@ -2,6 +2,7 @@
 package myaudio
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -77,8 +78,8 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 		return fmt.Errorf("error converting %v bit PCM data to float32: %w", conf.BitDepth, err)
 	}
 
-	// run BirdNET inference
-	results, err := bn.Predict(sampleData)
+	// run BirdNET and the additional models, sources take turns when all interpreters are busy
+	results, embedding, err := bn.PredictModelSetWithContext(birdnet.WithSource(context.Background(), source), sampleData)
 
 	// Return float32 buffer to pool after prediction
 	// This is safe because Predict copies the data to the input tensor
@@ -169,6 +170,7 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 		ElapsedTime: elapsedTime,
 		PCMdata:     data,
 		Results:     results,
+		Embedding:   embedding,
 		Source:      audioSource,
 	}
 
@@ -31,6 +31,20 @@ type AudioInfo struct {
 	BitDepth     int
 }
 
+// supportedAudioFormats lists the audio formats that can be analyzed, WAV, FLAC and Ogg Vorbis are
+// decoded natively and the other formats with FFmpeg
+const supportedAudioFormats = "wav,flac,mp3,ogg,oga,opus,m4a,aac"
+
+// IsSupportedAudioFile reports whether the file has the extension of an audio format that can be analyzed
+func IsSupportedAudioFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".flac", ".mp3", ".ogg", ".oga", ".opus", ".m4a", ".aac":
+		return true
+	default:
+		return false
+	}
+}
+
 // GetTotalChunks calculates the total number of chunks for a given audio file
 func GetTotalChunks(sampleRate, totalSamples int, overlap float64) int {
 	chunkSamples := 3 * sampleRate                          // samples in 3 seconds
@@ -109,13 +123,21 @@ func GetAudioInfo(filePath string) (AudioInfo, error) {
 		info, err = readWAVInfo(file)
 	case ".flac":
 		info, err = readFLACInfo(file)
+	case ".ogg", ".oga":
+		if isOggVorbis(file) {
+			info, err = readOggVorbisInfo(file)
+		} else {
+			info, err = readFFmpegInfo(filePath, "")
+		}
+	case ".mp3", ".opus", ".m4a", ".aac":
+		info, err = readFFmpegInfo(filePath, "")
 	default:
 		enhancedErr := errors.Newf("unsupported audio format: %s", ext).
 			Component("myaudio").
 			Category(errors.CategoryValidation).
 			Context("operation", "get_audio_info").
 			Context("file_extension", ext).
-			Context("supported_formats", "wav,flac").
+			Context("supported_formats", supportedAudioFormats).
 			Build()
 
 		if m := getFileMetrics(); m != nil {
@@ -231,13 +253,21 @@ func ReadAudioFileBuffered(settings *conf.Settings, callback AudioChunkCallback)
 		err = readWAVBuffered(file, settings, callback)
 	case ".flac":
 		err = readFLACBuffered(file, settings, callback)
+	case ".ogg", ".oga":
+		if isOggVorbis(file) {
+			err = readOggVorbisBuffered(file, settings, callback)
+		} else {
+			err = readFFmpegBuffered(file, settings, callback)
+		}
+	case ".mp3", ".opus", ".m4a", ".aac":
+		err = readFFmpegBuffered(file, settings, callback)
 	default:
 		enhancedErr := errors.Newf("unsupported audio format: %s", ext).
 			Component("myaudio").
 			Category(errors.CategoryValidation).
 			Context("operation", "read_audio_file_buffered").
 			Context("file_extension", ext).
-			Context("supported_formats", "wav,flac").
+			Context("supported_formats", supportedAudioFormats).
 			Build()
 
 		if m := getFileMetrics(); m != nil {
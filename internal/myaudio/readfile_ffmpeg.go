@@ -0,0 +1,220 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+var (
+	ffmpegDurationRegex = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+	ffmpegAudioRegex    = regexp.MustCompile(`Stream #\S+.*?: Audio: [^\n]*?, (\d+) Hz, ([^,\n]+)`)
+	ffmpegChannelsRegex = regexp.MustCompile(`^(\d+) channels`)
+)
+
+// ffmpegProbeTimeout limits how long FFmpeg may take to read the stream information of a file
+var ffmpegProbeTimeout = 30 * time.Second
+
+// ffmpegChannelLayouts maps FFmpeg channel layout names to channel counts
+var ffmpegChannelLayouts = map[string]int{
+	"mono":   1,
+	"stereo": 2,
+	"2.1":    3,
+	"3.0":    3,
+	"quad":   4,
+	"4.0":    4,
+	"5.0":    5,
+	"5.1":    6,
+	"7.1":    8,
+}
+
+// ffmpegDecoderPath returns the FFmpeg binary used to decode compressed audio files,
+// the configured path when set and otherwise FFmpeg found in PATH
+func ffmpegDecoderPath(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	if settings := conf.GetSettings(); settings != nil && settings.Realtime.Audio.FfmpegPath != "" {
+		return settings.Realtime.Audio.FfmpegPath, nil
+	}
+	path, err := exec.LookPath(conf.GetFfmpegBinaryName())
+	if err != nil {
+		return "", fmt.Errorf("FFmpeg is required to decode this audio format but was not found: %w", err)
+	}
+	return path, nil
+}
+
+// readFFmpegInfo reads the audio stream information of a file from the FFmpeg input summary
+func readFFmpegInfo(filePath, ffmpegPath string) (AudioInfo, error) {
+	ffmpegPath, err := ffmpegDecoderPath(ffmpegPath)
+	if err != nil {
+		return AudioInfo{}, err
+	}
+
+	// A damaged file or a stalled network mount must not block analysis forever
+	ctx, cancel := context.WithTimeout(context.Background(), ffmpegProbeTimeout)
+	defer cancel()
+
+	// Without an output FFmpeg prints the input summary and exits with an error
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostdin", "-i", filePath) //nolint:gosec // G204: FFmpeg path is validated by settings
+	cmd.Stderr = &stderr
+	_ = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return AudioInfo{}, fmt.Errorf("FFmpeg did not read the stream information of %s within %v", filePath, ffmpegProbeTimeout)
+	}
+
+	return parseFFmpegAudioInfo(stderr.String())
+}
+
+// parseFFmpegAudioInfo parses the duration, sample rate and channels of the first audio stream
+// from the FFmpeg input summary. Samples are decoded to 16-bit PCM.
+func parseFFmpegAudioInfo(output string) (AudioInfo, error) {
+	stream := ffmpegAudioRegex.FindStringSubmatch(output)
+	if stream == nil {
+		return AudioInfo{}, fmt.Errorf("no audio stream found: %s", lastLine(output))
+	}
+	sampleRate, err := strconv.Atoi(stream[1])
+	if err != nil || sampleRate <= 0 {
+		return AudioInfo{}, fmt.Errorf("invalid sample rate: %s", stream[1])
+	}
+
+	layout := strings.TrimSpace(stream[2])
+	channels := ffmpegChannelLayouts[strings.SplitN(layout, "(", 2)[0]]
+	if match := ffmpegChannelsRegex.FindStringSubmatch(layout); match != nil {
+		channels, _ = strconv.Atoi(match[1])
+	}
+
+	duration := ffmpegDurationRegex.FindStringSubmatch(output)
+	if duration == nil {
+		return AudioInfo{}, errors.New("audio duration is not available")
+	}
+	hours, _ := strconv.Atoi(duration[1])
+	minutes, _ := strconv.Atoi(duration[2])
+	seconds, _ := strconv.ParseFloat(duration[3], 64)
+	totalSeconds := float64(hours*3600+minutes*60) + seconds
+
+	return AudioInfo{
+		SampleRate:   sampleRate,
+		TotalSamples: int(math.Round(totalSeconds * float64(sampleRate))),
+		NumChannels:  channels,
+		BitDepth:     16,
+	}, nil
+}
+
+// readFFmpegBuffered decodes a compressed audio file with FFmpeg into mono 16-bit PCM at the
+// BirdNET sample rate and processes it in chunks like WAV and FLAC. FFmpeg resamples the whole
+// stream with its own filtered resampler.
+func readFFmpegBuffered(file *os.File, settings *conf.Settings, callback AudioChunkCallback) error {
+	ffmpegPath, err := ffmpegDecoderPath(settings.Realtime.Audio.FfmpegPath)
+	if err != nil {
+		return err
+	}
+	info, err := readFFmpegInfo(file.Name(), ffmpegPath)
+	if err != nil {
+		return err
+	}
+
+	if settings.Debug {
+		fmt.Println("Sample rate:", info.SampleRate)
+		fmt.Println("Channels:", info.NumChannels)
+	}
+
+	// The process is killed if processing stops before the whole file is decoded
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-nostdin",
+		"-i", file.Name(),
+		"-map", "0:a:0", "-vn",
+		"-ac", "1",
+		"-ar", strconv.Itoa(conf.SampleRate),
+		"-f", "s16le", "-acodec", "pcm_s16le",
+		"pipe:1",
+	}
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...) //nolint:gosec // G204: FFmpeg path is validated by settings
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create FFmpeg output pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start FFmpeg: %w", err)
+	}
+
+	if err := processPCM16Stream(stdout, settings, callback); err != nil {
+		cancel()
+		_ = cmd.Wait()
+		return err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("FFmpeg failed to decode %s: %w: %s", file.Name(), err, lastLine(stderr.String()))
+	}
+	return nil
+}
+
+// processPCM16Stream reads mono 16-bit little endian PCM at the BirdNET sample rate and passes it
+// to the callback in 3 second chunks
+func processPCM16Stream(reader io.Reader, settings *conf.Settings, callback AudioChunkCallback) error {
+	step := int((3 - settings.BirdNET.Overlap) * conf.SampleRate)
+	secondsSamples := int(3 * conf.SampleRate)
+
+	// Read one second at a time
+	buffer := make([]byte, conf.SampleRate*2)
+	var currentChunk []float32
+
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return readErr
+		}
+
+		for i := 0; i+1 < n; i += 2 {
+			currentChunk = append(currentChunk, float32(int16(binary.LittleEndian.Uint16(buffer[i:])))/32768.0) //nolint:gosec // G115: 16-bit PCM sample conversion
+		}
+
+		// Process complete 3-second chunks
+		for len(currentChunk) >= secondsSamples {
+			if err := callback(currentChunk[:secondsSamples], false); err != nil {
+				return err
+			}
+			currentChunk = currentChunk[step:]
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	// Handle the last chunk and signal EOF
+	if len(currentChunk) > 0 {
+		if len(currentChunk) < secondsSamples {
+			padding := make([]float32, secondsSamples-len(currentChunk))
+			currentChunk = append(currentChunk, padding...)
+		}
+		return callback(currentChunk, true)
+	}
+	// Signal EOF even if there's no final chunk to process
+	return callback(nil, true)
+}
+
+// lastLine returns the last non-empty line of command output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,222 @@
+package myaudio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestParseFFmpegAudioInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		output string
+		want   AudioInfo
+	}{
+		{
+			name: "mp3 with cover art",
+			output: `Input #0, mp3, from 'dawn.mp3':
+  Metadata:
+    title           : Dawn chorus
+  Duration: 00:01:30.05, start: 0.025057, bitrate: 128 kb/s
+  Stream #0:0: Audio: mp3 (mp3float), 44100 Hz, stereo, fltp, 128 kb/s
+  Stream #0:1: Video: mjpeg (Baseline), yuvj420p(pc), 500x500, 90k tbr, 90k tbn (attached pic)
+At least one output file must be specified`,
+			want: AudioInfo{SampleRate: 44100, TotalSamples: 3971205, NumChannels: 2, BitDepth: 16},
+		},
+		{
+			name: "m4a from a phone",
+			output: `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'memo.m4a':
+  Duration: 00:00:12.50, start: 0.000000, bitrate: 66 kb/s
+  Stream #0:0[0x1](und): Audio: aac (LC) (mp4a / 0x6134706D), 48000 Hz, mono, fltp, 64 kb/s (default)`,
+			want: AudioInfo{SampleRate: 48000, TotalSamples: 600000, NumChannels: 1, BitDepth: 16},
+		},
+		{
+			name: "opus with a channel count",
+			output: `Input #0, ogg, from 'field.opus':
+  Duration: 01:00:00.00, start: 0.000000, bitrate: 32 kb/s
+  Stream #0:0: Audio: opus, 48000 Hz, 4 channels, fltp`,
+			want: AudioInfo{SampleRate: 48000, TotalSamples: 172800000, NumChannels: 4, BitDepth: 16},
+		},
+		{
+			name: "vorbis surround",
+			output: `Input #0, ogg, from 'surround.ogg':
+  Duration: 00:00:03.00, start: 0.000000, bitrate: 400 kb/s
+  Stream #0:0: Audio: vorbis, 32000 Hz, 5.1(side), fltp, 400 kb/s`,
+			want: AudioInfo{SampleRate: 32000, TotalSamples: 96000, NumChannels: 6, BitDepth: 16},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			info, err := parseFFmpegAudioInfo(tt.output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+
+	_, err := parseFFmpegAudioInfo("notes.txt: Invalid data found when processing input\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid data found")
+
+	_, err = parseFFmpegAudioInfo("  Duration: N/A, bitrate: N/A\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n")
+	assert.Error(t, err, "streams without a duration are rejected")
+}
+
+// pcm16 returns mono 16-bit little endian PCM with every sample set to value
+func pcm16(samples int, value int16) []byte {
+	var buf bytes.Buffer
+	for range samples {
+		_ = binary.Write(&buf, binary.LittleEndian, value)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPCM16StreamOverlap(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.BirdNET.Overlap = 1.5
+
+	// 7 seconds at the BirdNET sample rate
+	var chunks, eofChunks int
+	err := processPCM16Stream(bytes.NewReader(pcm16(7*conf.SampleRate, 16384)), settings, func(chunk []float32, isEOF bool) error {
+		if isEOF {
+			eofChunks++
+		} else {
+			chunks++
+		}
+		if chunk != nil {
+			assert.Len(t, chunk, 3*conf.SampleRate)
+			assert.InDelta(t, 0.5, chunk[conf.SampleRate], 0.001)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, chunks, "3 second chunks every 1.5 seconds")
+	assert.Equal(t, 1, eofChunks)
+}
+
+func TestProcessPCM16StreamNativeRate(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+
+	var total int
+	err := processPCM16Stream(bytes.NewReader(pcm16(6*conf.SampleRate+1, -32768)), settings, func(chunk []float32, isEOF bool) error {
+		if isEOF && chunk == nil {
+			return nil
+		}
+		total += len(chunk)
+		assert.InDelta(t, -1, chunk[0], 0.0001)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9*conf.SampleRate, total, "a trailing sample is padded to a full chunk")
+}
+
+func TestIsSupportedAudioFile(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"a.wav", "b.FLAC", "c.mp3", "d.ogg", "e.oga", "f.opus", "g.M4A", "h.aac"} {
+		assert.True(t, IsSupportedAudioFile(name), name)
+	}
+	for _, name := range []string{"a.txt", "b", "c.wav.processing", "d.csv"} {
+		assert.False(t, IsSupportedAudioFile(name), name)
+	}
+}
+
+// TestReadAudioFileBufferedFFmpeg decodes an Opus file encoded by FFmpeg
+func TestReadAudioFileBufferedFFmpeg(t *testing.T) {
+	t.Parallel()
+	ffmpegPath, err := exec.LookPath(conf.GetFfmpegBinaryName())
+	if err != nil {
+		t.Skip("FFmpeg is not available")
+	}
+
+	path := filepath.Join(t.TempDir(), "tone.opus")
+	out, err := exec.Command(ffmpegPath, "-hide_banner", "-loglevel", "error",
+		"-f", "lavfi", "-i", "sine=frequency=3000:sample_rate=24000:duration=5",
+		"-c:a", "libopus", path).CombinedOutput()
+	if err != nil {
+		t.Skipf("FFmpeg cannot encode Opus: %s", out)
+	}
+
+	info, err := GetAudioInfo(path)
+	require.NoError(t, err)
+	assert.Equal(t, 48000, info.SampleRate, "Opus decodes at 48 kHz")
+	assert.InDelta(t, 5*48000, info.TotalSamples, 2000)
+
+	settings := &conf.Settings{}
+	settings.Input.Path = path
+	settings.Realtime.Audio.FfmpegPath = ffmpegPath
+	var samples int
+	require.NoError(t, ReadAudioFileBuffered(settings, func(chunk []float32, isEOF bool) error {
+		samples += len(chunk)
+		return nil
+	}))
+	assert.Equal(t, 6*conf.SampleRate, samples)
+}
+
+// TestReadFFmpegBufferedResamplesInFFmpeg tests that FFmpeg is asked for PCM at the BirdNET sample
+// rate, so a 44.1 kHz recording is resampled as one stream rather than in blocks
+func TestReadFFmpegBufferedResamplesInFFmpeg(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	dir := t.TempDir()
+	argsPath := filepath.Join(dir, "args")
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\n" +
+		"case \"$*\" in\n" +
+		"*pipe:1*) echo \"$@\" > " + argsPath + "; head -c 576000 /dev/zero ;;\n" +
+		"*) echo '  Duration: 00:00:06.00, start: 0.000000, bitrate: 128 kb/s' >&2\n" +
+		"   echo '  Stream #0:0: Audio: mp3 (mp3float), 44100 Hz, mono, fltp, 128 kb/s' >&2; exit 1 ;;\n" +
+		"esac\n"
+	require.NoError(t, os.WriteFile(ffmpegPath, []byte(script), 0o755)) //nolint:gosec // test script must be executable
+	path := filepath.Join(dir, "dawn.mp3")
+	require.NoError(t, os.WriteFile(path, []byte("mp3"), 0o600))
+
+	settings := &conf.Settings{}
+	settings.Input.Path = path
+	settings.Realtime.Audio.FfmpegPath = ffmpegPath
+	var samples int
+	require.NoError(t, ReadAudioFileBuffered(settings, func(chunk []float32, isEOF bool) error {
+		samples += len(chunk)
+		return nil
+	}))
+	assert.Equal(t, 6*conf.SampleRate, samples, "6 seconds of 16-bit PCM at 48 kHz")
+
+	args, err := os.ReadFile(argsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "-ac 1 -ar 48000 ")
+}
+
+// TestReadFFmpegInfoTimeout stops an FFmpeg that does not finish reading the file
+//
+//nolint:paralleltest // modifies ffmpegProbeTimeout
+func TestReadFFmpegInfoTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	ffmpegPath := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(ffmpegPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755)) //nolint:gosec // test script must be executable
+
+	original := ffmpegProbeTimeout
+	ffmpegProbeTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { ffmpegProbeTimeout = original })
+
+	start := time.Now()
+	_, err := readFFmpegInfo("recording.mp3", ffmpegPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not read")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
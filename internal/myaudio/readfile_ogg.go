@@ -0,0 +1,114 @@
+package myaudio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jfreymuth/oggvorbis"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio/resample"
+)
+
+// isOggVorbis reports whether the file is an Ogg Vorbis stream that is decoded natively. Ogg
+// files holding Opus or FLAC are decoded with FFmpeg. The file is rewound before returning.
+func isOggVorbis(file *os.File) bool {
+	_, err := oggvorbis.GetFormat(file)
+	if _, seekErr := file.Seek(0, io.SeekStart); seekErr != nil {
+		return false
+	}
+	return err == nil
+}
+
+func readOggVorbisInfo(file *os.File) (AudioInfo, error) {
+	length, format, err := oggvorbis.GetLength(file)
+	if err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid Ogg Vorbis file: %w", err)
+	}
+
+	return AudioInfo{
+		SampleRate:   format.SampleRate,
+		TotalSamples: int(length),
+		NumChannels:  format.Channels,
+		BitDepth:     32, // Vorbis decodes to float samples
+	}, nil
+}
+
+// readOggVorbisBuffered decodes an Ogg Vorbis file, mixes it down to mono, resamples it to the
+// BirdNET sample rate and processes it in chunks like WAV and FLAC
+func readOggVorbisBuffered(file *os.File, settings *conf.Settings, callback AudioChunkCallback) error {
+	reader, err := oggvorbis.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("invalid Ogg Vorbis file: %w", err)
+	}
+	channels := reader.Channels()
+
+	if settings.Debug {
+		fmt.Println("Sample rate:", reader.SampleRate())
+		fmt.Println("Channels:", channels)
+	}
+
+	var resampler *resample.Stream
+	if reader.SampleRate() != conf.SampleRate {
+		resampler, err = resample.NewStream(reader.SampleRate(), conf.SampleRate)
+		if err != nil {
+			return err
+		}
+	}
+
+	step := int((3 - settings.BirdNET.Overlap) * conf.SampleRate)
+	secondsSamples := int(3 * conf.SampleRate)
+
+	// Decode one second at a time
+	buffer := make([]float32, reader.SampleRate()*channels)
+	var currentChunk []float32
+
+	for {
+		n, readErr := reader.Read(buffer)
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+
+		mono := make([]float32, n/channels)
+		for i := range mono {
+			var sum float32
+			for _, v := range buffer[i*channels : (i+1)*channels] {
+				sum += v
+			}
+			mono[i] = sum / float32(channels)
+		}
+
+		if resampler != nil {
+			mono = resampler.Process(mono)
+			if readErr != nil {
+				mono = append(mono, resampler.Flush()...)
+			}
+		}
+		currentChunk = append(currentChunk, mono...)
+
+		// Process complete 3-second chunks
+		for len(currentChunk) >= secondsSamples {
+			if err := callback(currentChunk[:secondsSamples], false); err != nil {
+				return err
+			}
+			currentChunk = currentChunk[step:]
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	// Handle the last chunk and signal EOF
+	if len(currentChunk) > 0 {
+		if len(currentChunk) < secondsSamples {
+			padding := make([]float32, secondsSamples-len(currentChunk))
+			currentChunk = append(currentChunk, padding...)
+		}
+		return callback(currentChunk, true)
+	}
+	// Signal EOF even if there's no final chunk to process
+	return callback(nil, true)
+}
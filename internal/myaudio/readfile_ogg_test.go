@@ -0,0 +1,70 @@
+package myaudio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// vorbisTestFile is one second of mono Ogg Vorbis at 44.1 kHz from the oggvorbis test data
+const vorbisTestFile = "testdata/vorbis-44100-mono.ogg"
+
+func TestGetAudioInfoOggVorbis(t *testing.T) {
+	t.Parallel()
+	info, err := GetAudioInfo(vorbisTestFile)
+	require.NoError(t, err)
+	assert.Equal(t, AudioInfo{SampleRate: 44100, TotalSamples: 44100, NumChannels: 1, BitDepth: 32}, info)
+}
+
+// TestReadAudioFileBufferedOggVorbis decodes Ogg Vorbis without FFmpeg and resamples it to the
+// BirdNET sample rate
+func TestReadAudioFileBufferedOggVorbis(t *testing.T) {
+	t.Parallel()
+	settings := &conf.Settings{}
+	settings.Input.Path = vorbisTestFile
+	settings.Realtime.Audio.FfmpegPath = "/nonexistent/ffmpeg"
+
+	var chunks [][]float32
+	require.NoError(t, ReadAudioFileBuffered(settings, func(chunk []float32, isEOF bool) error {
+		if chunk != nil {
+			chunks = append(chunks, append([]float32(nil), chunk...))
+		}
+		assert.True(t, isEOF, "one second of audio is a single padded chunk")
+		return nil
+	}))
+	require.Len(t, chunks, 1)
+	require.Len(t, chunks[0], 3*conf.SampleRate)
+
+	// The decoded second is resampled to 48000 samples followed by padding
+	var lastAudible int
+	for i, v := range chunks[0] {
+		if v != 0 {
+			lastAudible = i
+		}
+	}
+	assert.InDelta(t, conf.SampleRate, lastAudible, conf.SampleRate/100)
+}
+
+func TestIsOggVorbis(t *testing.T) {
+	t.Parallel()
+	file, err := os.Open(vorbisTestFile)
+	require.NoError(t, err)
+	defer file.Close()
+	assert.True(t, isOggVorbis(file))
+	pos, err := file.Seek(0, 1)
+	require.NoError(t, err)
+	assert.Zero(t, pos, "the file is rewound")
+
+	notVorbis, err := os.CreateTemp(t.TempDir(), "*.ogg")
+	require.NoError(t, err)
+	defer notVorbis.Close()
+	_, err = notVorbis.WriteString("OggS but not really")
+	require.NoError(t, err)
+	_, err = notVorbis.Seek(0, 0)
+	require.NoError(t, err)
+	assert.False(t, isOggVorbis(notVorbis))
+}
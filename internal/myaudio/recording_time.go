@@ -0,0 +1,181 @@
+package myaudio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Sources of a recording start time, from most to least reliable
+const (
+	RecordingTimeGUANO    = "GUANO metadata"
+	RecordingTimeBext     = "bext chunk"
+	RecordingTimeFileName = "file name"
+	RecordingTimeModified = "file modification time"
+)
+
+// recordingNameTimestamp matches the start time in recorder file names such as
+// 20240501_063000.WAV (AudioMoth) or SMU01234_20240501_063000.wav (Song Meter)
+var recordingNameTimestamp = regexp.MustCompile(`(\d{8})[_T-]?(\d{6})`)
+
+// audioMothName matches the default AudioMoth file name, the start time in UTC
+var audioMothName = regexp.MustCompile(`^\d{8}_\d{6}\.[A-Za-z0-9]+$`)
+
+// maxMetadataChunkSize limits the metadata chunks read into memory
+const maxMetadataChunkSize = 64 * 1024
+
+// guanoTimestampLayouts are the ISO 8601 forms used in GUANO Timestamp fields
+var guanoTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// RecordingStartTime returns when an audio recording started and where the time was found.
+// WAV files are searched for a GUANO Timestamp and a Broadcast WAV bext origination time,
+// then the file name for a YYYYMMDD_HHMMSS timestamp. Metadata times without a zone are local
+// time, file name times are parsed in loc as described for ParseRecordingTimeFromName.
+// As a last resort the start is the modification time minus the duration of the recording.
+func RecordingStartTime(path string, duration time.Duration, loc *time.Location) (time.Time, string, error) {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		if t, source, ok := wavRecordingTime(path); ok {
+			return t, source, nil
+		}
+	}
+	if t, ok := ParseRecordingTimeFromName(filepath.Base(path), loc); ok {
+		return t, RecordingTimeFileName, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return info.ModTime().Add(-duration), RecordingTimeModified, nil
+}
+
+// ParseRecordingTimeFromName parses a YYYYMMDD_HHMMSS timestamp in a file name in loc.
+// With a nil loc the zone follows the recorder: AudioMoth names, only the timestamp, are UTC
+// and other names such as those of Song Meters are local time.
+func ParseRecordingTimeFromName(name string, loc *time.Location) (time.Time, bool) {
+	m := recordingNameTimestamp.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	if loc == nil {
+		loc = time.Local
+		if audioMothName.MatchString(name) {
+			loc = time.UTC
+		}
+	}
+	t, err := time.ParseInLocation("20060102150405", m[1]+m[2], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// ParseRecordingTimezone parses the time zone of recording file names: empty for the zone
+// of the recorder, "Local" or an IANA name such as "UTC" or "Europe/Helsinki"
+func ParseRecordingTimezone(value string) (*time.Location, error) {
+	if value == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording time zone %q: %w", value, err)
+	}
+	return loc, nil
+}
+
+// wavRecordingTime walks the RIFF chunks of a WAV file for guan and bext chunks.
+// Sample data is skipped without reading it.
+func wavRecordingTime(path string) (time.Time, string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	defer func() { _ = f.Close() }()
+
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return time.Time{}, "", false
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return time.Time{}, "", false
+	}
+
+	var bextTime time.Time
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			break
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		skip := size + size%2 // Chunks are word aligned
+
+		if (id == "guan" || id == "bext") && size <= maxMetadataChunkSize {
+			data := make([]byte, size)
+			if _, err := io.ReadFull(f, data); err != nil {
+				break
+			}
+			skip -= size
+			if id == "guan" {
+				if t, ok := parseGUANOTimestamp(data); ok {
+					return t, RecordingTimeGUANO, true
+				}
+			} else {
+				bextTime, _ = parseBextOrigination(data)
+			}
+		}
+		if _, err := f.Seek(skip, io.SeekCurrent); err != nil {
+			break
+		}
+	}
+	return bextTime, RecordingTimeBext, !bextTime.IsZero()
+}
+
+// parseGUANOTimestamp returns the Timestamp field of GUANO metadata, "Key: Value" lines
+func parseGUANOTimestamp(data []byte) (time.Time, bool) {
+	for _, line := range strings.Split(string(bytes.TrimRight(data, "\x00")), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "Timestamp" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		for _, layout := range guanoTimestampLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+	return time.Time{}, false
+}
+
+// parseBextOrigination returns the origination date and time of a Broadcast WAV bext chunk.
+// The date is at byte 320 after the description and originator fields, the EBU standard
+// allows any separator in yyyy-mm-dd and hh:mm:ss.
+func parseBextOrigination(data []byte) (time.Time, bool) {
+	const dateOffset = 320
+	if len(data) < dateOffset+18 {
+		return time.Time{}, false
+	}
+	date := bytes.Clone(data[dateOffset : dateOffset+10])
+	clock := bytes.Clone(data[dateOffset+10 : dateOffset+18])
+	date[4], date[7] = '-', '-'
+	clock[2], clock[5] = ':', ':'
+
+	t, err := time.ParseInLocation(time.DateTime, string(date)+" "+string(clock), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
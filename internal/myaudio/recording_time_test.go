@@ -0,0 +1,150 @@
+package myaudio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestWAV writes a WAV file with the given extra chunks before the sample data
+func writeTestWAV(t *testing.T, path string, chunks map[string][]byte, order ...string) {
+	t.Helper()
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+
+	writeChunk := func(id string, data []byte) {
+		body.WriteString(id)
+		require.NoError(t, binary.Write(&body, binary.LittleEndian, uint32(len(data))))
+		body.Write(data)
+		if len(data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1)     // PCM
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 1)     // mono
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 48000) // sample rate
+	binary.LittleEndian.PutUint32(fmtChunk[8:], 96000) // byte rate
+	binary.LittleEndian.PutUint16(fmtChunk[12:], 2)    // block align
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)   // bits per sample
+	writeChunk("fmt ", fmtChunk)
+	for _, id := range order {
+		writeChunk(id, chunks[id])
+	}
+	writeChunk("data", make([]byte, 960))
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	require.NoError(t, binary.Write(&file, binary.LittleEndian, uint32(body.Len())))
+	file.Write(body.Bytes())
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o600))
+}
+
+// bextChunk returns a bext chunk with the given origination date and time
+func bextChunk(date, clock string) []byte {
+	data := make([]byte, 602)
+	copy(data[320:], date)
+	copy(data[330:], clock)
+	return data
+}
+
+func TestRecordingStartTimeGUANO(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "20230101_000000.wav")
+	guano := "GUANO|Version: 1.0\nMake: Open Acoustic Devices\nTimestamp: 2024-05-01T06:30:00+03:00\nSerial: 24F31901\n"
+	writeTestWAV(t, path, map[string][]byte{
+		"bext": bextChunk("2024-04-30", "12:00:00"),
+		"guan": []byte(guano),
+	}, "bext", "guan")
+
+	start, source, err := RecordingStartTime(path, time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, RecordingTimeGUANO, source, "GUANO is preferred over bext and the file name")
+	assert.True(t, start.Equal(time.Date(2024, 5, 1, 3, 30, 0, 0, time.UTC)), "got %s", start)
+}
+
+func TestRecordingStartTimeBext(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "recording.WAV")
+	writeTestWAV(t, path, map[string][]byte{
+		"guan": []byte("GUANO|Version: 1.0\nMake: Test\n"),
+		"bext": bextChunk("2024_05_01", "06.30.15"),
+	}, "guan", "bext")
+
+	start, source, err := RecordingStartTime(path, time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, RecordingTimeBext, source)
+	assert.Equal(t, time.Date(2024, 5, 1, 6, 30, 15, 0, time.Local), start)
+}
+
+func TestRecordingStartTimeFileName(t *testing.T) {
+	t.Parallel()
+	zone := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"20240501_063000.WAV", nil, time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)}, // AudioMoth writes UTC
+		{"SMU01234_20240501_063015.wav", nil, time.Date(2024, 5, 1, 6, 30, 15, 0, time.Local)},
+		{"garden-20240501T221500.flac", nil, time.Date(2024, 5, 1, 22, 15, 0, 0, time.Local)},
+		{"20240501_063000.WAV", zone, time.Date(2024, 5, 1, 6, 30, 0, 0, zone)},
+		{"SMU01234_20240501_063015.wav", time.UTC, time.Date(2024, 5, 1, 6, 30, 15, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+fmt.Sprint(tt.loc), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			writeTestWAV(t, path, nil)
+
+			start, source, err := RecordingStartTime(path, time.Minute, tt.loc)
+			require.NoError(t, err)
+			assert.Equal(t, RecordingTimeFileName, source)
+			assert.True(t, tt.want.Equal(start), "got %v, want %v", start, tt.want)
+			assert.Equal(t, tt.want.Location(), start.Location())
+		})
+	}
+}
+
+func TestParseRecordingTimezone(t *testing.T) {
+	t.Parallel()
+
+	loc, err := ParseRecordingTimezone("")
+	require.NoError(t, err)
+	assert.Nil(t, loc, "empty follows the recorder")
+
+	loc, err = ParseRecordingTimezone("UTC")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	loc, err = ParseRecordingTimezone("Local")
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	_, err = ParseRecordingTimezone("Mars/Olympus_Mons")
+	assert.Error(t, err)
+}
+
+func TestRecordingStartTimeModified(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "garden.wav")
+	writeTestWAV(t, path, nil)
+	modified := time.Date(2024, 5, 1, 7, 0, 0, 0, time.Local)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+
+	start, source, err := RecordingStartTime(path, 30*time.Minute, nil)
+	require.NoError(t, err)
+	assert.Equal(t, RecordingTimeModified, source)
+	assert.Equal(t, modified.Add(-30*time.Minute), start)
+
+	_, _, err = RecordingStartTime(filepath.Join(t.TempDir(), "missing.wav"), time.Minute, nil)
+	assert.Error(t, err)
+}
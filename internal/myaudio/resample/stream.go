@@ -0,0 +1,117 @@
+package resample
+
+import (
+	"fmt"
+
+	"github.com/tphakala/birdnet-go/internal/myaudio/equalizer"
+)
+
+// Anti-aliasing filter applied before downsampling, the cut-off is a fraction of the target
+// Nyquist frequency
+const (
+	antiAliasCutoff = 0.8
+	antiAliasQ      = 0.707
+	antiAliasPasses = 4
+)
+
+// Stream resamples audio that is decoded block by block. Unlike Cubic it keeps the input
+// samples around block edges and the filter state between blocks, so the output does not
+// depend on the block size, and it low-pass filters the input before downsampling.
+type Stream struct {
+	step    float64           // input samples per output sample
+	written int               // output samples returned so far
+	dropped int               // input samples removed from the front of buf
+	buf     []float32         // input samples not yet consumed
+	filter  *equalizer.Filter // anti-aliasing filter, nil when upsampling
+}
+
+// NewStream creates a resampler from the original sample rate to the target sample rate
+func NewStream(originalRate, targetRate int) (*Stream, error) {
+	if originalRate <= 0 || targetRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rates %d Hz to %d Hz", originalRate, targetRate)
+	}
+
+	s := &Stream{step: float64(originalRate) / float64(targetRate)}
+	if targetRate < originalRate {
+		filter, err := equalizer.NewLowPass(float64(originalRate), antiAliasCutoff*float64(targetRate)/2, antiAliasQ, antiAliasPasses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create anti-aliasing filter: %w", err)
+		}
+		s.filter = filter
+	}
+	return s, nil
+}
+
+// Process resamples the next block of input. Output samples whose interpolation needs input
+// of the following block are returned by the next call or by Flush.
+func (s *Stream) Process(input []float32) []float32 {
+	if s.filter != nil {
+		samples := make([]float64, len(input))
+		for i, v := range input {
+			samples[i] = float64(v)
+		}
+		s.filter.ApplyBatch(samples)
+		for _, v := range samples {
+			s.buf = append(s.buf, float32(v))
+		}
+	} else {
+		s.buf = append(s.buf, input...)
+	}
+
+	output := make([]float32, 0, int(float64(len(input))/s.step)+1)
+	for int(s.pos())+2 < len(s.buf) {
+		output = append(output, s.interpolate())
+	}
+
+	// Keep the sample before the next position for the interpolation
+	if drop := int(s.pos()) - 1; drop > 0 {
+		s.buf = append(s.buf[:0], s.buf[drop:]...)
+		s.dropped += drop
+	}
+	return output
+}
+
+// Flush returns the remaining output samples at the end of the input
+func (s *Stream) Flush() []float32 {
+	n := len(s.buf)
+	if n == 0 {
+		return nil
+	}
+
+	// Repeat the last sample so the interpolation can look past the end
+	last := s.buf[n-1]
+	s.buf = append(s.buf, last, last)
+
+	var output []float32
+	for int(s.pos()) < n {
+		output = append(output, s.interpolate())
+	}
+	s.buf = s.buf[:0]
+	s.written = 0
+	s.dropped = 0
+	return output
+}
+
+// pos returns the position of the next output sample in buf. It is computed from the sample
+// counts rather than accumulated, so rounding does not depend on the block size.
+func (s *Stream) pos() float64 {
+	return float64(s.written)*s.step - float64(s.dropped)
+}
+
+// interpolate returns the output sample at the current position and advances it. The caller
+// makes sure two samples after the position are buffered.
+func (s *Stream) interpolate() float32 {
+	pos := s.pos()
+	index := int(pos)
+	frac := float32(pos - float64(index))
+
+	y0 := s.buf[max(index-1, 0)]
+	y1, y2, y3 := s.buf[index], s.buf[index+1], s.buf[index+2]
+	mu2 := frac * frac
+	a0 := -0.5*y0 + 1.5*y1 - 1.5*y2 + 0.5*y3
+	a1 := y0 - 2.5*y1 + 2*y2 - 0.5*y3
+	a2 := -0.5*y0 + 0.5*y2
+
+	s.written++
+	return a0*frac*mu2 + a1*mu2 + a2*frac + y1
+}
@@ -0,0 +1,76 @@
+package resample
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sine returns a second of a sine tone at the given sample rate
+func sine(frequency float64, sampleRate int) []float32 {
+	samples := make([]float32, sampleRate)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+	}
+	return samples
+}
+
+// resampleInBlocks resamples input in blocks of the given size
+func resampleInBlocks(t *testing.T, input []float32, originalRate, targetRate, blockSize int) []float32 {
+	t.Helper()
+	s, err := NewStream(originalRate, targetRate)
+	require.NoError(t, err)
+
+	var output []float32
+	for start := 0; start < len(input); start += blockSize {
+		output = append(output, s.Process(input[start:min(start+blockSize, len(input))])...)
+	}
+	return append(output, s.Flush()...)
+}
+
+// TestStreamBlockSizeIndependent tests that the output does not depend on how the input is split
+func TestStreamBlockSizeIndependent(t *testing.T) {
+	t.Parallel()
+	input := sine(1000, 44100)
+
+	whole := resampleInBlocks(t, input, 44100, 48000, len(input))
+	assert.InDelta(t, 48000, len(whole), 1, "the whole input is resampled, including the tail")
+
+	for _, blockSize := range []int{1, 7, 1000, 44099} {
+		blocks := resampleInBlocks(t, input, 44100, 48000, blockSize)
+		require.Len(t, blocks, len(whole), "block size %d", blockSize)
+		for i := range whole {
+			require.InDelta(t, whole[i], blocks[i], 1e-6, "block size %d sample %d", blockSize, i)
+		}
+	}
+}
+
+// TestStreamAntiAliasing tests that tones above the target Nyquist frequency are filtered out
+// when downsampling
+func TestStreamAntiAliasing(t *testing.T) {
+	t.Parallel()
+
+	rms := func(samples []float32) float64 {
+		var sum float64
+		for _, v := range samples[len(samples)/4:] { // skip the filter settling
+			sum += float64(v) * float64(v)
+		}
+		return math.Sqrt(sum / float64(len(samples)-len(samples)/4))
+	}
+
+	kept := resampleInBlocks(t, sine(2000, 48000), 48000, 16000, 4800)
+	assert.Greater(t, rms(kept), 0.3, "a tone below the cut-off passes")
+
+	filtered := resampleInBlocks(t, sine(20000, 48000), 48000, 16000, 4800)
+	assert.Less(t, rms(filtered), 0.01, "a tone above the target Nyquist frequency is filtered")
+}
+
+func TestNewStreamInvalidRates(t *testing.T) {
+	t.Parallel()
+	_, err := NewStream(0, 48000)
+	require.Error(t, err)
+	_, err = NewStream(44100, -1)
+	require.Error(t, err)
+}
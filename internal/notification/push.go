@@ -0,0 +1,307 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// DefaultPushTimeout is the delivery timeout used when a channel does not set one
+	DefaultPushTimeout = 30 * time.Second
+	// DefaultPushRateLimitWindow is the rate limit window used when a channel does not set one
+	DefaultPushRateLimitWindow = time.Hour
+)
+
+var (
+	// pushDispatcher is the singleton push dispatcher
+	pushDispatcher *PushDispatcher
+	pushMu         sync.Mutex
+)
+
+// PushMessage is a notification rendered for delivery by a provider
+type PushMessage struct {
+	Title        string
+	Body         string
+	Notification *Notification
+}
+
+// Provider delivers rendered notifications to an external service
+type Provider interface {
+	// Type returns the provider type, e.g. "ntfy"
+	Type() string
+	// Send delivers a message, honoring the context deadline
+	Send(ctx context.Context, message *PushMessage) error
+}
+
+// pushChannel is a configured delivery channel with its filters and rate limiter
+type pushChannel struct {
+	name        string
+	provider    Provider
+	types       []string
+	priorities  []string
+	components  []string
+	rateLimiter *RateLimiter // nil when the channel is not rate limited
+	title       *template.Template
+	message     *template.Template
+	timeout     time.Duration
+}
+
+// PushDispatcher delivers notifications created by the service to the configured channels
+type PushDispatcher struct {
+	channels []*pushChannel
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+	cancel   context.CancelFunc
+}
+
+// NewPushDispatcher creates a dispatcher for the enabled channels in the settings
+func NewPushDispatcher(settings *conf.PushSettings, debug bool) (*PushDispatcher, error) {
+	d := &PushDispatcher{
+		logger: getFileLogger(debug),
+	}
+
+	for i := range settings.Channels {
+		channelSettings := &settings.Channels[i]
+		if !channelSettings.Enabled {
+			continue
+		}
+
+		channel, err := newPushChannel(channelSettings)
+		if err != nil {
+			return nil, err
+		}
+		d.channels = append(d.channels, channel)
+	}
+
+	return d, nil
+}
+
+// newPushChannel creates a channel and its provider from the channel settings
+func newPushChannel(settings *conf.PushChannelSettings) (*pushChannel, error) {
+	provider, err := NewProvider(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	name := settings.Name
+	if name == "" {
+		name = settings.Type
+	}
+
+	channel := &pushChannel{
+		name:       name,
+		provider:   provider,
+		types:      settings.Types,
+		priorities: settings.Priorities,
+		components: settings.Components,
+		timeout:    DefaultPushTimeout,
+	}
+
+	if settings.Timeout > 0 {
+		channel.timeout = time.Duration(settings.Timeout) * time.Second
+	}
+
+	if settings.RateLimit > 0 {
+		window := DefaultPushRateLimitWindow
+		if settings.RateLimitWindow > 0 {
+			window = time.Duration(settings.RateLimitWindow) * time.Minute
+		}
+		channel.rateLimiter = NewRateLimiter(window, settings.RateLimit)
+	}
+
+	if channel.title, err = parsePushTemplate(name, "title", settings.TitleTemplate, "{{.Title}}"); err != nil {
+		return nil, err
+	}
+	if channel.message, err = parsePushTemplate(name, "message", settings.MessageTemplate, "{{.Message}}"); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// parsePushTemplate parses a channel template, falling back to the default when empty
+func parsePushTemplate(channel, field, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+
+	tmpl, err := template.New(field).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_push_template").
+			Context("channel", channel).
+			Context("template", field).
+			Build()
+	}
+	return tmpl, nil
+}
+
+// Start subscribes to the service and delivers notifications until the service stops
+func (d *PushDispatcher) Start(service *Service) {
+	ch, subCtx := service.Subscribe()
+	ctx, cancel := context.WithCancel(subCtx)
+	d.cancel = cancel
+
+	d.logger.Info("push notification delivery started", "channels", len(d.channels))
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer service.Unsubscribe(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notif, ok := <-ch:
+				if !ok || notif == nil {
+					return
+				}
+				// Deliver concurrently so a slow channel does not fill the subscriber buffer
+				d.wg.Add(1)
+				go func() {
+					defer d.wg.Done()
+					d.Dispatch(ctx, notif)
+				}()
+			}
+		}
+	}()
+}
+
+// Stop stops delivery and waits for deliveries in progress
+func (d *PushDispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// Dispatch delivers a notification to every matching channel and waits for the
+// deliveries to finish. Failures are logged only, reporting them as notifications
+// would feed back into the dispatcher.
+func (d *PushDispatcher) Dispatch(ctx context.Context, notif *Notification) {
+	// Toasts are transient UI feedback, not worth a push message
+	if isToastNotification(notif) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, channel := range d.channels {
+		if !channel.matches(notif) {
+			continue
+		}
+		if channel.rateLimiter != nil && !channel.rateLimiter.Allow() {
+			d.logger.Warn("push notification rate limit exceeded, dropping notification",
+				"channel", channel.name,
+				"notification_id", notif.ID,
+				"type", notif.Type,
+				"priority", notif.Priority)
+			continue
+		}
+
+		wg.Add(1)
+		go func(channel *pushChannel) {
+			defer wg.Done()
+			if err := channel.deliver(ctx, notif); err != nil {
+				d.logger.Error("push notification delivery failed",
+					"channel", channel.name,
+					"provider", channel.provider.Type(),
+					"notification_id", notif.ID,
+					"error", err)
+				return
+			}
+			d.logger.Debug("push notification delivered",
+				"channel", channel.name,
+				"provider", channel.provider.Type(),
+				"notification_id", notif.ID)
+		}(channel)
+	}
+	wg.Wait()
+}
+
+// matches applies the type, priority and component filters of the channel
+func (c *pushChannel) matches(notif *Notification) bool {
+	return matchesFilter(c.types, string(notif.Type)) &&
+		matchesFilter(c.priorities, string(notif.Priority)) &&
+		matchesFilter(c.components, notif.Component)
+}
+
+// matchesFilter reports whether value is in the filter list, an empty list matches everything
+func matchesFilter(filter []string, value string) bool {
+	return len(filter) == 0 || slices.ContainsFunc(filter, func(f string) bool {
+		return strings.EqualFold(f, value)
+	})
+}
+
+// deliver renders the notification and sends it with the channel timeout
+func (c *pushChannel) deliver(ctx context.Context, notif *Notification) error {
+	message, err := c.render(notif)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.provider.Send(ctx, message)
+}
+
+// render executes the channel templates with the notification
+func (c *pushChannel) render(notif *Notification) (*PushMessage, error) {
+	var title, body bytes.Buffer
+	if err := c.title.Execute(&title, notif); err != nil {
+		return nil, fmt.Errorf("failed to render title template: %w", err)
+	}
+	if err := c.message.Execute(&body, notif); err != nil {
+		return nil, fmt.Errorf("failed to render message template: %w", err)
+	}
+
+	return &PushMessage{
+		Title:        strings.TrimSpace(title.String()),
+		Body:         strings.TrimSpace(body.String()),
+		Notification: notif,
+	}, nil
+}
+
+// InitializePushDelivery starts delivering notifications to the channels configured in
+// the settings. It does nothing when push delivery is disabled or no channel is enabled.
+func InitializePushDelivery(settings *conf.Settings) error {
+	if settings == nil || !settings.Notification.Push.Enabled {
+		return nil
+	}
+
+	service := GetService()
+	if service == nil {
+		return fmt.Errorf("notification service not initialized")
+	}
+
+	dispatcher, err := NewPushDispatcher(&settings.Notification.Push, settings.Debug)
+	if err != nil {
+		return err
+	}
+	if len(dispatcher.channels) == 0 {
+		logger.Info("push notification delivery enabled but no channels are enabled")
+		return nil
+	}
+
+	pushMu.Lock()
+	defer pushMu.Unlock()
+	if pushDispatcher != nil {
+		pushDispatcher.Stop()
+	}
+	dispatcher.Start(service)
+	pushDispatcher = dispatcher
+
+	return nil
+}
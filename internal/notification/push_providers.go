@@ -0,0 +1,340 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Push provider types
+const (
+	ProviderSMTP     = "smtp"
+	ProviderNtfy     = "ntfy"
+	ProviderGotify   = "gotify"
+	ProviderWebhook  = "webhook"
+	ProviderTelegram = "telegram"
+)
+
+// defaultTelegramAPIURL is the bot API used when a telegram channel does not set a URL
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// pushResponseLimit is how much of an error response body is kept
+const pushResponseLimit = 512
+
+// pushHTTPClient is shared by the HTTP based providers, timeouts come from the context
+var pushHTTPClient = &http.Client{}
+
+// NewProvider creates the provider for a channel
+func NewProvider(settings *conf.PushChannelSettings) (Provider, error) {
+	switch strings.ToLower(settings.Type) {
+	case ProviderSMTP:
+		return &SMTPProvider{settings: settings.SMTP}, nil
+	case ProviderNtfy:
+		return &NtfyProvider{url: settings.URL, token: settings.Token}, nil
+	case ProviderGotify:
+		return &GotifyProvider{url: strings.TrimSuffix(settings.URL, "/"), token: settings.Token}, nil
+	case ProviderWebhook:
+		return &WebhookProvider{url: settings.URL, headers: settings.Headers}, nil
+	case ProviderTelegram:
+		apiURL := strings.TrimSuffix(settings.URL, "/")
+		if apiURL == "" {
+			apiURL = defaultTelegramAPIURL
+		}
+		return &TelegramProvider{apiURL: apiURL, token: settings.Token, chatID: settings.ChatID}, nil
+	default:
+		return nil, errors.Newf("unknown push provider type %q", settings.Type).
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_push_provider").
+			Context("channel", settings.Name).
+			Build()
+	}
+}
+
+// NtfyProvider publishes messages to an ntfy topic
+type NtfyProvider struct {
+	url   string // topic URL, e.g. https://ntfy.sh/my-topic
+	token string
+}
+
+// Type returns the provider type
+func (p *NtfyProvider) Type() string { return ProviderNtfy }
+
+// Send publishes the message body with the title, priority and tags as headers
+func (p *NtfyProvider) Send(ctx context.Context, message *PushMessage) error {
+	headers := map[string]string{
+		"X-Title":    message.Title,
+		"X-Priority": strconv.Itoa(ntfyPriority(message.Notification.Priority)),
+		"X-Tags":     string(message.Notification.Type),
+	}
+	if p.token != "" {
+		headers["Authorization"] = "Bearer " + p.token
+	}
+	return postPush(ctx, p.Type(), p.url, "text/plain; charset=utf-8", []byte(message.Body), headers)
+}
+
+// ntfyPriority maps a notification priority to the ntfy 1-5 scale
+func ntfyPriority(priority Priority) int {
+	switch priority {
+	case PriorityCritical:
+		return 5
+	case PriorityHigh:
+		return 4
+	case PriorityLow:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// GotifyProvider posts messages to a Gotify server
+type GotifyProvider struct {
+	url   string // server URL without the /message path
+	token string // application token
+}
+
+// Type returns the provider type
+func (p *GotifyProvider) Type() string { return ProviderGotify }
+
+// Send posts the message to the Gotify message API
+func (p *GotifyProvider) Send(ctx context.Context, message *PushMessage) error {
+	body, err := json.Marshal(map[string]any{
+		"title":    message.Title,
+		"message":  message.Body,
+		"priority": gotifyPriority(message.Notification.Priority),
+	})
+	if err != nil {
+		return err
+	}
+	return postPush(ctx, p.Type(), p.url+"/message", "application/json", body, map[string]string{
+		"X-Gotify-Key": p.token,
+	})
+}
+
+// gotifyPriority maps a notification priority to the Gotify 0-10 scale
+func gotifyPriority(priority Priority) int {
+	switch priority {
+	case PriorityCritical:
+		return 10
+	case PriorityHigh:
+		return 8
+	case PriorityLow:
+		return 2
+	default:
+		return 5
+	}
+}
+
+// WebhookPayload is the JSON body posted by the webhook provider
+type WebhookPayload struct {
+	Title        string        `json:"title"`
+	Message      string        `json:"message"`
+	Notification *Notification `json:"notification"`
+}
+
+// WebhookProvider posts messages as JSON to an HTTP endpoint
+type WebhookProvider struct {
+	url     string
+	headers map[string]string
+}
+
+// Type returns the provider type
+func (p *WebhookProvider) Type() string { return ProviderWebhook }
+
+// Send posts the rendered message together with the notification
+func (p *WebhookProvider) Send(ctx context.Context, message *PushMessage) error {
+	body, err := json.Marshal(WebhookPayload{
+		Title:        message.Title,
+		Message:      message.Body,
+		Notification: message.Notification,
+	})
+	if err != nil {
+		return err
+	}
+	return postPush(ctx, p.Type(), p.url, "application/json", body, p.headers)
+}
+
+// TelegramProvider sends messages through a Telegram compatible bot API
+type TelegramProvider struct {
+	apiURL string
+	token  string
+	chatID string
+}
+
+// Type returns the provider type
+func (p *TelegramProvider) Type() string { return ProviderTelegram }
+
+// Send sends the title and message as a single text message
+func (p *TelegramProvider) Send(ctx context.Context, message *PushMessage) error {
+	text := message.Body
+	if message.Title != "" {
+		text = message.Title + "\n\n" + message.Body
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"chat_id": p.chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+	return postPush(ctx, p.Type(), p.apiURL+"/bot"+p.token+"/sendMessage", "application/json", body, nil)
+}
+
+// postPush posts a request and treats any non-2xx response as a failure. Errors never
+// include the URL, bot APIs carry the token in the path. Delivery errors are plain
+// errors, enhanced errors are reported as notifications and would be pushed again.
+func postPush(ctx context.Context, provider, target, contentType string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid %s URL", provider)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "BirdNET-Go")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := pushHTTPClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s request to %s failed: %w", provider, req.URL.Host, err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, pushResponseLimit))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, pushResponseLimit))
+		return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+// SMTPProvider sends messages as plain text email
+type SMTPProvider struct {
+	settings conf.SMTPSettings
+}
+
+// Type returns the provider type
+func (p *SMTPProvider) Type() string { return ProviderSMTP }
+
+// Send delivers the message to all recipients. Implicit TLS is used when configured,
+// otherwise the connection is upgraded with STARTTLS when the server offers it.
+func (p *SMTPProvider) Send(ctx context.Context, message *PushMessage) error {
+	port := p.settings.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(p.settings.Host, strconv.Itoa(port))
+
+	client, err := p.dial(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("smtp connection to %s failed: %w", addr, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := p.sendMessage(client, message); err != nil {
+		return fmt.Errorf("smtp delivery via %s failed: %w", addr, err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the mail server, the context deadline covers the whole session
+func (p *SMTPProvider) dial(ctx context.Context, addr string) (*smtp.Client, error) {
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if p.settings.TLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: p.settings.Host, MinVersion: tls.VersionTLS12}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, p.settings.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// sendMessage runs the SMTP transaction on a connected client
+func (p *SMTPProvider) sendMessage(client *smtp.Client, message *PushMessage) error {
+	if !p.settings.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: p.settings.Host, MinVersion: tls.VersionTLS12}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.settings.Username, p.settings.Password, p.settings.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(p.settings.From); err != nil {
+		return err
+	}
+	for _, to := range p.settings.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildEmail(p.settings.From, p.settings.To, message, time.Now())); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// buildEmail formats a plain text email with an encoded subject
+func buildEmail(from string, to []string, message *PushMessage, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	// Normalize line endings as required by SMTP
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
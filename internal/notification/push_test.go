@@ -0,0 +1,363 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// recordingProvider records the messages it is asked to send
+type recordingProvider struct {
+	mu       sync.Mutex
+	messages []*PushMessage
+	err      error
+}
+
+func (p *recordingProvider) Type() string { return "recording" }
+
+func (p *recordingProvider) Send(_ context.Context, message *PushMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, message)
+	return p.err
+}
+
+func (p *recordingProvider) sent() []*PushMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*PushMessage(nil), p.messages...)
+}
+
+// newTestChannel creates a channel from settings and swaps in a recording provider
+func newTestChannel(t *testing.T, settings conf.PushChannelSettings) (*pushChannel, *recordingProvider) {
+	t.Helper()
+	if settings.Type == "" {
+		settings.Type = ProviderWebhook
+	}
+	channel, err := newPushChannel(&settings)
+	require.NoError(t, err)
+
+	provider := &recordingProvider{}
+	channel.provider = provider
+	return channel, provider
+}
+
+func TestPushDispatcher_Filters(t *testing.T) {
+	t.Parallel()
+
+	all, allProvider := newTestChannel(t, conf.PushChannelSettings{Name: "all"})
+	critical, criticalProvider := newTestChannel(t, conf.PushChannelSettings{
+		Name:       "critical",
+		Priorities: []string{"CRITICAL"},
+		Components: []string{"system"},
+	})
+	detections, detectionsProvider := newTestChannel(t, conf.PushChannelSettings{
+		Name:  "detections",
+		Types: []string{string(TypeDetection)},
+	})
+
+	dispatcher := &PushDispatcher{
+		channels: []*pushChannel{all, critical, detections},
+		logger:   getFileLogger(false),
+	}
+
+	ctx := context.Background()
+	dispatcher.Dispatch(ctx, NewNotification(TypeError, PriorityCritical, "Disk full", "No space left").WithComponent("system"))
+	dispatcher.Dispatch(ctx, NewNotification(TypeError, PriorityCritical, "Stream down", "RTSP failed").WithComponent("rtsp"))
+	dispatcher.Dispatch(ctx, NewNotification(TypeDetection, PriorityLow, "New species", "Eurasian Wren"))
+	dispatcher.Dispatch(ctx, NewToast("Settings saved", ToastTypeSuccess).ToNotification())
+
+	assert.Len(t, allProvider.sent(), 3, "toasts must not be delivered")
+	require.Len(t, criticalProvider.sent(), 1)
+	assert.Equal(t, "Disk full", criticalProvider.sent()[0].Title)
+	require.Len(t, detectionsProvider.sent(), 1)
+	assert.Equal(t, "Eurasian Wren", detectionsProvider.sent()[0].Body)
+}
+
+func TestPushDispatcher_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	channel, provider := newTestChannel(t, conf.PushChannelSettings{Name: "limited", RateLimit: 2})
+	require.NotNil(t, channel.rateLimiter)
+
+	dispatcher := &PushDispatcher{channels: []*pushChannel{channel}, logger: getFileLogger(false)}
+	for i := 0; i < 5; i++ {
+		dispatcher.Dispatch(context.Background(), NewNotification(TypeWarning, PriorityHigh, "Warning", strconv.Itoa(i)))
+	}
+
+	assert.Len(t, provider.sent(), 2)
+}
+
+func TestPushChannel_Templates(t *testing.T) {
+	t.Parallel()
+
+	channel, _ := newTestChannel(t, conf.PushChannelSettings{
+		TitleTemplate:   "[{{.Priority}}] {{.Title}}",
+		MessageTemplate: "{{.Message}}{{with .Component}} ({{.}}){{end}}{{with index .Metadata \"species\"}} - {{.}}{{end}}",
+	})
+
+	notif := NewNotification(TypeDetection, PriorityHigh, "New species", "First detection today").
+		WithComponent("detection").
+		WithMetadata("species", "Eurasian Wren")
+	message, err := channel.render(notif)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[high] New species", message.Title)
+	assert.Equal(t, "First detection today (detection) - Eurasian Wren", message.Body)
+	assert.Same(t, notif, message.Notification)
+
+	_, err = newPushChannel(&conf.PushChannelSettings{Type: ProviderWebhook, TitleTemplate: "{{.Title"})
+	require.Error(t, err, "invalid templates must be rejected when the channel is created")
+}
+
+func TestNewProvider_UnknownType(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProvider(&conf.PushChannelSettings{Type: "pager"})
+	require.Error(t, err)
+}
+
+// capturedRequest is a request received by a test server
+type capturedRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newCaptureServer starts a server that records requests and replies with status
+func newCaptureServer(t *testing.T, status int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	requests := make(chan capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{path: r.URL.Path, header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("response body"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestHTTPProviders(t *testing.T) {
+	t.Parallel()
+
+	notif := NewNotification(TypeError, PriorityCritical, "Disk full", "No space left").WithComponent("system")
+	message := &PushMessage{Title: "Disk full", Body: "No space left", Notification: notif}
+
+	t.Run("ntfy", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newCaptureServer(t, http.StatusOK)
+		provider, err := NewProvider(&conf.PushChannelSettings{Type: "ntfy", URL: server.URL + "/birdnet", Token: "tk_secret"})
+		require.NoError(t, err)
+		require.NoError(t, provider.Send(context.Background(), message))
+
+		req := <-requests
+		assert.Equal(t, "/birdnet", req.path)
+		assert.Equal(t, "Disk full", req.header.Get("X-Title"))
+		assert.Equal(t, "5", req.header.Get("X-Priority"))
+		assert.Equal(t, "error", req.header.Get("X-Tags"))
+		assert.Equal(t, "Bearer tk_secret", req.header.Get("Authorization"))
+		assert.Equal(t, "No space left", string(req.body))
+	})
+
+	t.Run("gotify", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newCaptureServer(t, http.StatusOK)
+		provider, err := NewProvider(&conf.PushChannelSettings{Type: "gotify", URL: server.URL + "/", Token: "app-token"})
+		require.NoError(t, err)
+		require.NoError(t, provider.Send(context.Background(), message))
+
+		req := <-requests
+		assert.Equal(t, "/message", req.path)
+		assert.Equal(t, "app-token", req.header.Get("X-Gotify-Key"))
+		assert.JSONEq(t, `{"title":"Disk full","message":"No space left","priority":10}`, string(req.body))
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newCaptureServer(t, http.StatusNoContent)
+		provider, err := NewProvider(&conf.PushChannelSettings{
+			Type:    "webhook",
+			URL:     server.URL + "/hook",
+			Headers: map[string]string{"Authorization": "Bearer abc"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, provider.Send(context.Background(), message))
+
+		req := <-requests
+		assert.Equal(t, "Bearer abc", req.header.Get("Authorization"))
+		var payload WebhookPayload
+		require.NoError(t, json.Unmarshal(req.body, &payload))
+		assert.Equal(t, "Disk full", payload.Title)
+		assert.Equal(t, "No space left", payload.Message)
+		require.NotNil(t, payload.Notification)
+		assert.Equal(t, notif.ID, payload.Notification.ID)
+		assert.Equal(t, "system", payload.Notification.Component)
+	})
+
+	t.Run("telegram", func(t *testing.T) {
+		t.Parallel()
+		server, requests := newCaptureServer(t, http.StatusOK)
+		provider, err := NewProvider(&conf.PushChannelSettings{Type: "telegram", URL: server.URL, Token: "123:abc", ChatID: "-100"})
+		require.NoError(t, err)
+		require.NoError(t, provider.Send(context.Background(), message))
+
+		req := <-requests
+		assert.Equal(t, "/bot123:abc/sendMessage", req.path)
+		assert.JSONEq(t, `{"chat_id":"-100","text":"Disk full\n\nNo space left"}`, string(req.body))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		t.Parallel()
+		server, _ := newCaptureServer(t, http.StatusUnauthorized)
+		provider, err := NewProvider(&conf.PushChannelSettings{Type: "telegram", URL: server.URL, Token: "123:secret", ChatID: "1"})
+		require.NoError(t, err)
+
+		err = provider.Send(context.Background(), message)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+		assert.NotContains(t, err.Error(), "secret", "errors must not leak the bot token")
+	})
+}
+
+// runFakeSMTPServer accepts a single SMTP session and returns the received envelope and data
+func runFakeSMTPServer(t *testing.T) (addr string, result <-chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	lines := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		var received []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM"), strings.HasPrefix(command, "RCPT TO"):
+				received = append(received, line)
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					data, err := reader.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					received = append(received, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				lines <- received
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+		lines <- received
+	}()
+
+	return listener.Addr().String(), lines
+}
+
+func TestSMTPProvider_Send(t *testing.T) {
+	t.Parallel()
+
+	addr, result := runFakeSMTPServer(t)
+	host, portText, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portText)
+	require.NoError(t, err)
+
+	provider, err := NewProvider(&conf.PushChannelSettings{
+		Type: "smtp",
+		SMTP: conf.SMTPSettings{
+			Host: host,
+			Port: port,
+			From: "birdnet@example.com",
+			To:   []string{"alice@example.com", "bob@example.com"},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	message := &PushMessage{Title: "Disk full", Body: "No space left", Notification: NewNotification(TypeError, PriorityCritical, "Disk full", "No space left")}
+	require.NoError(t, provider.Send(ctx, message))
+
+	received := <-result
+	assert.Contains(t, received, "MAIL FROM:<birdnet@example.com>")
+	assert.Contains(t, received, "RCPT TO:<alice@example.com>")
+	assert.Contains(t, received, "RCPT TO:<bob@example.com>")
+	assert.Contains(t, received, "Subject: Disk full")
+	assert.Contains(t, received, "No space left")
+}
+
+func TestBuildEmail(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)
+	email := string(buildEmail("birdnet@example.com", []string{"a@example.com", "b@example.com"},
+		&PushMessage{Title: "Käki havaittu", Body: "line one\nline two"}, date))
+
+	headers, body, found := strings.Cut(email, "\r\n\r\n")
+	require.True(t, found, "headers and body must be separated by an empty line")
+	assert.Contains(t, headers, "From: birdnet@example.com\r\n")
+	assert.Contains(t, headers, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, headers, "Subject: =?utf-8?q?K=C3=A4ki_havaittu?=\r\n")
+	assert.Contains(t, headers, "Date: Wed, 01 May 2024 06:30:00 +0000\r\n")
+	assert.Equal(t, "line one\r\nline two\r\n", body)
+}
+
+func TestPushDispatcher_Service(t *testing.T) {
+	t.Parallel()
+
+	service := NewService(&ServiceConfig{
+		MaxNotifications:   100,
+		CleanupInterval:    time.Hour,
+		RateLimitWindow:    time.Minute,
+		RateLimitMaxEvents: 60,
+	})
+	defer service.Stop()
+
+	channel, provider := newTestChannel(t, conf.PushChannelSettings{Name: "all"})
+	dispatcher := &PushDispatcher{channels: []*pushChannel{channel}, logger: getFileLogger(false)}
+	dispatcher.Start(service)
+
+	_, err := service.Create(TypeWarning, PriorityHigh, "Stream down", "RTSP source disconnected")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(provider.sent()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	dispatcher.Stop()
+	assert.Equal(t, "Stream down", provider.sent()[0].Title)
+}
@@ -30,6 +30,12 @@ type BirdNETMetrics struct {
 	ActiveProcessingGauge prometheus.Gauge
 	ModelLoadedGauge      prometheus.Gauge
 
+	// Interpreter pool metrics
+	InterpreterPoolSize     prometheus.Gauge
+	InterpretersBusy        prometheus.Gauge
+	InterpreterQueueDepth   *prometheus.GaugeVec
+	InterpreterWaitDuration *prometheus.HistogramVec
+
 	registry *prometheus.Registry
 }
 
@@ -149,6 +155,38 @@ func (m *BirdNETMetrics) initMetrics() error {
 		},
 	)
 
+	// Interpreter pool metrics
+	m.InterpreterPoolSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "birdnet_interpreter_pool_size",
+			Help: "Number of analysis interpreters available for parallel inference",
+		},
+	)
+
+	m.InterpretersBusy = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "birdnet_interpreters_busy",
+			Help: "Number of analysis interpreters currently running a prediction",
+		},
+	)
+
+	m.InterpreterQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "birdnet_interpreter_queue_depth",
+			Help: "Number of predictions waiting for a free interpreter partitioned by audio source",
+		},
+		[]string{"source"},
+	)
+
+	m.InterpreterWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "birdnet_interpreter_wait_duration_seconds",
+			Help:    "Time a prediction waited for a free interpreter partitioned by audio source",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12), // 1ms to ~4s
+		},
+		[]string{"source"},
+	)
+
 	return nil
 }
 
@@ -206,6 +244,26 @@ func (m *BirdNETMetrics) SetActiveProcessing(count float64) {
 	m.ActiveProcessingGauge.Set(count)
 }
 
+// SetInterpreterPoolSize sets the number of analysis interpreters in the pool
+func (m *BirdNETMetrics) SetInterpreterPoolSize(count int) {
+	m.InterpreterPoolSize.Set(float64(count))
+}
+
+// SetInterpretersBusy sets the number of interpreters currently running a prediction
+func (m *BirdNETMetrics) SetInterpretersBusy(count int) {
+	m.InterpretersBusy.Set(float64(count))
+}
+
+// SetInterpreterQueueDepth sets the number of predictions of a source waiting for an interpreter
+func (m *BirdNETMetrics) SetInterpreterQueueDepth(source string, depth int) {
+	m.InterpreterQueueDepth.WithLabelValues(source).Set(float64(depth))
+}
+
+// RecordInterpreterWait records how long a prediction of a source waited for an interpreter
+func (m *BirdNETMetrics) RecordInterpreterWait(source string, durationSeconds float64) {
+	m.InterpreterWaitDuration.WithLabelValues(source).Observe(durationSeconds)
+}
+
 // categorizeError returns a category string for the error type using enhanced error categories
 func categorizeError(err error) string {
 	if err == nil {
@@ -267,6 +325,12 @@ func (m *BirdNETMetrics) Describe(ch chan<- *prometheus.Desc) {
 	// State gauges
 	ch <- m.ActiveProcessingGauge.Desc()
 	ch <- m.ModelLoadedGauge.Desc()
+
+	// Interpreter pool metrics
+	ch <- m.InterpreterPoolSize.Desc()
+	ch <- m.InterpretersBusy.Desc()
+	m.InterpreterQueueDepth.Describe(ch)
+	m.InterpreterWaitDuration.Describe(ch)
 }
 
 // Collect implements the prometheus.Collector interface.
@@ -289,6 +353,12 @@ func (m *BirdNETMetrics) Collect(ch chan<- prometheus.Metric) {
 	// State gauges
 	ch <- m.ActiveProcessingGauge
 	ch <- m.ModelLoadedGauge
+
+	// Interpreter pool metrics
+	ch <- m.InterpreterPoolSize
+	ch <- m.InterpretersBusy
+	m.InterpreterQueueDepth.Collect(ch)
+	m.InterpreterWaitDuration.Collect(ch)
 }
 
 // RecordOperation implements the Recorder interface.
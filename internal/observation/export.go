@@ -0,0 +1,412 @@
+// export.go: streaming writers for exporting stored detections
+package observation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// ExportFormat identifies a detection export format
+type ExportFormat string
+
+// Supported detection export formats
+const (
+	ExportCSV        ExportFormat = "csv"   // Comma separated values with a header row
+	ExportJSONLines  ExportFormat = "jsonl" // One JSON object per line
+	ExportDarwinCore ExportFormat = "dwca"  // Darwin Core Archive occurrence core
+	ExportRaven      ExportFormat = "raven" // Raven Pro selection table
+)
+
+// ParseExportFormat validates an export format name
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(name)); format {
+	case ExportCSV, ExportJSONLines, ExportDarwinCore, ExportRaven:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q, use csv, jsonl, dwca or raven", name)
+	}
+}
+
+// FileName returns the name of the data file of the format
+func (f ExportFormat) FileName() string {
+	switch f {
+	case ExportDarwinCore:
+		return "occurrence.txt"
+	case ExportRaven:
+		return "detections.selections.txt"
+	case ExportJSONLines:
+		return "detections.jsonl"
+	default:
+		return "detections.csv"
+	}
+}
+
+// ContentType returns the MIME type of the data file of the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportJSONLines:
+		return "application/x-ndjson"
+	default:
+		return "text/tab-separated-values; charset=utf-8"
+	}
+}
+
+// ExportWriter writes detections in an export format
+type ExportWriter interface {
+	// WriteNote writes a single detection. clipPath is the path of the clip inside an
+	// export archive, empty when clips are not bundled.
+	WriteNote(note *datastore.Note, clipPath string) error
+	// Flush writes buffered data to the underlying writer
+	Flush() error
+}
+
+// NewExportWriter creates a writer for the format and writes the header of the format
+func NewExportWriter(format ExportFormat, w io.Writer) (ExportWriter, error) {
+	var ew ExportWriter
+	switch format {
+	case ExportCSV:
+		ew = &csvExportWriter{w: csv.NewWriter(w)}
+	case ExportJSONLines:
+		bw := bufio.NewWriter(w)
+		ew = &jsonLinesExportWriter{w: bw, enc: json.NewEncoder(bw)}
+	case ExportDarwinCore:
+		ew = &tableExportWriter{w: bufio.NewWriter(w), row: darwinCoreRow, header: darwinCoreHeader()}
+	case ExportRaven:
+		ew = &tableExportWriter{w: bufio.NewWriter(w), row: ravenRow, header: ravenHeader}
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	if hw, ok := ew.(interface{ writeHeader() error }); ok {
+		if err := hw.writeHeader(); err != nil {
+			return nil, fmt.Errorf("failed to write export header: %w", err)
+		}
+	}
+	return ew, nil
+}
+
+// ExportRecord is the detection representation of the CSV and JSON Lines exports
+type ExportRecord struct {
+	ID             uint    `json:"id"`
+	Date           string  `json:"date"`
+	Time           string  `json:"time"`
+	BeginTime      string  `json:"begin_time"`
+	EndTime        string  `json:"end_time"`
+	ScientificName string  `json:"scientific_name"`
+	CommonName     string  `json:"common_name"`
+	SpeciesCode    string  `json:"species_code"`
+	Confidence     float64 `json:"confidence"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	SourceNode     string  `json:"source_node"`
+	ClipName       string  `json:"clip_name,omitempty"`
+	Verified       string  `json:"verified,omitempty"` // correct or false_positive when reviewed
+	Locked         bool    `json:"locked"`
+}
+
+// exportCSVHeader lists the CSV columns in the order of newExportRecord
+var exportCSVHeader = []string{
+	"id", "date", "time", "begin_time", "end_time", "scientific_name", "common_name", "species_code",
+	"confidence", "latitude", "longitude", "source_node", "clip_name", "verified", "locked",
+}
+
+// newExportRecord converts a note, clipPath replaces the clip name when clips are bundled
+func newExportRecord(note *datastore.Note, clipPath string) ExportRecord {
+	clip := note.ClipName
+	if clipPath != "" {
+		clip = clipPath
+	}
+	begin, end := noteTimes(note)
+	return ExportRecord{
+		ID:             note.ID,
+		Date:           note.Date,
+		Time:           note.Time,
+		BeginTime:      begin.Format(time.RFC3339),
+		EndTime:        end.Format(time.RFC3339),
+		ScientificName: note.ScientificName,
+		CommonName:     note.CommonName,
+		SpeciesCode:    note.SpeciesCode,
+		Confidence:     note.Confidence,
+		Latitude:       note.Latitude,
+		Longitude:      note.Longitude,
+		SourceNode:     note.SourceNode,
+		ClipName:       clip,
+		Verified:       noteVerified(note),
+		Locked:         note.Locked || note.Lock != nil,
+	}
+}
+
+// noteTimes returns the begin and end time of a detection. Notes without a stored begin
+// time fall back to their date and time columns in the local time zone.
+func noteTimes(note *datastore.Note) (begin, end time.Time) {
+	begin, end = note.BeginTime, note.EndTime
+	if begin.IsZero() {
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", note.Date+" "+note.Time, time.Local); err == nil {
+			begin = t
+		}
+	}
+	if end.IsZero() || end.Before(begin) {
+		end = begin
+	}
+	return begin, end
+}
+
+// noteVerified returns the review status of a note
+func noteVerified(note *datastore.Note) string {
+	if note.Verified != "" {
+		return note.Verified
+	}
+	if note.Review != nil {
+		return note.Review.Verified
+	}
+	return ""
+}
+
+// csvExportWriter writes ExportRecords as CSV
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) writeHeader() error {
+	return c.w.Write(exportCSVHeader)
+}
+
+func (c *csvExportWriter) WriteNote(note *datastore.Note, clipPath string) error {
+	r := newExportRecord(note, clipPath)
+	return c.w.Write([]string{
+		strconv.FormatUint(uint64(r.ID), 10), r.Date, r.Time, r.BeginTime, r.EndTime,
+		r.ScientificName, r.CommonName, r.SpeciesCode,
+		strconv.FormatFloat(r.Confidence, 'f', 4, 64),
+		strconv.FormatFloat(r.Latitude, 'f', -1, 64), strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+		r.SourceNode, r.ClipName, r.Verified, strconv.FormatBool(r.Locked),
+	})
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonLinesExportWriter writes one ExportRecord per line
+type jsonLinesExportWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonLinesExportWriter) WriteNote(note *datastore.Note, clipPath string) error {
+	return j.enc.Encode(newExportRecord(note, clipPath))
+}
+
+func (j *jsonLinesExportWriter) Flush() error {
+	return j.w.Flush()
+}
+
+// tableExportWriter writes tab separated tables with a header row
+type tableExportWriter struct {
+	w      *bufio.Writer
+	header []string
+	row    func(n int, note *datastore.Note, clipPath string) []string
+	rows   int
+}
+
+func (t *tableExportWriter) writeHeader() error {
+	return t.writeRow(t.header)
+}
+
+func (t *tableExportWriter) WriteNote(note *datastore.Note, clipPath string) error {
+	t.rows++
+	return t.writeRow(t.row(t.rows, note, clipPath))
+}
+
+func (t *tableExportWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// writeRow writes a row, tabs and line breaks inside values would break the table
+func (t *tableExportWriter) writeRow(values []string) error {
+	for i, v := range values {
+		if i > 0 {
+			if err := t.w.WriteByte('\t'); err != nil {
+				return err
+			}
+		}
+		if _, err := t.w.WriteString(tableValueReplacer.Replace(v)); err != nil {
+			return err
+		}
+	}
+	return t.w.WriteByte('\n')
+}
+
+// tableValueReplacer replaces the separators of tab separated tables inside values
+var tableValueReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// ravenHeader lists the selection table columns. Begin and end times are seconds since
+// local midnight of the detection day, Begin Date and Begin Clock Time give the date.
+var ravenHeader = []string{
+	"Selection", "View", "Channel", "Begin File", "Begin Time (s)", "End Time (s)", "Low Freq (Hz)", "High Freq (Hz)",
+	"Begin Date", "Begin Clock Time", "Species Code", "Common Name", "Scientific Name", "Confidence",
+}
+
+// ravenRow formats a detection as a selection table row, low and high frequencies
+// match the file analysis output
+func ravenRow(n int, note *datastore.Note, clipPath string) []string {
+	begin, end := noteTimes(note)
+	begin, end = begin.Local(), end.Local()
+	midnight := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, begin.Location())
+
+	file := filepath.Base(note.ClipName)
+	if clipPath != "" {
+		file = clipPath
+	} else if note.ClipName == "" {
+		file = ""
+	}
+
+	return []string{
+		strconv.Itoa(n), "Spectrogram 1", "1", file,
+		strconv.FormatFloat(begin.Sub(midnight).Seconds(), 'f', 3, 64),
+		strconv.FormatFloat(end.Sub(midnight).Seconds(), 'f', 3, 64),
+		"0", "15000",
+		begin.Format("2006/01/02"), begin.Format("15:04:05.000"),
+		note.SpeciesCode, note.CommonName, note.ScientificName,
+		strconv.FormatFloat(note.Confidence, 'f', 4, 64),
+	}
+}
+
+// darwinCoreTerms lists the occurrence.txt columns with their Darwin Core term URIs. The
+// first column is the record identifier.
+var darwinCoreTerms = []struct {
+	name, uri string
+}{
+	{"occurrenceID", "http://rs.tdwg.org/dwc/terms/occurrenceID"},
+	{"basisOfRecord", "http://rs.tdwg.org/dwc/terms/basisOfRecord"},
+	{"eventDate", "http://rs.tdwg.org/dwc/terms/eventDate"},
+	{"scientificName", "http://rs.tdwg.org/dwc/terms/scientificName"},
+	{"vernacularName", "http://rs.tdwg.org/dwc/terms/vernacularName"},
+	{"occurrenceStatus", "http://rs.tdwg.org/dwc/terms/occurrenceStatus"},
+	{"decimalLatitude", "http://rs.tdwg.org/dwc/terms/decimalLatitude"},
+	{"decimalLongitude", "http://rs.tdwg.org/dwc/terms/decimalLongitude"},
+	{"geodeticDatum", "http://rs.tdwg.org/dwc/terms/geodeticDatum"},
+	{"identifiedBy", "http://rs.tdwg.org/dwc/terms/identifiedBy"},
+	{"identificationVerificationStatus", "http://rs.tdwg.org/dwc/terms/identificationVerificationStatus"},
+	{"identificationRemarks", "http://rs.tdwg.org/dwc/terms/identificationRemarks"},
+	{"recordedBy", "http://rs.tdwg.org/dwc/terms/recordedBy"},
+	{"associatedMedia", "http://rs.tdwg.org/dwc/terms/associatedMedia"},
+}
+
+// darwinCoreHeader returns the header row of occurrence.txt
+func darwinCoreHeader() []string {
+	header := make([]string, len(darwinCoreTerms))
+	for i, term := range darwinCoreTerms {
+		header[i] = term.name
+	}
+	return header
+}
+
+// darwinCoreRow formats a detection as an occurrence in the order of darwinCoreTerms
+func darwinCoreRow(_ int, note *datastore.Note, clipPath string) []string {
+	begin, end := noteTimes(note)
+	eventDate := begin.Format(time.RFC3339)
+	if end.After(begin) {
+		eventDate += "/" + end.Format(time.RFC3339)
+	}
+
+	node := note.SourceNode
+	if node == "" {
+		node = "BirdNET-Go"
+	}
+
+	status := "unverified"
+	switch noteVerified(note) {
+	case "correct":
+		status = "verified"
+	case "false_positive":
+		status = "rejected"
+	}
+
+	var latitude, longitude, datum string
+	if note.Latitude != 0 || note.Longitude != 0 {
+		latitude = strconv.FormatFloat(note.Latitude, 'f', -1, 64)
+		longitude = strconv.FormatFloat(note.Longitude, 'f', -1, 64)
+		datum = "WGS84"
+	}
+
+	return []string{
+		fmt.Sprintf("urn:birdnet-go:%s:%d", strings.ReplaceAll(node, ":", "_"), note.ID),
+		"MachineObservation",
+		eventDate,
+		note.ScientificName,
+		note.CommonName,
+		"present",
+		latitude,
+		longitude,
+		datum,
+		"BirdNET",
+		status,
+		"BirdNET confidence " + strconv.FormatFloat(note.Confidence, 'f', 4, 64),
+		node,
+		clipPath,
+	}
+}
+
+// darwinCoreMeta is the meta.xml descriptor of a Darwin Core Archive
+type darwinCoreMeta struct {
+	XMLName xml.Name `xml:"archive"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Core    struct {
+		Encoding           string `xml:"encoding,attr"`
+		FieldsTerminatedBy string `xml:"fieldsTerminatedBy,attr"`
+		LinesTerminatedBy  string `xml:"linesTerminatedBy,attr"`
+		FieldsEnclosedBy   string `xml:"fieldsEnclosedBy,attr"`
+		IgnoreHeaderLines  int    `xml:"ignoreHeaderLines,attr"`
+		RowType            string `xml:"rowType,attr"`
+		Location           string `xml:"files>location"`
+		ID                 struct {
+			Index int `xml:"index,attr"`
+		} `xml:"id"`
+		Fields []darwinCoreField `xml:"field"`
+	} `xml:"core"`
+}
+
+// darwinCoreField maps a column of the core file to a term
+type darwinCoreField struct {
+	Index int    `xml:"index,attr"`
+	Term  string `xml:"term,attr"`
+}
+
+// WriteDarwinCoreMeta writes the meta.xml descriptor for the occurrence.txt written by an
+// ExportDarwinCore writer
+func WriteDarwinCoreMeta(w io.Writer) error {
+	var meta darwinCoreMeta
+	meta.Xmlns = "http://rs.tdwg.org/dwc/text/"
+	meta.Core.Encoding = "UTF-8"
+	meta.Core.FieldsTerminatedBy = `\t`
+	meta.Core.LinesTerminatedBy = `\n`
+	meta.Core.IgnoreHeaderLines = 1
+	meta.Core.RowType = "http://rs.tdwg.org/dwc/terms/Occurrence"
+	meta.Core.Location = ExportDarwinCore.FileName()
+	for i, term := range darwinCoreTerms {
+		meta.Core.Fields = append(meta.Core.Fields, darwinCoreField{Index: i, Term: term.uri})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(meta); err != nil {
+		return fmt.Errorf("failed to write Darwin Core meta.xml: %w", err)
+	}
+	return enc.Close()
+}
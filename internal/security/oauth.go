@@ -32,11 +32,13 @@ import (
 type AuthCode struct {
 	Code      string
 	ExpiresAt time.Time
+	Username  string // User account the code was issued to, empty for the configured password
 }
 
 type AccessToken struct {
 	Token     string
 	ExpiresAt time.Time
+	Username  string // User account the token was issued to, empty for the configured password
 }
 
 type OAuth2Server struct {
@@ -352,6 +354,19 @@ func (s *OAuth2Server) IsUserAuthenticated(c echo.Context) bool {
 	return false
 }
 
+// IsConfiguredUser reports whether the username belongs to the credentials configured in the settings:
+// the basic auth client ID or a user ID allowed for Google or GitHub login. Access tokens issued
+// for the configured password carry no username, an empty username is a configured user.
+func (s *OAuth2Server) IsConfiguredUser(username string) bool {
+	if username == "" {
+		return true
+	}
+	security := s.Settings.Security
+	return strings.EqualFold(strings.TrimSpace(username), security.BasicAuth.ClientID) ||
+		(security.GoogleAuth.Enabled && isValidUserId(security.GoogleAuth.UserId, username)) ||
+		(security.GithubAuth.Enabled && isValidUserId(security.GithubAuth.UserId, username))
+}
+
 func isValidUserId(configuredIds, providedId string) bool {
 	if configuredIds == "" || providedId == "" {
 		return false
@@ -385,6 +400,12 @@ func isValidUserId(configuredIds, providedId string) bool {
 
 // GenerateAuthCode generates a new authorization code
 func (s *OAuth2Server) GenerateAuthCode() (string, error) {
+	return s.GenerateAuthCodeForUser("")
+}
+
+// GenerateAuthCodeForUser generates a new authorization code for a user account.
+// Access tokens exchanged for the code carry the username.
+func (s *OAuth2Server) GenerateAuthCodeForUser(username string) (string, error) {
 	logger().Debug("Generating new authorization code")
 	code := make([]byte, 32)
 	_, err := rand.Read(code)
@@ -401,6 +422,7 @@ func (s *OAuth2Server) GenerateAuthCode() (string, error) {
 	s.authCodes[authCode] = AuthCode{
 		Code:      authCode,
 		ExpiresAt: expiresAt,
+		Username:  username,
 	}
 	// Do not log the authCode itself
 	logger().Info("Generated and stored new authorization code", "expires_at", expiresAt)
@@ -456,6 +478,7 @@ func (s *OAuth2Server) ExchangeAuthCode(ctx context.Context, code string) (strin
 	s.accessTokens[accessToken] = AccessToken{
 		Token:     accessToken,
 		ExpiresAt: expiresAt,
+		Username:  authCode.Username,
 	}
 
 	// Invalidate the auth code after use
@@ -490,6 +513,22 @@ func (s *OAuth2Server) ValidateAccessToken(token string) error {
 	return nil // Return nil on success
 }
 
+// GetTokenUsername returns the username of the user account a valid access token was issued to.
+// It returns an empty string for tokens issued to the configured password.
+func (s *OAuth2Server) GetTokenUsername(token string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	accessToken, ok := s.accessTokens[token]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	if time.Now().After(accessToken.ExpiresAt) {
+		return "", ErrTokenExpired
+	}
+	return accessToken.Username, nil
+}
+
 // IsAuthenticationEnabled checks if any authentication method is enabled
 func (s *OAuth2Server) IsAuthenticationEnabled(ip string) bool {
 	logger := logger().With("ip", ip)
@@ -155,6 +155,37 @@ func TestOAuth2Server(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "tokens carry the user account of the auth code",
+			test: func(t *testing.T, s *OAuth2Server) {
+				t.Helper()
+				s.Settings = &conf.Settings{
+					Security: conf.Security{
+						BasicAuth: conf.BasicAuth{
+							AuthCodeExp:    10 * time.Minute,
+							AccessTokenExp: 1 * time.Hour,
+						},
+					},
+				}
+
+				code, err := s.GenerateAuthCodeForUser("alice")
+				if err != nil {
+					t.Fatalf("Failed to generate auth code: %v", err)
+				}
+				token, err := s.ExchangeAuthCode(context.Background(), code)
+				if err != nil {
+					t.Fatalf("Failed to exchange auth code: %v", err)
+				}
+
+				username, err := s.GetTokenUsername(token)
+				if err != nil || username != "alice" {
+					t.Errorf("Expected token of alice, got %q, %v", username, err)
+				}
+				if _, err := s.GetTokenUsername("unknown"); err != ErrTokenNotFound {
+					t.Errorf("Expected ErrTokenNotFound, got %v", err)
+				}
+			},
+		},
 		{
 			name: "subnet bypass validation",
 			test: func(t *testing.T, s *OAuth2Server) {
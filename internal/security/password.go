@@ -0,0 +1,34 @@
+package security
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the minimum length of user account passwords
+const MinPasswordLength = 8
+
+// ErrPasswordTooShort is returned when a user account password is shorter than MinPasswordLength
+var ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+
+// HashPassword returns the bcrypt hash of a user account password
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPasswordHash reports whether the password matches the bcrypt hash.
+// An empty hash never matches, accounts without a password only use social login.
+func CheckPasswordHash(hash, password string) bool {
+	if hash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,22 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	t.Parallel()
+
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+	assert.True(t, CheckPasswordHash(hash, "correct horse"))
+	assert.False(t, CheckPasswordHash(hash, "wrong horse"))
+	assert.False(t, CheckPasswordHash("", ""), "accounts without a password cannot log in with one")
+
+	_, err = HashPassword("short")
+	require.ErrorIs(t, err, ErrPasswordTooShort)
+}
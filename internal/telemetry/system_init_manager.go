@@ -149,6 +149,11 @@ func (m *SystemInitManager) initializeNotification() error {
 		}
 		
 		m.logger.Info("notification service initialized successfully", "debug", debug)
+
+		// Push delivery problems must not keep the in-app notifications from working
+		if err := notification.InitializePushDelivery(settings); err != nil {
+			m.logger.Error("failed to initialize push notification delivery", "error", err)
+		}
 	})
 	
 	return m.notificationErr